@@ -0,0 +1,62 @@
+package antispam
+
+// Action is what the bot does to a member caught spamming. Every action
+// deletes the spam; the others act on the member as well. Stored as
+// GuildSettings.AntiSpamAction.
+type Action string
+
+const (
+	ActionDelete     Action = "delete"
+	ActionTimeout    Action = "timeout"
+	ActionKick       Action = "kick"
+	ActionBan        Action = "ban"
+	ActionQuarantine Action = "quarantine"
+)
+
+// Actions lists every action in the order the dashboard offers them.
+var Actions = []Action{ActionDelete, ActionTimeout, ActionKick, ActionBan, ActionQuarantine}
+
+// ParseAction returns the action named s, or ActionTimeout — the
+// behaviour before actions were configurable — if s isn't one.
+func ParseAction(s string) Action {
+	for _, a := range Actions {
+		if string(a) == s {
+			return a
+		}
+	}
+	return ActionTimeout
+}
+
+// Label is the action's name on the dashboard.
+func (a Action) Label() string {
+	switch a {
+	case ActionDelete:
+		return "Delete messages only"
+	case ActionTimeout:
+		return "Delete and time out"
+	case ActionKick:
+		return "Delete and kick"
+	case ActionBan:
+		return "Delete and ban"
+	case ActionQuarantine:
+		return "Delete and assign quarantine role"
+	}
+	return string(a)
+}
+
+// Done completes "has …" in moderator reports, e.g. "been timed out".
+func (a Action) Done() string {
+	switch a {
+	case ActionDelete:
+		return "had their messages deleted"
+	case ActionTimeout:
+		return "been timed out"
+	case ActionKick:
+		return "been kicked"
+	case ActionBan:
+		return "been banned"
+	case ActionQuarantine:
+		return "been quarantined"
+	}
+	return string(a)
+}
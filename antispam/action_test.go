@@ -0,0 +1,15 @@
+package antispam
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAction(t *testing.T) {
+	for _, a := range Actions {
+		assert.Equal(t, a, ParseAction(string(a)))
+	}
+	assert.Equal(t, ActionTimeout, ParseAction(""), "rows saved before actions existed keep timing out")
+	assert.Equal(t, ActionTimeout, ParseAction("explode"))
+}
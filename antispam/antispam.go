@@ -0,0 +1,116 @@
+// Package antispam scores messages for spam. Each Rule looks at one
+// message, and the author's recent messages, and returns the points it adds
+// to the author's spam score; the gateway listener keeps the running score
+// per author and acts once it reaches the guild's AntiSpamCount.
+//
+// Rules are independent: adding a detector means implementing Rule and
+// appending it to Rules, plus whatever GuildSettings columns it reads.
+package antispam
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// Message is the part of a Discord message the rules look at. The listener
+// keeps a short history of these per author.
+type Message struct {
+	MessageID snowflake.ID
+	ChannelID snowflake.ID
+	Content   string
+	// Attachments describes each attachment as "name WxH size", so
+	// re-uploads of the same file compare as similar.
+	Attachments []string
+	// Mentions counts distinct user and role mentions, plus one for
+	// @everyone/@here.
+	Mentions int
+}
+
+// NewMessage captures the parts of m the rules need.
+func NewMessage(m discord.Message) *Message {
+	msg := &Message{
+		MessageID: m.ID,
+		ChannelID: m.ChannelID,
+		Content:   m.Content,
+		Mentions:  countMentions(m),
+	}
+	for _, att := range m.Attachments {
+		width := 0
+		height := 0
+		if att.Width != nil {
+			width = *att.Width
+		}
+		if att.Height != nil {
+			height = *att.Height
+		}
+		msg.Attachments = append(msg.Attachments, fmt.Sprintf("%s %dx%d %d", att.Filename, width, height, att.Size))
+	}
+	return msg
+}
+
+// Summary is the message content followed by its attachments, as shown in
+// moderator reports.
+func (m *Message) Summary() string {
+	if len(m.Attachments) == 0 {
+		return m.Content
+	}
+	return fmt.Sprintf("%s\nAttachments:\n%s", m.Content, strings.Join(m.Attachments, "\n"))
+}
+
+func countMentions(m discord.Message) int {
+	seen := make(map[snowflake.ID]struct{}, len(m.Mentions))
+	for _, u := range m.Mentions {
+		seen[u.ID] = struct{}{}
+	}
+	for _, r := range m.MentionRoles {
+		seen[r] = struct{}{}
+	}
+	n := len(seen)
+	if m.MentionEveryone {
+		n++
+	}
+	return n
+}
+
+// Rule is a single spam detector.
+type Rule interface {
+	// Name identifies the rule in moderator reports.
+	Name() string
+	// Score returns the points m adds to its author's spam score, or 0
+	// if the rule doesn't match or is turned off for the guild. recent
+	// holds the author's earlier messages, oldest first.
+	Score(m *Message, recent []*Message, settings *model.GuildSettings) int
+}
+
+// Rules are evaluated in order for every message.
+var Rules = []Rule{
+	similarRule{},
+	burstRule{},
+	mentionRule{},
+	inviteRule{},
+	mediaRule{},
+	zalgoRule{},
+	phishingRule{},
+}
+
+// Hit is a rule that matched a message.
+type Hit struct {
+	Rule   string
+	Points int
+}
+
+// Evaluate runs every rule against m and returns the ones that matched.
+func Evaluate(m *Message, recent []*Message, settings *model.GuildSettings) []Hit {
+	var hits []Hit
+	for _, rule := range Rules {
+		if points := rule.Score(m, recent, settings); points > 0 {
+			hits = append(hits, Hit{Rule: rule.Name(), Points: points})
+		}
+	}
+	return hits
+}
@@ -0,0 +1,270 @@
+package antispam
+
+import (
+	"hash/maphash"
+	"maps"
+	"math"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// maxCampaignsPerGuild caps how many distinct messages a guild's tracker
+// remembers. Busy guilds post far more unique messages than this within a
+// window; the oldest are forgotten first.
+const maxCampaignsPerGuild = 500
+
+// maxFingerprintLength caps how many runes of a message are compared, so
+// a wall of text can't make each comparison arbitrarily expensive.
+const maxFingerprintLength = 500
+
+// Post is one message seen by the CoordinatedTracker.
+type Post struct {
+	AuthorID   snowflake.ID
+	AuthorName string
+	Message    *Message
+}
+
+// Wave is the result of a post that belongs to a coordinated campaign: the
+// same or similar content from enough distinct authors within the window.
+type Wave struct {
+	// Sample is the first message of the campaign, for the report.
+	Sample string
+	// Messages holds the campaign's posts that haven't been deleted yet.
+	// The first wave of a campaign holds every post so far; later ones
+	// hold only the post that was just added.
+	Messages []Post
+	// NewAuthors holds the first post of each author in Messages who
+	// hasn't been acted on yet.
+	NewAuthors []Post
+	// Authors holds the first post of every author acted on in this
+	// campaign, NewAuthors included, in the order they posted.
+	Authors []Post
+	// ReportChannelID and ReportMessageID identify the moderator report
+	// for the campaign, if one has been sent; see SetReport.
+	ReportChannelID snowflake.ID
+	ReportMessageID snowflake.ID
+	// Failed holds why acting on an author in Authors failed, for those
+	// it failed for; see SetFailed.
+	Failed map[snowflake.ID]string
+
+	campaign *campaign
+}
+
+type campaign struct {
+	fingerprint string
+	hash        uint64
+	length      int
+	sample      string
+	posts       []Post
+	lastSeen    time.Time
+	triggered   bool
+	handled     map[snowflake.ID]struct{}
+	acted       []Post
+	reportCh    snowflake.ID
+	reportMsg   snowflake.ID
+	failed      map[snowflake.ID]string
+}
+
+// CoordinatedTracker groups messages across all authors in a guild by
+// content, to catch many accounts posting the same message once each. The
+// per-author score in the listener misses that entirely.
+type CoordinatedTracker struct {
+	mu     sync.Mutex
+	guilds map[snowflake.ID]*guildCampaigns
+}
+
+// guildCampaigns holds a guild's campaigns oldest first, indexed by the
+// hash of their fingerprint so exact repeats skip the similarity scan.
+type guildCampaigns struct {
+	list   []*campaign
+	byHash map[uint64]*campaign
+}
+
+var fingerprintSeed = maphash.MakeSeed()
+
+func NewCoordinatedTracker() *CoordinatedTracker {
+	return &CoordinatedTracker{guilds: make(map[snowflake.ID]*guildCampaigns)}
+}
+
+// Add records post and returns a Wave once its content has been posted by
+// at least minAuthors distinct authors within window, or nil. Messages too
+// short to fingerprint meaningfully are ignored.
+func (t *CoordinatedTracker) Add(guildID snowflake.ID, post Post, now time.Time, window time.Duration, minAuthors int) *Wave {
+	fp := fingerprint(post.Message)
+	length := utf8.RuneCountInString(fp)
+	if length < minMessageLength {
+		return nil
+	}
+	hash := maphash.String(fingerprintSeed, fp)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	g := t.guilds[guildID]
+	if g == nil {
+		g = &guildCampaigns{byHash: make(map[uint64]*campaign)}
+		t.guilds[guildID] = g
+	}
+	g.prune(now, window)
+
+	c := g.match(fp, hash, length)
+	if c == nil {
+		c = &campaign{
+			fingerprint: fp,
+			hash:        hash,
+			length:      length,
+			sample:      post.Message.Summary(),
+			handled:     make(map[snowflake.ID]struct{}),
+		}
+		if len(g.list) >= maxCampaignsPerGuild {
+			g.forget(g.list[0])
+			g.list = g.list[1:]
+		}
+		g.list = append(g.list, c)
+		if _, taken := g.byHash[hash]; !taken {
+			g.byHash[hash] = c
+		}
+	}
+	c.posts = append(c.posts, post)
+	c.lastSeen = now
+
+	if !c.triggered && distinctAuthors(c.posts) < minAuthors {
+		return nil
+	}
+	c.triggered = true
+
+	w := &Wave{
+		Sample:          c.sample,
+		Messages:        c.posts,
+		ReportChannelID: c.reportCh,
+		ReportMessageID: c.reportMsg,
+		Failed:          maps.Clone(c.failed),
+		campaign:        c,
+	}
+	for _, p := range c.posts {
+		if _, ok := c.handled[p.AuthorID]; ok {
+			continue
+		}
+		c.handled[p.AuthorID] = struct{}{}
+		c.acted = append(c.acted, p)
+		w.NewAuthors = append(w.NewAuthors, p)
+	}
+	w.Authors = append([]Post(nil), c.acted...)
+	// Posts that have been handed out for deletion don't need to be
+	// remembered; the campaign itself stays so latecomers still match.
+	c.posts = nil
+
+	return w
+}
+
+// SetReport remembers the moderator report sent for w's campaign, so later
+// waves of the same campaign can update it instead of sending another.
+func (t *CoordinatedTracker) SetReport(w *Wave, channelID, messageID snowflake.ID) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	w.campaign.reportCh = channelID
+	w.campaign.reportMsg = messageID
+}
+
+// SetFailed records why acting on authorID in w's campaign failed, in w
+// and for later waves of the campaign, so an updated report still says so.
+func (t *CoordinatedTracker) SetFailed(w *Wave, authorID snowflake.ID, reason string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if w.campaign.failed == nil {
+		w.campaign.failed = make(map[snowflake.ID]string)
+	}
+	w.campaign.failed[authorID] = reason
+	if w.Failed == nil {
+		w.Failed = make(map[snowflake.ID]string)
+	}
+	w.Failed[authorID] = reason
+}
+
+// DeleteExpired forgets campaigns that have been quiet for longer than
+// window, for guilds that haven't posted since.
+func (t *CoordinatedTracker) DeleteExpired(now time.Time, window time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for guildID, g := range t.guilds {
+		g.prune(now, window)
+		if len(g.list) == 0 {
+			delete(t.guilds, guildID)
+		}
+	}
+}
+
+// match returns the campaign fp belongs to, or nil. An exact repeat is
+// found by hash; otherwise only campaigns whose length is close enough to
+// fall within the similarity threshold are compared.
+func (g *guildCampaigns) match(fp string, hash uint64, length int) *campaign {
+	if c, ok := g.byHash[hash]; ok && c.fingerprint == fp {
+		return c
+	}
+	maxDistance := int(math.Ceil(float64(length) * maxLevenshteinDistancePercent / 100))
+	for _, c := range g.list {
+		if c.length < length-maxDistance || c.length > length+maxDistance {
+			continue
+		}
+		if c.fingerprint == fp || similarContent(fp, c.fingerprint) {
+			return c
+		}
+	}
+	return nil
+}
+
+// forget removes c from the hash index, if it is the campaign indexed
+// under its hash.
+func (g *guildCampaigns) forget(c *campaign) {
+	if g.byHash[c.hash] == c {
+		delete(g.byHash, c.hash)
+	}
+}
+
+// prune drops campaigns whose last post is older than window and, for
+// campaigns still collecting authors, the individual posts that have
+// fallen out of it.
+func (g *guildCampaigns) prune(now time.Time, window time.Duration) {
+	cutoff := now.Add(-window)
+	kept := g.list[:0]
+	for _, c := range g.list {
+		if c.lastSeen.Before(cutoff) {
+			g.forget(c)
+			continue
+		}
+		if !c.triggered {
+			posts := c.posts[:0]
+			for _, p := range c.posts {
+				if p.Message.MessageID.Time().After(cutoff) {
+					posts = append(posts, p)
+				}
+			}
+			c.posts = posts
+		}
+		kept = append(kept, c)
+	}
+	g.list = kept
+}
+
+func distinctAuthors(posts []Post) int {
+	seen := make(map[snowflake.ID]struct{}, len(posts))
+	for _, p := range posts {
+		seen[p.AuthorID] = struct{}{}
+	}
+	return len(seen)
+}
+
+// fingerprint normalises a message for comparison across authors: case and
+// whitespace are ignored, and only the first maxFingerprintLength runes
+// are kept.
+func fingerprint(m *Message) string {
+	fp := strings.ToLower(whitespaceReplacer.Replace(m.Summary()))
+	if utf8.RuneCountInString(fp) > maxFingerprintLength {
+		fp = string([]rune(fp)[:maxFingerprintLength])
+	}
+	return fp
+}
@@ -0,0 +1,119 @@
+package antispam
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const scam = "Free nitro for everyone! Claim it at example.com/nitro"
+
+func postAt(author snowflake.ID, offset time.Duration, content string) Post {
+	return Post{AuthorID: author, AuthorName: "user", Message: messageAt(offset, content)}
+}
+
+func authorIDs(posts []Post) []snowflake.ID {
+	var ids []snowflake.ID
+	for _, p := range posts {
+		ids = append(ids, p.AuthorID)
+	}
+	return ids
+}
+
+func TestCoordinatedTracker_Wave(t *testing.T) {
+	tracker := NewCoordinatedTracker()
+	add := func(p Post) *Wave {
+		return tracker.Add(1, p, p.Message.MessageID.Time(), time.Minute, 3)
+	}
+
+	assert.Nil(t, add(postAt(10, 0, scam)))
+	assert.Nil(t, add(postAt(10, time.Second, scam)), "the same author twice is still one author")
+	assert.Nil(t, add(postAt(11, 2*time.Second, "FREE NITRO for everyone!  Claim it at example.com/nitro")))
+
+	wave := add(postAt(12, 3*time.Second, scam))
+	require.NotNil(t, wave)
+	assert.Equal(t, []snowflake.ID{10, 11, 12}, authorIDs(wave.NewAuthors))
+	assert.Equal(t, []snowflake.ID{10, 11, 12}, authorIDs(wave.Authors))
+	assert.Len(t, wave.Messages, 4, "every message is deleted, including repeats")
+	assert.Equal(t, scam, wave.Sample)
+	assert.Zero(t, wave.ReportMessageID)
+
+	tracker.SetReport(wave, 50, 51)
+	tracker.SetFailed(wave, 11, "Discord rejected the request")
+	assert.Equal(t, map[snowflake.ID]string{11: "Discord rejected the request"}, wave.Failed)
+
+	late := add(postAt(13, 10*time.Second, scam))
+	require.NotNil(t, late, "latecomers are acted on without reaching the threshold again")
+	assert.Equal(t, []snowflake.ID{13}, authorIDs(late.NewAuthors))
+	assert.Equal(t, []snowflake.ID{10, 11, 12, 13}, authorIDs(late.Authors))
+	assert.Len(t, late.Messages, 1)
+	assert.Equal(t, snowflake.ID(51), late.ReportMessageID, "latecomers update the existing report")
+	assert.Equal(t, map[snowflake.ID]string{11: "Discord rejected the request"}, late.Failed,
+		"the updated report still names earlier failures")
+
+	again := add(postAt(10, 11*time.Second, scam))
+	require.NotNil(t, again)
+	assert.Empty(t, again.NewAuthors, "an author is only acted on once")
+	assert.Len(t, again.Messages, 1, "but their new message is still deleted")
+}
+
+func TestCoordinatedTracker_Window(t *testing.T) {
+	tracker := NewCoordinatedTracker()
+	add := func(p Post) *Wave {
+		return tracker.Add(1, p, p.Message.MessageID.Time(), 30*time.Second, 3)
+	}
+
+	assert.Nil(t, add(postAt(10, 0, scam)))
+	assert.Nil(t, add(postAt(11, 20*time.Second, scam)))
+	assert.Nil(t, add(postAt(12, 40*time.Second, scam)), "the first post fell out of the window")
+	assert.NotNil(t, add(postAt(13, 45*time.Second, scam)))
+}
+
+func TestCoordinatedTracker_Isolation(t *testing.T) {
+	tracker := NewCoordinatedTracker()
+	now := testNow
+
+	assert.Nil(t, tracker.Add(1, postAt(10, 0, scam), now, time.Minute, 2))
+	assert.Nil(t, tracker.Add(2, postAt(11, 0, scam), now, time.Minute, 2), "guilds are tracked separately")
+	assert.Nil(t, tracker.Add(1, postAt(11, 0, "something else entirely, nothing to see"), now, time.Minute, 2),
+		"different content is a different campaign")
+	assert.Nil(t, tracker.Add(1, postAt(12, 0, "lol"), now, time.Minute, 1), "short messages are ignored")
+}
+
+func TestCoordinatedTracker_DeleteExpired(t *testing.T) {
+	tracker := NewCoordinatedTracker()
+	tracker.Add(1, postAt(10, 0, scam), testNow, time.Minute, 3)
+	require.Len(t, tracker.guilds, 1)
+
+	tracker.DeleteExpired(testNow.Add(30*time.Second), time.Minute)
+	assert.Len(t, tracker.guilds, 1)
+
+	tracker.DeleteExpired(testNow.Add(2*time.Minute), time.Minute)
+	assert.Empty(t, tracker.guilds)
+}
+
+func TestCoordinatedTracker_Index(t *testing.T) {
+	tracker := NewCoordinatedTracker()
+	long := strings.Repeat("buy cheap followers now ", 40)
+	assert.Nil(t, tracker.Add(1, postAt(10, 0, long+"one"), testNow, time.Minute, 2))
+	assert.NotNil(t, tracker.Add(1, postAt(11, 0, long+"two"), testNow, time.Minute, 2),
+		"only the start of a long message is compared")
+
+	g := tracker.guilds[1]
+	require.Len(t, g.list, 1)
+	assert.LessOrEqual(t, g.list[0].length, maxFingerprintLength)
+
+	for i := range maxCampaignsPerGuild {
+		tracker.Add(1, postAt(12, 0, strings.Repeat(fmt.Sprintf("%d:", i), 10)), testNow, time.Minute, 2)
+	}
+	assert.Len(t, g.list, maxCampaignsPerGuild)
+	assert.Len(t, g.byHash, maxCampaignsPerGuild, "evicted campaigns leave the index")
+
+	tracker.DeleteExpired(testNow.Add(2*time.Minute), time.Minute)
+	assert.Empty(t, g.byHash)
+}
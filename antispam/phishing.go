@@ -0,0 +1,287 @@
+package antispam
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"iter"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/idna"
+)
+
+// Blocklist is a set of scam and phishing domains loaded from a file, or
+// every file in a directory. A domain on the list also blocks its
+// subdomains.
+//
+// Files hold one domain per line. Blank lines, "#" comments and
+// hosts-file lines ("0.0.0.0 example.com") are accepted, so most published
+// lists can be used as downloaded.
+type Blocklist struct {
+	mu      sync.RWMutex
+	path    string
+	stamp   string
+	domains map[string]struct{}
+	// tlds holds the last label of every domain, for HasTLD.
+	tlds map[string]struct{}
+}
+
+// PhishingBlocklist is the bot-wide blocklist, configured with
+// antispam.phishing_blocklist.
+var PhishingBlocklist = &Blocklist{}
+
+// SetPath points the blocklist at a file or directory and loads it.
+// An empty path disables the blocklist.
+func (b *Blocklist) SetPath(path string) error {
+	b.mu.Lock()
+	b.path = path
+	b.stamp = ""
+	b.domains = nil
+	b.tlds = nil
+	b.mu.Unlock()
+	_, err := b.Reload()
+	return err
+}
+
+// Reload rereads the blocklist if any of its files changed since the last
+// load, and reports whether it did. On error the previous domains are
+// kept, so a half-written file doesn't empty the list.
+func (b *Blocklist) Reload() (bool, error) {
+	b.mu.RLock()
+	path, prevStamp := b.path, b.stamp
+	b.mu.RUnlock()
+	if path == "" {
+		return false, nil
+	}
+
+	files, stamp, err := blocklistFiles(path)
+	if err != nil {
+		return false, err
+	}
+	if stamp == prevStamp {
+		return false, nil
+	}
+
+	domains := map[string]struct{}{}
+	for _, f := range files {
+		if err := readBlocklistFile(f, domains); err != nil {
+			return false, err
+		}
+	}
+
+	tlds := map[string]struct{}{}
+	for d := range domains {
+		tlds[d[strings.LastIndex(d, ".")+1:]] = struct{}{}
+	}
+
+	b.mu.Lock()
+	b.stamp = stamp
+	b.domains = domains
+	b.tlds = tlds
+	b.mu.Unlock()
+	slog.Info("Loaded phishing blocklist.", "path", path, "files", len(files), "domains", len(domains))
+	return true, nil
+}
+
+// Len is the number of domains on the list.
+func (b *Blocklist) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.domains)
+}
+
+// Contains reports whether host or one of its parent domains is on the
+// list. host must be normalized with NormalizeDomain.
+func (b *Blocklist) Contains(host string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.domains) == 0 {
+		return false
+	}
+	for d := range parentDomains(host) {
+		if _, ok := b.domains[d]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTLD reports whether any domain on the list ends in tld, given
+// without the leading dot and normalized like the domains.
+func (b *Blocklist) HasTLD(tld string) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.tlds[tld]
+	return ok
+}
+
+// blocklistFiles lists the files making up the blocklist at path, with a
+// stamp of their names, sizes and modification times for change
+// detection. Dotfiles in a directory are skipped.
+func blocklistFiles(path string) ([]string, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", err
+	}
+	if !info.IsDir() {
+		return []string{path}, fileStamp(path, info), nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, "", err
+	}
+	var files []string
+	var stamp strings.Builder
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, "", err
+		}
+		f := filepath.Join(path, e.Name())
+		files = append(files, f)
+		stamp.WriteString(fileStamp(f, info))
+	}
+	return files, stamp.String(), nil
+}
+
+func fileStamp(path string, info fs.FileInfo) string {
+	return fmt.Sprintf("%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+}
+
+func readBlocklistFile(path string, domains map[string]struct{}) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		// Hosts-file lines put the domain last.
+		if d, ok := NormalizeDomain(fields[len(fields)-1]); ok {
+			domains[d] = struct{}{}
+		}
+	}
+	return scanner.Err()
+}
+
+// NormalizeDomain lowercases a domain and converts it to its ASCII
+// (punycode) form, so "exämple.com" and "xn--exmple-cua.com" compare
+// equal. A leading scheme, "*." or "www." and anything after the host are
+// dropped. Returns false if s is not a domain name.
+func NormalizeDomain(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "://"); i >= 0 {
+		s = s[i+3:]
+	}
+	if i := strings.IndexAny(s, "/?#"); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.LastIndex(s, "@"); i >= 0 {
+		s = s[i+1:]
+	}
+	if host, _, ok := strings.Cut(s, ":"); ok {
+		s = host
+	}
+	s = strings.TrimPrefix(s, "*.")
+	s = strings.TrimSuffix(s, ".")
+
+	ascii, err := idna.Lookup.ToASCII(s)
+	ascii = strings.TrimPrefix(ascii, "www.")
+	if err != nil || !strings.Contains(ascii, ".") {
+		return "", false
+	}
+	return ascii, true
+}
+
+// parentDomains yields host and each of its parent domains down to the
+// last two labels: a.b.example.com, b.example.com, example.com.
+func parentDomains(host string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for {
+			if !yield(host) {
+				return
+			}
+			_, rest, ok := strings.Cut(host, ".")
+			if !ok || !strings.Contains(rest, ".") {
+				return
+			}
+			host = rest
+		}
+	}
+}
+
+// DomainMatches reports whether host or one of its parent domains is in
+// domains. host must be normalized with NormalizeDomain.
+func DomainMatches(host string, domains []string) bool {
+	for d := range parentDomains(host) {
+		if slices.Contains(domains, d) {
+			return true
+		}
+	}
+	return false
+}
+
+// linkRe finds URLs with a scheme, and bare domains with a path (which
+// Discord doesn't link, but scammers post anyway hoping they're copied).
+var linkRe = regexp.MustCompile(`(?i)\bhttps?://[^\s<>()\[\]"']+|(?:[\pL\pN-]+\.)+[\pL]{2,}/[^\s<>()\[\]"']*`)
+
+// bareDomainRe finds bare domains without a path. Ordinary text is full of
+// those lookalikes ("e.g.", "file.txt"), so LinkDomains leaves them out
+// and BareDomains only keeps the ones the caller asks for.
+var bareDomainRe = regexp.MustCompile(`(?i)\b(?:[\pL\pN-]+\.)+[\pL]{2,}\b`)
+
+// LinkDomains returns the normalized domains of the links in content,
+// without duplicates.
+func LinkDomains(content string) []string {
+	var domains []string
+	for _, link := range linkRe.FindAllString(content, -1) {
+		// Punctuation ending a sentence isn't part of the link.
+		raw := strings.TrimRight(link, ".,;:!?")
+		if !strings.Contains(raw, "://") {
+			raw = "http://" + raw
+		}
+		u, err := url.Parse(raw)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		d, ok := NormalizeDomain(u.Hostname())
+		if ok && !slices.Contains(domains, d) {
+			domains = append(domains, d)
+		}
+	}
+	return domains
+}
+
+// BareDomains returns the normalized domains written in content without a
+// scheme or path, without duplicates, keeping only those whose TLD keep
+// accepts, e.g. PhishingBlocklist.HasTLD.
+func BareDomains(content string, keep func(tld string) bool) []string {
+	var domains []string
+	for _, raw := range bareDomainRe.FindAllString(content, -1) {
+		d, ok := NormalizeDomain(raw)
+		if !ok || slices.Contains(domains, d) {
+			continue
+		}
+		if keep(d[strings.LastIndex(d, ".")+1:]) {
+			domains = append(domains, d)
+		}
+	}
+	return domains
+}
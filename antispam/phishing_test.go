@@ -0,0 +1,102 @@
+package antispam
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeDomain(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"Example.COM", "example.com", true},
+		{"https://www.example.com:8443/login?x=1", "example.com", true},
+		{"*.example.com", "example.com", true},
+		{"user@example.com", "example.com", true},
+		{"exämple.com", "xn--exmple-cua.com", true},
+		{"xn--exmple-cua.com", "xn--exmple-cua.com", true},
+		{"localhost", "", false},
+		{"", "", false},
+	}
+	for _, c := range cases {
+		got, ok := NormalizeDomain(c.in)
+		assert.Equal(t, c.ok, ok, c.in)
+		assert.Equal(t, c.want, got, c.in)
+	}
+}
+
+func TestLinkDomains(t *testing.T) {
+	content := "free nitro https://Discord-Gift.example.com/claim and http://exämple.com, " +
+		"also steamcommunity.ru/trade and again https://discord-gift.example.com/x — see example.org too"
+	assert.Equal(t, []string{"discord-gift.example.com", "xn--exmple-cua.com", "steamcommunity.ru"}, LinkDomains(content))
+	assert.Empty(t, LinkDomains("no links, just a sentence. With dots."))
+}
+
+func TestBareDomains(t *testing.T) {
+	content := "claim at Steam-Gift.ru or steam-gift.ru, see example.org and https://x.ru/path, e.g. file.txt"
+	onlyRU := func(tld string) bool { return tld == "ru" }
+	assert.Equal(t, []string{"steam-gift.ru", "x.ru"}, BareDomains(content, onlyRU))
+	assert.Empty(t, BareDomains("no domains. Just text.", func(string) bool { return true }))
+}
+
+func TestDomainMatches(t *testing.T) {
+	assert.True(t, DomainMatches("example.com", []string{"example.com"}))
+	assert.True(t, DomainMatches("a.b.example.com", []string{"example.com"}))
+	assert.False(t, DomainMatches("notexample.com", []string{"example.com"}))
+	assert.False(t, DomainMatches("example.com", []string{"a.example.com"}))
+}
+
+func TestBlocklist_LoadAndReload(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("list.txt", "# scam domains\nscam.example\n\n0.0.0.0 hosts.example # hosts format\n")
+	write(".hidden", "hidden.example\n")
+
+	var b Blocklist
+	require.NoError(t, b.SetPath(dir))
+	assert.Equal(t, 2, b.Len())
+	assert.True(t, b.Contains("scam.example"))
+	assert.True(t, b.Contains("login.scam.example"), "subdomains are blocked")
+	assert.True(t, b.Contains("hosts.example"))
+	assert.False(t, b.Contains("hidden.example"), "dotfiles are skipped")
+	assert.True(t, b.HasTLD("example"))
+	assert.False(t, b.HasTLD("com"))
+
+	changed, err := b.Reload()
+	require.NoError(t, err)
+	assert.False(t, changed, "unchanged files aren't reread")
+
+	write("more.txt", "пример.example\n")
+	changed, err = b.Reload()
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.True(t, b.Contains("xn--e1afmkfd.example"))
+
+	// A file that vanishes keeps the previous list.
+	require.NoError(t, os.Remove(filepath.Join(dir, "list.txt")))
+	_, err = b.Reload()
+	require.NoError(t, err)
+	assert.False(t, b.Contains("scam.example"))
+
+	assert.Error(t, b.SetPath(filepath.Join(dir, "missing")))
+	assert.Zero(t, b.Len(), "a new path drops the old domains")
+	assert.False(t, b.HasTLD("example"))
+}
+
+func TestPhishingDomain(t *testing.T) {
+	var list Blocklist
+	list.domains = map[string]struct{}{"scam.example": {}}
+
+	assert.Equal(t, "scam.example", phishingDomain([]string{"ok.example", "scam.example"}, nil, nil, &list))
+	assert.Equal(t, "a.evil.example", phishingDomain([]string{"a.evil.example"}, nil, []string{"evil.example"}, &list))
+	assert.Empty(t, phishingDomain([]string{"safe.scam.example"}, []string{"safe.scam.example"}, nil, &list), "allowed domains win")
+	assert.Empty(t, phishingDomain([]string{"ok.example"}, nil, nil, &list))
+}
@@ -0,0 +1,268 @@
+package antispam
+
+import (
+	"log/slog"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/agnivade/levenshtein"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// Points each rule adds to the spam score when it matches. A repeated
+// message is the baseline; rules that catch something no legitimate
+// message does on its own are worth more.
+const (
+	similarPoints = 1
+	burstPoints   = 1
+	mentionPoints = 3
+	invitePoints  = 2
+	mediaPoints   = 2
+	zalgoPoints   = 2
+)
+
+const minMessageLength = 10
+const maxLevenshteinDistancePercent = 5
+
+var whitespaceReplacer = strings.NewReplacer(
+	" ", "",
+	"\u00A0", "",
+	"\u202F", "",
+	"\t", "",
+	"\u200b", "",
+)
+
+// similarRule matches a message that is nearly identical to one of the
+// author's recent messages, in any channel. It is always on when anti-spam
+// is enabled.
+type similarRule struct{}
+
+func (similarRule) Name() string { return "repeated messages" }
+
+func (similarRule) Score(m *Message, recent []*Message, _ *model.GuildSettings) int {
+	if len(recent) == 0 {
+		return 0
+	}
+
+	slog.Debug("Comparing message to previous messages.", "current_message", m.Content, "previous_messages_count", len(recent))
+
+	// Remove all whitespace from the message content
+	currentMessage := whitespaceReplacer.Replace(m.Summary())
+	if utf8.RuneCountInString(currentMessage) < minMessageLength {
+		return 0
+	}
+
+	for _, prev := range recent {
+		prevMessage := whitespaceReplacer.Replace(prev.Summary())
+
+		if similarContent(currentMessage, prevMessage) {
+			slog.Info("Found similar message.", "current_message", m.Content, "previous_message", prev.Content)
+			return similarPoints
+		}
+		slog.Debug("Messages are not similar enough.", "current_message", m.Content, "previous_message", prev.Content)
+	}
+	return 0
+}
+
+// similarContent reports whether two whitespace-stripped messages are
+// within maxLevenshteinDistancePercent of each other.
+func similarContent(current, prev string) bool {
+	distance := levenshtein.ComputeDistance(current, prev)
+	messageLength := float64(utf8.RuneCountInString(current))
+	maxLevenshteinDistance := int(math.Ceil(messageLength * maxLevenshteinDistancePercent / 100))
+	return distance <= maxLevenshteinDistance
+}
+
+// burstRule matches every message past AntiSpamBurstCount sent within
+// AntiSpamBurstSeconds, whatever the content.
+type burstRule struct{}
+
+func (burstRule) Name() string { return "message burst" }
+
+func (burstRule) Score(m *Message, recent []*Message, s *model.GuildSettings) int {
+	if !s.AntiSpamBurstEnabled || s.AntiSpamBurstCount <= 0 {
+		return 0
+	}
+	since := m.MessageID.Time().Add(-time.Duration(s.AntiSpamBurstSeconds) * time.Second)
+	count := 1
+	for _, prev := range recent {
+		if prev.MessageID.Time().After(since) {
+			count++
+		}
+	}
+	if count > s.AntiSpamBurstCount {
+		return burstPoints
+	}
+	return 0
+}
+
+// mentionRule matches a message that mentions more than AntiSpamMaxMentions
+// users and roles.
+type mentionRule struct{}
+
+func (mentionRule) Name() string { return "mention flood" }
+
+func (mentionRule) Score(m *Message, _ []*Message, s *model.GuildSettings) int {
+	if s.AntiSpamMentionsEnabled && m.Mentions > s.AntiSpamMaxMentions {
+		return mentionPoints
+	}
+	return 0
+}
+
+var inviteLinkRe = regexp.MustCompile(`(?i)\b(?:discord(?:app)?\.com/invite|discord\.gg)/[a-z0-9-]+`)
+
+// inviteRule matches a message with more than AntiSpamMaxInvites Discord
+// invite links.
+type inviteRule struct{}
+
+func (inviteRule) Name() string { return "invite links" }
+
+func (inviteRule) Score(m *Message, _ []*Message, s *model.GuildSettings) int {
+	if !s.AntiSpamInvitesEnabled {
+		return 0
+	}
+	if len(inviteLinkRe.FindAllStringIndex(m.Content, -1)) > s.AntiSpamMaxInvites {
+		return invitePoints
+	}
+	return 0
+}
+
+// mediaRule matches a message with more than AntiSpamMaxAttachments
+// attachments or more than AntiSpamMaxEmoji emoji.
+type mediaRule struct{}
+
+func (mediaRule) Name() string { return "attachments/emoji" }
+
+func (mediaRule) Score(m *Message, _ []*Message, s *model.GuildSettings) int {
+	if !s.AntiSpamMediaEnabled {
+		return 0
+	}
+	if len(m.Attachments) > s.AntiSpamMaxAttachments || countEmoji(m.Content) > s.AntiSpamMaxEmoji {
+		return mediaPoints
+	}
+	return 0
+}
+
+var customEmojiRe = regexp.MustCompile(`<a?:\w{2,32}:\d{17,20}>`)
+
+// countEmoji counts custom emoji and Unicode emoji in s. ZWJ sequences,
+// skin tones and flags count as one emoji each, as Discord renders them.
+func countEmoji(s string) int {
+	n := len(customEmojiRe.FindAllStringIndex(s, -1))
+	s = customEmojiRe.ReplaceAllString(s, "")
+
+	joined := false
+	halfFlag := false
+	for _, r := range s {
+		switch {
+		case r == '\u200d':
+			joined = true
+			continue
+		case isEmojiModifier(r):
+			continue
+		case r >= 0x1F1E6 && r <= 0x1F1FF:
+			// Regional indicators pair up into a flag.
+			if !halfFlag {
+				n++
+			}
+			halfFlag = !halfFlag
+			joined = false
+			continue
+		case isEmoji(r) && !joined:
+			n++
+		}
+		joined = false
+		halfFlag = false
+	}
+	return n
+}
+
+func isEmoji(r rune) bool {
+	return (r >= 0x1F000 && r <= 0x1FAFF) ||
+		(r >= 0x2600 && r <= 0x27BF) ||
+		(r >= 0x2300 && r <= 0x23FF) ||
+		(r >= 0x2B00 && r <= 0x2BFF)
+}
+
+// isEmojiModifier reports variation selectors, skin tones and the keycap
+// mark, which modify the emoji before them rather than adding one.
+func isEmojiModifier(r rune) bool {
+	return r == '\ufe0f' || r == '\ufe0e' || r == '\u20e3' || (r >= 0x1F3FB && r <= 0x1F3FF)
+}
+
+// zalgoRule matches text with more than AntiSpamMaxCombiningMarks
+// combining or invisible characters stacked on a single character.
+type zalgoRule struct{}
+
+func (zalgoRule) Name() string { return "zalgo text" }
+
+func (zalgoRule) Score(m *Message, _ []*Message, s *model.GuildSettings) int {
+	if s.AntiSpamZalgoEnabled && longestMarkRun(m.Content) > s.AntiSpamMaxCombiningMarks {
+		return zalgoPoints
+	}
+	return 0
+}
+
+// longestMarkRun returns the longest run of consecutive combining marks
+// and invisible format characters in s. The zero-width joiner is left out
+// so emoji sequences don't count.
+func longestMarkRun(s string) int {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r != '\u200d' && unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return longest
+}
+
+// phishingRule matches a message linking to a domain on PhishingBlocklist
+// or the guild's deny list, unless the guild allows it. Bare domains
+// ("scam.example" with no scheme or path) count too when the blocklist
+// has domains with the same TLD. One link is enough: the rule scores
+// AntiSpamCount points so the guild's action applies straight away.
+type phishingRule struct{}
+
+func (phishingRule) Name() string { return "scam link" }
+
+func (phishingRule) Score(m *Message, _ []*Message, s *model.GuildSettings) int {
+	if !s.AntiSpamPhishingEnabled {
+		return 0
+	}
+	domains := append(LinkDomains(m.Content), BareDomains(m.Content, PhishingBlocklist.HasTLD)...)
+	if len(domains) == 0 {
+		return 0
+	}
+	allow, deny, err := model.GetPhishingDomains(s.GuildID)
+	if err != nil {
+		slog.Error("Failed to load phishing domains.", "err", err, "guild_id", s.GuildID)
+	}
+	domain := phishingDomain(domains, allow, deny, PhishingBlocklist)
+	if domain == "" {
+		return 0
+	}
+	slog.Info("Found scam link.", "domain", domain, "guild_id", s.GuildID, "message", m.MessageID)
+	return max(s.AntiSpamCount, 1)
+}
+
+// phishingDomain returns the first of domains that isn't allowed and is
+// either denied or on list, or "" if there is none.
+func phishingDomain(domains, allow, deny []string, list *Blocklist) string {
+	for _, d := range domains {
+		if DomainMatches(d, allow) {
+			continue
+		}
+		if DomainMatches(d, deny) || list.Contains(d) {
+			return d
+		}
+	}
+	return ""
+}
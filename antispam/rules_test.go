@@ -0,0 +1,222 @@
+package antispam
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+var testNow = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+// messageAt returns a message whose ID encodes testNow plus offset, since
+// the rules read send times from message IDs.
+func messageAt(offset time.Duration, content string) *Message {
+	return &Message{
+		MessageID: snowflake.New(testNow.Add(offset)),
+		ChannelID: 1,
+		Content:   content,
+	}
+}
+
+func TestSimilarRule(t *testing.T) {
+	recent := []*Message{messageAt(-5*time.Second, "buy cheap nitro at example.com")}
+
+	cases := []struct {
+		name    string
+		content string
+		recent  []*Message
+		want    int
+	}{
+		{"identical", "buy cheap nitro at example.com", recent, similarPoints},
+		{"whitespace differs", "buy  cheap nitro\tat example.com", recent, similarPoints},
+		{"one character differs", "buy cheap nitro at example.con", recent, similarPoints},
+		{"different", "has anyone seen the new episode?", recent, 0},
+		{"too short to compare", "hi", []*Message{messageAt(-time.Second, "hi")}, 0},
+		{"no history", "buy cheap nitro at example.com", nil, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := similarRule{}.Score(messageAt(0, tc.content), tc.recent, &model.GuildSettings{})
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestBurstRule(t *testing.T) {
+	settings := &model.GuildSettings{AntiSpamBurstEnabled: true, AntiSpamBurstCount: 3, AntiSpamBurstSeconds: 2}
+
+	burst := func(offsets ...time.Duration) []*Message {
+		var msgs []*Message
+		for _, o := range offsets {
+			msgs = append(msgs, messageAt(o, "x"))
+		}
+		return msgs
+	}
+
+	cases := []struct {
+		name     string
+		recent   []*Message
+		settings *model.GuildSettings
+		want     int
+	}{
+		{"at the limit", burst(-time.Second, -500*time.Millisecond), settings, 0},
+		{"over the limit", burst(-1500*time.Millisecond, -time.Second, -500*time.Millisecond), settings, burstPoints},
+		{"spread out", burst(-10*time.Second, -6*time.Second, -3*time.Second), settings, 0},
+		{"disabled", burst(-1500*time.Millisecond, -time.Second, -500*time.Millisecond),
+			&model.GuildSettings{AntiSpamBurstCount: 3, AntiSpamBurstSeconds: 2}, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, burstRule{}.Score(messageAt(0, "x"), tc.recent, tc.settings))
+		})
+	}
+}
+
+func TestMentionRule(t *testing.T) {
+	settings := &model.GuildSettings{AntiSpamMentionsEnabled: true, AntiSpamMaxMentions: 3}
+
+	cases := []struct {
+		name     string
+		mentions int
+		settings *model.GuildSettings
+		want     int
+	}{
+		{"none", 0, settings, 0},
+		{"at the limit", 3, settings, 0},
+		{"over the limit", 4, settings, mentionPoints},
+		{"disabled", 20, &model.GuildSettings{AntiSpamMaxMentions: 3}, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := messageAt(0, "hey")
+			m.Mentions = tc.mentions
+			assert.Equal(t, tc.want, mentionRule{}.Score(m, nil, tc.settings))
+		})
+	}
+}
+
+func TestCountMentions(t *testing.T) {
+	m := discord.Message{
+		Mentions:        []discord.User{{ID: 1}, {ID: 2}, {ID: 1}},
+		MentionRoles:    []snowflake.ID{10},
+		MentionEveryone: true,
+	}
+	assert.Equal(t, 4, countMentions(m), "repeated users count once; @everyone counts as one")
+}
+
+func TestInviteRule(t *testing.T) {
+	settings := &model.GuildSettings{AntiSpamInvitesEnabled: true}
+
+	cases := []struct {
+		name     string
+		content  string
+		settings *model.GuildSettings
+		want     int
+	}{
+		{"no links", "come hang out sometime", settings, 0},
+		{"discord.gg", "join discord.gg/abc123", settings, invitePoints},
+		{"discord.com/invite", "https://discord.com/invite/abc-123", settings, invitePoints},
+		{"discordapp.com/invite", "https://DISCORDAPP.com/invite/abc", settings, invitePoints},
+		{"other discord link", "https://discord.com/channels/1/2/3", settings, 0},
+		{"within allowance", "discord.gg/abc", &model.GuildSettings{AntiSpamInvitesEnabled: true, AntiSpamMaxInvites: 1}, 0},
+		{"over allowance", "discord.gg/abc discord.gg/def", &model.GuildSettings{AntiSpamInvitesEnabled: true, AntiSpamMaxInvites: 1}, invitePoints},
+		{"disabled", "discord.gg/abc", &model.GuildSettings{}, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, inviteRule{}.Score(messageAt(0, tc.content), nil, tc.settings))
+		})
+	}
+}
+
+func TestMediaRule(t *testing.T) {
+	settings := &model.GuildSettings{AntiSpamMediaEnabled: true, AntiSpamMaxAttachments: 2, AntiSpamMaxEmoji: 3}
+
+	cases := []struct {
+		name        string
+		content     string
+		attachments int
+		settings    *model.GuildSettings
+		want        int
+	}{
+		{"plain", "hello", 0, settings, 0},
+		{"attachments at the limit", "", 2, settings, 0},
+		{"too many attachments", "", 3, settings, mediaPoints},
+		{"emoji at the limit", "😀😀😀", 0, settings, 0},
+		{"too many emoji", "😀😀😀😀", 0, settings, mediaPoints},
+		{"too many custom emoji", strings.Repeat("<:pog:123456789012345678>", 4), 0, settings, mediaPoints},
+		{"disabled", "😀😀😀😀😀😀", 10, &model.GuildSettings{}, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := messageAt(0, tc.content)
+			for range tc.attachments {
+				m.Attachments = append(m.Attachments, "image.png 100x100 1024")
+			}
+			assert.Equal(t, tc.want, mediaRule{}.Score(m, nil, tc.settings))
+		})
+	}
+}
+
+func TestCountEmoji(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int
+	}{
+		{"no emoji here", 0},
+		{"😀 🎉", 2},
+		{"👍🏽", 1},
+		{"👨\u200d👩\u200d👧", 1},
+		{"🇳🇴🇸🇪", 2},
+		{"❤\ufe0f", 1},
+		{"<:pog:123456789012345678> <a:dance:123456789012345678>", 2},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, countEmoji(tc.in), "%q", tc.in)
+	}
+}
+
+func TestZalgoRule(t *testing.T) {
+	settings := &model.GuildSettings{AntiSpamZalgoEnabled: true, AntiSpamMaxCombiningMarks: 3}
+
+	cases := []struct {
+		name     string
+		content  string
+		settings *model.GuildSettings
+		want     int
+	}{
+		{"plain", "hello there", settings, 0},
+		{"accents", "café naïve", settings, 0},
+		{"decomposed accents", "cafe\u0301", settings, 0},
+		{"emoji sequence", "👨\u200d👩\u200d👧 ❤\ufe0f", settings, 0},
+		{"zalgo", "h\u0336\u0321\u0327\u035de\u0337llo", settings, zalgoPoints},
+		{"invisible characters", "hi\u200b\u200b\u200b\u200bthere", settings, zalgoPoints},
+		{"disabled", "h\u0336\u0321\u0327\u035de", &model.GuildSettings{AntiSpamMaxCombiningMarks: 3}, 0},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, zalgoRule{}.Score(messageAt(0, tc.content), nil, tc.settings))
+		})
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	settings := &model.GuildSettings{
+		AntiSpamMentionsEnabled: true, AntiSpamMaxMentions: 1,
+		AntiSpamInvitesEnabled: true,
+	}
+	m := messageAt(0, "free nitro discord.gg/scam")
+	m.Mentions = 5
+
+	hits := Evaluate(m, nil, settings)
+	assert.Equal(t, []Hit{
+		{Rule: "mention flood", Points: mentionPoints},
+		{Rule: "invite links", Points: invitePoints},
+	}, hits)
+}
@@ -0,0 +1,326 @@
+package audit
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// Audit log archives hold entries the retention task pruned, for guilds
+// with AuditArchiveEnabled. They live under the configured directory as
+//
+//	<dir>/<guild ID>/<year>/<YYYY-MM-DD>.jsonl.gz
+//
+// one file per UTC day the entries were created, one ArchiveRecord per
+// line. Each pruning run appends a new gzip member to the day's file,
+// which gzip readers treat as one continuous stream.
+//
+// Archiving happens before the delete, so a delete that fails after the
+// write leaves the entries to be archived again on the next run. Readers
+// drop the duplicates by ID.
+
+// archiveBatchSize is how many rows Archive loads per query.
+const archiveBatchSize = 500
+
+// ArchiveRecord is an archived entry, field for field, so it can be
+// checked against the hash chain. IDs are strings since snowflakes don't
+// survive JSON numbers; Details is the stored JSON as-is.
+type ArchiveRecord struct {
+	ID         uint            `json:"id"`
+	GuildID    string          `json:"guild_id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Category   string          `json:"category"`
+	EventType  string          `json:"event_type"`
+	ActorID    string          `json:"actor_id,omitempty"`
+	ActorKind  string          `json:"actor_kind"`
+	TargetID   string          `json:"target_id,omitempty"`
+	TargetKind string          `json:"target_kind"`
+	Source     string          `json:"source"`
+	Reason     string          `json:"reason,omitempty"`
+	Details    json.RawMessage `json:"details,omitempty"`
+	PrevHash   string          `json:"prev_hash,omitempty"`
+	Hash       string          `json:"hash,omitempty"`
+}
+
+func NewArchiveRecord(e model.AuditLogEntry) ArchiveRecord {
+	r := ArchiveRecord{
+		ID:         e.ID,
+		GuildID:    e.GuildID.String(),
+		CreatedAt:  e.CreatedAt.UTC(),
+		Category:   e.Category,
+		EventType:  e.EventType,
+		ActorID:    exportID(e.ActorID),
+		ActorKind:  e.ActorKind,
+		TargetID:   exportID(e.TargetID),
+		TargetKind: e.TargetKind,
+		Source:     e.Source,
+		Reason:     e.Reason,
+		PrevHash:   e.PrevHash,
+		Hash:       e.Hash,
+	}
+	if json.Valid([]byte(e.Details)) {
+		r.Details = json.RawMessage(e.Details)
+	}
+	return r
+}
+
+// Entry turns the record back into the row it was archived from.
+func (r ArchiveRecord) Entry() (model.AuditLogEntry, error) {
+	guildID, err := snowflake.Parse(r.GuildID)
+	if err != nil {
+		return model.AuditLogEntry{}, fmt.Errorf("entry %d: guild ID: %w", r.ID, err)
+	}
+	actorID, err := archiveID(r.ActorID)
+	if err != nil {
+		return model.AuditLogEntry{}, fmt.Errorf("entry %d: actor ID: %w", r.ID, err)
+	}
+	targetID, err := archiveID(r.TargetID)
+	if err != nil {
+		return model.AuditLogEntry{}, fmt.Errorf("entry %d: target ID: %w", r.ID, err)
+	}
+	return model.AuditLogEntry{
+		ID:         r.ID,
+		GuildID:    guildID,
+		CreatedAt:  r.CreatedAt,
+		Category:   r.Category,
+		EventType:  r.EventType,
+		ActorID:    actorID,
+		ActorKind:  r.ActorKind,
+		TargetID:   targetID,
+		TargetKind: r.TargetKind,
+		Source:     r.Source,
+		Reason:     r.Reason,
+		Details:    string(r.Details),
+		PrevHash:   r.PrevHash,
+		Hash:       r.Hash,
+	}, nil
+}
+
+func archiveID(s string) (*snowflake.ID, error) {
+	if s == "" {
+		return nil, nil
+	}
+	id, err := snowflake.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// ArchivePath is the file holding the guild's entries created on day.
+func ArchivePath(dir string, guildID snowflake.ID, day time.Time) string {
+	day = day.UTC()
+	return filepath.Join(dir, guildID.String(), day.Format("2006"), day.Format("2006-01-02")+".jsonl.gz")
+}
+
+// Archive appends the guild's entries in category with an ID at or below
+// through to their day files in dir, and returns how many it wrote. Files
+// are synced before it returns, so the caller can delete the entries.
+func Archive(ctx context.Context, dir string, guildID snowflake.ID, category string, through uint) (int, error) {
+	w := &archiveWriter{dir: dir, guildID: guildID}
+	n := 0
+	err := model.EachAuditLogEntry(ctx, guildID, model.AuditLogFilter{Category: category, ThroughID: through}, archiveBatchSize,
+		func(e model.AuditLogEntry) error {
+			n++
+			return w.write(NewArchiveRecord(e))
+		})
+	if closeErr := w.close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
+
+// archiveWriter keeps the current day's file open while consecutive
+// entries fall on the same day, which they almost always do.
+type archiveWriter struct {
+	dir     string
+	guildID snowflake.ID
+
+	path string
+	file *os.File
+	gz   *gzip.Writer
+	buf  *bufio.Writer
+	enc  *json.Encoder
+}
+
+func (w *archiveWriter) write(r ArchiveRecord) error {
+	path := ArchivePath(w.dir, w.guildID, r.CreatedAt)
+	if path != w.path {
+		if err := w.close(); err != nil {
+			return err
+		}
+		if err := w.open(path); err != nil {
+			return err
+		}
+	}
+	return w.enc.Encode(r)
+}
+
+func (w *archiveWriter) open(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+	w.path = path
+	w.file = f
+	w.gz = gzip.NewWriter(f)
+	w.buf = bufio.NewWriter(w.gz)
+	w.enc = json.NewEncoder(w.buf)
+	w.enc.SetEscapeHTML(false)
+	return nil
+}
+
+func (w *archiveWriter) close() error {
+	if w.file == nil {
+		return nil
+	}
+	err := errors.Join(w.buf.Flush(), w.gz.Close(), w.file.Sync(), w.file.Close())
+	w.path, w.file, w.gz, w.buf, w.enc = "", nil, nil, nil, nil
+	if err != nil {
+		return fmt.Errorf("writing audit archive: %w", err)
+	}
+	return nil
+}
+
+// ArchiveQuery narrows an archive search. From is inclusive and To
+// exclusive, as in model.AuditLogFilter; zero values are open-ended. Text
+// matches case-insensitively anywhere in the reason or details.
+type ArchiveQuery struct {
+	From      time.Time
+	To        time.Time
+	Category  string
+	EventType string
+	Text      string
+}
+
+func (q ArchiveQuery) matches(r ArchiveRecord) bool {
+	if !q.From.IsZero() && r.CreatedAt.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !r.CreatedAt.Before(q.To) {
+		return false
+	}
+	if q.Category != "" && r.Category != q.Category {
+		return false
+	}
+	if q.EventType != "" && r.EventType != q.EventType {
+		return false
+	}
+	if q.Text != "" {
+		text := strings.ToLower(q.Text)
+		if !strings.Contains(strings.ToLower(r.Reason), text) && !strings.Contains(strings.ToLower(string(r.Details)), text) {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrStopSearch can be returned from a SearchArchive callback to stop
+// reading without an error.
+var ErrStopSearch = errors.New("stop archive search")
+
+// SearchArchive calls fn for each of the guild's archived entries that
+// match q, oldest day first. Only the day files inside the query's range
+// are read. Returning ErrStopSearch from fn ends the search early.
+func SearchArchive(ctx context.Context, dir string, guildID snowflake.ID, q ArchiveQuery, fn func(ArchiveRecord) error) error {
+	files, err := archiveFiles(dir, guildID, q.From, q.To)
+	if err != nil {
+		return err
+	}
+	seen := map[uint]struct{}{}
+	for _, path := range files {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := readArchiveFile(path, func(r ArchiveRecord) error {
+			if _, dup := seen[r.ID]; dup || !q.matches(r) {
+				return nil
+			}
+			seen[r.ID] = struct{}{}
+			return fn(r)
+		})
+		if errors.Is(err, ErrStopSearch) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// archiveFiles lists the guild's day files overlapping [from, to), in
+// date order.
+func archiveFiles(dir string, guildID snowflake.ID, from, to time.Time) ([]string, error) {
+	root := filepath.Join(dir, guildID.String())
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == root {
+				return fs.SkipAll
+			}
+			return err
+		}
+		name, ok := strings.CutSuffix(d.Name(), ".jsonl.gz")
+		if d.IsDir() || !ok {
+			return nil
+		}
+		day, err := time.Parse("2006-01-02", name)
+		if err != nil {
+			return nil
+		}
+		if !from.IsZero() && !day.Add(24*time.Hour).After(from) {
+			return nil
+		}
+		if !to.IsZero() && !day.Before(to) {
+			return nil
+		}
+		files = append(files, path)
+		return nil
+	})
+	slices.SortFunc(files, func(a, b string) int { return strings.Compare(filepath.Base(a), filepath.Base(b)) })
+	return files, err
+}
+
+func readArchiveFile(path string, fn func(ArchiveRecord) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	sc := bufio.NewScanner(gz)
+	// Entries carry whole messages; leave room well past Discord's limits.
+	sc.Buffer(make([]byte, 0, 64<<10), 4<<20)
+	for sc.Scan() {
+		var r ArchiveRecord
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			return err
+		}
+		if err := fn(r); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
@@ -0,0 +1,117 @@
+package audit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+func archiveTestEntry(t *testing.T, guildID snowflake.ID, category, reason string, createdAt time.Time) model.AuditLogEntry {
+	t.Helper()
+	actor := snowflake.ID(42)
+	e := model.AuditLogEntry{
+		GuildID: guildID, Category: category, EventType: category + ".test",
+		ActorID: &actor, ActorKind: "user", TargetKind: "none", Source: "gateway",
+		Reason: reason, Details: `{"content":"free nitro"}`, CreatedAt: createdAt,
+	}
+	require.NoError(t, model.CreateAuditLogEntry(&e))
+	return e
+}
+
+func searchAll(t *testing.T, dir string, guildID snowflake.ID, q ArchiveQuery) []ArchiveRecord {
+	t.Helper()
+	var got []ArchiveRecord
+	require.NoError(t, SearchArchive(context.Background(), dir, guildID, q, func(r ArchiveRecord) error {
+		got = append(got, r)
+		return nil
+	}))
+	return got
+}
+
+func TestArchive_WritesDayFilesAndSearches(t *testing.T) {
+	setupTestDB(t)
+	dir := t.TempDir()
+	guildID := snowflake.ID(1201)
+	day1 := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	day2 := day1.Add(24 * time.Hour)
+
+	a := archiveTestEntry(t, guildID, "message", "spam", day1)
+	b := archiveTestEntry(t, guildID, "message", "raid", day2)
+	archiveTestEntry(t, guildID, "member", "not archived", day1)
+
+	n, err := Archive(context.Background(), dir, guildID, "message", b.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.FileExists(t, ArchivePath(dir, guildID, day1))
+	assert.FileExists(t, filepath.Join(dir, "1201", "2026", "2026-03-02.jsonl.gz"))
+
+	got := searchAll(t, dir, guildID, ArchiveQuery{})
+	require.Len(t, got, 2)
+	entry, err := got[0].Entry()
+	require.NoError(t, err)
+	assert.Equal(t, a.ID, entry.ID)
+	assert.Equal(t, a.Hash, entry.Hash)
+	assert.Equal(t, a.Details, entry.Details)
+	assert.Equal(t, snowflake.ID(42), *entry.ActorID)
+	assert.True(t, a.CreatedAt.Equal(entry.CreatedAt))
+
+	got = searchAll(t, dir, guildID, ArchiveQuery{From: day2})
+	require.Len(t, got, 1)
+	assert.Equal(t, "raid", got[0].Reason)
+
+	got = searchAll(t, dir, guildID, ArchiveQuery{To: day2})
+	require.Len(t, got, 1)
+	assert.Equal(t, "spam", got[0].Reason)
+
+	assert.Len(t, searchAll(t, dir, guildID, ArchiveQuery{Text: "NITRO"}), 2, "text matches details, case-insensitively")
+	assert.Empty(t, searchAll(t, dir, guildID, ArchiveQuery{Category: "member"}))
+	assert.Empty(t, searchAll(t, dir, 9999, ArchiveQuery{}), "a guild without archives finds nothing")
+}
+
+func TestArchive_AppendsAndDropsDuplicates(t *testing.T) {
+	setupTestDB(t)
+	dir := t.TempDir()
+	guildID := snowflake.ID(1202)
+	day := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	a := archiveTestEntry(t, guildID, "message", "first", day)
+	_, err := Archive(context.Background(), dir, guildID, "message", a.ID)
+	require.NoError(t, err)
+
+	// A second run archives the same entry again (as after a failed
+	// delete) plus a new one, into the same day file.
+	b := archiveTestEntry(t, guildID, "message", "second", day.Add(time.Hour))
+	_, err = Archive(context.Background(), dir, guildID, "message", b.ID)
+	require.NoError(t, err)
+
+	got := searchAll(t, dir, guildID, ArchiveQuery{})
+	require.Len(t, got, 2)
+	assert.Equal(t, "first", got[0].Reason)
+	assert.Equal(t, "second", got[1].Reason)
+}
+
+func TestSearchArchive_Stop(t *testing.T) {
+	setupTestDB(t)
+	dir := t.TempDir()
+	guildID := snowflake.ID(1203)
+	day := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	archiveTestEntry(t, guildID, "message", "a", day)
+	last := archiveTestEntry(t, guildID, "message", "b", day)
+	_, err := Archive(context.Background(), dir, guildID, "message", last.ID)
+	require.NoError(t, err)
+
+	calls := 0
+	err = SearchArchive(context.Background(), dir, guildID, ArchiveQuery{}, func(ArchiveRecord) error {
+		calls++
+		return ErrStopSearch
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
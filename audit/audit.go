@@ -39,6 +39,24 @@ const (
 	EventMessageEdit   EventType = "message.edit"
 	EventMessageDelete EventType = "message.delete"
 
+	// EventMessageBulkDelete is one row per bulk delete (a moderator or
+	// bot purging a channel) in place of a message.delete row per message.
+	// The target is the channel; details.messages snapshots each deleted
+	// message the bot had cached or stored, and details.message_ids lists
+	// every ID.
+	EventMessageBulkDelete EventType = "message.bulk_delete"
+
+	// Threads, forum posts included (details.forum_post). Creates are
+	// attributed to the thread's owner; archive / lock changes and
+	// deletes go through the native audit log. details.archived /
+	// details.locked hold the new state. Threads archived for inactivity
+	// have no native entry, so they stay unattributed. Retained as
+	// message events, since threads and posts are member content.
+	EventThreadCreate  EventType = "thread.create"
+	EventThreadArchive EventType = "thread.archive"
+	EventThreadLock    EventType = "thread.lock"
+	EventThreadDelete  EventType = "thread.delete"
+
 	// EventMemberUpdate is retained for filter compatibility with rows
 	// written before the split into per-change types below. New entries
 	// use the more specific event types so the viewer can filter on
@@ -49,6 +67,41 @@ const (
 	EventMemberTimeoutAdd   EventType = "member.timeout_add"
 	EventMemberTimeoutClear EventType = "member.timeout_clear"
 
+	// EventMemberJoin carries the invite the member joined through, when
+	// it could be determined, and the visit number. EventMemberLeave
+	// carries the tenure of the visit that ended. Kicks and bans also
+	// produce a leave row; the guild.kick / guild.ban row names the
+	// moderator.
+	EventMemberJoin  EventType = "member.join"
+	EventMemberLeave EventType = "member.leave"
+
+	// Voice activity, recorded only when the guild has opted in with
+	// AuditVoiceEnabled. details.channel_id is the channel joined, left
+	// or moved into; moves also carry from_channel_id. Leaves and moves
+	// are attributed to the member unless the native audit log names a
+	// moderator. EventVoiceDisconnect is the moderator's side of a
+	// disconnect, written from the native audit log, which doesn't say
+	// who was disconnected; the matching leave row does.
+	EventVoiceJoin         EventType = "voice.join"
+	EventVoiceLeave        EventType = "voice.leave"
+	EventVoiceMove         EventType = "voice.move"
+	EventVoiceServerMute   EventType = "voice.server_mute"
+	EventVoiceServerDeafen EventType = "voice.server_deafen"
+	EventVoiceDisconnect   EventType = "voice.disconnect"
+
+	// Invites. Creates name the inviter and carry max_uses and expiry;
+	// deletes carry what the invite tracker knew about the invite and are
+	// attributed through the native audit log when a moderator revoked
+	// it. Retained as member events alongside the joins they lead to.
+	EventInviteCreate EventType = "invite.create"
+	EventInviteDelete EventType = "invite.delete"
+
+	// Gatekeep queue decisions. Written by the shared gatekeep code path,
+	// so Source tells the slash command apart from the dashboard queue.
+	EventGatekeepApprove EventType = "gatekeep.approve"
+	EventGatekeepDeny    EventType = "gatekeep.deny"
+	EventGatekeepKick    EventType = "gatekeep.kick"
+
 	EventGuildBan   EventType = "guild.ban"
 	EventGuildUnban EventType = "guild.unban"
 	EventGuildKick  EventType = "guild.kick"
@@ -56,6 +109,80 @@ const (
 
 	EventBotWarn EventType = "bot.warn"
 
+	// EventFilterMatch is a message that matched the guild's word filter,
+	// whatever the entry's action. details carry the message content, so
+	// it is retained as a message event.
+	EventFilterMatch EventType = "filter.match"
+
+	// Anti-spam actions, one row per spammer, named after the action
+	// taken. details.messages snapshots the triggering messages and
+	// details.rules names the detectors that fired. Observe-only mode
+	// writes EventAntiSpamObserve, since nothing was done. Retained as
+	// message events, like filter.match, because they carry content.
+	EventAntiSpamDelete     EventType = "antispam.delete"
+	EventAntiSpamTimeout    EventType = "antispam.timeout"
+	EventAntiSpamKick       EventType = "antispam.kick"
+	EventAntiSpamBan        EventType = "antispam.ban"
+	EventAntiSpamQuarantine EventType = "antispam.quarantine"
+	EventAntiSpamObserve    EventType = "antispam.observe"
+
+	// EventAutoModAction is one action Discord's AutoMod took, one row per
+	// action (a rule that blocks and alerts writes two). details carry the
+	// rule, the matched keyword and content, and the action. Written with
+	// ActorSystem, since the action is Discord's own. Retained as a message
+	// event because it carries content.
+	EventAutoModAction EventType = "automod.action"
+
+	// Raid lockdown start / end. Automatic starts are written with
+	// ActorSystem; manual starts and every end name the moderator.
+	EventLockdownStart EventType = "lockdown.start"
+	EventLockdownEnd   EventType = "lockdown.end"
+
+	// Channel /lock and /unlock. details.channel_id is set so the viewer's
+	// channel filter finds them. Expired locks are unlocked by the system.
+	EventChannelLock   EventType = "channel.lock"
+	EventChannelUnlock EventType = "channel.unlock"
+
+	// Channel and role configuration. Written from the gateway events and
+	// attributed through the native audit log. Position-only updates
+	// (dragging channels or roles around) are not recorded.
+	// EventChannelPermissionsUpdate carries the permission overwrite diff
+	// of a channel update separately from its other changes, so the
+	// viewer can filter on permission changes alone. EventRoleUpdate
+	// carries the role's permissions_added / permissions_removed.
+	EventChannelCreate            EventType = "channel.create"
+	EventChannelUpdate            EventType = "channel.update"
+	EventChannelDelete            EventType = "channel.delete"
+	EventChannelPermissionsUpdate EventType = "channel.permissions_update"
+	EventRoleCreate               EventType = "role.create"
+	EventRoleUpdate               EventType = "role.update"
+	EventRoleDelete               EventType = "role.delete"
+
+	// Emoji and sticker changes come from the gateway and are attributed
+	// through the native audit log. Webhook create / delete rows are
+	// written from the native audit log itself, since the gateway only
+	// says that some webhook in a channel changed. Integration rows cover
+	// bots and other applications being added to or removed from the
+	// guild.
+	EventEmojiCreate       EventType = "emoji.create"
+	EventEmojiUpdate       EventType = "emoji.update"
+	EventEmojiDelete       EventType = "emoji.delete"
+	EventStickerCreate     EventType = "sticker.create"
+	EventStickerUpdate     EventType = "sticker.update"
+	EventStickerDelete     EventType = "sticker.delete"
+	EventWebhookCreate     EventType = "webhook.create"
+	EventWebhookDelete     EventType = "webhook.delete"
+	EventIntegrationCreate EventType = "integration.create"
+	EventIntegrationDelete EventType = "integration.delete"
+
+	// AutoMod rule changes come from the gateway and are attributed through
+	// the native audit log. Updates are diffed against the bot's copy of
+	// the guild's rules; keyword, regex and allow-list edits are recorded
+	// as the entries added and removed.
+	EventAutoModRuleCreate EventType = "automod_rule.create"
+	EventAutoModRuleUpdate EventType = "automod_rule.update"
+	EventAutoModRuleDelete EventType = "automod_rule.delete"
+
 	// EventSettingsUpdate is the canonical event for any settings change
 	// regardless of origin (web dashboard or slash command). Source on
 	// the persisted row distinguishes which path produced it.
@@ -67,6 +194,11 @@ const (
 	// dropdown; new entries should use EventSettingsUpdate.
 	EventWebSettingsUpdate EventType = "web.settings.update"
 
+	// EventAuditLogExport records who exported the audit log, in which
+	// format and with which filters, so handing records out is itself on
+	// the record.
+	EventAuditLogExport EventType = "audit_log.export"
+
 	EventWebPostCreate EventType = "web.post.create"
 	EventWebPostUpdate EventType = "web.post.update"
 	EventWebPostDelete EventType = "web.post.delete"
@@ -96,6 +228,13 @@ const (
 	TargetMessage TargetKind = "message"
 	TargetGuild   TargetKind = "guild"
 	TargetNone    TargetKind = "none"
+
+	TargetEmoji       TargetKind = "emoji"
+	TargetSticker     TargetKind = "sticker"
+	TargetWebhook     TargetKind = "webhook"
+	TargetIntegration TargetKind = "integration"
+	TargetAutoModRule TargetKind = "automod_rule"
+	TargetInvite      TargetKind = "invite"
 )
 
 // Source identifies the path that produced the entry, useful both for
@@ -112,15 +251,36 @@ const (
 // for unknown types — callers should fail closed and skip those.
 func EventCategory(t EventType) Category {
 	switch t {
-	case EventMessageEdit, EventMessageDelete:
+	case EventMessageEdit, EventMessageDelete, EventMessageBulkDelete,
+		EventThreadCreate, EventThreadArchive, EventThreadLock, EventThreadDelete,
+		EventFilterMatch,
+		EventAntiSpamDelete, EventAntiSpamTimeout, EventAntiSpamKick,
+		EventAntiSpamBan, EventAntiSpamQuarantine, EventAntiSpamObserve,
+		EventAutoModAction:
 		return CategoryMessage
 	case EventMemberUpdate,
 		EventMemberNickChange, EventMemberRoleChange,
-		EventMemberTimeoutAdd, EventMemberTimeoutClear:
+		EventMemberTimeoutAdd, EventMemberTimeoutClear,
+		EventMemberJoin, EventMemberLeave,
+		EventVoiceJoin, EventVoiceLeave, EventVoiceMove,
+		EventVoiceServerMute, EventVoiceServerDeafen, EventVoiceDisconnect,
+		EventInviteCreate, EventInviteDelete,
+		EventGatekeepApprove, EventGatekeepDeny, EventGatekeepKick:
 		return CategoryMember
 	case EventGuildBan, EventGuildUnban, EventGuildKick, EventGuildPrune,
 		EventBotWarn,
+		EventLockdownStart, EventLockdownEnd,
+		EventChannelLock, EventChannelUnlock,
+		EventChannelCreate, EventChannelUpdate, EventChannelDelete,
+		EventChannelPermissionsUpdate,
+		EventRoleCreate, EventRoleUpdate, EventRoleDelete,
+		EventEmojiCreate, EventEmojiUpdate, EventEmojiDelete,
+		EventStickerCreate, EventStickerUpdate, EventStickerDelete,
+		EventWebhookCreate, EventWebhookDelete,
+		EventIntegrationCreate, EventIntegrationDelete,
+		EventAutoModRuleCreate, EventAutoModRuleUpdate, EventAutoModRuleDelete,
 		EventSettingsUpdate, EventWebSettingsUpdate,
+		EventAuditLogExport,
 		EventWebPostCreate, EventWebPostUpdate, EventWebPostDelete:
 		return CategoryGuild
 	}
@@ -132,7 +292,15 @@ func EventCategory(t EventType) Category {
 // callers from having to import gorm or worry about gorm tags.
 //
 // Category is derived from EventType via EventCategory if left empty.
+// CreatedAt is when the event happened; LogPending sets it when the entry
+// arrives, so an entry held for enrichment keeps its place in time, and
+// Log leaves it to the commit.
+//
+// ID is the audit log row's ID. Callers leave it zero; once the row is
+// written, commit fills in ID and the stored CreatedAt so sinks can refer
+// to the row.
 type Entry struct {
+	ID         uint
 	GuildID    snowflake.ID
 	Category   Category
 	EventType  EventType
@@ -143,6 +311,7 @@ type Entry struct {
 	Source     Source
 	Reason     string
 	Details    map[string]any
+	CreatedAt  time.Time
 }
 
 // Log writes the entry immediately. Use this for events that need no
@@ -210,6 +379,13 @@ func shouldLog(guildID snowflake.ID) bool {
 	return settings.AuditLogEnabled
 }
 
+// Enabled reports whether the guild has audit logging turned on, from the
+// same cache Log uses. Listeners that do extra work to build an entry
+// check it first so disabled guilds don't pay for it.
+func Enabled(guildID snowflake.ID) bool {
+	return shouldLog(guildID)
+}
+
 // InvalidateShouldLogCache clears the cached AuditLogEnabled flag for a
 // guild. Web/command handlers that modify the toggle must call this after
 // a successful save so the new value takes effect on the very next event
@@ -251,8 +427,9 @@ func CommitFailureTotal() int64 {
 	return commitFailuresTotal.Load()
 }
 
-// commit serializes details and writes the row. Errors are logged at warn
-// rather than returned for the same fail-soft reason as Log.
+// commit serializes details, writes the row and hands the entry to the
+// sinks. Errors are logged at warn rather than returned for the same
+// fail-soft reason as Log.
 func commit(entry Entry) {
 	detailsJSON, err := marshalDetails(entry.Details)
 	if err != nil {
@@ -270,8 +447,9 @@ func commit(entry Entry) {
 		Source:     string(entry.Source),
 		Reason:     entry.Reason,
 		Details:    detailsJSON,
+		CreatedAt:  entry.CreatedAt,
 	}
-	if err := model.DB.Create(row).Error; err != nil {
+	if err := model.CreateAuditLogEntry(row); err != nil {
 		commitFailures.Add(1)
 		commitFailuresTotal.Add(1)
 		slog.Warn("audit: failed to write entry",
@@ -279,7 +457,11 @@ func commit(entry Entry) {
 			"event", entry.EventType,
 			"guild_id", entry.GuildID,
 		)
+		return
 	}
+	entry.ID = row.ID
+	entry.CreatedAt = row.CreatedAt
+	notifySinks(entry)
 }
 
 func marshalDetails(d map[string]any) (string, error) {
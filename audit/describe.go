@@ -0,0 +1,1207 @@
+package audit
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/antispam"
+	"github.com/NLLCommunity/heimdallr/utils"
+)
+
+// DetailSection is one heading + body block of an entry's detail, e.g.
+// the Before / After of a message edit.
+type DetailSection struct {
+	Heading string
+	Body    string
+}
+
+// Describe extracts a one-line human summary plus optional
+// sections from the already-decoded details map for a given event type.
+// The dashboard viewer and the log channel stream both render entries
+// through it, so an event reads the same wherever it is shown.
+//
+// Sections are headed text blocks; the viewer puts them in an expandable
+// <details> disclosure, the stream in embed fields. Events that produce
+// only a short summary (no sections) render the summary as a small
+// subtitle under the event label.
+//
+// client + guildID are used to resolve embedded channel/role IDs in the
+// details payload (web settings updates can carry these as referenced
+// values).
+//
+// Returns ("", nil) when there's nothing useful to surface. A nil details
+// map (legacy or malformed-JSON row) is treated as "nothing to surface".
+func Describe(client *bot.Client, guildID snowflake.ID, eventType string, d map[string]any) (summary string, sections []DetailSection) {
+	if d == nil {
+		return "", nil
+	}
+
+	switch eventType {
+	case string(EventMessageDelete):
+		full := stringField(d, "before_content")
+		author := messageAuthorFromDetails(d)
+		if full == "" && author == "" {
+			return "", nil
+		}
+
+		sections := make([]DetailSection, 0, 2)
+		if author != "" {
+			sections = append(sections, DetailSection{Heading: "Message author", Body: author})
+		}
+		if full != "" {
+			sections = append(sections, DetailSection{Heading: "Deleted message", Body: full})
+		}
+
+		summary := truncate(full, 120)
+		if author != "" {
+			if summary == "" {
+				summary = "Message by " + author
+			} else {
+				summary = author + ": " + summary
+			}
+		}
+		return summary, sections
+
+	case string(EventMessageEdit):
+		before := stringField(d, "before_content")
+		after := stringField(d, "after_content")
+		if before == "" && after == "" {
+			return "", nil
+		}
+		summary := truncate(before, 60) + "  →  " + truncate(after, 60)
+		return summary, []DetailSection{
+			{Heading: "Before", Body: before},
+			{Heading: "After", Body: after},
+		}
+
+	case string(EventMessageBulkDelete):
+		return bulkDeleteDetail(d)
+
+	case string(EventThreadCreate), string(EventThreadDelete):
+		return threadSummary(d), nil
+
+	case string(EventThreadArchive):
+		if archived, _ := d["archived"].(bool); archived {
+			return "archived", nil
+		}
+		return "unarchived", nil
+
+	case string(EventThreadLock):
+		if locked, _ := d["locked"].(bool); locked {
+			return "locked", nil
+		}
+		return "unlocked", nil
+
+	case string(EventFilterMatch):
+		summary := filterMatchSummary(d)
+		if content := stringField(d, "content"); content != "" {
+			return summary, []DetailSection{{Heading: "Message", Body: content}}
+		}
+		return summary, nil
+
+	case string(EventAntiSpamDelete), string(EventAntiSpamTimeout),
+		string(EventAntiSpamKick), string(EventAntiSpamBan),
+		string(EventAntiSpamQuarantine), string(EventAntiSpamObserve):
+		return antiSpamDetail(d, func(id snowflake.ID) string {
+			return ResolveChannelName(client, id)
+		})
+
+	case string(EventAutoModAction):
+		return autoModActionDetail(client, d)
+
+	case string(EventMemberNickChange):
+		before := stringField(d, "nick_before")
+		after := stringField(d, "nick_after")
+		if before == "" {
+			before = "—"
+		}
+		if after == "" {
+			after = "—"
+		}
+		return before + " → " + after, nil
+
+	case string(EventMemberRoleChange):
+		return roleChangeSummary(d), nil
+
+	case string(EventMemberTimeoutAdd):
+		if v, ok := d["timeout_until"].(string); ok {
+			return "until " + v, nil
+		}
+
+	case string(EventMemberJoin):
+		return memberJoinSummary(d), nil
+
+	case string(EventMemberLeave):
+		return memberLeaveSummary(d), nil
+
+	case string(EventVoiceJoin), string(EventVoiceLeave), string(EventVoiceMove),
+		string(EventVoiceServerMute), string(EventVoiceServerDeafen), string(EventVoiceDisconnect):
+		return voiceSummary(client, eventType, d), nil
+
+	case string(EventInviteCreate):
+		return inviteSummary(client, d), nil
+
+	case string(EventInviteDelete):
+		// The actor is whoever revoked the invite; name its creator too.
+		summary := inviteSummary(client, d)
+		if inviter := stringField(d, "inviter_username"); inviter != "" {
+			summary = strings.TrimPrefix(summary+", created by @"+inviter, ", ")
+		}
+		return summary, nil
+
+	case string(EventLockdownStart):
+		return stringField(d, "reason"), nil
+
+	case string(EventLockdownEnd):
+		if secs, ok := d["duration_seconds"].(float64); ok {
+			return "after " + utils.ApproxDuration(time.Duration(secs)*time.Second), nil
+		}
+
+	case string(EventChannelLock), string(EventChannelUnlock):
+		return channelLockSummary(d), nil
+
+	case string(EventChannelCreate), string(EventChannelDelete):
+		return channelConfigSummary(d), permissionOverwriteSections(d)
+
+	case string(EventChannelUpdate):
+		return configChangeDetail(channelConfigName(d), d)
+
+	case string(EventChannelPermissionsUpdate):
+		return channelPermissionsDetail(d)
+
+	case string(EventRoleCreate), string(EventRoleDelete):
+		summary := roleConfigName(d)
+		if perms := stringList(d, "permissions"); len(perms) > 0 {
+			return summary, []DetailSection{{Heading: "Permissions", Body: strings.Join(perms, "\n")}}
+		}
+		return summary, nil
+
+	case string(EventRoleUpdate):
+		return roleUpdateDetail(d)
+
+	case string(EventEmojiCreate), string(EventEmojiDelete):
+		return emojiName(d), nil
+
+	case string(EventEmojiUpdate):
+		return configChangeDetail(emojiName(d), d)
+
+	case string(EventStickerCreate), string(EventStickerDelete):
+		return stringField(d, "sticker_name"), nil
+
+	case string(EventStickerUpdate):
+		return configChangeDetail(stringField(d, "sticker_name"), d)
+
+	case string(EventWebhookCreate), string(EventWebhookDelete):
+		return webhookSummary(client, d), nil
+
+	case string(EventIntegrationCreate), string(EventIntegrationDelete):
+		return integrationSummary(d), nil
+
+	case string(EventAutoModRuleCreate), string(EventAutoModRuleDelete):
+		return stringField(d, "automod_rule_name"), autoModRuleSections(d)
+
+	case string(EventAutoModRuleUpdate):
+		return autoModRuleUpdateDetail(d)
+
+	case string(EventBotWarn):
+		if w, ok := d["weight"].(float64); ok {
+			return "severity " + strconv.FormatFloat(w, 'f', -1, 64), nil
+		}
+
+	case string(EventGuildPrune):
+		removed := stringField(d, "members_removed")
+		days := stringField(d, "delete_member_days")
+		switch {
+		case removed != "" && days != "":
+			return removed + " removed (inactive ≥ " + days + " days)", nil
+		case removed != "":
+			return removed + " removed", nil
+		case days != "":
+			return "inactive ≥ " + days + " days", nil
+		}
+
+	case string(EventSettingsUpdate),
+		string(EventWebSettingsUpdate):
+		return formatSettingsUpdate(client, guildID, d)
+
+	case string(EventWebPostCreate),
+		string(EventWebPostUpdate),
+		string(EventWebPostDelete):
+		return stringField(d, "post_name"), nil
+
+	case string(EventAuditLogExport):
+		return auditExportSummary(d), nil
+	}
+	return "", nil
+}
+
+// auditExportSummary renders an audit_log.export entry as e.g.
+// "120 entries as CSV · category=message, from=2026-01-01".
+func auditExportSummary(d map[string]any) string {
+	n, _ := d["entries"].(float64)
+	out := strconv.FormatFloat(n, 'f', -1, 64) + " entries as " + strings.ToUpper(stringField(d, "format"))
+	filters, _ := d["filters"].(map[string]any)
+	if len(filters) == 0 {
+		return out
+	}
+	keys := make([]string, 0, len(filters))
+	for k := range filters {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+"="+fmt.Sprint(filters[k]))
+	}
+	return out + " · " + strings.Join(parts, ", ")
+}
+
+// joinInviteSummary describes the invite recorded on a member.join entry.
+// memberJoinSummary prefixes the invite summary with the visit number for
+// rejoins; first visits show the invite alone.
+func memberJoinSummary(d map[string]any) string {
+	invite := joinInviteSummary(d)
+	visit, _ := d["visit"].(float64)
+	if visit <= 1 {
+		return invite
+	}
+	summary := utils.Ordinal(int(visit)) + " visit"
+	if invite != "" {
+		summary += ", " + invite
+	}
+	return summary
+}
+
+// memberLeaveSummary renders the tenure of the visit that ended.
+func memberLeaveSummary(d map[string]any) string {
+	secs, ok := d["tenure_seconds"].(float64)
+	if !ok {
+		return ""
+	}
+	return "after " + utils.ApproxDuration(time.Duration(secs)*time.Second)
+}
+
+func channelLockSummary(d map[string]any) string {
+	summary := ""
+	if name := stringField(d, "channel_name"); name != "" {
+		summary = "#" + name
+	}
+	if until := stringField(d, "until"); until != "" {
+		summary = strings.TrimSpace(summary + " until " + until)
+	}
+	if expired, _ := d["expired"].(bool); expired {
+		summary = strings.TrimSpace(summary + " (expired)")
+	}
+	return summary
+}
+
+// voiceSummary renders a voice entry as e.g. "#General → #AFK" for a
+// move or "muted in #General" for a server mute.
+func voiceSummary(client *bot.Client, eventType string, d map[string]any) string {
+	channel := voiceChannelName(client, d, "channel")
+	switch eventType {
+	case string(EventVoiceMove):
+		return orDash(voiceChannelName(client, d, "from_channel")) + " → " + orDash(channel)
+	case string(EventVoiceServerMute):
+		verb := "unmuted"
+		if muted, _ := d["muted"].(bool); muted {
+			verb = "muted"
+		}
+		return strings.TrimSpace(verb + " in " + channel)
+	case string(EventVoiceServerDeafen):
+		verb := "undeafened"
+		if deafened, _ := d["deafened"].(bool); deafened {
+			verb = "deafened"
+		}
+		return strings.TrimSpace(verb + " in " + channel)
+	case string(EventVoiceDisconnect):
+		n, _ := d["count"].(float64)
+		if n == 1 {
+			return "1 member"
+		}
+		if n > 1 {
+			return strconv.FormatFloat(n, 'f', -1, 64) + " members"
+		}
+		return ""
+	}
+	return channel
+}
+
+// voiceChannelName renders the channel recorded under prefix+"_name",
+// falling back to resolving prefix+"_id" for rows without a name.
+func voiceChannelName(client *bot.Client, d map[string]any, prefix string) string {
+	if name := stringField(d, prefix+"_name"); name != "" {
+		return "#" + name
+	}
+	if id, err := snowflake.Parse(stringField(d, prefix+"_id")); err == nil {
+		return "#" + ResolveChannelName(client, id)
+	}
+	return ""
+}
+
+// channelConfigName renders the channel_name recorded on channel
+// configuration events as "#name", or "" when it is missing.
+func channelConfigName(d map[string]any) string {
+	if name := stringField(d, "channel_name"); name != "" {
+		return "#" + name
+	}
+	return ""
+}
+
+// channelConfigSummary renders "#name (voice channel)" for channel
+// create / delete entries.
+func channelConfigSummary(d map[string]any) string {
+	summary := channelConfigName(d)
+	if kind := stringField(d, "channel_type"); kind != "" {
+		summary = strings.TrimSpace(summary + " (" + kind + ")")
+	}
+	return summary
+}
+
+// roleConfigName renders the role_name recorded on role configuration
+// events as "@name". @everyone is stored with its @ already.
+func roleConfigName(d map[string]any) string {
+	name := stringField(d, "role_name")
+	if name == "" {
+		return ""
+	}
+	return "@" + strings.TrimPrefix(name, "@")
+}
+
+// configChangeDetail summarises the details.changes list of a channel or
+// role update as "<name>: topic, slowmode changed", with a Changes section
+// listing each field's before and after value.
+func configChangeDetail(name string, d map[string]any) (string, []DetailSection) {
+	changes, _ := d["changes"].([]any)
+	fields := make([]string, 0, len(changes))
+	lines := make([]string, 0, len(changes))
+	for _, raw := range changes {
+		c, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		field := humanizeFieldName(stringField(c, "field"))
+		fields = append(fields, strings.ToLower(field))
+		lines = append(lines, field+": "+orDash(stringField(c, "before"))+" → "+orDash(stringField(c, "after")))
+	}
+	if len(fields) == 0 {
+		return name, nil
+	}
+	summary := strings.Join(fields, ", ") + " changed"
+	if name != "" {
+		summary = name + ": " + summary
+	}
+	return summary, []DetailSection{{Heading: "Changes", Body: strings.Join(lines, "\n")}}
+}
+
+// channelPermissionsDetail summarises a channel.permissions_update entry
+// by the roles and members whose overwrites changed, giving each its own
+// section.
+func channelPermissionsDetail(d map[string]any) (string, []DetailSection) {
+	sections := permissionOverwriteSections(d)
+	names := make([]string, 0, len(sections))
+	for _, s := range sections {
+		names = append(names, strings.SplitN(s.Heading, " (", 2)[0])
+	}
+	summary := strings.Join(names, ", ")
+	if name := channelConfigName(d); name != "" {
+		summary = strings.TrimSuffix(name+": "+summary, ": ")
+	}
+	return summary, sections
+}
+
+// permissionOverwriteSections renders details.overwrites, one section per
+// role or member overwrite, headed e.g. "@Mods (role, added)" and listing
+// the permissions it now allows, denies, or leaves to inherit.
+func permissionOverwriteSections(d map[string]any) []DetailSection {
+	overwrites, _ := d["overwrites"].([]any)
+	sections := make([]DetailSection, 0, len(overwrites))
+	for _, raw := range overwrites {
+		o, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		name := stringField(o, "name")
+		if name == "" {
+			name = stringField(o, "id")
+		}
+		heading := "@" + strings.TrimPrefix(name, "@") + " (" + stringField(o, "kind")
+		if action := stringField(o, "action"); action != "" {
+			heading += ", " + action
+		}
+		heading += ")"
+
+		var lines []string
+		for _, part := range []struct{ key, label string }{
+			{"allowed", "Allowed"},
+			{"denied", "Denied"},
+			{"inherited", "Inherited"},
+		} {
+			if perms := stringList(o, part.key); len(perms) > 0 {
+				lines = append(lines, part.label+": "+strings.Join(perms, ", "))
+			}
+		}
+		sections = append(sections, DetailSection{Heading: heading, Body: strings.Join(lines, "\n")})
+	}
+	return sections
+}
+
+// roleUpdateDetail summarises a role.update entry, leading with the
+// permissions granted and revoked since those are what moderators scan
+// the log for.
+func roleUpdateDetail(d map[string]any) (string, []DetailSection) {
+	granted := stringList(d, "permissions_added")
+	revoked := stringList(d, "permissions_removed")
+	fieldSummary, sections := configChangeDetail("", d)
+
+	var parts []string
+	if len(granted) > 0 {
+		parts = append(parts, "granted "+strings.Join(granted, ", "))
+	}
+	if len(revoked) > 0 {
+		parts = append(parts, "revoked "+strings.Join(revoked, ", "))
+	}
+	if fieldSummary != "" {
+		parts = append(parts, fieldSummary)
+	}
+	summary := strings.Join(parts, "; ")
+	if name := roleConfigName(d); name != "" {
+		summary = strings.TrimSuffix(name+": "+summary, ": ")
+	}
+
+	var out []DetailSection
+	if len(granted) > 0 {
+		out = append(out, DetailSection{Heading: "Permissions granted", Body: strings.Join(granted, "\n")})
+	}
+	if len(revoked) > 0 {
+		out = append(out, DetailSection{Heading: "Permissions revoked", Body: strings.Join(revoked, "\n")})
+	}
+	return summary, append(out, sections...)
+}
+
+// autoModActionDetail summarises an automod.action entry as e.g. `No
+// slurs: "badword" — message blocked`, with the member's content in its
+// own section.
+func autoModActionDetail(client *bot.Client, d map[string]any) (string, []DetailSection) {
+	summary := stringField(d, "rule_name")
+	if kw := stringField(d, "matched_keyword"); kw != "" {
+		summary = strings.TrimPrefix(summary+": "+strconv.Quote(kw), ": ")
+	}
+	outcome := ""
+	switch stringField(d, "action") {
+	case "block_message":
+		outcome = "message blocked"
+	case "send_alert":
+		outcome = "alert sent"
+		if id, err := snowflake.Parse(stringField(d, "alert_channel_id")); err == nil {
+			outcome += " to #" + ResolveChannelName(client, id)
+		}
+	case "timeout":
+		outcome = "timed out"
+		if secs, ok := d["timeout_seconds"].(float64); ok && secs > 0 {
+			outcome += " for " + utils.ApproxDuration(time.Duration(secs)*time.Second)
+		}
+	case "block_interaction":
+		outcome = "member interaction blocked"
+	}
+	if outcome != "" {
+		summary = strings.TrimPrefix(summary+" — "+outcome, " — ")
+	}
+
+	var sections []DetailSection
+	if content := stringField(d, "content"); content != "" {
+		heading := "Message"
+		if id := channelIDFromDetails(d); id != 0 {
+			heading += " in #" + ResolveChannelName(client, id)
+		}
+		sections = append(sections, DetailSection{Heading: heading, Body: content})
+	}
+	if matched := stringField(d, "matched_content"); matched != "" && matched != stringField(d, "matched_keyword") {
+		sections = append(sections, DetailSection{Heading: "Matched content", Body: matched})
+	}
+	return summary, sections
+}
+
+// autoModRuleLists are the list-valued parts of an AutoMod rule, under
+// the details keys the listener records them with. Updates record each as
+// key+"_added" / key+"_removed".
+var autoModRuleLists = []struct{ key, label string }{
+	{"keywords", "Keywords"},
+	{"regex_patterns", "Regex patterns"},
+	{"allow_list", "Allow list"},
+	{"presets", "Keyword presets"},
+}
+
+// autoModRuleSections renders a created or deleted rule: its settings,
+// then one section per non-empty keyword list.
+func autoModRuleSections(d map[string]any) []DetailSection {
+	var lines []string
+	for _, f := range []struct{ key, label string }{
+		{"trigger", "Trigger"},
+		{"event", "Checks"},
+		{"mention_limit", "Mention limit"},
+	} {
+		if v := stringField(d, f.key); v != "" {
+			lines = append(lines, f.label+": "+v)
+		}
+	}
+	for _, f := range []struct{ key, label string }{
+		{"actions", "Actions"},
+		{"exempt_roles", "Exempt roles"},
+		{"exempt_channels", "Exempt channels"},
+	} {
+		if list := stringList(d, f.key); len(list) > 0 {
+			lines = append(lines, f.label+": "+strings.Join(list, ", "))
+		}
+	}
+	if enabled, ok := d["enabled"].(bool); ok && !enabled {
+		lines = append(lines, "Disabled")
+	}
+
+	var sections []DetailSection
+	if len(lines) > 0 {
+		sections = append(sections, DetailSection{Heading: "Rule", Body: strings.Join(lines, "\n")})
+	}
+	for _, l := range autoModRuleLists {
+		if list := stringList(d, l.key); len(list) > 0 {
+			sections = append(sections, DetailSection{Heading: l.label, Body: strings.Join(list, "\n")})
+		}
+	}
+	return sections
+}
+
+// autoModRuleUpdateDetail summarises an automod_rule.update entry,
+// leading with the keyword list edits, the way roleUpdateDetail leads
+// with permissions.
+func autoModRuleUpdateDetail(d map[string]any) (string, []DetailSection) {
+	fieldSummary, changeSections := configChangeDetail("", d)
+
+	var parts []string
+	var sections []DetailSection
+	for _, l := range autoModRuleLists {
+		label := strings.ToLower(l.label)
+		if added := stringList(d, l.key+"_added"); len(added) > 0 {
+			parts = append(parts, "added "+label+" "+strings.Join(added, ", "))
+			sections = append(sections, DetailSection{Heading: l.label + " added", Body: strings.Join(added, "\n")})
+		}
+		if removed := stringList(d, l.key+"_removed"); len(removed) > 0 {
+			parts = append(parts, "removed "+label+" "+strings.Join(removed, ", "))
+			sections = append(sections, DetailSection{Heading: l.label + " removed", Body: strings.Join(removed, "\n")})
+		}
+	}
+	if fieldSummary != "" {
+		parts = append(parts, fieldSummary)
+	}
+	summary := strings.Join(parts, "; ")
+	if name := stringField(d, "automod_rule_name"); name != "" {
+		summary = strings.TrimSuffix(name+": "+summary, ": ")
+	}
+	return summary, append(sections, changeSections...)
+}
+
+// bulkDeleteDetail summarises a message.bulk_delete entry by how many
+// messages went, giving each message the bot still had its own section.
+func bulkDeleteDetail(d map[string]any) (string, []DetailSection) {
+	messages, _ := d["messages"].([]any)
+	count, _ := d["count"].(float64)
+	if count == 0 {
+		count = float64(len(stringList(d, "message_ids")))
+	}
+	summary := strconv.FormatFloat(count, 'f', -1, 64) + " messages"
+	if count == 1 {
+		summary = "1 message"
+	}
+	if len(messages) > 0 && float64(len(messages)) < count {
+		summary += " (" + strconv.Itoa(len(messages)) + " with content)"
+	}
+
+	sections := make([]DetailSection, 0, len(messages))
+	for _, raw := range messages {
+		m, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		heading := "Message"
+		if author := stringField(m, "author_username"); author != "" {
+			heading = "@" + author
+		}
+		sections = append(sections, DetailSection{Heading: heading, Body: stringField(m, "content")})
+	}
+	return summary, sections
+}
+
+// threadSummary renders "#name in #parent", marking forum posts.
+func threadSummary(d map[string]any) string {
+	summary := ""
+	if name := stringField(d, "thread_name"); name != "" {
+		summary = "#" + name
+	}
+	if parent := stringField(d, "parent_name"); parent != "" {
+		summary = strings.TrimSpace(summary + " in #" + parent)
+	}
+	if post, _ := d["forum_post"].(bool); post {
+		summary = strings.TrimSpace(summary + " (forum post)")
+	}
+	return summary
+}
+
+// inviteSummary renders an invite's channel, use limit and lifetime, e.g.
+// "#welcome, 3 of 10 uses, expires after 7 days".
+func inviteSummary(client *bot.Client, d map[string]any) string {
+	var parts []string
+	if id := channelIDFromDetails(d); id != 0 {
+		name := stringField(d, "channel_name")
+		if name == "" {
+			name = ResolveChannelName(client, id)
+		}
+		parts = append(parts, "#"+name)
+	}
+	maxUses, _ := d["max_uses"].(float64)
+	uses, hasUses := d["uses"].(float64)
+	switch {
+	case maxUses > 0 && hasUses:
+		parts = append(parts, strconv.FormatFloat(uses, 'f', -1, 64)+" of "+strconv.FormatFloat(maxUses, 'f', -1, 64)+" uses")
+	case maxUses > 0:
+		parts = append(parts, "max "+strconv.FormatFloat(maxUses, 'f', -1, 64)+" uses")
+	case hasUses:
+		parts = append(parts, strconv.FormatFloat(uses, 'f', -1, 64)+" uses")
+	}
+	if secs, ok := d["max_age_seconds"].(float64); ok {
+		if secs > 0 {
+			parts = append(parts, "expires after "+utils.ApproxDuration(time.Duration(secs)*time.Second))
+		} else {
+			parts = append(parts, "never expires")
+		}
+	}
+	if temporary, _ := d["temporary"].(bool); temporary {
+		parts = append(parts, "temporary membership")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func emojiName(d map[string]any) string {
+	if name := stringField(d, "emoji_name"); name != "" {
+		return ":" + name + ":"
+	}
+	return ""
+}
+
+// webhookSummary renders "Captain Hook in #announcements (incoming)".
+func webhookSummary(client *bot.Client, d map[string]any) string {
+	summary := stringField(d, "webhook_name")
+	if chID := channelIDFromDetails(d); chID != 0 {
+		summary = strings.TrimSpace(summary + " in #" + ResolveChannelName(client, chID))
+	}
+	if kind := stringField(d, "webhook_type"); kind != "" {
+		summary = strings.TrimSpace(summary + " (" + kind + ")")
+	}
+	return summary
+}
+
+// integrationSummary renders an integration row as e.g. "bot MEE6
+// (@mee6) with bot, applications.commands". Removals only know the
+// application ID, which the gateway event carries.
+func integrationSummary(d map[string]any) string {
+	summary := strings.TrimSpace(stringField(d, "integration_type") + " " + stringField(d, "integration_name"))
+	if bot := stringField(d, "bot_username"); bot != "" {
+		summary += " (@" + bot + ")"
+	}
+	if scopes := stringList(d, "scopes"); len(scopes) > 0 {
+		summary += " with " + strings.Join(scopes, ", ")
+	}
+	if summary == "" {
+		if app := stringField(d, "application_id"); app != "" {
+			return "application " + app
+		}
+	}
+	return summary
+}
+
+// stringList pulls a list of strings out of a decoded JSON object,
+// skipping non-string items.
+func stringList(d map[string]any, key string) []string {
+	raw, _ := d[key].([]any)
+	out := make([]string, 0, len(raw))
+	for _, item := range raw {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "—"
+	}
+	return s
+}
+
+// filterMatchSummary names what a word filter match matched and what was
+// done about it.
+func filterMatchSummary(d map[string]any) string {
+	summary := ""
+	if matched := stringField(d, "matched"); matched != "" {
+		summary = strconv.Quote(matched)
+	}
+	switch stringField(d, "action") {
+	case "delete":
+		summary += " — deleted"
+	case "warn":
+		summary += " — deleted and warned"
+		if w, ok := d["severity"].(float64); ok {
+			summary += " (severity " + strconv.FormatFloat(w, 'f', -1, 64) + ")"
+		}
+	case "alert":
+		summary += " — moderators alerted"
+	}
+	if edited, _ := d["edited"].(bool); edited {
+		summary += ", after an edit"
+	}
+	return strings.TrimPrefix(summary, " — ")
+}
+
+// antiSpamDetail summarises an anti-spam action by the detectors that
+// fired, and gives each triggering message its own section. channelName
+// resolves the channel IDs stored with each message.
+func antiSpamDetail(d map[string]any, channelName func(snowflake.ID) string) (string, []DetailSection) {
+	var rules []string
+	if list, ok := d["rules"].([]any); ok {
+		for _, r := range list {
+			if name, ok := r.(string); ok {
+				rules = append(rules, name)
+			}
+		}
+	}
+	messages, _ := d["messages"].([]any)
+
+	summary := strings.Join(rules, ", ")
+	if len(messages) > 0 {
+		count := strconv.Itoa(len(messages)) + " messages"
+		if len(messages) == 1 {
+			count = "1 message"
+		}
+		summary = strings.TrimPrefix(summary+" — "+count, " — ")
+	}
+	if observeOnly, _ := d["observe_only"].(bool); observeOnly {
+		if action := stringField(d, "action"); action != "" {
+			summary += "; they would have " + antispam.Action(action).Done()
+		}
+	} else if failed, _ := d["failed"].(bool); failed {
+		summary += "; " + stringField(d, "action") + " failed"
+		if reason := stringField(d, "error"); reason != "" {
+			summary += ": " + reason
+		}
+	} else if minutes, ok := d["timeout_minutes"].(float64); ok && minutes > 0 {
+		summary += ", timed out for " + utils.ApproxDuration(time.Duration(minutes)*time.Minute)
+	}
+
+	sections := make([]DetailSection, 0, len(messages))
+	for _, raw := range messages {
+		m, ok := raw.(map[string]any)
+		if !ok {
+			continue
+		}
+		heading := "Message"
+		if id, err := snowflake.Parse(stringField(m, "channel_id")); err == nil {
+			heading += " in #" + channelName(id)
+		}
+		body := stringField(m, "content")
+		if attachments, ok := m["attachments"].([]any); ok {
+			for _, a := range attachments {
+				if name, ok := a.(string); ok {
+					body = strings.TrimPrefix(body+"\nAttachment: "+name, "\n")
+				}
+			}
+		}
+		sections = append(sections, DetailSection{Heading: heading, Body: body})
+	}
+	return summary, sections
+}
+
+func joinInviteSummary(d map[string]any) string {
+	code := stringField(d, "invite_code")
+	if code == "" {
+		return ""
+	}
+	if vanity, _ := d["vanity"].(bool); vanity {
+		return "via vanity URL " + code
+	}
+	if inviter := stringField(d, "inviter_username"); inviter != "" {
+		return "via invite " + code + " (created by @" + inviter + ")"
+	}
+	return "via invite " + code
+}
+
+// messageAuthorFromDetails returns the original author captured when the
+// gateway delivers a message-delete event. This is distinct from the audit
+// entry actor, which is replaced with the moderator during native enrichment.
+func messageAuthorFromDetails(d map[string]any) string {
+	if username := stringField(d, "author_username"); username != "" {
+		return "@" + username
+	}
+	if id := stringField(d, "author_id"); id != "" {
+		return "@" + id
+	}
+	return ""
+}
+
+// settingsUpdateMetadataKeys are keys present in a settings.update
+// Details payload that are NOT user-changed settings — they're
+// attribution / routing metadata co-located in the same map (e.g.
+// actor_username for cache-miss rendering, section for the form group).
+// formatSettingsUpdate skips these when building the "Changed values"
+// section so the viewer doesn't claim "Actor username: alice" is a
+// setting that was modified.
+var settingsUpdateMetadataKeys = map[string]bool{
+	"section":         true,
+	"actor_username":  true,
+	"target_username": true,
+}
+
+// formatSettingsUpdate renders a web.settings.update entry's details into
+// a humanized summary + a "Changed values" section listing each field as
+// "Field name: value". Channel and role IDs are resolved via the cache
+// so the disclosure shows "#general" rather than the raw snowflake.
+//
+// Keys in settingsUpdateMetadataKeys are skipped — they're attribution
+// metadata, not actual settings.
+func formatSettingsUpdate(client *bot.Client, guildID snowflake.ID, d map[string]any) (string, []DetailSection) {
+	section := stringField(d, "section")
+	summary := settingsSectionLabel(section)
+
+	type field struct {
+		key, label, value string
+	}
+	var fields []field
+	for k, raw := range d {
+		if settingsUpdateMetadataKeys[k] {
+			continue
+		}
+		v := formatSettingsValue(client, guildID, k, raw)
+		fields = append(fields, field{key: k, label: humanizeFieldName(k), value: v})
+	}
+	if len(fields) == 0 {
+		return summary, nil
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].label < fields[j].label })
+
+	var b strings.Builder
+	for i, f := range fields {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(f.label)
+		b.WriteString(": ")
+		b.WriteString(f.value)
+	}
+	return summary, []DetailSection{
+		{Heading: "Changed values", Body: b.String()},
+	}
+}
+
+// settingsSectionLabel maps the internal section identifier (snake_case
+// matching the form route, e.g. "audit_log") to the label moderators see
+// in the audit log viewer.
+func settingsSectionLabel(section string) string {
+	switch section {
+	case "mod_channel":
+		return "Moderator channel"
+	case "infractions":
+		return "Infractions"
+	case "anti_spam":
+		return "Anti-spam"
+	case "raid":
+		return "Raid protection"
+	case "ban_footer":
+		return "Ban footer"
+	case "modmail":
+		return "Modmail"
+	case "gatekeep":
+		return "Gatekeep"
+	case "join_leave":
+		return "Join/leave messages"
+	case "audit_log":
+		return "Audit log"
+	case "message_log":
+		return "Message log"
+	case "audit_stream":
+		return "Audit log channels"
+	}
+	if section == "" {
+		return "Settings"
+	}
+	return section
+}
+
+// humanizeFieldName converts a snake_case settings key into Sentence case.
+// Used as the label inside the changed-values disclosure body. Empty
+// parts (from leading / trailing / consecutive underscores) are dropped
+// so we never index into "" and never emit leading/duplicate spaces.
+func humanizeFieldName(key string) string {
+	if key == "" {
+		return ""
+	}
+	parts := strings.Split(key, "_")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p == "" {
+			continue
+		}
+		if len(out) == 0 {
+			p = strings.ToUpper(p[:1]) + p[1:]
+		}
+		out = append(out, p)
+	}
+	return strings.Join(out, " ")
+}
+
+// formatSettingsValue renders a setting's stored value for display.
+// Booleans become On/Off, channel/role IDs resolve to readable names via
+// the cache. Cleared channel/role fields render as "(none)" — the web
+// dashboard serializes a cleared snowflake as "" via idStr() while
+// command-side updates emit "0", so both shapes funnel to the same label.
+// True override-style fields (retention days, etc.) keep "(default)" for
+// empty values; an explicit "0" for those fields renders as the literal
+// "0". parseRetentionField now collapses 0-when-ceiling-is-0 to nil at
+// save time, so a literal "0" in the audit Details payload should only
+// appear on legacy rows written before that normalization landed.
+func formatSettingsValue(client *bot.Client, guildID snowflake.ID, key string, raw any) string {
+	isSnowflakeKey := strings.HasSuffix(key, "_channel") || strings.HasSuffix(key, "_role")
+	switch v := raw.(type) {
+	case bool:
+		if v {
+			return "On"
+		}
+		return "Off"
+	case string:
+		if v == "" {
+			if isSnowflakeKey {
+				return "(none)"
+			}
+			return "(default)"
+		}
+		if v == "0" && isSnowflakeKey {
+			return "(none)"
+		}
+		if strings.HasSuffix(key, "_channel") {
+			if id, err := snowflake.Parse(v); err == nil {
+				return "#" + ResolveChannelName(client, id)
+			}
+		}
+		if strings.HasSuffix(key, "_role") {
+			if id, err := snowflake.Parse(v); err == nil {
+				return "@" + ResolveRoleName(client, guildID, id)
+			}
+		}
+		return v
+	case float64:
+		if v == float64(int64(v)) {
+			return strconv.FormatInt(int64(v), 10)
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		if isSnowflakeKey {
+			return "(none)"
+		}
+		return "(default)"
+	}
+	return fmt.Sprintf("%v", raw)
+}
+
+// stringField pulls a string out of a generic decoded JSON object,
+// returning "" for both missing and non-string keys.
+func stringField(d map[string]any, key string) string {
+	if v, ok := d[key].(string); ok {
+		return v
+	}
+	return ""
+}
+
+// truncate shortens long strings to width, appending an ellipsis. width
+// is in runes so we don't mid-truncate a UTF-8 sequence.
+func truncate(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	return string(r[:width]) + "…"
+}
+
+// roleChangeSummary builds "+@RoleA, -@RoleB" from a member.role_change
+// details payload. The slices are stored as [{id, name}, ...] by the
+// listener so we can render readable names without re-fetching.
+func roleChangeSummary(d map[string]any) string {
+	added := roleNames(d, "roles_added")
+	removed := roleNames(d, "roles_removed")
+	parts := make([]string, 0, len(added)+len(removed))
+	for _, name := range added {
+		parts = append(parts, "+@"+name)
+	}
+	for _, name := range removed {
+		parts = append(parts, "-@"+name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func roleNames(d map[string]any, key string) []string {
+	raw, ok := d[key].([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(raw))
+	for _, item := range raw {
+		if obj, ok := item.(map[string]any); ok {
+			if name, ok := obj["name"].(string); ok && name != "" {
+				out = append(out, name)
+			}
+		}
+	}
+	return out
+}
+
+// EventLabel returns the human-friendly label for an event type. Falls
+// back to the raw type for unmapped values so adding a new event type
+// degrades gracefully even before its label is added to EventOptions.
+func EventLabel(t string) string {
+	if label, ok := eventLabels[t]; ok {
+		return label
+	}
+	return t
+}
+
+// RefineMemberUpdateLabel returns a more specific label for legacy
+// member.update rows by inspecting which keys are in the already-decoded
+// details map. Current listeners write split event types (member.nick_change,
+// member.role_change, member.timeout_add, member.timeout_clear), so the
+// only member.update rows that reach this function are either pre-split
+// historical rows or the fallback emitted when memberUpdateEnrichmentTargets
+// doesn't recognise any change key — neither path writes timeout fields.
+func RefineMemberUpdateLabel(fallback string, d map[string]any) string {
+	if d == nil {
+		return fallback
+	}
+	// Type-assert against string so a JSON `null` value is treated as
+	// absent — matches the old pointer-based decode where `null` decoded
+	// to a nil *string. Current writers don't emit null nicks (they go
+	// to EventMemberNickChange), but legacy/hand-edited rows might.
+	_, hasNickBefore := d["nick_before"].(string)
+	_, hasNickAfter := d["nick_after"].(string)
+	rolesAdded, _ := d["roles_added"].([]any)
+	rolesRemoved, _ := d["roles_removed"].([]any)
+
+	switch {
+	case len(rolesAdded) > 0 && len(rolesRemoved) > 0:
+		return "Roles changed"
+	case len(rolesAdded) > 0:
+		return "Role added"
+	case len(rolesRemoved) > 0:
+		return "Role removed"
+	case hasNickAfter || hasNickBefore:
+		return "Nickname changed"
+	}
+	return fallback
+}
+
+// EventOption is a labelled event type, as offered by the viewer's event
+// filter.
+type EventOption struct {
+	Type  EventType
+	Label string
+}
+
+// EventOptions lists every current event type with its label, in the
+// order the viewer's filter dropdown shows them. A new event type needs
+// an entry here and a case in EventCategory.
+var EventOptions = []EventOption{
+	{EventMessageEdit, "Message edited"},
+	{EventMessageDelete, "Message deleted"},
+	{EventMessageBulkDelete, "Messages bulk deleted"},
+	{EventThreadCreate, "Thread created"},
+	{EventThreadArchive, "Thread archive changed"},
+	{EventThreadLock, "Thread lock changed"},
+	{EventThreadDelete, "Thread deleted"},
+	{EventFilterMatch, "Word filter match"},
+	{EventAntiSpamDelete, "Anti-spam deleted messages"},
+	{EventAntiSpamTimeout, "Anti-spam timeout"},
+	{EventAntiSpamKick, "Anti-spam kick"},
+	{EventAntiSpamBan, "Anti-spam ban"},
+	{EventAntiSpamQuarantine, "Anti-spam quarantine"},
+	{EventAntiSpamObserve, "Anti-spam (observe only)"},
+	{EventAutoModAction, "AutoMod action"},
+	{EventMemberNickChange, "Nickname changed"},
+	{EventMemberRoleChange, "Roles changed"},
+	{EventMemberTimeoutAdd, "Member timed out"},
+	{EventMemberTimeoutClear, "Timeout cleared"},
+	{EventMemberJoin, "Member joined"},
+	{EventMemberLeave, "Member left"},
+	{EventVoiceJoin, "Joined voice"},
+	{EventVoiceLeave, "Left voice"},
+	{EventVoiceMove, "Moved voice channel"},
+	{EventVoiceServerMute, "Server mute changed"},
+	{EventVoiceServerDeafen, "Server deafen changed"},
+	{EventVoiceDisconnect, "Disconnected from voice"},
+	{EventInviteCreate, "Invite created"},
+	{EventInviteDelete, "Invite deleted"},
+	{EventGatekeepApprove, "Gatekeep approved"},
+	{EventGatekeepDeny, "Gatekeep denied"},
+	{EventGatekeepKick, "Gatekeep kicked"},
+	{EventGuildBan, "Member banned"},
+	{EventGuildUnban, "Member unbanned"},
+	{EventGuildKick, "Member kicked"},
+	{EventGuildPrune, "Members pruned"},
+	{EventBotWarn, "Bot warning issued"},
+	{EventLockdownStart, "Lockdown started"},
+	{EventLockdownEnd, "Lockdown ended"},
+	{EventChannelLock, "Channel locked"},
+	{EventChannelUnlock, "Channel unlocked"},
+	{EventChannelCreate, "Channel created"},
+	{EventChannelUpdate, "Channel updated"},
+	{EventChannelDelete, "Channel deleted"},
+	{EventChannelPermissionsUpdate, "Channel permissions changed"},
+	{EventRoleCreate, "Role created"},
+	{EventRoleUpdate, "Role updated"},
+	{EventRoleDelete, "Role deleted"},
+	{EventEmojiCreate, "Emoji added"},
+	{EventEmojiUpdate, "Emoji updated"},
+	{EventEmojiDelete, "Emoji removed"},
+	{EventStickerCreate, "Sticker added"},
+	{EventStickerUpdate, "Sticker updated"},
+	{EventStickerDelete, "Sticker removed"},
+	{EventWebhookCreate, "Webhook created"},
+	{EventWebhookDelete, "Webhook deleted"},
+	{EventIntegrationCreate, "Integration added"},
+	{EventIntegrationDelete, "Integration removed"},
+	{EventAutoModRuleCreate, "AutoMod rule created"},
+	{EventAutoModRuleUpdate, "AutoMod rule updated"},
+	{EventAutoModRuleDelete, "AutoMod rule deleted"},
+	{EventSettingsUpdate, "Settings updated"},
+	{EventAuditLogExport, "Audit log exported"},
+	{EventWebPostCreate, "Post created"},
+	{EventWebPostUpdate, "Post updated"},
+	{EventWebPostDelete, "Post deleted"},
+}
+
+// eventLabels backs EventLabel; it runs per row, so an O(1) map derived
+// from EventOptions rather than a scan.
+var eventLabels = func() map[string]string {
+	m := make(map[string]string, len(EventOptions)+2)
+	for _, opt := range EventOptions {
+		m[string(opt.Type)] = opt.Label
+	}
+	// Legacy event types — kept out of the filter dropdown (new entries
+	// use the renamed types) but still possible on DB rows written
+	// before the rename, so they get a readable label rather than
+	// falling through to the raw event string. RefineMemberUpdateLabel
+	// may further refine the member.update label when the details
+	// payload identifies the specific change.
+	m[string(EventMemberUpdate)] = "Member updated"
+	m[string(EventWebSettingsUpdate)] = "Settings updated"
+	return m
+}()
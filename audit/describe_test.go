@@ -0,0 +1,284 @@
+package audit
+
+import (
+	"testing"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescribe_MessageDeleteIncludesOriginalAuthor(t *testing.T) {
+	summary, sections := Describe(nil, 0, string(EventMessageDelete), map[string]any{
+		"author_id":       "123",
+		"author_username": "messageAuthor",
+		"before_content":  "Deleted by a moderator.",
+	})
+
+	assert.Equal(t, "@messageAuthor: Deleted by a moderator.", summary)
+	require.Len(t, sections, 2)
+	assert.Equal(t, "Message author", sections[0].Heading)
+	assert.Equal(t, "@messageAuthor", sections[0].Body)
+	assert.Equal(t, "Deleted message", sections[1].Heading)
+	assert.Equal(t, "Deleted by a moderator.", sections[1].Body)
+}
+
+func TestMemberJoinLeaveSummary(t *testing.T) {
+	assert.Equal(t, "via invite abc", memberJoinSummary(map[string]any{"invite_code": "abc", "visit": float64(1)}))
+	assert.Equal(t, "3rd visit, via invite abc", memberJoinSummary(map[string]any{"invite_code": "abc", "visit": float64(3)}))
+	assert.Equal(t, "2nd visit", memberJoinSummary(map[string]any{"visit": float64(2)}))
+
+	assert.Equal(t, "", memberLeaveSummary(map[string]any{}))
+	assert.Equal(t, "after 2 days", memberLeaveSummary(map[string]any{"tenure_seconds": float64(2*24*3600 + 60)}))
+}
+
+func TestChannelLockSummary(t *testing.T) {
+	assert.Equal(t, "", channelLockSummary(map[string]any{}))
+	assert.Equal(t, "#general until 2026-01-02T03:04:05Z", channelLockSummary(map[string]any{
+		"channel_name": "general", "until": "2026-01-02T03:04:05Z",
+	}))
+	assert.Equal(t, "#general (expired)", channelLockSummary(map[string]any{
+		"channel_name": "general", "expired": true,
+	}))
+}
+
+func TestAuditExportSummary(t *testing.T) {
+	assert.Equal(t, "12 entries as CSV", auditExportSummary(map[string]any{"format": "csv", "entries": float64(12)}))
+	assert.Equal(t, "0 entries as JSONL · category=message, from=2026-01-01", auditExportSummary(map[string]any{
+		"format": "jsonl", "entries": float64(0),
+		"filters": map[string]any{"from": "2026-01-01", "category": "message"},
+	}))
+}
+
+func TestAntiSpamDetail(t *testing.T) {
+	channelName := func(id snowflake.ID) string { return "chan" + id.String() }
+	d := map[string]any{
+		"action":          "timeout",
+		"rules":           []any{"repeated messages", "message burst"},
+		"timeout_minutes": float64(60),
+		"messages": []any{
+			map[string]any{"channel_id": "1", "message_id": "10", "content": "buy nitro"},
+			map[string]any{"channel_id": "2", "message_id": "11", "content": "buy nitro", "attachments": []any{"nitro.png 10x10 100"}},
+		},
+	}
+	summary, sections := antiSpamDetail(d, channelName)
+	assert.Equal(t, "repeated messages, message burst — 2 messages, timed out for 1 hour", summary)
+	require.Len(t, sections, 2)
+	assert.Equal(t, "Message in #chan1", sections[0].Heading)
+	assert.Equal(t, "buy nitro", sections[0].Body)
+	assert.Equal(t, "buy nitro\nAttachment: nitro.png 10x10 100", sections[1].Body)
+
+	d["observe_only"] = true
+	d["messages"] = d["messages"].([]any)[:1]
+	summary, _ = antiSpamDetail(d, channelName)
+	assert.Equal(t, "repeated messages, message burst — 1 message; they would have been timed out", summary)
+
+	delete(d, "observe_only")
+	delete(d, "timeout_minutes")
+	d["action"], d["failed"], d["error"] = "ban", true, "Discord rejected the request"
+	summary, _ = antiSpamDetail(d, channelName)
+	assert.Equal(t, "repeated messages, message burst — 1 message; ban failed: Discord rejected the request", summary)
+
+	summary, sections = antiSpamDetail(map[string]any{}, channelName)
+	assert.Empty(t, summary)
+	assert.Empty(t, sections)
+}
+
+func TestFilterMatchSummary(t *testing.T) {
+	assert.Equal(t, "", filterMatchSummary(map[string]any{}))
+	assert.Equal(t, `"b4d" — deleted`, filterMatchSummary(map[string]any{"matched": "b4d", "action": "delete"}))
+	assert.Equal(t, `"bad" — deleted and warned (severity 1.5), after an edit`, filterMatchSummary(map[string]any{
+		"matched": "bad", "action": "warn", "severity": 1.5, "edited": true,
+	}))
+	assert.Equal(t, "moderators alerted", filterMatchSummary(map[string]any{"action": "alert"}))
+}
+
+func TestJoinInviteSummary(t *testing.T) {
+	assert.Equal(t, "", joinInviteSummary(map[string]any{}))
+	assert.Equal(t, "via invite abc", joinInviteSummary(map[string]any{"invite_code": "abc"}))
+	assert.Equal(t, "via invite abc (created by @alice)", joinInviteSummary(map[string]any{
+		"invite_code": "abc", "inviter_username": "alice",
+	}))
+	assert.Equal(t, "via vanity URL cool", joinInviteSummary(map[string]any{
+		"invite_code": "cool", "vanity": true,
+	}))
+}
+
+func TestFormatSettingsUpdate_SkipsMetadata(t *testing.T) {
+	// formatSettingsUpdate calls formatSettingsValue, which for plain
+	// scalar values (no channel/role suffix) doesn't touch the disgo
+	// cache — we can pass a nil client + zero guildID and still exercise
+	// the metadata-filter path. The "Changed values" section body should
+	// contain only the real-setting keys, not actor_username/section.
+	details := map[string]any{
+		"section":        "audit_log",
+		"actor_username": "@admin",
+		"enabled":        true,
+		"half_life_days": float64(14),
+	}
+
+	summary, sections := formatSettingsUpdate(nil, 0, details)
+	assert.Equal(t, "Audit log", summary)
+	require.Len(t, sections, 1)
+	body := sections[0].Body
+
+	// Must contain the real settings.
+	assert.Contains(t, body, "Enabled:")
+	assert.Contains(t, body, "Half life days:")
+	// Must NOT contain attribution metadata.
+	assert.NotContains(t, body, "Actor username")
+	assert.NotContains(t, body, "Section:")
+}
+
+func TestFormatSettingsUpdate_OnlyMetadata_ReturnsNoSections(t *testing.T) {
+	// A degenerate payload that only carries metadata (no settings were
+	// actually included) should still render the section header but
+	// produce no "Changed values" disclosure.
+	details := map[string]any{
+		"section":        "modmail",
+		"actor_username": "@admin",
+	}
+
+	summary, sections := formatSettingsUpdate(nil, 0, details)
+	assert.Equal(t, "Modmail", summary)
+	assert.Nil(t, sections)
+}
+
+func TestDescribe_RoleUpdate(t *testing.T) {
+	summary, sections := Describe(nil, 0, string(EventRoleUpdate), map[string]any{
+		"role_name":           "Helpers",
+		"permissions_added":   []any{"Administrator"},
+		"permissions_removed": []any{"Kick Members"},
+		"changes":             []any{map[string]any{"field": "name", "before": "Helper", "after": "Helpers"}},
+	})
+	assert.Equal(t, "@Helpers: granted Administrator; revoked Kick Members; name changed", summary)
+	require.Len(t, sections, 3)
+	assert.Equal(t, DetailSection{Heading: "Permissions granted", Body: "Administrator"}, sections[0])
+	assert.Equal(t, DetailSection{Heading: "Changes", Body: "Name: Helper → Helpers"}, sections[2])
+}
+
+func TestDescribe_ChannelPermissionsUpdate(t *testing.T) {
+	summary, sections := Describe(nil, 0, string(EventChannelPermissionsUpdate), map[string]any{
+		"channel_name": "staff",
+		"overwrites": []any{
+			map[string]any{"kind": "role", "name": "@everyone", "action": "changed", "denied": []any{"View Channel"}},
+			map[string]any{"kind": "member", "id": "42", "action": "added", "allowed": []any{"View Channel", "Send Messages"}},
+		},
+	})
+	assert.Equal(t, "#staff: @everyone, @42", summary)
+	assert.Equal(t, []DetailSection{
+		{Heading: "@everyone (role, changed)", Body: "Denied: View Channel"},
+		{Heading: "@42 (member, added)", Body: "Allowed: View Channel, Send Messages"},
+	}, sections)
+}
+
+func TestDescribe_ChannelCreate(t *testing.T) {
+	summary, sections := Describe(nil, 0, string(EventChannelCreate), map[string]any{
+		"channel_name": "voice-1", "channel_type": "voice channel",
+	})
+	assert.Equal(t, "#voice-1 (voice channel)", summary)
+	assert.Empty(t, sections)
+}
+
+func TestIntegrationSummary(t *testing.T) {
+	assert.Equal(t, "bot MEE6 (@mee6) with bot, applications.commands", integrationSummary(map[string]any{
+		"integration_type": "bot", "integration_name": "MEE6", "bot_username": "mee6",
+		"scopes": []any{"bot", "applications.commands"},
+	}))
+	assert.Equal(t, "application 42", integrationSummary(map[string]any{"application_id": "42"}))
+}
+
+func TestDescribe_EmojiUpdate(t *testing.T) {
+	summary, sections := Describe(nil, 0, string(EventEmojiUpdate), map[string]any{
+		"emoji_name": "pog",
+		"changes":    []any{map[string]any{"field": "name", "before": "poggers", "after": "pog"}},
+	})
+	assert.Equal(t, ":pog:: name changed", summary)
+	require.Len(t, sections, 1)
+	assert.Equal(t, "Name: poggers → pog", sections[0].Body)
+}
+
+func TestVoiceSummary(t *testing.T) {
+	assert.Equal(t, "#General", voiceSummary(nil, string(EventVoiceJoin), map[string]any{"channel_name": "General"}))
+	assert.Equal(t, "#General → #Gaming", voiceSummary(nil, string(EventVoiceMove), map[string]any{
+		"from_channel_name": "General", "channel_name": "Gaming",
+	}))
+	assert.Equal(t, "muted in #General", voiceSummary(nil, string(EventVoiceServerMute), map[string]any{
+		"channel_name": "General", "muted": true,
+	}))
+	assert.Equal(t, "3 members", voiceSummary(nil, string(EventVoiceDisconnect), map[string]any{"count": float64(3)}))
+}
+
+func TestDescribe_AutoModAction(t *testing.T) {
+	summary, sections := Describe(nil, 0, string(EventAutoModAction), map[string]any{
+		"rule_name":       "Slurs",
+		"matched_keyword": "badword",
+		"matched_content": "badwords",
+		"action":          "timeout",
+		"timeout_seconds": float64(600),
+		"content":         "you badwords",
+	})
+	assert.Equal(t, `Slurs: "badword" — timed out for 10 minutes`, summary)
+	assert.Equal(t, []DetailSection{
+		{Heading: "Message", Body: "you badwords"},
+		{Heading: "Matched content", Body: "badwords"},
+	}, sections)
+}
+
+func TestDescribe_AutoModRuleUpdate(t *testing.T) {
+	summary, sections := Describe(nil, 0, string(EventAutoModRuleUpdate), map[string]any{
+		"automod_rule_name": "Slurs",
+		"keywords_added":    []any{"baz"},
+		"changes":           []any{map[string]any{"field": "enabled", "before": "On", "after": "Off"}},
+	})
+	assert.Equal(t, "Slurs: added keywords baz; enabled changed", summary)
+	assert.Equal(t, []DetailSection{
+		{Heading: "Keywords added", Body: "baz"},
+		{Heading: "Changes", Body: "Enabled: On → Off"},
+	}, sections)
+}
+
+func TestDescribe_BulkDelete(t *testing.T) {
+	summary, sections := Describe(nil, 0, string(EventMessageBulkDelete), map[string]any{
+		"channel_id":  "5",
+		"count":       float64(3),
+		"message_ids": []any{"10", "11", "12"},
+		"messages": []any{
+			map[string]any{"id": "10", "author_username": "alice", "content": "hi"},
+			map[string]any{"id": "11", "author_username": "bob", "content": "spam"},
+		},
+	})
+	assert.Equal(t, "3 messages (2 with content)", summary)
+	assert.Equal(t, []DetailSection{
+		{Heading: "@alice", Body: "hi"},
+		{Heading: "@bob", Body: "spam"},
+	}, sections)
+
+	summary, sections = Describe(nil, 0, string(EventMessageBulkDelete), map[string]any{
+		"message_ids": []any{"10", "11"},
+	})
+	assert.Equal(t, "2 messages", summary)
+	assert.Empty(t, sections)
+}
+
+func TestThreadSummary(t *testing.T) {
+	assert.Equal(t, "#help in #support (forum post)", threadSummary(map[string]any{
+		"thread_name": "help", "parent_name": "support", "forum_post": true,
+	}))
+	assert.Equal(t, "#chat", threadSummary(map[string]any{"thread_name": "chat"}))
+}
+
+func TestInviteSummary(t *testing.T) {
+	assert.Equal(t, "#general, max 5 uses, never expires, temporary membership", inviteSummary(nil, map[string]any{
+		"channel_id": "5", "channel_name": "general",
+		"max_uses": float64(5), "max_age_seconds": float64(0), "temporary": true,
+	}))
+
+	summary, _ := Describe(nil, 0, string(EventInviteDelete), map[string]any{
+		"code": "abc", "uses": float64(2), "max_uses": float64(10), "inviter_username": "alice",
+	})
+	assert.Equal(t, "2 of 10 uses, created by @alice", summary)
+
+	summary, _ = Describe(nil, 0, string(EventInviteDelete), map[string]any{"code": "abc", "inviter_username": "alice"})
+	assert.Equal(t, "created by @alice", summary)
+}
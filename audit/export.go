@@ -0,0 +1,240 @@
+package audit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// ExportFormat is a file format audit log entries can be exported in.
+type ExportFormat string
+
+const (
+	ExportCSV   ExportFormat = "csv"
+	ExportJSONL ExportFormat = "jsonl"
+)
+
+// exportBatchSize is how many rows Export loads per query.
+const exportBatchSize = 500
+
+// ParseExportFormat accepts "csv" or "jsonl", case-insensitively. An empty
+// string means CSV.
+func ParseExportFormat(s string) (ExportFormat, error) {
+	switch ExportFormat(strings.ToLower(strings.TrimSpace(s))) {
+	case "", ExportCSV:
+		return ExportCSV, nil
+	case ExportJSONL:
+		return ExportJSONL, nil
+	}
+	return "", fmt.Errorf("unknown export format %q", s)
+}
+
+func (f ExportFormat) ContentType() string {
+	if f == ExportJSONL {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// ExportFilename names an export file after the guild and the time it
+// was made, e.g. "audit-log-123-20260102-150405.csv".
+func ExportFilename(guildID snowflake.ID, f ExportFormat, now time.Time) string {
+	return fmt.Sprintf("audit-log-%d-%s.%s", guildID, now.UTC().Format("20060102-150405"), f)
+}
+
+// ExportRecord is one exported entry. Actor and Target are the names the
+// viewer would show at export time; the IDs are kept alongside because
+// names change and users leave.
+type ExportRecord struct {
+	ID         uint           `json:"id"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Category   string         `json:"category"`
+	EventType  string         `json:"event_type"`
+	Actor      string         `json:"actor"`
+	ActorID    string         `json:"actor_id"`
+	ActorKind  string         `json:"actor_kind"`
+	Target     string         `json:"target"`
+	TargetID   string         `json:"target_id"`
+	TargetKind string         `json:"target_kind"`
+	Source     string         `json:"source"`
+	Reason     string         `json:"reason"`
+	Details    map[string]any `json:"details"`
+}
+
+var exportCSVHeader = []string{
+	"id", "created_at", "category", "event_type",
+	"actor", "actor_id", "actor_kind",
+	"target", "target_id", "target_kind",
+	"source", "reason", "details",
+}
+
+// NewExportRecord resolves e's actor and target names the same way the
+// viewer does and decodes its Details.
+func NewExportRecord(client *bot.Client, guildID snowflake.ID, e model.AuditLogEntry) ExportRecord {
+	var details map[string]any
+	if e.Details != "" {
+		if err := json.Unmarshal([]byte(e.Details), &details); err != nil {
+			slog.Warn("audit export: unparseable Details JSON", "err", err, "entry_id", e.ID)
+		}
+	}
+	return ExportRecord{
+		ID:         e.ID,
+		CreatedAt:  e.CreatedAt.UTC(),
+		Category:   e.Category,
+		EventType:  e.EventType,
+		Actor:      exportName(FormatActor(client, guildID, ActorKind(e.ActorKind), e.ActorID, details)),
+		ActorID:    exportID(e.ActorID),
+		ActorKind:  e.ActorKind,
+		Target:     exportName(FormatTarget(client, guildID, TargetKind(e.TargetKind), e.TargetID, details)),
+		TargetID:   exportID(e.TargetID),
+		TargetKind: e.TargetKind,
+		Source:     e.Source,
+		Reason:     e.Reason,
+		Details:    details,
+	}
+}
+
+// exportName drops the viewer's "—" placeholder; an empty cell says the
+// same thing in a file.
+func exportName(s string) string {
+	if s == "—" {
+		return ""
+	}
+	return s
+}
+
+func exportID(id *snowflake.ID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+// ExportWriter writes ExportRecords to an io.Writer. Call Flush once
+// done; for CSV the header is written even when there are no records.
+type ExportWriter struct {
+	csv  *csv.Writer
+	json *json.Encoder
+}
+
+func NewExportWriter(w io.Writer, f ExportFormat) *ExportWriter {
+	x := &ExportWriter{}
+	if f == ExportJSONL {
+		x.json = json.NewEncoder(w)
+		x.json.SetEscapeHTML(false)
+		return x
+	}
+	x.csv = csv.NewWriter(w)
+	_ = x.csv.Write(exportCSVHeader)
+	return x
+}
+
+func (x *ExportWriter) Write(r ExportRecord) error {
+	if x.json != nil {
+		return x.json.Encode(r)
+	}
+	details := ""
+	if r.Details != nil {
+		b, err := json.Marshal(r.Details)
+		if err != nil {
+			return err
+		}
+		details = string(b)
+	}
+	return x.csv.Write([]string{
+		strconv.FormatUint(uint64(r.ID), 10),
+		r.CreatedAt.Format(time.RFC3339),
+		r.Category,
+		r.EventType,
+		csvCell(r.Actor),
+		r.ActorID,
+		r.ActorKind,
+		csvCell(r.Target),
+		r.TargetID,
+		r.TargetKind,
+		r.Source,
+		csvCell(r.Reason),
+		csvCell(details),
+	})
+}
+
+func (x *ExportWriter) Flush() error {
+	if x.csv == nil {
+		return nil
+	}
+	x.csv.Flush()
+	return x.csv.Error()
+}
+
+// csvCell defuses user-supplied text that a spreadsheet would otherwise
+// run as a formula when the file is opened, by prefixing a quote. Every
+// free-text column goes through it: names and details are as
+// user-controlled as the reason, and "@name" is itself a formula prefix.
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// Export writes every entry in the guild matching filter to w, oldest
+// first, and returns how many it wrote.
+func Export(ctx context.Context, w io.Writer, client *bot.Client, guildID snowflake.ID, filter model.AuditLogFilter, f ExportFormat) (int, error) {
+	x := NewExportWriter(w, f)
+	n := 0
+	err := model.EachAuditLogEntry(ctx, guildID, filter, exportBatchSize, func(e model.AuditLogEntry) error {
+		n++
+		return x.Write(NewExportRecord(client, guildID, e))
+	})
+	if err != nil {
+		return n, err
+	}
+	return n, x.Flush()
+}
+
+// LogExport records an audit_log.export entry. filters holds the filter
+// values the export was made with, as entered; empty values are left out.
+// A zero actorID is logged as a system action.
+func LogExport(guildID, actorID snowflake.ID, actorUsername string, source Source, f ExportFormat, count int, filters map[string]string) {
+	details := map[string]any{
+		"format":  string(f),
+		"entries": count,
+	}
+	applied := make(map[string]string, len(filters))
+	for k, v := range filters {
+		if v != "" {
+			applied[k] = v
+		}
+	}
+	if len(applied) > 0 {
+		details["filters"] = applied
+	}
+
+	gid := guildID
+	entry := Entry{
+		GuildID:    guildID,
+		EventType:  EventAuditLogExport,
+		ActorKind:  ActorSystem,
+		TargetID:   &gid,
+		TargetKind: TargetGuild,
+		Source:     source,
+		Details:    details,
+	}
+	if actorID != 0 {
+		entry.ActorID = &actorID
+		entry.ActorKind = ActorUser
+		details["actor_username"] = actorUsername
+	}
+	Log(entry)
+}
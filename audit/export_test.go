@@ -0,0 +1,88 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExportFormat(t *testing.T) {
+	f, err := ParseExportFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, ExportCSV, f)
+
+	f, err = ParseExportFormat(" JSONL ")
+	require.NoError(t, err)
+	assert.Equal(t, ExportJSONL, f)
+
+	_, err = ParseExportFormat("xlsx")
+	assert.Error(t, err)
+}
+
+func TestExportFilename(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	assert.Equal(t, "audit-log-123-20260102-150405.jsonl", ExportFilename(123, ExportJSONL, now))
+}
+
+func exportTestRecord() ExportRecord {
+	return ExportRecord{
+		ID:         7,
+		CreatedAt:  time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+		Category:   "guild",
+		EventType:  "guild.ban",
+		Actor:      "@mod",
+		ActorID:    "1",
+		ActorKind:  "user",
+		Target:     "+spammer",
+		TargetID:   "2",
+		TargetKind: "user",
+		Source:     "gateway",
+		Reason:     "=HYPERLINK(\"x\")",
+		Details:    map[string]any{"target_username": "spammer"},
+	}
+}
+
+func TestExportWriter_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	x := NewExportWriter(&buf, ExportCSV)
+	require.NoError(t, x.Write(exportTestRecord()))
+	require.NoError(t, x.Flush())
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, exportCSVHeader, rows[0])
+	assert.Equal(t, []string{
+		"7", "2026-01-02T15:04:05Z", "guild", "guild.ban",
+		"'@mod", "1", "user", "'+spammer", "2", "user",
+		"gateway", "'=HYPERLINK(\"x\")", `{"target_username":"spammer"}`,
+	}, rows[1])
+}
+
+func TestExportWriter_CSVHeaderOnlyWhenEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, NewExportWriter(&buf, ExportCSV).Flush())
+	assert.Equal(t, strings.Join(exportCSVHeader, ",")+"\n", buf.String())
+}
+
+func TestExportWriter_JSONL(t *testing.T) {
+	var buf bytes.Buffer
+	x := NewExportWriter(&buf, ExportJSONL)
+	require.NoError(t, x.Write(exportTestRecord()))
+	require.NoError(t, x.Write(exportTestRecord()))
+	require.NoError(t, x.Flush())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	var got map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &got))
+	assert.Equal(t, "=HYPERLINK(\"x\")", got["reason"], "JSONL is not spreadsheet-escaped")
+	assert.Equal(t, map[string]any{"target_username": "spammer"}, got["details"])
+	assert.Equal(t, "2026-01-02T15:04:05Z", got["created_at"])
+}
@@ -181,6 +181,9 @@ func LogPending(entry Entry, enrichable []EnrichField) {
 	if !shouldLog(entry.GuildID) {
 		return
 	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
 
 	allowed := make(map[EnrichField]bool, len(enrichable))
 	for _, f := range enrichable {
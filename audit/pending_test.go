@@ -88,6 +88,33 @@ func TestLogPending_FlushesAfterTTL(t *testing.T) {
 	assert.EqualValues(t, 1, countRows(t, guildID), "row should have been committed after TTL")
 }
 
+// A pending entry is stamped when it arrives, not when it commits, so a
+// Log call made while it waits is stored with a later time despite its
+// lower ID.
+func TestLogPending_KeepsArrivalTime(t *testing.T) {
+	setupTestDB(t)
+	guildID := snowflake.ID(1011)
+	guildEnabled(t, guildID)
+
+	user := snowflake.ID(2)
+	LogPending(Entry{
+		GuildID: guildID, EventType: EventVoiceLeave, ActorKind: ActorUser,
+		TargetID: &user, TargetKind: TargetUser, Source: SourceGateway,
+	}, []EnrichField{EnrichActor})
+	time.Sleep(5 * time.Millisecond)
+	Log(Entry{
+		GuildID: guildID, EventType: EventVoiceJoin, ActorKind: ActorUser,
+		TargetID: &user, TargetKind: TargetUser, Source: SourceGateway,
+	})
+	time.Sleep(pendingTTL * 3)
+
+	var rows []model.AuditLogEntry
+	require.NoError(t, model.DB.Where("guild_id = ?", guildID).Order("id").Find(&rows).Error)
+	require.Len(t, rows, 2)
+	assert.Equal(t, string(EventVoiceJoin), rows[0].EventType, "the pending leave commits last")
+	assert.True(t, rows[1].CreatedAt.Before(rows[0].CreatedAt), "but keeps the time it arrived")
+}
+
 func TestTryEnrich_AttachesActorAndCommits(t *testing.T) {
 	setupTestDB(t)
 	guildID := snowflake.ID(1002)
@@ -655,6 +682,10 @@ func TestLog_NoopWhenDisabled(t *testing.T) {
 	}
 	assert.Equal(t, int64(3), hits.Load())
 	assert.EqualValues(t, 0, countRows(t, guildID), "no rows should be written while disabled")
+	assert.False(t, Enabled(guildID))
+
+	guildEnabled(t, guildID)
+	assert.True(t, Enabled(guildID))
 }
 
 // Stress test: many goroutines mixing LogPending and TryEnrich for the
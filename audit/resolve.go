@@ -2,6 +2,7 @@ package audit
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/snowflake/v2"
@@ -168,13 +169,50 @@ func FormatTarget(
 
 	case TargetChannel:
 		if id != nil {
+			// Deleted channels are gone from the cache; channel
+			// configuration and thread events record the name at write
+			// time.
+			if _, ok := client.Caches.Channel(*id); !ok {
+				if name, ok := details["channel_name"].(string); ok && name != "" {
+					return "#" + name
+				}
+				if name, ok := details["thread_name"].(string); ok && name != "" {
+					return "#" + name
+				}
+			}
 			return "#" + ResolveChannelName(client, *id)
 		}
 		return "—"
 
 	case TargetRole:
 		if id != nil {
-			return "@" + ResolveRoleName(client, guildID, *id)
+			if _, ok := client.Caches.Role(guildID, *id); !ok {
+				if name, ok := details["role_name"].(string); ok && name != "" {
+					return "@" + strings.TrimPrefix(name, "@")
+				}
+			}
+			return "@" + strings.TrimPrefix(ResolveRoleName(client, guildID, *id), "@")
+		}
+		return "—"
+
+	case TargetEmoji, TargetSticker, TargetWebhook, TargetIntegration, TargetAutoModRule:
+		// These can't be resolved from the cache once removed, so the
+		// listeners record their names at write time.
+		name, _ := details[string(kind)+"_name"].(string)
+		switch {
+		case name != "" && kind == TargetEmoji:
+			return ":" + name + ":"
+		case name != "":
+			return name
+		case id != nil:
+			return id.String()
+		}
+		return "—"
+
+	case TargetInvite:
+		// Invites are keyed by code rather than a snowflake.
+		if code, ok := details["code"].(string); ok && code != "" {
+			return code
 		}
 		return "—"
 
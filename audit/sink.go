@@ -0,0 +1,29 @@
+package audit
+
+import "sync"
+
+// Sink receives each entry after it has been written, with any native
+// audit log enrichment already applied. Sinks run on the committing
+// goroutine (a gateway listener or a pending-entry timer), so they must
+// hand slow work such as Discord API calls off rather than block.
+type Sink func(Entry)
+
+var (
+	sinksMu sync.RWMutex
+	sinks   []Sink
+)
+
+// AddSink registers s to receive every entry written from now on.
+func AddSink(s Sink) {
+	sinksMu.Lock()
+	sinks = append(sinks, s)
+	sinksMu.Unlock()
+}
+
+func notifySinks(entry Entry) {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	for _, s := range sinks {
+		s(entry)
+	}
+}
@@ -0,0 +1,60 @@
+package audit
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+func TestSink_ReceivesEnrichedEntries(t *testing.T) {
+	setupTestDB(t)
+	guildID := snowflake.ID(1101)
+	guildEnabled(t, guildID)
+
+	var mu sync.Mutex
+	var got []Entry
+	// Sinks can't be removed, so this one ignores other tests' guilds.
+	AddSink(func(e Entry) {
+		if e.GuildID != guildID {
+			return
+		}
+		mu.Lock()
+		got = append(got, e)
+		mu.Unlock()
+	})
+
+	target := snowflake.ID(3101)
+	LogPending(Entry{
+		GuildID:    guildID,
+		EventType:  EventGuildBan,
+		ActorKind:  ActorUnknown,
+		TargetID:   &target,
+		TargetKind: TargetUser,
+		Source:     SourceGateway,
+	}, []EnrichField{EnrichActor})
+
+	mu.Lock()
+	assert.Empty(t, got, "pending entries reach sinks only once written")
+	mu.Unlock()
+
+	moderator := snowflake.ID(4101)
+	TryEnrich(guildID, EventGuildBan, &target, nil, &moderator, ActorUser, "mod", "", MatchFirst, 0)
+	Log(Entry{GuildID: 9999, EventType: EventBotWarn})
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, got, 1)
+	require.NotNil(t, got[0].ActorID)
+	assert.Equal(t, moderator, *got[0].ActorID)
+	assert.Equal(t, CategoryGuild, got[0].Category)
+
+	var row model.AuditLogEntry
+	require.NoError(t, model.DB.Where("guild_id = ?", guildID).First(&row).Error)
+	assert.Equal(t, row.ID, got[0].ID, "sinks see the written row's ID")
+	assert.True(t, row.CreatedAt.Equal(got[0].CreatedAt), "sinks see the written row's time")
+}
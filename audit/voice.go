@@ -0,0 +1,106 @@
+package audit
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// VoiceEventTypes are the event types that place a member in or take
+// them out of a voice channel, as VoiceSessions reads them.
+var VoiceEventTypes = []string{
+	string(EventVoiceJoin),
+	string(EventVoiceLeave),
+	string(EventVoiceMove),
+}
+
+// VoiceSession is one stay of a member in a voice channel, reconstructed
+// from the voice audit log entries.
+type VoiceSession struct {
+	UserID   snowflake.ID
+	Username string
+	// Start is zero when the member was already connected before the
+	// first entry read; End is zero when they were still connected after
+	// the last one.
+	Start time.Time
+	End   time.Time
+}
+
+// Overlaps reports whether the session overlaps the window [from, to).
+func (s VoiceSession) Overlaps(from, to time.Time) bool {
+	return (s.Start.IsZero() || s.Start.Before(to)) && (s.End.IsZero() || s.End.After(from))
+}
+
+// VoiceSessions replays voice join / leave / move entries in the order
+// they happened, by CreatedAt rather than ID: leaves and moves are held
+// for enrichment before they are written, so a quick rejoin can get the
+// lower ID. It returns the sessions in channelID in the order they
+// started. A
+// member seen leaving without a recorded join gets a session with no
+// start; one seen joining twice without a leave in between (the bot
+// missed it, e.g. while restarting) has the earlier session ended at the
+// second join.
+func VoiceSessions(entries []model.AuditLogEntry, channelID snowflake.ID) []VoiceSession {
+	type openSession struct {
+		channelID snowflake.ID
+		index     int // into out, -1 when in another channel
+	}
+	entries = slices.Clone(entries)
+	slices.SortStableFunc(entries, func(a, b model.AuditLogEntry) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	var out []VoiceSession
+	open := map[snowflake.ID]openSession{}
+
+	leave := func(user snowflake.ID, ch snowflake.ID, at time.Time, username string) {
+		o, ok := open[user]
+		delete(open, user)
+		if ok && o.index >= 0 {
+			out[o.index].End = at
+		}
+		if (!ok || o.channelID != ch) && ch == channelID {
+			out = append(out, VoiceSession{UserID: user, Username: username, End: at})
+		}
+	}
+	join := func(user snowflake.ID, ch snowflake.ID, at time.Time, username string) {
+		if o, ok := open[user]; ok && o.index >= 0 {
+			out[o.index].End = at
+		}
+		o := openSession{channelID: ch, index: -1}
+		if ch == channelID {
+			o.index = len(out)
+			out = append(out, VoiceSession{UserID: user, Username: username, Start: at})
+		}
+		open[user] = o
+	}
+
+	for _, e := range entries {
+		if e.TargetID == nil {
+			continue
+		}
+		var d struct {
+			ChannelID      snowflake.ID `json:"channel_id"`
+			FromChannelID  snowflake.ID `json:"from_channel_id"`
+			TargetUsername string       `json:"target_username"`
+		}
+		if err := json.Unmarshal([]byte(e.Details), &d); err != nil {
+			continue
+		}
+		user := *e.TargetID
+		switch EventType(e.EventType) {
+		case EventVoiceJoin:
+			join(user, d.ChannelID, e.CreatedAt, d.TargetUsername)
+		case EventVoiceLeave:
+			leave(user, d.ChannelID, e.CreatedAt, d.TargetUsername)
+		case EventVoiceMove:
+			leave(user, d.FromChannelID, e.CreatedAt, d.TargetUsername)
+			join(user, d.ChannelID, e.CreatedAt, d.TargetUsername)
+		}
+	}
+	return out
+}
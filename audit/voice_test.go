@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+func voiceEntry(eventType EventType, user snowflake.ID, at time.Time, details string) model.AuditLogEntry {
+	return model.AuditLogEntry{EventType: string(eventType), TargetID: &user, CreatedAt: at, Details: details}
+}
+
+func TestVoiceSessions(t *testing.T) {
+	base := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	alice, bob, carol := snowflake.ID(1), snowflake.ID(2), snowflake.ID(3)
+	entries := []model.AuditLogEntry{
+		// Bob was already connected: only his leave is recorded.
+		voiceEntry(EventVoiceLeave, bob, base.Add(5*time.Minute), `{"channel_id":"100","target_username":"bob"}`),
+		voiceEntry(EventVoiceJoin, alice, base.Add(10*time.Minute), `{"channel_id":"100","target_username":"alice"}`),
+		voiceEntry(EventVoiceJoin, carol, base.Add(15*time.Minute), `{"channel_id":"200","target_username":"carol"}`),
+		voiceEntry(EventVoiceMove, alice, base.Add(30*time.Minute), `{"from_channel_id":"100","channel_id":"200","target_username":"alice"}`),
+		voiceEntry(EventVoiceMove, carol, base.Add(40*time.Minute), `{"from_channel_id":"200","channel_id":"100","target_username":"carol"}`),
+	}
+
+	got := VoiceSessions(entries, 100)
+	require.Len(t, got, 3)
+	assert.Equal(t, VoiceSession{UserID: bob, Username: "bob", End: base.Add(5 * time.Minute)}, got[0])
+	assert.Equal(t, VoiceSession{UserID: alice, Username: "alice", Start: base.Add(10 * time.Minute), End: base.Add(30 * time.Minute)}, got[1])
+	assert.Equal(t, VoiceSession{UserID: carol, Username: "carol", Start: base.Add(40 * time.Minute)}, got[2])
+
+	got = VoiceSessions(entries, 200)
+	require.Len(t, got, 2)
+	assert.Equal(t, carol, got[0].UserID)
+	assert.Equal(t, base.Add(40*time.Minute), got[0].End)
+	assert.Equal(t, alice, got[1].UserID)
+	assert.True(t, got[1].End.IsZero())
+}
+
+// A second join without a leave in between (the bot missed the leave)
+// closes the earlier session at the second join rather than leaving two
+// open sessions for the member.
+func TestVoiceSessions_MissedLeave(t *testing.T) {
+	base := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	user := snowflake.ID(1)
+	got := VoiceSessions([]model.AuditLogEntry{
+		voiceEntry(EventVoiceJoin, user, base, `{"channel_id":"100"}`),
+		voiceEntry(EventVoiceJoin, user, base.Add(time.Hour), `{"channel_id":"100"}`),
+	}, 100)
+	require.Len(t, got, 2)
+	assert.Equal(t, base.Add(time.Hour), got[0].End)
+	assert.True(t, got[1].End.IsZero())
+}
+
+// A leave is held for enrichment before it is written, so a member who
+// rejoins within pendingTTL has the join stored first. Replaying by time
+// keeps them in the channel.
+func TestVoiceSessions_RejoinWithinPendingTTL(t *testing.T) {
+	base := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	user := snowflake.ID(1)
+	got := VoiceSessions([]model.AuditLogEntry{
+		voiceEntry(EventVoiceJoin, user, base, `{"channel_id":"100"}`),
+		voiceEntry(EventVoiceJoin, user, base.Add(time.Hour+time.Second), `{"channel_id":"100"}`),
+		voiceEntry(EventVoiceLeave, user, base.Add(time.Hour), `{"channel_id":"100"}`),
+	}, 100)
+	require.Len(t, got, 2)
+	assert.Equal(t, base.Add(time.Hour), got[0].End)
+	assert.Equal(t, base.Add(time.Hour+time.Second), got[1].Start)
+	assert.True(t, got[1].End.IsZero(), "the member is still in the channel")
+}
+
+func TestVoiceSessionOverlaps(t *testing.T) {
+	from := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	assert.True(t, VoiceSession{Start: from.Add(-time.Hour), End: from.Add(time.Minute)}.Overlaps(from, to))
+	assert.False(t, VoiceSession{Start: from.Add(-time.Hour), End: from}.Overlaps(from, to))
+	assert.False(t, VoiceSession{Start: to}.Overlaps(from, to))
+	assert.True(t, VoiceSession{}.Overlaps(from, to))
+}
@@ -0,0 +1,88 @@
+package auditstream
+
+import (
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+)
+
+const (
+	// maxDescription and maxField keep a full batch of cards under
+	// Discord's 6000 character limit for a message's embeds.
+	maxDescription = 1000
+	maxField       = 1000
+	// maxSections is how many of Describe's detail sections a card shows.
+	maxSections = 3
+)
+
+var categoryColors = map[audit.Category]int{
+	audit.CategoryMessage: 0x5865F2,
+	audit.CategoryMember:  0x57F287,
+	audit.CategoryGuild:   0xEB459E,
+}
+
+// card builds the stream card for an audit entry.
+func card(client *bot.Client, entry audit.Entry, now time.Time) discord.Embed {
+	d := decodedDetails(entry)
+
+	label := audit.EventLabel(string(entry.EventType))
+	if entry.EventType == audit.EventMemberUpdate {
+		label = audit.RefineMemberUpdateLabel(label, d)
+	}
+	summary, sections := audit.Describe(client, entry.GuildID, string(entry.EventType), d)
+
+	b := discord.NewEmbedBuilder().
+		SetTitle(label).
+		SetColor(categoryColors[entry.Category]).
+		SetDescription(truncate(summary, maxDescription)).
+		AddField("Actor", audit.FormatActor(client, entry.GuildID, entry.ActorKind, entry.ActorID, d), true).
+		AddField("Target", audit.FormatTarget(client, entry.GuildID, entry.TargetKind, entry.TargetID, d), true).
+		SetFooterText(string(entry.EventType)).
+		SetTimestamp(now)
+	if entry.Reason != "" {
+		b.AddField("Reason", truncate(entry.Reason, maxField), false)
+	}
+	for i, s := range sections {
+		if i == maxSections {
+			break
+		}
+		if s.Body == "" {
+			continue
+		}
+		b.AddField(truncate(s.Heading, 256), truncate(s.Body, maxField), false)
+	}
+	return b.Build()
+}
+
+// decodedDetails returns entry.Details as the audit log viewer sees them,
+// after a round trip through JSON: Describe expects float64 numbers and
+// []any slices, not the native types sinks receive.
+func decodedDetails(entry audit.Entry) map[string]any {
+	if len(entry.Details) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(entry.Details)
+	if err != nil {
+		slog.Warn("audit stream: failed to encode details", "err", err, "event_type", entry.EventType)
+		return nil
+	}
+	var d map[string]any
+	if err := json.Unmarshal(b, &d); err != nil {
+		slog.Warn("audit stream: failed to decode details", "err", err, "event_type", entry.EventType)
+		return nil
+	}
+	return d
+}
+
+func truncate(s string, maxRunes int) string {
+	r := []rune(s)
+	if len(r) <= maxRunes {
+		return s
+	}
+	return string(r[:maxRunes-1]) + "…"
+}
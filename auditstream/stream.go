@@ -0,0 +1,121 @@
+// Package auditstream mirrors audit log entries to Discord channels, one
+// channel per category, so moderators can follow the log without opening
+// the dashboard. Entries are described the same way the audit log viewer
+// describes them and batched with logchannel.Batcher.
+package auditstream
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/logchannel"
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// Settings returns the channel entries of the given category and event
+// type are streamed to in a guild, or 0 when the category has no channel
+// or the event type is muted.
+type Settings func(guildID snowflake.ID, category audit.Category, eventType audit.EventType) snowflake.ID
+
+// Stream turns audit entries into cards and batches them per channel.
+type Stream struct {
+	batcher  *logchannel.Batcher
+	client   *bot.Client
+	settings Settings
+	now      func() time.Time
+}
+
+// NewStream returns a stream that posts with send and looks up each
+// guild's settings with settings. client resolves names in cards and may
+// be nil in tests.
+func NewStream(client *bot.Client, send logchannel.Sender, settings Settings) *Stream {
+	return &Stream{
+		batcher:  logchannel.NewBatcher("audit stream", send, overflowNotice),
+		client:   client,
+		settings: settings,
+		now:      time.Now,
+	}
+}
+
+func overflowNotice(dropped int) string {
+	return fmt.Sprintf("-# %d more audit log entries were left out to keep the channel readable. See the audit log for all of them.", dropped)
+}
+
+// Handle is an audit.Sink. It queues a card for entries whose category
+// has a channel in the guild.
+func (s *Stream) Handle(entry audit.Entry) {
+	channelID := s.settings(entry.GuildID, entry.Category, entry.EventType)
+	if channelID == 0 {
+		return
+	}
+	s.batcher.Add(channelID, card(s.client, entry, s.now()))
+}
+
+// Stop posts every pending batch and drops cards queued afterwards.
+func (s *Stream) Stop() {
+	s.batcher.Stop()
+}
+
+var stream *Stream
+
+// Start posts the audit log streams with client and subscribes them to the
+// audit log.
+func Start(client *bot.Client) {
+	stream = NewStream(
+		client,
+		func(channelID snowflake.ID, message discord.MessageCreate) error {
+			_, err := client.Rest.CreateMessage(channelID, message)
+			return err
+		},
+		guildSettings,
+	)
+	audit.AddSink(stream.Handle)
+}
+
+// Stop posts the cards still waiting in their batches. Call it after the
+// audit log's pending entries have been flushed.
+func Stop() {
+	if stream != nil {
+		stream.Stop()
+	}
+}
+
+func guildSettings(guildID snowflake.ID, category audit.Category, eventType audit.EventType) snowflake.ID {
+	settings, err := model.GetGuildSettings(guildID)
+	if err != nil {
+		slog.Warn("audit stream: failed to read guild settings", "err", err, "guild_id", guildID)
+		return 0
+	}
+	channelID := ChannelFor(settings, category)
+	if channelID == 0 {
+		return 0
+	}
+	muted, err := model.GetAuditStreamMutedEvents(guildID)
+	if err != nil {
+		slog.Warn("audit stream: failed to read muted events", "err", err, "guild_id", guildID)
+	}
+	if slices.Contains(muted, string(eventType)) {
+		return 0
+	}
+	return channelID
+}
+
+// ChannelFor returns the guild's stream channel for category.
+func ChannelFor(settings *model.GuildSettings, category audit.Category) snowflake.ID {
+	switch category {
+	case audit.CategoryMessage:
+		return settings.AuditStreamMessageChannel
+	case audit.CategoryMember:
+		return settings.AuditStreamMemberChannel
+	case audit.CategoryGuild:
+		return settings.AuditStreamGuildChannel
+	}
+	return 0
+}
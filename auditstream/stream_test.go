@@ -0,0 +1,128 @@
+package auditstream
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+type sent struct {
+	channelID snowflake.ID
+	message   discord.MessageCreate
+}
+
+func testStream(channels map[audit.Category]snowflake.ID, muted ...audit.EventType) (*Stream, *[]sent) {
+	var mu sync.Mutex
+	var out []sent
+	s := NewStream(
+		nil,
+		func(channelID snowflake.ID, message discord.MessageCreate) error {
+			mu.Lock()
+			defer mu.Unlock()
+			out = append(out, sent{channelID, message})
+			return nil
+		},
+		func(_ snowflake.ID, category audit.Category, eventType audit.EventType) snowflake.ID {
+			for _, m := range muted {
+				if m == eventType {
+					return 0
+				}
+			}
+			return channels[category]
+		},
+	)
+	s.now = func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) }
+	return s, &out
+}
+
+func settingsEntry(reason string) audit.Entry {
+	gid := snowflake.ID(1)
+	return audit.Entry{
+		GuildID:    gid,
+		Category:   audit.CategoryGuild,
+		EventType:  audit.EventSettingsUpdate,
+		ActorKind:  audit.ActorSystem,
+		TargetID:   &gid,
+		TargetKind: audit.TargetGuild,
+		Source:     audit.SourceWeb,
+		Reason:     reason,
+		Details: map[string]any{
+			"section": "message_log",
+			"changes": map[string]any{"message_log_channel": map[string]any{"old": "", "new": "none"}},
+		},
+	}
+}
+
+func TestCard_Settings(t *testing.T) {
+	embed := card(nil, settingsEntry("tidying up"), time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.Equal(t, audit.EventLabel(string(audit.EventSettingsUpdate)), embed.Title)
+	assert.Equal(t, categoryColors[audit.CategoryGuild], embed.Color)
+	assert.Equal(t, string(audit.EventSettingsUpdate), embed.Footer.Text)
+	require.GreaterOrEqual(t, len(embed.Fields), 3)
+	assert.Equal(t, "Actor", embed.Fields[0].Name)
+	assert.Equal(t, "System", embed.Fields[0].Value)
+	assert.Equal(t, "Reason", embed.Fields[2].Name)
+	assert.Equal(t, "tidying up", embed.Fields[2].Value)
+}
+
+func TestCard_NumbersSurviveRoundTrip(t *testing.T) {
+	gid := snowflake.ID(1)
+	entry := audit.Entry{
+		GuildID:    gid,
+		Category:   audit.CategoryGuild,
+		EventType:  audit.EventAuditLogExport,
+		ActorKind:  audit.ActorSystem,
+		TargetID:   &gid,
+		TargetKind: audit.TargetGuild,
+		Details:    map[string]any{"format": "csv", "entries": 42},
+	}
+	embed := card(nil, entry, time.Now())
+	assert.Contains(t, embed.Description, "42")
+}
+
+func TestCard_LongReasonTruncated(t *testing.T) {
+	long := make([]rune, 3000)
+	for i := range long {
+		long[i] = 'x'
+	}
+	embed := card(nil, settingsEntry(string(long)), time.Now())
+	assert.Len(t, []rune(embed.Fields[2].Value), maxField)
+}
+
+func TestStream_RoutesByCategory(t *testing.T) {
+	s, out := testStream(map[audit.Category]snowflake.ID{audit.CategoryGuild: 50})
+	s.Handle(settingsEntry(""))
+	s.Handle(audit.Entry{GuildID: 1, Category: audit.CategoryMember, EventType: audit.EventMemberJoin})
+	s.Stop()
+
+	require.Len(t, *out, 1)
+	assert.Equal(t, snowflake.ID(50), (*out)[0].channelID)
+	assert.Len(t, (*out)[0].message.Embeds, 1)
+}
+
+func TestStream_MutedEventSkipped(t *testing.T) {
+	s, out := testStream(map[audit.Category]snowflake.ID{audit.CategoryGuild: 50}, audit.EventSettingsUpdate)
+	s.Handle(settingsEntry(""))
+	s.Stop()
+	assert.Empty(t, *out)
+}
+
+func TestChannelFor(t *testing.T) {
+	settings := &model.GuildSettings{
+		AuditStreamMessageChannel: 1,
+		AuditStreamMemberChannel:  2,
+		AuditStreamGuildChannel:   3,
+	}
+	assert.Equal(t, snowflake.ID(1), ChannelFor(settings, audit.CategoryMessage))
+	assert.Equal(t, snowflake.ID(2), ChannelFor(settings, audit.CategoryMember))
+	assert.Equal(t, snowflake.ID(3), ChannelFor(settings, audit.CategoryGuild))
+	assert.Equal(t, snowflake.ID(0), ChannelFor(settings, ""))
+}
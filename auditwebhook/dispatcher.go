@@ -0,0 +1,282 @@
+// Package auditwebhook POSTs audit log entries to HTTP endpoints
+// configured by the bot operator, for feeding moderation events into
+// other tooling.
+//
+// Each entry an endpoint subscribes to is written to a persistent queue
+// (model.WebhookDelivery) when it is committed and delivered from there,
+// in order per endpoint. A failed delivery holds back the endpoint's
+// later ones and is retried with exponential backoff, so events survive
+// both endpoint outages and bot restarts.
+package auditwebhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+const (
+	// pollInterval is how often the queue is checked for retries that
+	// have come due.
+	pollInterval = 5 * time.Second
+	// requestTimeout bounds one delivery attempt.
+	requestTimeout = 10 * time.Second
+	// batchSize is how many of an endpoint's deliveries one pass loads.
+	batchSize = 50
+	// Retries back off from baseBackoff, doubling up to maxBackoff. After
+	// maxAttempts failures the delivery is dropped, roughly a day after
+	// the event.
+	baseBackoff = 30 * time.Second
+	maxBackoff  = time.Hour
+	maxAttempts = 30
+)
+
+// Dispatcher queues entries for its endpoints and delivers them.
+type Dispatcher struct {
+	endpoints []Endpoint
+	client    *http.Client
+	now       func() time.Time
+
+	wake chan struct{}
+
+	// blockedUntil holds back an endpoint's queue after a failure, until
+	// the failed delivery's next attempt. Only the delivery goroutine
+	// touches it.
+	blockedUntil map[string]time.Time
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewDispatcher returns a dispatcher for endpoints. Call Run to start
+// delivering.
+func NewDispatcher(endpoints []Endpoint, client *http.Client) *Dispatcher {
+	return &Dispatcher{
+		endpoints:    endpoints,
+		client:       client,
+		now:          time.Now,
+		wake:         make(chan struct{}, 1),
+		blockedUntil: map[string]time.Time{},
+	}
+}
+
+// Handle is an audit.Sink. It queues a delivery of entry for each
+// endpoint that wants it.
+func (d *Dispatcher) Handle(entry audit.Entry) {
+	now := d.now()
+	env := NewEnvelope(entry)
+	var deliveries []model.WebhookDelivery
+	var payload []byte
+	for i := range d.endpoints {
+		if !d.endpoints[i].Wants(entry) {
+			continue
+		}
+		if payload == nil {
+			var err error
+			if payload, err = json.Marshal(env); err != nil {
+				slog.Warn("webhooks: failed to encode event", "err", err, "event_type", entry.EventType)
+				return
+			}
+		}
+		deliveries = append(deliveries, model.WebhookDelivery{
+			Endpoint:      d.endpoints[i].Name,
+			EventID:       env.ID,
+			EventType:     env.EventType,
+			Payload:       string(payload),
+			NextAttemptAt: now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+	if err := model.EnqueueWebhookDeliveries(deliveries); err != nil {
+		slog.Warn("webhooks: failed to queue event", "err", err, "event_type", entry.EventType)
+		return
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run delivers queued events until Stop is called. Deliveries queued for
+// endpoints that are no longer configured are dropped first.
+func (d *Dispatcher) Run() {
+	names := make([]string, 0, len(d.endpoints))
+	for _, ep := range d.endpoints {
+		names = append(names, ep.Name)
+	}
+	if n, err := model.DeleteWebhookDeliveriesExcept(names); err != nil {
+		slog.Warn("webhooks: failed to drop deliveries for removed endpoints", "err", err)
+	} else if n > 0 {
+		slog.Info("webhooks: dropped deliveries for removed endpoints", "count", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.mu.Lock()
+	d.cancel = cancel
+	d.done = make(chan struct{})
+	done := d.done
+	d.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		for {
+			d.deliverDue(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-d.wake:
+			}
+		}
+	}()
+}
+
+// Stop waits for the delivery in flight, if any, and stops delivering.
+// Events still queued are delivered after the next start.
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	cancel, done := d.cancel, d.done
+	d.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+}
+
+// deliverDue makes one pass over every endpoint's due deliveries.
+func (d *Dispatcher) deliverDue(ctx context.Context) {
+	for i := range d.endpoints {
+		ep := &d.endpoints[i]
+		if d.now().Before(d.blockedUntil[ep.Name]) {
+			continue
+		}
+		deliveries, err := model.DueWebhookDeliveries(ep.Name, d.now(), batchSize)
+		if err != nil {
+			slog.Warn("webhooks: failed to load queued deliveries", "err", err, "endpoint", ep.Name)
+			continue
+		}
+		for _, delivery := range deliveries {
+			if ctx.Err() != nil {
+				return
+			}
+			if !d.attempt(ctx, ep, delivery) {
+				break
+			}
+		}
+	}
+}
+
+// attempt sends one delivery and updates the queue. It returns false
+// when the endpoint's remaining deliveries should wait.
+func (d *Dispatcher) attempt(ctx context.Context, ep *Endpoint, delivery model.WebhookDelivery) bool {
+	err := d.send(ctx, ep, delivery)
+	if err == nil {
+		if err := model.DeleteWebhookDelivery(delivery.ID); err != nil {
+			slog.Warn("webhooks: failed to remove delivered event", "err", err, "endpoint", ep.Name, "event_id", delivery.EventID)
+		}
+		return true
+	}
+	if ctx.Err() != nil {
+		// Shutting down; the delivery stays queued as it was.
+		return false
+	}
+
+	attempts := delivery.Attempts + 1
+	if attempts >= maxAttempts {
+		slog.Warn("webhooks: giving up on event", "err", err, "endpoint", ep.Name,
+			"event_id", delivery.EventID, "event_type", delivery.EventType, "attempts", attempts)
+		if err := model.DeleteWebhookDelivery(delivery.ID); err != nil {
+			slog.Warn("webhooks: failed to remove abandoned event", "err", err, "endpoint", ep.Name, "event_id", delivery.EventID)
+		}
+		return true
+	}
+	next := d.now().Add(backoff(attempts))
+	slog.Debug("webhooks: delivery failed", "err", err, "endpoint", ep.Name,
+		"event_id", delivery.EventID, "attempts", attempts, "next_attempt", next)
+	if err := model.RescheduleWebhookDelivery(delivery.ID, attempts, next, err.Error()); err != nil {
+		slog.Warn("webhooks: failed to reschedule event", "err", err, "endpoint", ep.Name, "event_id", delivery.EventID)
+	}
+	d.blockedUntil[ep.Name] = next
+	return false
+}
+
+func (d *Dispatcher) send(ctx context.Context, ep *Endpoint, delivery model.WebhookDelivery) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Heimdallr-Webhooks/1")
+	req.Header.Set(HeaderEventID, delivery.EventID)
+	req.Header.Set(HeaderEventType, delivery.EventType)
+	req.Header.Set(HeaderSignature, Sign(ep.Secret, d.now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+	return nil
+}
+
+// backoff is the wait before retry number attempts.
+func backoff(attempts int) time.Duration {
+	wait := baseBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
+
+var dispatcher *Dispatcher
+
+// Start delivers to the configured endpoints, if any, and subscribes them
+// to the audit log.
+func Start() error {
+	endpoints, err := LoadEndpoints()
+	if err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		// Whatever was queued before the endpoints were removed.
+		if _, err := model.DeleteWebhookDeliveriesExcept(nil); err != nil {
+			slog.Warn("webhooks: failed to drop deliveries for removed endpoints", "err", err)
+		}
+		return nil
+	}
+	dispatcher = NewDispatcher(endpoints, &http.Client{})
+	dispatcher.Run()
+	audit.AddSink(dispatcher.Handle)
+	slog.Info("webhooks: delivering audit events", "endpoints", len(endpoints))
+	return nil
+}
+
+// Stop stops delivering. Call it after the audit log's pending entries
+// have been flushed, so they are queued for the next start.
+func Stop() {
+	if dispatcher != nil {
+		dispatcher.Stop()
+	}
+}
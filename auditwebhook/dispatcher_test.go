@@ -0,0 +1,181 @@
+package auditwebhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+func setupTestDB(t *testing.T) {
+	t.Helper()
+	if _, err := model.InitDB(filepath.Join(t.TempDir(), "test.db")); err != nil {
+		t.Fatalf("InitDB: %v", err)
+	}
+}
+
+// receiver is a local stand-in for an endpoint. It fails the first
+// failures requests, then accepts.
+type receiver struct {
+	mu       sync.Mutex
+	bodies   []string
+	headers  []http.Header
+	failures atomic.Int32
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if rc.failures.Add(-1) >= 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	rc.bodies = append(rc.bodies, string(body))
+	rc.headers = append(rc.headers, r.Header.Clone())
+	rc.mu.Unlock()
+}
+
+func testDispatcher(t *testing.T, url string, events ...string) (*Dispatcher, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := NewDispatcher([]Endpoint{{Name: "test", URL: url, Secret: "secret", Events: events}}, &http.Client{})
+	d.now = func() time.Time { return now }
+	return d, &now
+}
+
+func queued(t *testing.T) []model.WebhookDelivery {
+	t.Helper()
+	var rows []model.WebhookDelivery
+	require.NoError(t, model.DB.Order("id").Find(&rows).Error)
+	return rows
+}
+
+func banEntry() audit.Entry {
+	return audit.Entry{ID: 1, GuildID: 1, Category: audit.CategoryGuild, EventType: audit.EventGuildBan, ActorKind: audit.ActorSystem, CreatedAt: time.Now()}
+}
+
+func TestDispatcher_DeliversSigned(t *testing.T) {
+	setupTestDB(t)
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	d, now := testDispatcher(t, srv.URL)
+
+	d.Handle(banEntry())
+	d.deliverDue(t.Context())
+
+	require.Len(t, rc.bodies, 1)
+	h := rc.headers[0]
+	assert.Equal(t, "application/json", h.Get("Content-Type"))
+	assert.Equal(t, "guild.ban", h.Get(HeaderEventType))
+	assert.Equal(t, "1", h.Get(HeaderEventID))
+	assert.Equal(t, Sign("secret", *now, []byte(rc.bodies[0])), h.Get(HeaderSignature))
+	assert.Empty(t, queued(t), "delivered events leave the queue")
+}
+
+func TestDispatcher_FilteredEventsNotQueued(t *testing.T) {
+	setupTestDB(t)
+	d, _ := testDispatcher(t, "http://127.0.0.1:1", "member.*")
+	d.Handle(banEntry())
+	assert.Empty(t, queued(t))
+}
+
+func TestDispatcher_RetriesWithBackoffInOrder(t *testing.T) {
+	setupTestDB(t)
+	rc := &receiver{}
+	rc.failures.Store(1)
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	d, now := testDispatcher(t, srv.URL)
+
+	first := banEntry()
+	first.Reason = "first"
+	second := banEntry()
+	second.Reason = "second"
+	d.Handle(first)
+	d.Handle(second)
+
+	d.deliverDue(t.Context())
+	assert.Empty(t, rc.bodies, "the failure holds back later events")
+	rows := queued(t)
+	require.Len(t, rows, 2)
+	assert.Equal(t, 1, rows[0].Attempts)
+	assert.Contains(t, rows[0].LastError, "503")
+	assert.Equal(t, now.Add(baseBackoff), rows[0].NextAttemptAt.UTC())
+
+	d.deliverDue(t.Context())
+	assert.Empty(t, rc.bodies, "nothing is sent before the backoff passes")
+
+	*now = now.Add(baseBackoff)
+	d.deliverDue(t.Context())
+	require.Len(t, rc.bodies, 2)
+	assert.Contains(t, rc.bodies[0], `"reason":"first"`)
+	assert.Contains(t, rc.bodies[1], `"reason":"second"`)
+	assert.Empty(t, queued(t))
+}
+
+func TestDispatcher_QueueSurvivesRestart(t *testing.T) {
+	setupTestDB(t)
+	rc := &receiver{}
+	rc.failures.Store(1)
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	before, _ := testDispatcher(t, srv.URL)
+	before.Handle(banEntry())
+	before.deliverDue(t.Context())
+	require.Len(t, queued(t), 1)
+
+	after, now := testDispatcher(t, srv.URL)
+	*now = now.Add(time.Hour)
+	after.Run()
+	defer after.Stop()
+
+	require.Eventually(t, func() bool {
+		rc.mu.Lock()
+		defer rc.mu.Unlock()
+		return len(rc.bodies) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestDispatcher_GivesUpAfterMaxAttempts(t *testing.T) {
+	setupTestDB(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	d, _ := testDispatcher(t, srv.URL)
+
+	d.Handle(banEntry())
+	require.NoError(t, model.DB.Model(&model.WebhookDelivery{}).Where("1 = 1").Update("attempts", maxAttempts-1).Error)
+	d.deliverDue(t.Context())
+	assert.Empty(t, queued(t))
+}
+
+func TestDispatcher_RunDropsRemovedEndpoints(t *testing.T) {
+	setupTestDB(t)
+	require.NoError(t, model.EnqueueWebhookDeliveries([]model.WebhookDelivery{
+		{Endpoint: "gone", EventID: "1", EventType: "guild.ban", Payload: "{}", NextAttemptAt: time.Now().Add(time.Hour)},
+	}))
+	d, _ := testDispatcher(t, "http://127.0.0.1:1")
+	d.Run()
+	d.Stop()
+	assert.Empty(t, queued(t))
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, baseBackoff, backoff(1))
+	assert.Equal(t, 2*baseBackoff, backoff(2))
+	assert.Equal(t, 4*baseBackoff, backoff(3))
+	assert.Equal(t, maxBackoff, backoff(20))
+}
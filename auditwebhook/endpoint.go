@@ -0,0 +1,96 @@
+package auditwebhook
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/spf13/viper"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+)
+
+// Endpoint is an HTTP endpoint configured under [[webhooks.endpoints]].
+//
+// Events filters by event type: an exact type ("guild.ban"), a prefix
+// wildcard ("member.*") or "*". Guilds filters by guild ID. Either left
+// empty matches everything.
+type Endpoint struct {
+	Name   string   `mapstructure:"name"`
+	URL    string   `mapstructure:"url"`
+	Secret string   `mapstructure:"secret"`
+	Events []string `mapstructure:"events"`
+	Guilds []string `mapstructure:"guilds"`
+
+	guilds []snowflake.ID
+}
+
+// LoadEndpoints reads and validates the configured endpoints. Names must
+// be unique: queued deliveries are keyed by them.
+func LoadEndpoints() ([]Endpoint, error) {
+	var endpoints []Endpoint
+	if err := viper.UnmarshalKey("webhooks.endpoints", &endpoints); err != nil {
+		return nil, fmt.Errorf("webhooks.endpoints: %w", err)
+	}
+	seen := make([]string, 0, len(endpoints))
+	for i := range endpoints {
+		ep := &endpoints[i]
+		if err := ep.validate(); err != nil {
+			return nil, fmt.Errorf("webhooks.endpoints[%d]: %w", i, err)
+		}
+		if slices.Contains(seen, ep.Name) {
+			return nil, fmt.Errorf("webhooks.endpoints[%d]: duplicate name %q", i, ep.Name)
+		}
+		seen = append(seen, ep.Name)
+	}
+	return endpoints, nil
+}
+
+func (ep *Endpoint) validate() error {
+	ep.Name = strings.TrimSpace(ep.Name)
+	if ep.Name == "" {
+		return errors.New("name is required")
+	}
+	u, err := url.Parse(ep.URL)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %w", ep.URL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url %q: must be an absolute http or https URL", ep.URL)
+	}
+	if ep.Secret == "" {
+		return errors.New("secret is required to sign deliveries")
+	}
+	ep.guilds = ep.guilds[:0]
+	for _, g := range ep.Guilds {
+		id, err := snowflake.Parse(g)
+		if err != nil {
+			return fmt.Errorf("invalid guild ID %q", g)
+		}
+		ep.guilds = append(ep.guilds, id)
+	}
+	return nil
+}
+
+// Wants reports whether the endpoint subscribes to entry.
+func (ep *Endpoint) Wants(entry audit.Entry) bool {
+	if len(ep.guilds) > 0 && !slices.Contains(ep.guilds, entry.GuildID) {
+		return false
+	}
+	if len(ep.Events) == 0 {
+		return true
+	}
+	t := string(entry.EventType)
+	for _, pattern := range ep.Events {
+		if pattern == "*" || pattern == t {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok && strings.HasPrefix(t, prefix) {
+			return true
+		}
+	}
+	return false
+}
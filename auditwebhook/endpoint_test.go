@@ -0,0 +1,68 @@
+package auditwebhook
+
+import (
+	"testing"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+)
+
+func TestEndpointWants(t *testing.T) {
+	entry := audit.Entry{GuildID: 5, EventType: audit.EventMemberTimeoutAdd}
+	tests := []struct {
+		name   string
+		events []string
+		guilds []snowflake.ID
+		want   bool
+	}{
+		{"no filters", nil, nil, true},
+		{"exact type", []string{"member.timeout_add"}, nil, true},
+		{"other type", []string{"guild.ban"}, nil, false},
+		{"prefix wildcard", []string{"guild.*", "member.*"}, nil, true},
+		{"star", []string{"*"}, nil, true},
+		{"guild listed", nil, []snowflake.ID{4, 5}, true},
+		{"guild not listed", nil, []snowflake.ID{4}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ep := Endpoint{Events: tt.events, guilds: tt.guilds}
+			assert.Equal(t, tt.want, ep.Wants(entry))
+		})
+	}
+}
+
+func TestLoadEndpoints(t *testing.T) {
+	t.Cleanup(func() { viper.Set("webhooks.endpoints", nil) })
+	endpoint := func(name, url, secret string, guilds ...string) map[string]any {
+		return map[string]any{"name": name, "url": url, "secret": secret, "guilds": guilds}
+	}
+
+	viper.Set("webhooks.endpoints", []map[string]any{
+		endpoint("a", "https://example.com/hook", "s", "123"),
+		endpoint("b", "http://localhost:9000", "s"),
+	})
+	endpoints, err := LoadEndpoints()
+	require.NoError(t, err)
+	require.Len(t, endpoints, 2)
+	assert.Equal(t, []snowflake.ID{123}, endpoints[0].guilds)
+
+	bad := map[string][]map[string]any{
+		"missing name":   {endpoint("", "https://example.com", "s")},
+		"relative url":   {endpoint("a", "/hook", "s")},
+		"other scheme":   {endpoint("a", "ftp://example.com", "s")},
+		"missing secret": {endpoint("a", "https://example.com", "")},
+		"invalid guild":  {endpoint("a", "https://example.com", "s", "general")},
+		"duplicate name": {endpoint("a", "https://example.com", "s"), endpoint("a", "https://example.org", "s")},
+	}
+	for name, config := range bad {
+		t.Run(name, func(t *testing.T) {
+			viper.Set("webhooks.endpoints", config)
+			_, err := LoadEndpoints()
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package auditwebhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+)
+
+// SchemaVersion is bumped whenever a field of Envelope changes meaning or
+// is removed. Adding fields doesn't bump it; receivers should ignore
+// fields they don't know.
+const SchemaVersion = 1
+
+// Header names set on every delivery.
+const (
+	HeaderSignature = "X-Heimdallr-Signature"
+	HeaderEventID   = "X-Heimdallr-Event-Id"
+	HeaderEventType = "X-Heimdallr-Event-Type"
+)
+
+// Envelope is the JSON body POSTed for each audit entry. Every committed
+// audit log entry is sent, which includes the moderation actions (bans,
+// kicks, timeouts, warnings, antispam actions) the bot records there.
+//
+// ID is the audit log entry's ID, so it is unique per event and the same
+// for every endpoint and retry, and receivers can drop duplicates.
+// OccurredAt is the entry's time in the audit log. IDs are strings, since
+// snowflakes don't fit in a JSON number without losing precision. Details
+// holds the entry's event-specific fields, as stored in the audit log.
+type Envelope struct {
+	SchemaVersion int            `json:"schema_version"`
+	ID            string         `json:"id"`
+	OccurredAt    time.Time      `json:"occurred_at"`
+	GuildID       string         `json:"guild_id"`
+	Category      string         `json:"category"`
+	EventType     string         `json:"event_type"`
+	Actor         Party          `json:"actor"`
+	Target        Party          `json:"target"`
+	Source        string         `json:"source"`
+	Reason        string         `json:"reason"`
+	Details       map[string]any `json:"details"`
+}
+
+// Party is the actor or target of an event. ID is empty when the event
+// has none, e.g. a system actor.
+type Party struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+}
+
+// NewEnvelope wraps entry, which must have been written to the audit log.
+func NewEnvelope(entry audit.Entry) Envelope {
+	details := entry.Details
+	if details == nil {
+		details = map[string]any{}
+	}
+	return Envelope{
+		SchemaVersion: SchemaVersion,
+		ID:            strconv.FormatUint(uint64(entry.ID), 10),
+		OccurredAt:    entry.CreatedAt.UTC(),
+		GuildID:       entry.GuildID.String(),
+		Category:      string(entry.Category),
+		EventType:     string(entry.EventType),
+		Actor:         party(entry.ActorID, string(entry.ActorKind)),
+		Target:        party(entry.TargetID, string(entry.TargetKind)),
+		Source:        string(entry.Source),
+		Reason:        entry.Reason,
+		Details:       details,
+	}
+}
+
+func party(id *snowflake.ID, kind string) Party {
+	p := Party{Kind: kind}
+	if id != nil {
+		p.ID = id.String()
+	}
+	return p
+}
+
+// Sign returns the HeaderSignature value for body sent at t:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<unix seconds>.<body>">".
+// Receivers recompute the HMAC with the shared secret and should reject
+// timestamps too far from their own clock to stop replays.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,65 @@
+package auditwebhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+)
+
+func TestSign_Verifiable(t *testing.T) {
+	body := []byte(`{"id":"x"}`)
+	sig := Sign("secret", time.Unix(1700000000, 0), body)
+
+	ts, mac, ok := strings.Cut(sig, ",v1=")
+	require.True(t, ok)
+	assert.Equal(t, "t=1700000000", ts)
+
+	h := hmac.New(sha256.New, []byte("secret"))
+	h.Write([]byte("1700000000." + string(body)))
+	assert.Equal(t, hex.EncodeToString(h.Sum(nil)), mac)
+
+	assert.NotEqual(t, sig, Sign("other", time.Unix(1700000000, 0), body))
+}
+
+func TestNewEnvelope_Schema(t *testing.T) {
+	actor := snowflake.ID(7)
+	entry := audit.Entry{
+		ID:         42,
+		GuildID:    1,
+		Category:   audit.CategoryGuild,
+		EventType:  audit.EventGuildBan,
+		ActorID:    &actor,
+		ActorKind:  audit.ActorUser,
+		TargetKind: audit.TargetNone,
+		Source:     audit.SourceGateway,
+		Reason:     "spam",
+		Details:    map[string]any{"delete_message_seconds": 3600},
+		CreatedAt:  time.Date(2026, 1, 2, 4, 4, 5, 0, time.FixedZone("CET", 3600)),
+	}
+	env := NewEnvelope(entry)
+	b, err := json.Marshal(env)
+	require.NoError(t, err)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, float64(SchemaVersion), got["schema_version"])
+	assert.Equal(t, "42", got["id"])
+	assert.Equal(t, "2026-01-02T03:04:05Z", got["occurred_at"])
+	assert.Equal(t, "1", got["guild_id"])
+	assert.Equal(t, "guild", got["category"])
+	assert.Equal(t, "guild.ban", got["event_type"])
+	assert.Equal(t, map[string]any{"id": "7", "kind": "user"}, got["actor"])
+	assert.Equal(t, map[string]any{"id": "", "kind": string(audit.TargetNone)}, got["target"])
+	assert.Equal(t, "spam", got["reason"])
+	assert.Equal(t, map[string]any{"delete_message_seconds": float64(3600)}, got["details"])
+}
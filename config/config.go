@@ -158,6 +158,14 @@ func init() {
 	viper.SetDefault("audit_log.member_retention_days", 90)
 	viper.SetDefault("audit_log.guild_retention_days", 0)
 	viper.SetDefault("audit_log.prune_interval_hours", 6)
+	// Directory for archives of pruned entries, for guilds that turn
+	// archiving on. Empty makes the option unavailable.
+	viper.SetDefault("audit_log.archive_dir", "")
+
+	// Scam/phishing domain blocklist: a file, or a directory of files, with
+	// one domain per line. Reloaded automatically when it changes. Empty
+	// disables the bot-wide list; guilds can still deny domains themselves.
+	viper.SetDefault("antispam.phishing_blocklist", "")
 
 	viper.AutomaticEnv()
 
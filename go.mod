@@ -20,7 +20,10 @@ require (
 	gorm.io/gorm v1.31.2
 )
 
-require golang.org/x/sync v0.22.0
+require (
+	golang.org/x/net v0.51.0
+	golang.org/x/sync v0.22.0
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
@@ -40,6 +40,7 @@ func Register(r *handler.Mux) []discord.ApplicationCommandCreate {
 
 			r.Command("/posts", AdminPostsHandler)
 			r.Command("/audit-log", AdminAuditLogHandler)
+			r.Command("/audit-export", AdminAuditExportHandler)
 		},
 	)
 
@@ -69,6 +70,7 @@ var AdminCommand = discord.SlashCommandCreate{
 		banFooterSubcommand,
 		postsSubcommand,
 		auditLogSubcommand,
+		auditExportSubcommand,
 	},
 }
 
@@ -2,7 +2,9 @@ package admin
 
 import (
 	"testing"
+	"time"
 
+	"github.com/disgoorg/snowflake/v2"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,3 +39,19 @@ func TestSectionEmbed_HeadingOnly(t *testing.T) {
 	assert.Equal(t, "Title", got.Title)
 	assert.Empty(t, got.Description)
 }
+
+func TestAuditExportFilter(t *testing.T) {
+	now := time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	f := auditExportFilter("", 0, 0, 0, now)
+	assert.Empty(t, f.Category)
+	assert.Nil(t, f.ActorIDs)
+	assert.Nil(t, f.TargetIDs)
+	assert.True(t, f.From.IsZero(), "no days means everything retained")
+
+	f = auditExportFilter("message", 1, 2, 3, now)
+	assert.Equal(t, "message", f.Category)
+	assert.Equal(t, []snowflake.ID{1}, f.ActorIDs)
+	assert.Equal(t, []snowflake.ID{2}, f.TargetIDs)
+	assert.Equal(t, time.Date(2026, 1, 7, 12, 0, 0, 0, time.UTC), f.From)
+}
@@ -0,0 +1,154 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/interactions"
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/utils"
+)
+
+// maxExportUpload is Discord's attachment size limit for bots in servers
+// without boosts. Larger exports have to be narrowed or taken from the
+// dashboard, which streams them instead.
+const maxExportUpload = 10 << 20
+
+var auditExportSubcommand = discord.ApplicationCommandOptionSubCommand{
+	Name:        "audit-export",
+	Description: "Export audit log entries as a file",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionString{
+			Name:        "format",
+			Description: "File format (default CSV)",
+			Required:    false,
+			Choices: []discord.ApplicationCommandOptionChoiceString{
+				{Name: "CSV", Value: string(audit.ExportCSV)},
+				{Name: "JSONL", Value: string(audit.ExportJSONL)},
+			},
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "category",
+			Description: "Only export this category",
+			Required:    false,
+			Choices: []discord.ApplicationCommandOptionChoiceString{
+				{Name: "Message", Value: string(audit.CategoryMessage)},
+				{Name: "Member", Value: string(audit.CategoryMember)},
+				{Name: "Guild", Value: string(audit.CategoryGuild)},
+			},
+		},
+		discord.ApplicationCommandOptionUser{
+			Name:        "actor",
+			Description: "Only export entries by this user",
+			Required:    false,
+		},
+		discord.ApplicationCommandOptionUser{
+			Name:        "target",
+			Description: "Only export entries about this user",
+			Required:    false,
+		},
+		discord.ApplicationCommandOptionInt{
+			Name:        "days",
+			Description: "Only export the last N days (default: everything retained)",
+			Required:    false,
+			MinValue:    new(1),
+		},
+	},
+}
+
+func AdminAuditExportHandler(e *handler.CommandEvent) error {
+	utils.LogInteraction("admin", e)
+
+	data := e.SlashCommandInteractionData()
+	guild, isGuild := e.Guild()
+	if !isGuild {
+		return interactions.ErrEventNoGuildID
+	}
+
+	format, err := audit.ParseExportFormat(data.String("format"))
+	if err != nil {
+		return e.CreateMessage(interactions.EphemeralMessageContent("Unknown export format."))
+	}
+	var actorID, targetID snowflake.ID
+	if u, ok := data.OptUser("actor"); ok {
+		actorID = u.ID
+	}
+	if u, ok := data.OptUser("target"); ok {
+		targetID = u.ID
+	}
+	filter := auditExportFilter(data.String("category"), actorID, targetID, data.Int("days"), time.Now())
+
+	// A busy guild's export can take a while to build; defer so the
+	// interaction doesn't time out.
+	if err := e.DeferCreateMessage(true); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	count, err := audit.Export(context.Background(), &buf, e.Client(), guild.ID, filter, format)
+	if err != nil {
+		slog.Error("audit log export failed", "err", err, "guild_id", guild.ID)
+		_, err = e.CreateFollowupMessage(interactions.EphemeralMessageContent("Failed to export the audit log."))
+		return err
+	}
+	if buf.Len() > maxExportUpload {
+		_, err = e.CreateFollowupMessage(interactions.EphemeralMessageContentf(
+			"The export is %d entries (%.1f MiB), too large to upload. Narrow the filters, or download it from the dashboard's audit log page.",
+			count, float64(buf.Len())/(1<<20)))
+		return err
+	}
+
+	audit.LogExport(guild.ID, e.User().ID, e.User().Username, audit.SourceCommand, format, count, map[string]string{
+		"category": filter.Category,
+		"actor":    idOrEmpty(actorID),
+		"target":   idOrEmpty(targetID),
+		"days":     daysOrEmpty(data.Int("days")),
+	})
+
+	_, err = e.CreateFollowupMessage(
+		discord.NewMessageCreate().
+			WithEphemeral(true).
+			WithContent(fmt.Sprintf("Exported %d audit log entries.", count)).
+			AddFiles(discord.NewFile(audit.ExportFilename(guild.ID, format, time.Now()), "", &buf)),
+	)
+	return err
+}
+
+// auditExportFilter builds the model filter from the command options.
+// Zero values leave that axis unfiltered; days counts back from now.
+func auditExportFilter(category string, actorID, targetID snowflake.ID, days int, now time.Time) model.AuditLogFilter {
+	filter := model.AuditLogFilter{Category: category}
+	if actorID != 0 {
+		filter.ActorIDs = []snowflake.ID{actorID}
+	}
+	if targetID != 0 {
+		filter.TargetIDs = []snowflake.ID{targetID}
+	}
+	if days > 0 {
+		filter.From = now.Add(-time.Duration(days) * 24 * time.Hour).UTC()
+	}
+	return filter
+}
+
+func idOrEmpty(id snowflake.ID) string {
+	if id == 0 {
+		return ""
+	}
+	return id.String()
+}
+
+func daysOrEmpty(days int) string {
+	if days <= 0 {
+		return ""
+	}
+	return strconv.Itoa(days)
+}
@@ -2,6 +2,7 @@ package admin
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/handler"
@@ -29,6 +30,11 @@ var modChannelSubcommand = discord.ApplicationCommandOptionSubCommand{
 				{Name: "Reset", Value: "reset"},
 			},
 		},
+		discord.ApplicationCommandOptionBool{
+			Name:        "notify-join-invite",
+			Description: "Post the invite each joining member used to the moderator channel",
+			Required:    false,
+		},
 	},
 }
 
@@ -41,12 +47,13 @@ func AdminModChannelHandler(e *handler.CommandEvent) error {
 
 	channel, hasChannel := data.OptChannel("channel")
 	resetOption, hasReset := data.OptString("reset")
+	notifyJoinInvite, hasNotifyJoinInvite := data.OptBool("notify-join-invite")
 	settings, err := model.GetGuildSettings(guild.ID)
 	if err != nil {
 		return err
 	}
 
-	if !hasChannel && !hasReset {
+	if !hasChannel && !hasReset && !hasNotifyJoinInvite {
 		return e.CreateMessage(interactions.EphemeralMessageContent(modChannelInfo(settings)))
 	}
 
@@ -59,25 +66,31 @@ func AdminModChannelHandler(e *handler.CommandEvent) error {
 		settings.ModeratorChannel = channel.ID
 		message = fmt.Sprintf("Moderator channel set to <#%d>", channel.ID)
 	}
+	if hasNotifyJoinInvite {
+		settings.NotifyJoinInvite = notifyJoinInvite
+		message += fmt.Sprintf("\nJoin invite notices %s.", utils.Iif(notifyJoinInvite, "enabled", "disabled"))
+	}
 
 	err = model.SetGuildSettings(settings)
 	if err != nil {
 		return err
 	}
 	logSettingsCommandUpdate(guild.ID, e.User(), "mod_channel", map[string]any{
-		"moderator_channel": settings.ModeratorChannel.String(),
+		"moderator_channel":  settings.ModeratorChannel.String(),
+		"notify_join_invite": settings.NotifyJoinInvite,
 	})
 
 	return e.CreateMessage(
-		interactions.EphemeralMessageContent(message),
+		interactions.EphemeralMessageContent(strings.TrimSpace(message)),
 	)
 }
 
 func modChannelInfo(settings *model.GuildSettings) string {
 	modChannelInfo := "> This is the channel in which notifications and other information for moderators and administrators are sent."
 	return fmt.Sprintf(
-		"**Moderator channel:** %s\n%s",
+		"**Moderator channel:** %s\n%s\n\n**Join invite notices:** %s",
 		utils.MentionChannelOrDefault(&settings.ModeratorChannel, "not set"),
 		modChannelInfo,
+		utils.Iif(settings.NotifyJoinInvite, "enabled", "disabled"),
 	)
 }
@@ -0,0 +1,188 @@
+package gatekeep
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/utils"
+)
+
+var (
+	// ErrActionInProgress is returned when another approve/deny/kick for
+	// the same member is still running.
+	ErrActionInProgress = errors.New("gatekeep action already in progress for this member")
+	// ErrSettingsUnavailable wraps a failure to load the guild settings.
+	ErrSettingsUnavailable = errors.New("failed to get guild settings")
+	// ErrGatekeepDisabled is returned when gatekeep is turned off for the guild.
+	ErrGatekeepDisabled = errors.New("gatekeep is not enabled in this server")
+	// ErrAlreadyApproved is returned by ApproveMember when the member
+	// already holds the approved role and is not pending.
+	ErrAlreadyApproved = errors.New("member is already approved")
+	// ErrNotPending is returned by DenyMember and KickMember when the member
+	// does not hold the pending role, so there is nothing to deny.
+	ErrNotPending = errors.New("member is not pending approval")
+)
+
+var activeApprovalProcesses = make(map[snowflake.ID]bool)
+var activeApprovalMutex = &sync.Mutex{}
+
+// acquireGatekeepAction ensures that the member is not already being
+// approved, denied or kicked by another command invocation or dashboard
+// request. The returned release func must be called once the action ends.
+func acquireGatekeepAction(userID snowflake.ID) (release func(), ok bool) {
+	activeApprovalMutex.Lock()
+	defer activeApprovalMutex.Unlock()
+	if activeApprovalProcesses[userID] {
+		return nil, false
+	}
+	activeApprovalProcesses[userID] = true
+
+	return func() {
+		activeApprovalMutex.Lock()
+		delete(activeApprovalProcesses, userID)
+		activeApprovalMutex.Unlock()
+	}, true
+}
+
+// DenyMember removes the pending role without granting the approved role,
+// taking the member out of the approval queue. The member stays in the
+// server; use KickMember to remove them entirely.
+func DenyMember(
+	client *bot.Client,
+	guild discord.Guild,
+	member discord.Member,
+	moderator discord.User,
+	reason string,
+	source audit.Source,
+) error {
+	release, ok := acquireGatekeepAction(member.User.ID)
+	if !ok {
+		return ErrActionInProgress
+	}
+	defer release()
+
+	guildSettings, err := pendingSettings(guild.ID, member)
+	if err != nil {
+		return err
+	}
+
+	err = client.Rest.RemoveMemberRole(
+		guild.ID, member.User.ID,
+		guildSettings.GatekeepPendingRole,
+		rest.WithReason(gatekeepReason("denied", moderator, reason)),
+	)
+	if err != nil {
+		slog.Warn(
+			"Failed to remove pending role from denied user",
+			"guild_id", guild.ID,
+			"user_id", member.User.ID,
+			"role_id", guildSettings.GatekeepPendingRole,
+			"err", err,
+		)
+		return err
+	}
+
+	logGatekeepAction(guild.ID, audit.EventGatekeepDeny, moderator, member.User, reason, source)
+	return nil
+}
+
+// pendingSettings loads the guild settings for a deny or kick, checking
+// that gatekeep is enabled and member is pending approval.
+func pendingSettings(guildID snowflake.ID, member discord.Member) (*model.GuildSettings, error) {
+	guildSettings, err := model.GetGuildSettings(guildID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSettingsUnavailable, err)
+	}
+	if !guildSettings.GatekeepEnabled {
+		return nil, ErrGatekeepDisabled
+	}
+	if guildSettings.GatekeepPendingRole == 0 || !utils.HasRole(member, guildSettings.GatekeepPendingRole) {
+		return nil, ErrNotPending
+	}
+	return guildSettings, nil
+}
+
+// KickMember kicks a member from the approval queue. Only pending members
+// can be kicked this way; use /kick for anyone else. Discord's native audit
+// log additionally reports the kick (with the bot as actor); the
+// gatekeep.kick row written here is what attributes it to the moderator.
+func KickMember(
+	client *bot.Client,
+	guild discord.Guild,
+	member discord.Member,
+	moderator discord.User,
+	reason string,
+	source audit.Source,
+) error {
+	release, ok := acquireGatekeepAction(member.User.ID)
+	if !ok {
+		return ErrActionInProgress
+	}
+	defer release()
+
+	if _, err := pendingSettings(guild.ID, member); err != nil {
+		return err
+	}
+
+	err := client.Rest.RemoveMember(
+		guild.ID, member.User.ID,
+		rest.WithReason(gatekeepReason("kicked", moderator, reason)),
+	)
+	if err != nil {
+		slog.Warn(
+			"Failed to kick pending user",
+			"guild_id", guild.ID,
+			"user_id", member.User.ID,
+			"err", err,
+		)
+		return err
+	}
+
+	logGatekeepAction(guild.ID, audit.EventGatekeepKick, moderator, member.User, reason, source)
+	return nil
+}
+
+func gatekeepReason(verb string, moderator discord.User, reason string) string {
+	r := fmt.Sprintf("Gatekeep %s by: %s (%s)", verb, moderator.Username, moderator.ID)
+	if reason != "" {
+		r += ": " + reason
+	}
+	return r
+}
+
+// logGatekeepAction records a gatekeep.{approve,deny,kick} audit entry.
+// source distinguishes the slash command path from the dashboard queue.
+func logGatekeepAction(
+	guildID snowflake.ID,
+	eventType audit.EventType,
+	moderator discord.User,
+	target discord.User,
+	reason string,
+	source audit.Source,
+) {
+	actorID := moderator.ID
+	targetID := target.ID
+	audit.Log(audit.Entry{
+		GuildID:    guildID,
+		EventType:  eventType,
+		ActorID:    &actorID,
+		ActorKind:  audit.ActorUser,
+		TargetID:   &targetID,
+		TargetKind: audit.TargetUser,
+		Source:     source,
+		Reason:     reason,
+		Details: map[string]any{
+			"actor_username":  moderator.Username,
+			"target_username": target.Username,
+		},
+	})
+}
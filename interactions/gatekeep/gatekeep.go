@@ -1,9 +1,9 @@
 package gatekeep
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
-	"sync"
 
 	"github.com/cbroglie/mustache"
 	"github.com/disgoorg/disgo/bot"
@@ -12,19 +12,18 @@ import (
 	"github.com/disgoorg/disgo/rest"
 	"github.com/disgoorg/snowflake/v2"
 
+	"github.com/NLLCommunity/heimdallr/audit"
 	"github.com/NLLCommunity/heimdallr/interactions"
 	"github.com/NLLCommunity/heimdallr/model"
 	"github.com/NLLCommunity/heimdallr/utils"
 )
 
-var activeApprovalProcesses = make(map[snowflake.ID]bool)
-var activeApprovalMutex = &sync.Mutex{}
-
 func Register(r *handler.Mux) []discord.ApplicationCommandCreate {
 	r.Command("/approve", ApproveSlashCommandHandler)
 	r.Command("/Approve", ApproveUserCommandHandler)
+	r.Command("/deny", DenySlashCommandHandler)
 
-	return []discord.ApplicationCommandCreate{ApproveSlashCommand, ApproveUserCommand}
+	return []discord.ApplicationCommandCreate{ApproveSlashCommand, ApproveUserCommand, DenySlashCommand}
 }
 
 func getGuild(e *handler.CommandEvent) (guild discord.Guild, success bool, inGuild bool) {
@@ -50,26 +49,6 @@ func getGuild(e *handler.CommandEvent) (guild discord.Guild, success bool, inGui
 }
 
 func approvedInnerHandler(e *handler.CommandEvent, guild discord.Guild, member discord.ResolvedMember) error {
-	// Ensure that the user is not already being approved
-	// by another command invocation.
-	activeApprovalMutex.Lock()
-	if activeApprovalProcesses[member.User.ID] {
-		activeApprovalMutex.Unlock()
-		return e.CreateMessage(
-			interactions.EphemeralMessageContentf(
-				"%s is already being approved.", member.Mention(),
-			),
-		)
-	}
-	activeApprovalProcesses[member.User.ID] = true
-	activeApprovalMutex.Unlock()
-
-	defer func() {
-		activeApprovalMutex.Lock()
-		delete(activeApprovalProcesses, member.User.ID)
-		activeApprovalMutex.Unlock()
-	}()
-
 	slog.InfoContext(e.Ctx, "Entered approvedInnerHandler")
 	// If the deferred ack fails, every subsequent CreateFollowupMessage
 	// call will also fail — Discord only accepts followups after an
@@ -81,20 +60,95 @@ func approvedInnerHandler(e *handler.CommandEvent, guild discord.Guild, member d
 		return err
 	}
 
+	outcome, err := ApproveMember(e.Client(), guild, member.Member, e.User(), audit.SourceCommand)
+	switch {
+	case errors.Is(err, ErrActionInProgress):
+		_, err = e.CreateFollowupMessage(
+			interactions.EphemeralMessageContentf(
+				"%s is already being approved.", member.Mention(),
+			),
+		)
+		return err
+	case errors.Is(err, ErrSettingsUnavailable):
+		_, err = e.CreateFollowupMessage(interactions.EphemeralMessageContent("Failed to get guild information."))
+		return err
+	case errors.Is(err, ErrGatekeepDisabled):
+		_, err = e.CreateFollowupMessage(interactions.EphemeralMessageContent("Gatekeep is not enabled in this server."))
+		return err
+	case errors.Is(err, ErrAlreadyApproved):
+		_, err = e.CreateFollowupMessage(
+			interactions.EphemeralMessageContentf(
+				"User %s is already approved.", member.Mention(),
+			),
+		)
+		return err
+	case err != nil:
+		return err
+	}
+
+	_, err = e.CreateFollowupMessage(interactions.EphemeralMessageContent(outcome.String()))
+	return err
+}
+
+// ApproveOutcome reports what happened to the welcome message after an
+// approval's role changes went through.
+type ApproveOutcome int
+
+const (
+	ApproveMessageSent ApproveOutcome = iota
+	ApproveNoMessage
+	ApproveNoChannel
+	ApproveMessageFailed
+)
+
+// String returns the moderator-facing confirmation for the outcome. Shared
+// by the slash command followup and the dashboard result list so both
+// paths describe the same result the same way.
+func (o ApproveOutcome) String() string {
+	switch o {
+	case ApproveNoMessage:
+		return "No approved message set; not sending message. Roles have been set."
+	case ApproveNoChannel:
+		return "User approved, but no channel is configured for the welcome message. Set a Join/Leave channel in settings (or a system channel for this server)."
+	case ApproveMessageFailed:
+		return "Failed to send message to approved user."
+	}
+	return "User has been approved!"
+}
+
+// ApproveMember grants the approved role, removes the pending role, sends
+// the configured approval message and records a gatekeep.approve audit
+// entry. It is the single approval code path: the /approve slash command,
+// the Approve user command and the dashboard queue all go through here.
+//
+// Role-change failures are returned as errors; a failure to send the
+// welcome message is not, since the approval itself has already happened
+// — it is reported through the returned ApproveOutcome instead.
+func ApproveMember(
+	client *bot.Client,
+	guild discord.Guild,
+	member discord.Member,
+	approver discord.User,
+	source audit.Source,
+) (ApproveOutcome, error) {
+	release, ok := acquireGatekeepAction(member.User.ID)
+	if !ok {
+		return 0, ErrActionInProgress
+	}
+	defer release()
+
 	guildSettings, err := model.GetGuildSettings(guild.ID)
 	if err != nil {
-		slog.ErrorContext(
-			e.Ctx, "Failed to get guild settings.",
+		slog.Error(
+			"Failed to get guild settings.",
 			"guild_id", guild.ID,
 			"err", err,
 		)
-		_, err = e.CreateFollowupMessage(interactions.EphemeralMessageContent("Failed to get guild information."))
-		return err
+		return 0, fmt.Errorf("%w: %w", ErrSettingsUnavailable, err)
 	}
 
 	if !guildSettings.GatekeepEnabled {
-		_, err = e.CreateFollowupMessage(interactions.EphemeralMessageContent("Gatekeep is not enabled in this server."))
-		return err
+		return 0, ErrGatekeepDisabled
 	}
 
 	hasApprovedRole := false
@@ -109,19 +163,14 @@ func approvedInnerHandler(e *handler.CommandEvent, guild discord.Guild, member d
 	}
 
 	if hasApprovedRole && (!hasPendingRole || !guildSettings.GatekeepAddPendingRoleOnJoin) {
-		_, err = e.CreateFollowupMessage(
-			interactions.EphemeralMessageContentf(
-				"User %s is already approved.", member.Mention(),
-			),
-		)
-		return err
+		return 0, ErrAlreadyApproved
 	}
 
 	if guildSettings.GatekeepApprovedRole != 0 {
-		err = e.Client().Rest.AddMemberRole(
+		err = client.Rest.AddMemberRole(
 			guild.ID, member.User.ID,
 			guildSettings.GatekeepApprovedRole,
-			rest.WithReason(fmt.Sprintf("Gatekeep approved by: %s (%s)", e.User().Username, e.User().ID)),
+			rest.WithReason(fmt.Sprintf("Gatekeep approved by: %s (%s)", approver.Username, approver.ID)),
 		)
 		if err != nil {
 			slog.Warn(
@@ -130,14 +179,14 @@ func approvedInnerHandler(e *handler.CommandEvent, guild discord.Guild, member d
 				"user_id", member.User.ID,
 				"role_id", guildSettings.GatekeepApprovedRole,
 			)
-			return err
+			return 0, err
 		}
 	}
 	if guildSettings.GatekeepPendingRole != 0 {
-		err = e.Client().Rest.RemoveMemberRole(
+		err = client.Rest.RemoveMemberRole(
 			guild.ID, member.User.ID,
 			guildSettings.GatekeepPendingRole,
-			rest.WithReason(fmt.Sprintf("Gatekeep approved by: %s (%s)", e.User().Username, e.User().ID)),
+			rest.WithReason(fmt.Sprintf("Gatekeep approved by: %s (%s)", approver.Username, approver.ID)),
 		)
 		if err != nil {
 			slog.Warn(
@@ -146,26 +195,22 @@ func approvedInnerHandler(e *handler.CommandEvent, guild discord.Guild, member d
 				"user_id", member.User.ID,
 				"role_id", guildSettings.GatekeepPendingRole,
 			)
-			return err
+			return 0, err
 		}
 	}
 
-	slog.InfoContext(
-		e.Ctx, "user has been approved",
+	slog.Info(
+		"user has been approved",
 		"guild_id", guild.ID,
 	)
+	logGatekeepAction(guild.ID, audit.EventGatekeepApprove, approver, member.User, "", source)
 
 	hasV2 := guildSettings.GatekeepApprovedMessageV2 && guildSettings.GatekeepApprovedMessageV2Json != ""
 	hasPlain := guildSettings.GatekeepApprovedMessage != ""
 
 	if !hasV2 && !hasPlain {
 		slog.Info("No approved message set; not sending message.")
-		_, err = e.CreateFollowupMessage(
-			interactions.EphemeralMessageContent(
-				"No approved message set; not sending message. Roles have been set.",
-			),
-		)
-		return err
+		return ApproveNoMessage, nil
 	}
 
 	channel, channelOk := resolveApprovedMessageChannel(guildSettings, guild)
@@ -175,19 +220,14 @@ func approvedInnerHandler(e *handler.CommandEvent, guild discord.Guild, member d
 			"guild_id", guild.ID,
 			"user_id", member.User.ID,
 		)
-		_, err = e.CreateFollowupMessage(
-			interactions.EphemeralMessageContent(
-				"User approved, but no channel is configured for the welcome message. Set a Join/Leave channel in settings (or a system channel for this server).",
-			),
-		)
-		return err
+		return ApproveNoChannel, nil
 	}
 
-	templateData := utils.NewMessageTemplateData(member.Member, guild)
+	templateData := utils.NewMessageTemplateData(member, guild)
 
 	data := &gatekeepData{
-		approver:      e.User(),
-		client:        e.Client(),
+		approver:      approver,
+		client:        client,
 		guild:         guild,
 		member:        member,
 		guildSettings: guildSettings,
@@ -201,27 +241,17 @@ func approvedInnerHandler(e *handler.CommandEvent, guild discord.Guild, member d
 		_, err = createV1ApprovedMessage(data)
 	}
 	if err != nil {
-		_, err = e.CreateFollowupMessage(
-			interactions.EphemeralMessageContent(
-				"Failed to send message to approved user.",
-			),
-		)
-		return err
+		return ApproveMessageFailed, nil
 	}
 
-	_, err = e.CreateFollowupMessage(
-		interactions.EphemeralMessageContent(
-			"User has been approved!",
-		),
-	)
-	return err
+	return ApproveMessageSent, nil
 }
 
 type gatekeepData struct {
 	approver      discord.User
 	client        *bot.Client
 	guild         discord.Guild
-	member        discord.ResolvedMember
+	member        discord.Member
 	guildSettings *model.GuildSettings
 	templateData  utils.MessageTemplateData
 	channel       snowflake.ID
@@ -1,12 +1,15 @@
 package gatekeep
 
 import (
+	"errors"
 	"log/slog"
 
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/handler"
 	"github.com/disgoorg/omit"
 
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/interactions"
 	"github.com/NLLCommunity/heimdallr/utils"
 )
 
@@ -45,3 +48,75 @@ func ApproveSlashCommandHandler(e *handler.CommandEvent) error {
 
 	return approvedInnerHandler(e, guild, member)
 }
+
+var DenySlashCommand = discord.SlashCommandCreate{
+	Name:                     "deny",
+	Description:              "Deny a pending user, removing them from the approval queue",
+	DefaultMemberPermissions: omit.NewPtr(discord.PermissionKickMembers),
+	IntegrationTypes:         []discord.ApplicationIntegrationType{discord.ApplicationIntegrationTypeGuildInstall},
+	Contexts: []discord.InteractionContextType{
+		discord.InteractionContextTypeGuild,
+	},
+
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionUser{
+			Name:        "user",
+			Description: "The user to deny",
+			Required:    true,
+		},
+		discord.ApplicationCommandOptionBool{
+			Name:        "kick",
+			Description: "Also kick the user from the server",
+			Required:    false,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "reason",
+			Description: "The reason for denying the user",
+			Required:    false,
+		},
+	},
+}
+
+func DenySlashCommandHandler(e *handler.CommandEvent) error {
+	utils.LogInteractionContext("gatekeep", e, e.Ctx)
+
+	guild, success, inGuild := getGuild(e)
+	if !inGuild {
+		slog.Warn("deny command supplied in DMs or guild ID is otherwise nil")
+		return nil
+	}
+	if !success {
+		slog.Warn("deny command: failed to get guild")
+		return nil
+	}
+
+	data := e.SlashCommandInteractionData()
+	member := data.Member("user")
+	reason := data.String("reason")
+
+	var err error
+	if data.Bool("kick") {
+		err = KickMember(e.Client(), guild, member.Member, e.User(), reason, audit.SourceCommand)
+	} else {
+		err = DenyMember(e.Client(), guild, member.Member, e.User(), reason, audit.SourceCommand)
+	}
+
+	switch {
+	case errors.Is(err, ErrActionInProgress):
+		return e.CreateMessage(interactions.EphemeralMessageContentf("%s is already being processed.", member.Mention()))
+	case errors.Is(err, ErrSettingsUnavailable):
+		return e.CreateMessage(interactions.EphemeralMessageContent("Failed to get guild information."))
+	case errors.Is(err, ErrGatekeepDisabled):
+		return e.CreateMessage(interactions.EphemeralMessageContent("Gatekeep is not enabled in this server."))
+	case errors.Is(err, ErrNotPending):
+		return e.CreateMessage(interactions.EphemeralMessageContentf("User %s is not pending approval.", member.Mention()))
+	case err != nil:
+		_ = e.CreateMessage(interactions.EphemeralMessageContentf("Failed to deny user %s.", member.Mention()))
+		return err
+	}
+
+	if data.Bool("kick") {
+		return e.CreateMessage(interactions.EphemeralMessageContentf("User %s was denied and kicked.", member.Mention()))
+	}
+	return e.CreateMessage(interactions.EphemeralMessageContentf("User %s was denied.", member.Mention()))
+}
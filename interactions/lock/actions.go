@@ -0,0 +1,268 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+var (
+	// ErrAlreadyLocked is returned by LockChannel when the channel is
+	// already locked.
+	ErrAlreadyLocked = errors.New("channel is already locked")
+	// ErrReadOnly is returned by LockChannel when @everyone already can't
+	// send messages in the channel, so there is nothing to lock.
+	ErrReadOnly = errors.New("channel is already read-only for @everyone")
+	// ErrNotLocked is returned by UnlockChannel when the channel is not
+	// locked.
+	ErrNotLocked = errors.New("channel is not locked")
+)
+
+// lockPermissions are denied to @everyone while a channel is locked.
+// Threads have their own send permission, so it is denied as well.
+const lockPermissions = discord.PermissionSendMessages | discord.PermissionSendMessagesInThreads
+
+// LockChannel snapshots the channel's @everyone overwrite, posts a notice
+// and then denies @everyone from sending messages. The notice goes out
+// first because the bot may itself lose the right to post once @everyone
+// is denied, and is deleted again if the lock then fails. until is nil for
+// a lock without a duration.
+func LockChannel(
+	client *bot.Client,
+	channel discord.GuildChannel,
+	moderator discord.User,
+	reason string,
+	until *time.Time,
+	source audit.Source,
+) error {
+	guildID := channel.GuildID()
+	current, had := channel.PermissionOverwrites().Role(guildID)
+	if current.Deny.Has(discord.PermissionSendMessages) {
+		return ErrReadOnly
+	}
+
+	lock := &model.ChannelLock{
+		ChannelID:    channel.ID(),
+		GuildID:      guildID,
+		LockedBy:     moderator.ID,
+		Reason:       reason,
+		Until:        until,
+		HadOverwrite: had,
+		Allow:        current.Allow,
+		Deny:         current.Deny,
+	}
+	created, err := model.CreateChannelLock(lock)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return ErrAlreadyLocked
+	}
+
+	notice := "🔒 This channel has been locked by the moderators."
+	if reason != "" {
+		notice += "\n>>> " + reason
+	}
+	if until != nil {
+		notice += fmt.Sprintf("\n-# Unlocks <t:%d:R>.", until.Unix())
+	}
+	noticeID := sendNotice(client, channel.ID(), notice)
+
+	allow := current.Allow.Remove(lockPermissions)
+	deny := current.Deny.Add(lockPermissions)
+	err = client.Rest.UpdatePermissionOverwrite(channel.ID(), guildID, discord.RolePermissionOverwriteUpdate{
+		Allow: &allow,
+		Deny:  &deny,
+	}, rest.WithReason(lockReason("Channel locked", &moderator, reason)))
+	if err != nil {
+		if _, delErr := model.DeleteChannelLock(channel.ID()); delErr != nil {
+			slog.Error("lock: failed to drop lock after overwrite failure", "channel_id", channel.ID(), "err", delErr)
+		}
+		retractNotice(client, channel.ID(), noticeID)
+		return err
+	}
+
+	details := map[string]any{
+		"channel_id":   channel.ID().String(),
+		"channel_name": channel.Name(),
+	}
+	if until != nil {
+		details["until"] = until.UTC().Format(time.RFC3339)
+	}
+	logLock(guildID, audit.EventChannelLock, &moderator, channel.ID(), reason, source, details)
+	return nil
+}
+
+// UnlockChannel puts the channel's @everyone overwrite back to the
+// snapshot taken by LockChannel — removing it entirely if the channel had
+// none — and posts a notice. moderator is nil when the lock expired.
+//
+// Changes made to the @everyone overwrite while the channel was locked are
+// discarded; the snapshot wins.
+func UnlockChannel(
+	client *bot.Client,
+	lock model.ChannelLock,
+	moderator *discord.User,
+	reason string,
+	source audit.Source,
+) error {
+	opt := rest.WithReason(lockReason("Channel unlocked", moderator, reason))
+	var err error
+	if lock.HadOverwrite {
+		allow, deny := lock.Allow, lock.Deny
+		err = client.Rest.UpdatePermissionOverwrite(lock.ChannelID, lock.GuildID, discord.RolePermissionOverwriteUpdate{
+			Allow: &allow,
+			Deny:  &deny,
+		}, opt)
+	} else {
+		err = client.Rest.DeletePermissionOverwrite(lock.ChannelID, lock.GuildID, opt)
+	}
+	// A deleted channel has nothing left to restore; drop the lock as if
+	// the unlock succeeded. Anything else keeps the lock so it can be
+	// retried.
+	channelGone := rest.IsJSONErrorCode(err, rest.JSONErrorCodeUnknownChannel)
+	if err != nil && !channelGone {
+		return err
+	}
+
+	deleted, err := model.DeleteChannelLock(lock.ChannelID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNotLocked
+	}
+	if channelGone {
+		return nil
+	}
+
+	sendNotice(client, lock.ChannelID, "🔓 This channel has been unlocked.")
+
+	details := map[string]any{
+		"channel_id": lock.ChannelID.String(),
+	}
+	if ch, ok := client.Caches.Channel(lock.ChannelID); ok {
+		details["channel_name"] = ch.Name()
+	}
+	if moderator == nil {
+		details["expired"] = true
+	}
+	logLock(lock.GuildID, audit.EventChannelUnlock, moderator, lock.ChannelID, reason, source, details)
+	return nil
+}
+
+// PublicChannels returns the guild's text and announcement channels that
+// @everyone can currently see and send messages in, in channel-list order.
+func PublicChannels(client *bot.Client, guildID snowflake.ID) []discord.GuildChannel {
+	everyone, ok := client.Caches.Role(guildID, guildID)
+	if !ok {
+		return nil
+	}
+	var channels []discord.GuildChannel
+	for ch := range client.Caches.ChannelsForGuild(guildID) {
+		if isPublicTextChannel(everyone.Permissions, ch) {
+			channels = append(channels, ch)
+		}
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		return channels[i].Position() < channels[j].Position()
+	})
+	return channels
+}
+
+// isPublicTextChannel applies the channel's @everyone overwrite to the
+// @everyone role permissions and reports whether the result can both view
+// the channel and send messages in it.
+func isPublicTextChannel(everyonePerms discord.Permissions, ch discord.GuildChannel) bool {
+	switch ch.Type() {
+	case discord.ChannelTypeGuildText, discord.ChannelTypeGuildNews:
+	default:
+		return false
+	}
+	perms := everyonePerms
+	if ow, ok := ch.PermissionOverwrites().Role(ch.GuildID()); ok {
+		perms = perms.Remove(ow.Deny).Add(ow.Allow)
+	}
+	return perms.Has(discord.PermissionViewChannel, discord.PermissionSendMessages)
+}
+
+// sendNotice posts content to the channel and returns the message's ID,
+// or 0 if it couldn't be posted.
+func sendNotice(client *bot.Client, channelID snowflake.ID, content string) snowflake.ID {
+	msg, err := client.Rest.CreateMessage(channelID, discord.NewMessageCreate().
+		WithContent(content).
+		WithAllowedMentions(&discord.AllowedMentions{}))
+	if err != nil {
+		slog.Warn("lock: failed to post notice", "channel_id", channelID, "err", err)
+		return 0
+	}
+	return msg.ID
+}
+
+// retractNotice deletes a lock notice for a lock that didn't go through,
+// posting a correction instead if the notice can't be deleted.
+func retractNotice(client *bot.Client, channelID, noticeID snowflake.ID) {
+	if noticeID == 0 {
+		return
+	}
+	err := client.Rest.DeleteMessage(channelID, noticeID)
+	if err == nil {
+		return
+	}
+	slog.Warn("lock: failed to delete notice for failed lock", "channel_id", channelID, "message_id", noticeID, "err", err)
+	sendNotice(client, channelID, "🔓 Never mind: locking this channel failed, so it is still open.")
+}
+
+func lockReason(action string, moderator *discord.User, reason string) string {
+	r := action
+	if moderator != nil {
+		r += fmt.Sprintf(" by: %s (%s)", moderator.Username, moderator.ID)
+	} else {
+		r += " (lock expired)"
+	}
+	if reason != "" {
+		r += ": " + reason
+	}
+	return r
+}
+
+// logLock records a channel.{lock,unlock} audit entry. moderator is nil
+// for expired locks, which are attributed to the system.
+func logLock(
+	guildID snowflake.ID,
+	eventType audit.EventType,
+	moderator *discord.User,
+	channelID snowflake.ID,
+	reason string,
+	source audit.Source,
+	details map[string]any,
+) {
+	targetID := channelID
+	entry := audit.Entry{
+		GuildID:    guildID,
+		EventType:  eventType,
+		ActorKind:  audit.ActorSystem,
+		TargetID:   &targetID,
+		TargetKind: audit.TargetChannel,
+		Source:     source,
+		Reason:     reason,
+		Details:    details,
+	}
+	if moderator != nil {
+		actorID := moderator.ID
+		entry.ActorID = &actorID
+		entry.ActorKind = audit.ActorUser
+		details["actor_username"] = moderator.Username
+	}
+	audit.Log(entry)
+}
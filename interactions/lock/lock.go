@@ -0,0 +1,248 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/omit"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/interactions"
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/utils"
+)
+
+func Register(r *handler.Mux) []discord.ApplicationCommandCreate {
+	r.Command("/lock", LockHandler)
+	r.Command("/unlock", UnlockHandler)
+
+	return []discord.ApplicationCommandCreate{LockCommand, UnlockCommand}
+}
+
+var lockableChannelTypes = []discord.ChannelType{
+	discord.ChannelTypeGuildText,
+	discord.ChannelTypeGuildNews,
+}
+
+var LockCommand = discord.SlashCommandCreate{
+	Name:                     "lock",
+	Description:              "Stop @everyone from sending messages in a channel",
+	Contexts:                 []discord.InteractionContextType{discord.InteractionContextTypeGuild},
+	IntegrationTypes:         []discord.ApplicationIntegrationType{discord.ApplicationIntegrationTypeGuildInstall},
+	DefaultMemberPermissions: omit.NewPtr(discord.PermissionManageChannels),
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionString{
+			Name:        "reason",
+			Description: "Reason for the lock. Shown in the notice posted to the channel.",
+			Required:    true,
+			MaxLength:   new(400),
+		},
+		discord.ApplicationCommandOptionChannel{
+			Name:         "channel",
+			Description:  "The channel to lock (defaults to this channel)",
+			Required:     false,
+			ChannelTypes: lockableChannelTypes,
+		},
+		discord.ApplicationCommandOptionBool{
+			Name:        "all-public",
+			Description: "Lock every channel @everyone can currently send messages in",
+			Required:    false,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "duration",
+			Description: "Unlock automatically after this long (format: 1d2h30m)",
+			Required:    false,
+		},
+	},
+}
+
+var UnlockCommand = discord.SlashCommandCreate{
+	Name:                     "unlock",
+	Description:              "Restore a locked channel's previous permissions",
+	Contexts:                 []discord.InteractionContextType{discord.InteractionContextTypeGuild},
+	IntegrationTypes:         []discord.ApplicationIntegrationType{discord.ApplicationIntegrationTypeGuildInstall},
+	DefaultMemberPermissions: omit.NewPtr(discord.PermissionManageChannels),
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionChannel{
+			Name:         "channel",
+			Description:  "The channel to unlock (defaults to this channel)",
+			Required:     false,
+			ChannelTypes: lockableChannelTypes,
+		},
+		discord.ApplicationCommandOptionBool{
+			Name:        "all-public",
+			Description: "Unlock every channel locked with /lock",
+			Required:    false,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "reason",
+			Description: "Reason for unlocking",
+			Required:    false,
+		},
+	},
+}
+
+func LockHandler(e *handler.CommandEvent) error {
+	utils.LogInteraction("lock", e)
+
+	guildID := e.GuildID()
+	if guildID == nil {
+		return interactions.ErrEventNoGuildID
+	}
+
+	data := e.SlashCommandInteractionData()
+	reason := strings.TrimSpace(data.String("reason"))
+
+	var until *time.Time
+	if durationStr := data.String("duration"); durationStr != "" {
+		duration, err := utils.ParseLongDuration(durationStr)
+		if err != nil || duration < time.Minute {
+			return e.CreateMessage(interactions.EphemeralMessageContent(
+				"Invalid duration. Use the format 1d2h30m, at least one minute."))
+		}
+		t := time.Now().Add(duration)
+		until = &t
+	}
+
+	var channels []discord.GuildChannel
+	if data.Bool("all-public") {
+		channels = PublicChannels(e.Client(), *guildID)
+		if len(channels) == 0 {
+			return e.CreateMessage(interactions.EphemeralMessageContent("There are no public channels to lock."))
+		}
+	} else {
+		ch, err := targetChannel(e)
+		if err != nil {
+			return e.CreateMessage(interactions.EphemeralMessageContent("Could not find that channel."))
+		}
+		channels = []discord.GuildChannel{ch}
+	}
+
+	// Locking many channels is several REST calls each; defer so the
+	// interaction doesn't time out.
+	if err := e.DeferCreateMessage(true); err != nil {
+		return err
+	}
+
+	var locked, skipped []string
+	for _, ch := range channels {
+		err := LockChannel(e.Client(), ch, e.User(), reason, until, audit.SourceCommand)
+		switch {
+		case err == nil:
+			locked = append(locked, discord.ChannelMention(ch.ID()))
+		case errors.Is(err, ErrAlreadyLocked):
+			skipped = append(skipped, discord.ChannelMention(ch.ID())+" (already locked)")
+		case errors.Is(err, ErrReadOnly):
+			skipped = append(skipped, discord.ChannelMention(ch.ID())+" (already read-only)")
+		default:
+			slog.Warn("Failed to lock channel.", "guild_id", *guildID, "channel_id", ch.ID(), "err", err)
+			skipped = append(skipped, discord.ChannelMention(ch.ID())+" (failed)")
+		}
+	}
+
+	summary := lockSummary("Locked", locked, skipped)
+	if until != nil && len(locked) > 0 {
+		summary += fmt.Sprintf("\nUnlocks <t:%d:R>.", until.Unix())
+	}
+	_, err := e.CreateFollowupMessage(interactions.EphemeralMessageContent(summary))
+	return err
+}
+
+func UnlockHandler(e *handler.CommandEvent) error {
+	utils.LogInteraction("lock", e)
+
+	guildID := e.GuildID()
+	if guildID == nil {
+		return interactions.ErrEventNoGuildID
+	}
+
+	data := e.SlashCommandInteractionData()
+	reason := strings.TrimSpace(data.String("reason"))
+
+	var locks []model.ChannelLock
+	if data.Bool("all-public") {
+		var err error
+		locks, err = model.GetChannelLocks(*guildID)
+		if err != nil {
+			return err
+		}
+		if len(locks) == 0 {
+			return e.CreateMessage(interactions.EphemeralMessageContent("No channels are locked."))
+		}
+	} else {
+		ch, err := targetChannel(e)
+		if err != nil {
+			return e.CreateMessage(interactions.EphemeralMessageContent("Could not find that channel."))
+		}
+		lock, err := model.GetChannelLock(ch.ID())
+		if err != nil {
+			return err
+		}
+		if lock == nil {
+			return e.CreateMessage(interactions.EphemeralMessageContentf(
+				"%s was not locked with /lock.", discord.ChannelMention(ch.ID())))
+		}
+		locks = []model.ChannelLock{*lock}
+	}
+
+	if err := e.DeferCreateMessage(true); err != nil {
+		return err
+	}
+
+	moderator := e.User()
+	var unlocked, skipped []string
+	for _, lock := range locks {
+		err := UnlockChannel(e.Client(), lock, &moderator, reason, audit.SourceCommand)
+		switch {
+		case err == nil:
+			unlocked = append(unlocked, discord.ChannelMention(lock.ChannelID))
+		case errors.Is(err, ErrNotLocked):
+			skipped = append(skipped, discord.ChannelMention(lock.ChannelID)+" (already unlocked)")
+		default:
+			slog.Warn("Failed to unlock channel.", "guild_id", *guildID, "channel_id", lock.ChannelID, "err", err)
+			skipped = append(skipped, discord.ChannelMention(lock.ChannelID)+" (failed)")
+		}
+	}
+
+	_, err := e.CreateFollowupMessage(interactions.EphemeralMessageContent(lockSummary("Unlocked", unlocked, skipped)))
+	return err
+}
+
+// targetChannel resolves the "channel" option, defaulting to the channel
+// the command was used in.
+func targetChannel(e *handler.CommandEvent) (discord.GuildChannel, error) {
+	channelID := e.Channel().ID()
+	if opt, ok := e.SlashCommandInteractionData().OptChannel("channel"); ok {
+		channelID = opt.ID
+	}
+	if ch, ok := e.Client().Caches.Channel(channelID); ok {
+		return ch, nil
+	}
+	ch, err := e.Client().Rest.GetChannel(channelID)
+	if err != nil {
+		return nil, err
+	}
+	guildCh, ok := ch.(discord.GuildChannel)
+	if !ok {
+		return nil, fmt.Errorf("channel %d is not a guild channel", channelID)
+	}
+	return guildCh, nil
+}
+
+func lockSummary(verb string, done, skipped []string) string {
+	var b strings.Builder
+	if len(done) > 0 {
+		fmt.Fprintf(&b, "%s %s.", verb, strings.Join(done, ", "))
+	} else {
+		fmt.Fprintf(&b, "%s nothing.", verb)
+	}
+	if len(skipped) > 0 {
+		fmt.Fprintf(&b, "\nSkipped: %s", strings.Join(skipped, ", "))
+	}
+	return b.String()
+}
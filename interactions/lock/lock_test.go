@@ -0,0 +1,65 @@
+package lock
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testGuildID = 100
+
+// testChannel builds a guild channel the way the gateway delivers it; the
+// disgo channel types have no exported fields.
+func testChannel(t *testing.T, channelType discord.ChannelType, overwrites string) discord.GuildChannel {
+	t.Helper()
+	raw := fmt.Sprintf(`{"id":"1","guild_id":"%d","type":%d,"name":"test","permission_overwrites":[%s]}`,
+		testGuildID, channelType, overwrites)
+	var ch discord.UnmarshalChannel
+	require.NoError(t, json.Unmarshal([]byte(raw), &ch))
+	guildCh, ok := ch.Channel.(discord.GuildChannel)
+	require.True(t, ok)
+	return guildCh
+}
+
+func everyoneOverwrite(allow, deny discord.Permissions) string {
+	b, _ := json.Marshal(discord.RolePermissionOverwrite{RoleID: testGuildID, Allow: allow, Deny: deny})
+	return string(b)
+}
+
+func TestIsPublicTextChannel(t *testing.T) {
+	base := discord.PermissionViewChannel | discord.PermissionSendMessages
+
+	cases := []struct {
+		name       string
+		everyone   discord.Permissions
+		typ        discord.ChannelType
+		overwrites string
+		want       bool
+	}{
+		{"plain text channel", base, discord.ChannelTypeGuildText, "", true},
+		{"announcement channel", base, discord.ChannelTypeGuildNews, "", true},
+		{"voice channel", base, discord.ChannelTypeGuildVoice, "", false},
+		{"hidden by overwrite", base, discord.ChannelTypeGuildText,
+			everyoneOverwrite(0, discord.PermissionViewChannel), false},
+		{"read-only by overwrite", base, discord.ChannelTypeGuildText,
+			everyoneOverwrite(0, discord.PermissionSendMessages), false},
+		{"role lacks send, overwrite allows it", discord.PermissionViewChannel, discord.ChannelTypeGuildText,
+			everyoneOverwrite(discord.PermissionSendMessages, 0), true},
+		{"role lacks send", discord.PermissionViewChannel, discord.ChannelTypeGuildText, "", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ch := testChannel(t, tc.typ, tc.overwrites)
+			assert.Equal(t, tc.want, isPublicTextChannel(tc.everyone, ch))
+		})
+	}
+}
+
+func TestLockSummary(t *testing.T) {
+	assert.Equal(t, "Locked <#1>, <#2>.", lockSummary("Locked", []string{"<#1>", "<#2>"}, nil))
+	assert.Equal(t, "Unlocked nothing.\nSkipped: <#1> (failed)", lockSummary("Unlocked", nil, []string{"<#1> (failed)"}))
+}
@@ -0,0 +1,328 @@
+package lockdown
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/omit"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/utils"
+)
+
+var (
+	// ErrLockdownActive is returned by Start when the guild is already
+	// locked down.
+	ErrLockdownActive = errors.New("the server is already in lockdown")
+	// ErrNoLockdown is returned by End when the guild is not locked down.
+	ErrNoLockdown = errors.New("the server is not in lockdown")
+	// ErrSettingsUnavailable wraps a failure to load the guild settings.
+	ErrSettingsUnavailable = errors.New("failed to get guild settings")
+)
+
+// invitePauseDuration is how long invites are paused for. Discord rejects
+// anything longer than 24 hours.
+const invitePauseDuration = 24 * time.Hour
+
+// waveRoleMax caps how many of the members that triggered detection get
+// the pending role at lockdown start, so one raid can't spend the bot's
+// whole rate-limit budget on role changes.
+const waveRoleMax = 50
+
+// Trigger describes why a lockdown is being started.
+type Trigger struct {
+	// Reason is shown in the alert, on the dashboard and in the audit log.
+	Reason string
+	// UserIDs are the members whose joins tripped raid detection. They get
+	// the gatekeep pending role, like everyone who joins during the
+	// lockdown.
+	UserIDs []snowflake.ID
+}
+
+// Start locks the guild down: it raises the verification level, pauses
+// invites if configured, gives the triggering members the gatekeep pending
+// role and posts an alert with an "End lockdown" button to the moderator
+// channel. moderator is nil when raid detection started the lockdown.
+//
+// Each step is best-effort: a failed step is logged and reported in the
+// alert, but does not stop the others — a half-applied lockdown is better
+// than none during a raid.
+func Start(
+	client *bot.Client,
+	guildID snowflake.ID,
+	trigger Trigger,
+	moderator *discord.User,
+	source audit.Source,
+) error {
+	settings, err := model.GetGuildSettings(guildID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSettingsUnavailable, err)
+	}
+
+	lockdown := &model.GuildLockdown{
+		GuildID: guildID,
+		Reason:  trigger.Reason,
+	}
+	if moderator != nil {
+		lockdown.StartedBy = moderator.ID
+	}
+	created, err := model.CreateGuildLockdown(lockdown)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return ErrLockdownActive
+	}
+
+	reason := rest.WithReason(auditReason("Raid lockdown started", moderator, trigger.Reason))
+	var notes []string
+
+	currentLevel, err := guildVerificationLevel(client, guildID)
+	if err != nil {
+		slog.Warn("lockdown: failed to get guild", "guild_id", guildID, "err", err)
+		notes = append(notes, "⚠️ Could not read the verification level.")
+	} else {
+		lockdown.PreviousVerificationLevel = int(currentLevel)
+		target := discord.VerificationLevel(settings.RaidVerificationLevel)
+		if currentLevel < target {
+			_, err := client.Rest.UpdateGuild(guildID, discord.GuildUpdate{
+				VerificationLevel: omit.NewPtr(target),
+			}, reason)
+			if err != nil {
+				slog.Warn("lockdown: failed to raise verification level", "guild_id", guildID, "err", err)
+				notes = append(notes, "⚠️ Could not raise the verification level.")
+			} else {
+				lockdown.VerificationRaised = true
+				notes = append(notes, "Verification level raised to **"+VerificationLevelName(target)+"**.")
+			}
+		}
+	}
+
+	if settings.RaidPauseInvites {
+		until := time.Now().Add(invitePauseDuration)
+		_, err := client.Rest.UpdateGuildIncidentActions(guildID, discord.GuildIncidentActionsUpdate{
+			InvitesDisabledUntil: omit.NewPtr(until),
+		}, reason)
+		if err != nil {
+			slog.Warn("lockdown: failed to pause invites", "guild_id", guildID, "err", err)
+			notes = append(notes, "⚠️ Could not pause invites.")
+		} else {
+			lockdown.InvitesPaused = true
+			notes = append(notes, "Invites paused for up to 24 hours.")
+		}
+	}
+
+	if settings.GatekeepPendingRole != 0 {
+		assigned := assignPendingRole(client, guildID, settings.GatekeepPendingRole, trigger.UserIDs)
+		notes = append(notes, "New members get the gatekeep pending role until the lockdown ends.")
+		if assigned > 0 {
+			notes = append(notes, fmt.Sprintf("%d recent joins were given the pending role.", assigned))
+		}
+	}
+
+	if settings.ModeratorChannel != 0 {
+		msg, err := client.Rest.CreateMessage(settings.ModeratorChannel,
+			discord.NewMessageCreate().
+				WithContent(startMessage(lockdown, moderator, notes)).
+				WithAllowedMentions(&discord.AllowedMentions{}).
+				AddActionRow(discord.NewDangerButton("End lockdown", "/lockdown/end")),
+		)
+		if err != nil {
+			slog.Warn("lockdown: failed to send alert", "guild_id", guildID, "err", err)
+		} else {
+			lockdown.AlertChannelID = msg.ChannelID
+			lockdown.AlertMessageID = msg.ID
+		}
+	}
+
+	if saved, err := model.SaveGuildLockdown(lockdown); err != nil {
+		slog.Error("lockdown: failed to save lockdown state", "guild_id", guildID, "err", err)
+	} else if !saved {
+		slog.Warn("lockdown: lockdown ended while it was being started", "guild_id", guildID)
+	}
+
+	details := map[string]any{
+		"reason":              trigger.Reason,
+		"users":               len(trigger.UserIDs),
+		"verification_raised": lockdown.VerificationRaised,
+		"invites_paused":      lockdown.InvitesPaused,
+	}
+	logLockdown(guildID, audit.EventLockdownStart, moderator, trigger.Reason, source, details)
+	return nil
+}
+
+// End lifts the lockdown: it restores the verification level, resumes
+// invites and updates the alert. Members who were given the pending role
+// keep it and stay in the gatekeep queue for review.
+func End(client *bot.Client, guildID snowflake.ID, moderator discord.User, source audit.Source) error {
+	lockdown, err := model.GetGuildLockdown(guildID)
+	if err != nil {
+		return err
+	}
+	if lockdown == nil {
+		return ErrNoLockdown
+	}
+	deleted, err := model.DeleteGuildLockdown(guildID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNoLockdown
+	}
+
+	reason := rest.WithReason(auditReason("Raid lockdown ended", &moderator, ""))
+
+	if lockdown.VerificationRaised {
+		_, err := client.Rest.UpdateGuild(guildID, discord.GuildUpdate{
+			VerificationLevel: omit.NewPtr(discord.VerificationLevel(lockdown.PreviousVerificationLevel)),
+		}, reason)
+		if err != nil {
+			slog.Warn("lockdown: failed to restore verification level", "guild_id", guildID, "err", err)
+		}
+	}
+
+	if lockdown.InvitesPaused {
+		_, err := client.Rest.UpdateGuildIncidentActions(guildID, discord.GuildIncidentActionsUpdate{
+			InvitesDisabledUntil: omit.New[*time.Time](nil),
+		}, reason)
+		if err != nil {
+			slog.Warn("lockdown: failed to resume invites", "guild_id", guildID, "err", err)
+		}
+	}
+
+	if lockdown.AlertMessageID != 0 {
+		_, err := client.Rest.UpdateMessage(lockdown.AlertChannelID, lockdown.AlertMessageID,
+			discord.NewMessageUpdate().
+				WithContent(endMessage(lockdown, moderator, time.Now())).
+				WithAllowedMentions(&discord.AllowedMentions{}).
+				ClearComponents(),
+		)
+		if err != nil {
+			slog.Warn("lockdown: failed to update alert", "guild_id", guildID, "err", err)
+		}
+	}
+
+	details := map[string]any{
+		"reason":           lockdown.Reason,
+		"duration_seconds": int64(time.Since(lockdown.CreatedAt).Seconds()),
+	}
+	logLockdown(guildID, audit.EventLockdownEnd, &moderator, "", source, details)
+	return nil
+}
+
+func guildVerificationLevel(client *bot.Client, guildID snowflake.ID) (discord.VerificationLevel, error) {
+	if guild, ok := client.Caches.Guild(guildID); ok {
+		return guild.VerificationLevel, nil
+	}
+	guild, err := client.Rest.GetGuild(guildID, false)
+	if err != nil {
+		return 0, err
+	}
+	return guild.VerificationLevel, nil
+}
+
+// assignPendingRole gives up to waveRoleMax of the triggering members the
+// pending role and returns how many succeeded.
+func assignPendingRole(client *bot.Client, guildID, roleID snowflake.ID, userIDs []snowflake.ID) int {
+	if len(userIDs) > waveRoleMax {
+		userIDs = userIDs[len(userIDs)-waveRoleMax:]
+	}
+	assigned := 0
+	for _, userID := range userIDs {
+		err := client.Rest.AddMemberRole(guildID, userID, roleID, rest.WithReason("Raid lockdown"))
+		if err != nil {
+			slog.Warn("lockdown: failed to add pending role", "guild_id", guildID, "user_id", userID, "err", err)
+			continue
+		}
+		assigned++
+	}
+	return assigned
+}
+
+// VerificationLevelName returns Discord's name for a verification level.
+func VerificationLevelName(level discord.VerificationLevel) string {
+	switch level {
+	case discord.VerificationLevelNone:
+		return "None"
+	case discord.VerificationLevelLow:
+		return "Low"
+	case discord.VerificationLevelMedium:
+		return "Medium"
+	case discord.VerificationLevelHigh:
+		return "High"
+	case discord.VerificationLevelVeryHigh:
+		return "Highest"
+	}
+	return fmt.Sprintf("Level %d", level)
+}
+
+func startMessage(lockdown *model.GuildLockdown, moderator *discord.User, notes []string) string {
+	var b strings.Builder
+	if moderator != nil {
+		fmt.Fprintf(&b, "🚨 **Lockdown started** by %s: %s", moderator.Mention(), lockdown.Reason)
+	} else {
+		fmt.Fprintf(&b, "🚨 **Possible raid — lockdown started**: %s", lockdown.Reason)
+	}
+	for _, note := range notes {
+		b.WriteString("\n- " + note)
+	}
+	return b.String()
+}
+
+func endMessage(lockdown *model.GuildLockdown, moderator discord.User, now time.Time) string {
+	return fmt.Sprintf(
+		"✅ **Lockdown ended** by %s after %s.\n-# Started for: %s\n-# Members given the pending role during the lockdown are still in the gatekeep queue.",
+		moderator.Mention(),
+		utils.ApproxDuration(now.Sub(lockdown.CreatedAt)),
+		lockdown.Reason,
+	)
+}
+
+func auditReason(action string, moderator *discord.User, detail string) string {
+	r := action
+	if moderator != nil {
+		r += fmt.Sprintf(" by: %s (%s)", moderator.Username, moderator.ID)
+	}
+	if detail != "" {
+		r += ": " + detail
+	}
+	return r
+}
+
+// logLockdown records a lockdown.{start,end} audit entry. moderator is nil
+// for automatic starts, which are attributed to the system.
+func logLockdown(
+	guildID snowflake.ID,
+	eventType audit.EventType,
+	moderator *discord.User,
+	reason string,
+	source audit.Source,
+	details map[string]any,
+) {
+	gid := guildID
+	entry := audit.Entry{
+		GuildID:    guildID,
+		EventType:  eventType,
+		ActorKind:  audit.ActorSystem,
+		TargetID:   &gid,
+		TargetKind: audit.TargetGuild,
+		Source:     source,
+		Reason:     reason,
+		Details:    details,
+	}
+	if moderator != nil {
+		actorID := moderator.ID
+		entry.ActorID = &actorID
+		entry.ActorKind = audit.ActorUser
+		details["actor_username"] = moderator.Username
+	}
+	audit.Log(entry)
+}
@@ -0,0 +1,58 @@
+package lockdown
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	ix "github.com/NLLCommunity/heimdallr/interactions"
+	"github.com/NLLCommunity/heimdallr/utils"
+)
+
+// Register wires the "End lockdown" button on the moderator channel alert.
+// Lockdowns are started by raid detection or the dashboard, so there is no
+// slash command to register.
+func Register(r *handler.Mux) []discord.ApplicationCommandCreate {
+	r.Component("/lockdown/end", EndLockdownButtonHandler)
+
+	return nil
+}
+
+// EndLockdownButtonHandler ends the guild's lockdown. The alert is posted
+// in the moderator channel, but anyone who can see that channel can click
+// the button, so Manage Server is checked here as well.
+func EndLockdownButtonHandler(e *handler.ComponentEvent) error {
+	utils.LogInteraction("lockdown", e)
+
+	guildID := e.GuildID()
+	if guildID == nil {
+		return ix.ErrEventNoGuildID
+	}
+	member := e.Member()
+	if member == nil || !member.Permissions.Has(discord.PermissionManageGuild) {
+		return e.CreateMessage(ix.EphemeralMessageContent("You need the Manage Server permission to end the lockdown."))
+	}
+
+	// Ending the lockdown is several REST calls (verification level,
+	// invites, roles); defer so the interaction doesn't time out.
+	if err := e.DeferCreateMessage(true); err != nil {
+		return err
+	}
+	reply := func(content string) error {
+		_, err := e.UpdateInteractionResponse(discord.NewMessageUpdate().WithContent(content))
+		return err
+	}
+
+	err := End(e.Client(), *guildID, e.User(), audit.SourceCommand)
+	switch {
+	case errors.Is(err, ErrNoLockdown):
+		return reply("The server is not in lockdown.")
+	case err != nil:
+		slog.Error("Failed to end lockdown.", "guild_id", *guildID, "err", err)
+		return reply("Failed to end the lockdown.")
+	}
+	return reply("Lockdown ended.")
+}
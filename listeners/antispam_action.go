@@ -0,0 +1,97 @@
+package listeners
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/omit"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/antispam"
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// errNoQuarantineRole is returned by punishSpammer when the quarantine
+// action is skipped because the guild has no quarantine role.
+var errNoQuarantineRole = errors.New("no quarantine role is configured")
+
+// punishSpammer applies action to the member and, if the guild asks for
+// it, records an infraction. It returns errNoQuarantineRole if the action
+// was skipped, or Discord's error if it failed; the infraction is recorded
+// either way. Deleting the spam is left to the caller, since which
+// messages go depends on the detector. Callers skip this entirely in
+// observe-only mode.
+func punishSpammer(
+	client *bot.Client,
+	guildID snowflake.ID,
+	guildSettings *model.GuildSettings,
+	action antispam.Action,
+	user discord.User,
+	reason string,
+) error {
+	var err error
+	switch action {
+	case antispam.ActionTimeout:
+		expiry := time.Now().Add(time.Duration(guildSettings.AntiSpamTimeoutMinutes) * time.Minute)
+		_, err = client.Rest.UpdateMember(guildID, user.ID, discord.MemberUpdate{
+			CommunicationDisabledUntil: omit.NewPtr(expiry),
+		}, rest.WithReason("User timed out due to anti-spam settings: "+reason))
+	case antispam.ActionKick:
+		err = client.Rest.RemoveMember(guildID, user.ID, rest.WithReason("User kicked due to anti-spam settings: "+reason))
+	case antispam.ActionBan:
+		err = client.Rest.AddBan(guildID, user.ID, 0, rest.WithReason("User banned due to anti-spam settings: "+reason))
+	case antispam.ActionQuarantine:
+		if guildSettings.AntiSpamQuarantineRole == 0 {
+			err = errNoQuarantineRole
+			break
+		}
+		err = client.Rest.AddMemberRole(guildID, user.ID, guildSettings.AntiSpamQuarantineRole,
+			rest.WithReason("User quarantined due to anti-spam settings: "+reason))
+	}
+
+	if guildSettings.AntiSpamInfractionEnabled {
+		createSpamInfraction(client, guildID, guildSettings.AntiSpamInfractionSeverity, user, reason)
+	}
+	return err
+}
+
+// logPunishFailure logs punishSpammer's error and returns why the action
+// wasn't taken, for moderator reports.
+func logPunishFailure(err error, guildID snowflake.ID, userID snowflake.ID, action antispam.Action) string {
+	if errors.Is(err, errNoQuarantineRole) {
+		slog.Warn("Anti-spam quarantine has no role configured; only deleting messages.", "guild", guildID, "user", userID)
+		return err.Error()
+	}
+	slog.Error("Failed to act on spammer.", "err", err, "guild", guildID, "user", userID, "action", action)
+	return "Discord rejected the request"
+}
+
+// createSpamInfraction records a silent infraction issued by the bot, so
+// repeat spammers accumulate weight the same way warned members do.
+func createSpamInfraction(client *bot.Client, guildID snowflake.ID, severity float64, user discord.User, reason string) {
+	if _, err := logBotInfraction(client, guildID, user.ID, user.Username, "Anti-spam: "+reason, severity, true); err != nil {
+		slog.Error("Failed to create anti-spam infraction.", "err", err, "guild", guildID, "user", user.ID)
+	}
+}
+
+// spamActionSummary is the first line of a moderator report about a single
+// spammer. failure is why the action wasn't taken, if it wasn't.
+func spamActionSummary(action antispam.Action, observeOnly bool, failure string, username string, messageCount int) string {
+	if observeOnly {
+		return fmt.Sprintf("**Observe only:** User %s triggered anti-spam with %d messages. "+
+			"They would have %s; nothing was done.", username, messageCount, action.Done())
+	}
+	if failure != "" {
+		return fmt.Sprintf("User %s triggered anti-spam but has **not** %s: %s. Deleted %d messages.",
+			username, action.Done(), failure, messageCount)
+	}
+	if action == antispam.ActionDelete {
+		return fmt.Sprintf("User %s has had their messages deleted for spamming. Deleted %d messages.", username, messageCount)
+	}
+	return fmt.Sprintf("User %s has %s for spamming. Deleted %d messages.", username, action.Done(), messageCount)
+}
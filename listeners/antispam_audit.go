@@ -0,0 +1,103 @@
+package listeners
+
+import (
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/antispam"
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// antiSpamEventType is the audit event for an anti-spam action.
+func antiSpamEventType(action antispam.Action, observeOnly bool) audit.EventType {
+	if observeOnly {
+		return audit.EventAntiSpamObserve
+	}
+	switch action {
+	case antispam.ActionDelete:
+		return audit.EventAntiSpamDelete
+	case antispam.ActionKick:
+		return audit.EventAntiSpamKick
+	case antispam.ActionBan:
+		return audit.EventAntiSpamBan
+	case antispam.ActionQuarantine:
+		return audit.EventAntiSpamQuarantine
+	}
+	return audit.EventAntiSpamTimeout
+}
+
+// logSpamAction records what anti-spam did to user, with a snapshot of
+// the messages that triggered it, so false positives can be reviewed
+// after the moderator report is gone. Observe-only runs are logged too.
+// failure is why the action wasn't taken, if it wasn't; the entry then
+// says so rather than claiming the action.
+func logSpamAction(
+	client *bot.Client,
+	guildID snowflake.ID,
+	guildSettings *model.GuildSettings,
+	action antispam.Action,
+	failure string,
+	user discord.User,
+	rules []string,
+	msgs []*antispam.Message,
+	coordinated bool,
+) {
+	botID := client.ID()
+	targetID := user.ID
+	audit.Log(audit.Entry{
+		GuildID:    guildID,
+		EventType:  antiSpamEventType(action, guildSettings.AntiSpamObserveOnly),
+		ActorID:    &botID,
+		ActorKind:  audit.ActorBot,
+		TargetID:   &targetID,
+		TargetKind: audit.TargetUser,
+		Source:     audit.SourceGateway,
+		Details:    spamActionDetails(guildSettings, action, failure, user, rules, msgs, coordinated),
+	})
+}
+
+func spamActionDetails(
+	guildSettings *model.GuildSettings,
+	action antispam.Action,
+	failure string,
+	user discord.User,
+	rules []string,
+	msgs []*antispam.Message,
+	coordinated bool,
+) map[string]any {
+	messages := make([]map[string]any, 0, len(msgs))
+	for _, m := range msgs {
+		snapshot := map[string]any{
+			"channel_id": m.ChannelID.String(),
+			"message_id": m.MessageID.String(),
+			"content":    m.Content,
+		}
+		if len(m.Attachments) > 0 {
+			snapshot["attachments"] = m.Attachments
+		}
+		messages = append(messages, snapshot)
+	}
+
+	details := map[string]any{
+		"action":          string(action),
+		"observe_only":    guildSettings.AntiSpamObserveOnly,
+		"rules":           rules,
+		"coordinated":     coordinated,
+		"messages":        messages,
+		"target_username": user.Username,
+	}
+	// The latest message's channel, so the viewer's channel filter finds
+	// the entry.
+	if len(msgs) > 0 {
+		details["channel_id"] = msgs[len(msgs)-1].ChannelID.String()
+	}
+	if failure != "" {
+		details["failed"] = true
+		details["error"] = failure
+	} else if action == antispam.ActionTimeout {
+		details["timeout_minutes"] = guildSettings.AntiSpamTimeoutMinutes
+	}
+	return details
+}
@@ -0,0 +1,204 @@
+package listeners
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/antispam"
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// maxCoordinatedWindow is the longest AntiSpamCoordinatedWindowSeconds the
+// dashboard accepts. Campaigns quiet for longer are forgotten by
+// RemoveExpiredMessagesInTTLCache.
+const maxCoordinatedWindow = 10 * time.Minute
+
+// maxReportedAuthors caps the accounts listed in a coordinated spam report
+// so it stays under Discord's message length limit.
+const maxReportedAuthors = 20
+
+// maxMessageContent is Discord's limit on plain message content, in runes.
+const maxMessageContent = 2000
+
+// maxBulkDelete is the most messages Discord deletes in one bulk request.
+const maxBulkDelete = 100
+
+var coordinatedSpam = antispam.NewCoordinatedTracker()
+
+func OnCoordinatedSpamMessageCreate(e *events.GuildMessageCreate) {
+	if e.Message.Author.Bot || e.Message.WebhookID != nil {
+		return
+	}
+
+	guildSettings, err := model.GetGuildSettings(e.GuildID)
+	if err != nil {
+		slog.Warn("Failed to get guild settings.", "err", err, "guild_id", e.GuildID)
+		return
+	}
+	if !guildSettings.AntiSpamEnabled || !guildSettings.AntiSpamCoordinatedEnabled {
+		return
+	}
+	guildSettings = antispamSettingsFor(e.Client(), guildSettings, e.Message)
+	if guildSettings == nil {
+		return
+	}
+
+	window := time.Duration(guildSettings.AntiSpamCoordinatedWindowSeconds) * time.Second
+	wave := coordinatedSpam.Add(e.GuildID, antispam.Post{
+		AuthorID:   e.Message.Author.ID,
+		AuthorName: e.Message.Author.Username,
+		Message:    antispam.NewMessage(e.Message),
+	}, time.Now(), window, guildSettings.AntiSpamCoordinatedAuthors)
+	if wave == nil {
+		return
+	}
+
+	actOnCoordinatedSpam(e.Client(), e.GuildID, guildSettings, wave)
+}
+
+// coordinatedSpamAction is the action taken against each author of a
+// coordinated campaign.
+func coordinatedSpamAction(guildSettings *model.GuildSettings) antispam.Action {
+	if guildSettings.AntiSpamCoordinatedBan {
+		return antispam.ActionBan
+	}
+	return antispam.ParseAction(guildSettings.AntiSpamAction)
+}
+
+// actOnCoordinatedSpam applies the coordinated spam action to the wave's
+// new authors, deletes its messages and sends or updates the campaign's
+// moderator report. In observe-only mode it only reports.
+func actOnCoordinatedSpam(client *bot.Client, guildID snowflake.ID, guildSettings *model.GuildSettings, wave *antispam.Wave) {
+	action := coordinatedSpamAction(guildSettings)
+	if !guildSettings.AntiSpamObserveOnly {
+		for _, p := range wave.NewAuthors {
+			err := punishSpammer(client, guildID, guildSettings, action,
+				discord.User{ID: p.AuthorID, Username: p.AuthorName}, "coordinated spam")
+			if err != nil {
+				coordinatedSpam.SetFailed(wave, p.AuthorID, logPunishFailure(err, guildID, p.AuthorID, action))
+			}
+		}
+
+		deleteSpamMessages(client, guildID, wave.Messages)
+	}
+	for _, p := range wave.NewAuthors {
+		logSpamAction(client, guildID, guildSettings, action, wave.Failed[p.AuthorID],
+			discord.User{ID: p.AuthorID, Username: p.AuthorName},
+			[]string{"coordinated spam"}, authorMessages(wave.Messages, p.AuthorID), true)
+	}
+
+	if guildSettings.ModeratorChannel == 0 {
+		return
+	}
+
+	report := coordinatedSpamReport(wave, guildSettings)
+	if wave.ReportMessageID != 0 {
+		_, err := client.Rest.UpdateMessage(wave.ReportChannelID, wave.ReportMessageID,
+			discord.NewMessageUpdate().WithContent(report).WithAllowedMentions(&discord.AllowedMentions{}))
+		if err == nil {
+			return
+		}
+		slog.Warn("Failed to update coordinated spam report; sending a new one.", "err", err, "guild", guildID)
+	}
+
+	msg, err := client.Rest.CreateMessage(guildSettings.ModeratorChannel,
+		discord.NewMessageCreate().WithContent(report).WithAllowedMentions(&discord.AllowedMentions{}))
+	if err != nil {
+		slog.Error(
+			"Failed to send coordinated spam report to moderator channel.",
+			"err", err,
+			"guild", guildID,
+			"channel", guildSettings.ModeratorChannel,
+		)
+		return
+	}
+	coordinatedSpam.SetReport(wave, msg.ChannelID, msg.ID)
+}
+
+// authorMessages returns the messages among posts written by authorID.
+func authorMessages(posts []antispam.Post, authorID snowflake.ID) []*antispam.Message {
+	var msgs []*antispam.Message
+	for _, p := range posts {
+		if p.AuthorID == authorID {
+			msgs = append(msgs, p.Message)
+		}
+	}
+	return msgs
+}
+
+// deleteSpamMessages deletes posts channel by channel, in bulk where there
+// is more than one.
+func deleteSpamMessages(client *bot.Client, guildID snowflake.ID, posts []antispam.Post) {
+	byChannel := make(map[snowflake.ID][]snowflake.ID)
+	var channels []snowflake.ID
+	for _, p := range posts {
+		if _, ok := byChannel[p.Message.ChannelID]; !ok {
+			channels = append(channels, p.Message.ChannelID)
+		}
+		byChannel[p.Message.ChannelID] = append(byChannel[p.Message.ChannelID], p.Message.MessageID)
+	}
+
+	opt := rest.WithReason("Message deleted due to anti-spam settings.")
+	for _, channelID := range channels {
+		ids := byChannel[channelID]
+		for len(ids) > 0 {
+			batch := ids[:min(len(ids), maxBulkDelete)]
+			ids = ids[len(batch):]
+
+			var err error
+			if len(batch) == 1 {
+				err = client.Rest.DeleteMessage(channelID, batch[0], opt)
+			} else {
+				err = client.Rest.BulkDeleteMessages(channelID, batch, opt)
+			}
+			if err != nil {
+				slog.Error("Failed to delete spam messages.", "err", err, "guild", guildID, "channel", channelID, "count", len(batch))
+			}
+		}
+	}
+}
+
+func coordinatedSpamReport(wave *antispam.Wave, guildSettings *model.GuildSettings) string {
+	action := coordinatedSpamAction(guildSettings)
+	var outcome string
+	switch {
+	case guildSettings.AntiSpamObserveOnly:
+		outcome = "They would have " + action.Done() + "; nothing was done."
+	case action == antispam.ActionDelete:
+		outcome = "Their messages were deleted."
+	case len(wave.Failed) > 0:
+		outcome = fmt.Sprintf("Their messages were deleted, but %d of them have not %s.", len(wave.Failed), action.Done())
+	default:
+		outcome = "They have " + action.Done() + " and their messages were deleted."
+	}
+
+	var b strings.Builder
+	if guildSettings.AntiSpamObserveOnly {
+		b.WriteString("**Observe only:** ")
+	}
+	fmt.Fprintf(&b, "🚨 **Coordinated spam:** %d accounts posted the same message within %ds. %s\n",
+		len(wave.Authors), guildSettings.AntiSpamCoordinatedWindowSeconds, outcome)
+
+	for i, p := range wave.Authors {
+		if i == maxReportedAuthors {
+			fmt.Fprintf(&b, "-# … and %d more.\n", len(wave.Authors)-maxReportedAuthors)
+			break
+		}
+		if failure, ok := wave.Failed[p.AuthorID]; ok && !guildSettings.AntiSpamObserveOnly {
+			fmt.Fprintf(&b, "- <@%d> (%s) — not acted on: %s\n", p.AuthorID, p.AuthorName, failure)
+			continue
+		}
+		fmt.Fprintf(&b, "- <@%d> (%s)\n", p.AuthorID, p.AuthorName)
+	}
+
+	fmt.Fprintf(&b, ">>> %s", truncateContent(wave.Sample, maxPerMessageContent))
+	return truncateContent(b.String(), maxMessageContent)
+}
@@ -0,0 +1,55 @@
+package listeners
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// antispamSettingsFor returns the anti-spam settings that apply to the
+// message: the guild's, with any override for its channel applied. It
+// returns nil if the channel or one of the author's roles is exempt.
+func antispamSettingsFor(client *bot.Client, guildSettings *model.GuildSettings, message discord.Message) *model.GuildSettings {
+	guildID := guildSettings.GuildID
+
+	if message.Member != nil && len(message.Member.RoleIDs) > 0 {
+		exemptRoles, err := model.GetAntiSpamExemptRoles(guildID)
+		if err != nil {
+			slog.Warn("Failed to get anti-spam exempt roles.", "err", err, "guild_id", guildID)
+		}
+		for _, roleID := range message.Member.RoleIDs {
+			if slices.Contains(exemptRoles, roleID) {
+				return nil
+			}
+		}
+	}
+
+	override, err := model.GetAntiSpamChannelOverride(guildID, overrideChannel(client, message.ChannelID))
+	if err != nil {
+		slog.Warn("Failed to get anti-spam channel override.", "err", err, "guild_id", guildID)
+		return guildSettings
+	}
+	if override == nil {
+		return guildSettings
+	}
+	if override.Exempt {
+		return nil
+	}
+	return override.Apply(guildSettings)
+}
+
+// overrideChannel is the channel whose override applies to messages in
+// channelID. Threads follow their parent channel.
+func overrideChannel(client *bot.Client, channelID snowflake.ID) snowflake.ID {
+	if thread, ok := client.Caches.GuildThread(channelID); ok {
+		if parentID := thread.ParentID(); parentID != nil {
+			return *parentID
+		}
+	}
+	return channelID
+}
@@ -3,51 +3,36 @@ package listeners
 import (
 	"fmt"
 	"log/slog"
-	"math"
+	"slices"
 	"strings"
 	"time"
 	"unicode/utf8"
 
-	"github.com/agnivade/levenshtein"
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/events"
 	"github.com/disgoorg/disgo/rest"
-	"github.com/disgoorg/omit"
-	"github.com/disgoorg/snowflake/v2"
 	"github.com/jellydator/ttlcache/v3"
 
+	"github.com/NLLCommunity/heimdallr/antispam"
 	"github.com/NLLCommunity/heimdallr/model"
 )
 
-const minMessageLength = 10
-const maxLevenshteinDistancePercent = 5
 const maxMessages = 20
 
-var whitespaceReplacer = strings.NewReplacer(
-	" ", "",
-	"\u00A0", "",
-	"\u202F", "",
-	"\t", "",
-	"\u200b", "",
-)
-
 var userMessages = ttlcache.New[string, userMessagesInfo](
 	ttlcache.WithTTL[string, userMessagesInfo](60 * time.Second),
 )
 
 func RemoveExpiredMessagesInTTLCache() {
 	userMessages.DeleteExpired()
+	coordinatedSpam.DeleteExpired(time.Now(), maxCoordinatedWindow)
 }
 
 type userMessagesInfo struct {
 	Score    int
-	Messages []*messageDetails
-}
-
-type messageDetails struct {
-	Content   string
-	ChannelID snowflake.ID
-	MessageID snowflake.ID
+	Messages []*antispam.Message
+	// Rules names the rules that added to Score, for the moderator report.
+	Rules []string
 }
 
 func OnAntispamMessageCreate(e *events.GuildMessageCreate) {
@@ -58,67 +43,58 @@ func OnAntispamMessageCreate(e *events.GuildMessageCreate) {
 		return
 	}
 
-	cooldown := time.Duration(guildSettings.AntiSpamCooldownSeconds) * time.Second
-
 	if !guildSettings.AntiSpamEnabled {
 		return
 	}
-
-	if !userMessages.Has(uHash) {
-		createMessageInfoForUser(uHash, e.Message, cooldown)
+	guildSettings = antispamSettingsFor(e.Client(), guildSettings, e.Message)
+	if guildSettings == nil {
 		return
 	}
 
-	messagesInfo := userMessages.Get(uHash)
-	if messagesInfo == nil {
-		slog.Warn("Failed to get messages info for user.", "guild", e.GuildID, "user", e.Message.Author.ID)
-		return
-	}
+	cooldown := time.Duration(guildSettings.AntiSpamCooldownSeconds) * time.Second
 
-	info := messagesInfo.Value()
+	var info userMessagesInfo
+	if item := userMessages.Get(uHash); item != nil {
+		info = item.Value()
+	}
 
 	if len(info.Messages) >= maxMessages {
 		info.Messages = info.Messages[1:]
 	}
 
-	messageDetails := createMessageDetails(e.Message)
+	message := antispam.NewMessage(e.Message)
 
-	// Check if this message is similar to any previous message in the user's
-	// recent buffer (same channel or different — the cross-channel guard was
-	// removed deliberately in 5b2170f to broaden detection).
-	matchesPreviousMessage := compareToPreviousMessages(messageDetails, info)
-	if matchesPreviousMessage {
-		info.Score++
+	// Every rule sees the message against the user's recent buffer (same
+	// channel or different — the cross-channel guard was removed
+	// deliberately in 5b2170f to broaden detection).
+	for _, hit := range antispam.Evaluate(message, info.Messages, guildSettings) {
+		info.Score += hit.Points
+		if !slices.Contains(info.Rules, hit.Rule) {
+			info.Rules = append(info.Rules, hit.Rule)
+		}
 	}
 
-	info.Messages = append(info.Messages, messageDetails)
+	info.Messages = append(info.Messages, message)
 
 	if info.Score >= guildSettings.AntiSpamCount {
-		timeoutUser(e, guildSettings, info)
+		actOnSpammer(e, guildSettings, info)
 		info.Score = 0
+		info.Rules = nil
 	}
 
 	userMessages.Set(uHash, info, cooldown)
 }
 
-func timeoutUser(e *events.GuildMessageCreate, guildSettings *model.GuildSettings, info userMessagesInfo) {
-	userID := e.Message.Author.ID
+// actOnSpammer applies the guild's anti-spam action to the author, deletes
+// their messages from within the cooldown and reports to the moderator
+// channel. The messages are deleted even if the action fails, and the
+// report says that it did. In observe-only mode it only reports.
+func actOnSpammer(e *events.GuildMessageCreate, guildSettings *model.GuildSettings, info userMessagesInfo) {
 	cooldown := time.Duration(guildSettings.AntiSpamCooldownSeconds) * time.Second
 	cutoffTime := time.Now().Add(-cooldown)
+	action := antispam.ParseAction(guildSettings.AntiSpamAction)
 
-	expiry := time.Now().Add(time.Duration(guildSettings.AntiSpamTimeoutMinutes) * time.Minute)
-	_, err := e.Client().Rest.UpdateMember(
-		e.GuildID, userID, discord.MemberUpdate{
-			CommunicationDisabledUntil: omit.NewPtr(expiry),
-		}, rest.WithReason("User timed out due to anti-spam settings."),
-	)
-
-	if err != nil {
-		slog.Error("Failed to timeout user.", "err", err, "guild", e.GuildID, "user", userID)
-		return
-	}
-
-	var removableMessages []*messageDetails
+	var removableMessages []*antispam.Message
 	for _, m := range info.Messages {
 		if m.MessageID.Time().Before(cutoffTime) {
 			continue
@@ -127,29 +103,40 @@ func timeoutUser(e *events.GuildMessageCreate, guildSettings *model.GuildSetting
 		removableMessages = append(removableMessages, m)
 	}
 
-	for _, m := range removableMessages {
-		err := e.Client().Rest.DeleteMessage(m.ChannelID, m.MessageID, rest.WithReason("Message deleted due to anti-spam settings."))
+	var failure string
+	if !guildSettings.AntiSpamObserveOnly {
+		err := punishSpammer(e.Client(), e.GuildID, guildSettings, action, e.Message.Author, strings.Join(info.Rules, ", "))
 		if err != nil {
-			slog.Error(
-				"Failed to delete message.", "err", err, "guild", e.GuildID, "channel", m.ChannelID, "message",
-				m.MessageID,
-			)
+			failure = logPunishFailure(err, e.GuildID, e.Message.Author.ID, action)
+		}
+
+		for _, m := range removableMessages {
+			err := e.Client().Rest.DeleteMessage(m.ChannelID, m.MessageID, rest.WithReason("Message deleted due to anti-spam settings."))
+			if err != nil {
+				slog.Error(
+					"Failed to delete message.", "err", err, "guild", e.GuildID, "channel", m.ChannelID, "message",
+					m.MessageID,
+				)
+			}
 		}
 	}
 
+	logSpamAction(e.Client(), e.GuildID, guildSettings, action, failure, e.Message.Author, info.Rules, removableMessages, false)
+
 	if guildSettings.ModeratorChannel == 0 {
 		return
 	}
 
-	timeoutMessage := createTimeoutMessage(e, removableMessages, len(removableMessages))
+	summary := spamActionSummary(action, guildSettings.AntiSpamObserveOnly, failure, e.Message.Author.Username, len(removableMessages))
+	report := createSpamReport(summary, info.Rules, removableMessages)
 
-	_, err = e.Client().Rest.CreateMessage(
-		guildSettings.ModeratorChannel, timeoutMessage.WithAllowedMentions(&discord.AllowedMentions{}),
+	_, err := e.Client().Rest.CreateMessage(
+		guildSettings.ModeratorChannel, report.WithAllowedMentions(&discord.AllowedMentions{}),
 	)
 
 	if err != nil {
 		slog.Error(
-			"Failed to send timeout message to moderator channel.",
+			"Failed to send anti-spam report to moderator channel.",
 			"err", err,
 			"guild", e.GuildID,
 			"channel", guildSettings.ModeratorChannel,
@@ -184,8 +171,10 @@ func truncateContent(s string, maxRunes int) string {
 	return string(runes[:maxRunes-markerLen]) + truncationMarker
 }
 
-func createTimeoutMessage(e *events.GuildMessageCreate, msgs []*messageDetails, deletedCount int) discord.MessageCreate {
-	summary := fmt.Sprintf("User %s has been timed out for spamming. Deleted %d messages.", e.Message.Author.Username, deletedCount)
+func createSpamReport(summary string, rules []string, msgs []*antispam.Message) discord.MessageCreate {
+	if len(rules) > 0 {
+		summary += "\n-# Triggered by: " + strings.Join(rules, ", ")
+	}
 
 	components := []discord.LayoutComponent{discord.NewTextDisplay(summary)}
 	totalComponents := 1
@@ -195,7 +184,7 @@ func createTimeoutMessage(e *events.GuildMessageCreate, msgs []*messageDetails,
 	omitted := 0
 
 	for i, m := range msgs {
-		content := truncateContent(m.Content, maxPerMessageContent)
+		content := truncateContent(m.Summary(), maxPerMessageContent)
 		contentText := fmt.Sprintf(">>> %s", content)
 		channelText := fmt.Sprintf("-# Channel: <#%d>", m.ChannelID)
 
@@ -232,74 +221,3 @@ func createTimeoutMessage(e *events.GuildMessageCreate, msgs []*messageDetails,
 
 	return discord.NewMessageCreateV2(components...)
 }
-
-func compareToPreviousMessages(details *messageDetails, info userMessagesInfo) bool {
-	if len(info.Messages) == 0 {
-		return false
-	}
-
-	slog.Debug("Comparing message to previous messages.", "current_message", details.Content, "previous_messages_count", len(info.Messages))
-
-	// Remove all whitespace from the message content
-	currentMessage := whitespaceReplacer.Replace(details.Content)
-
-	for _, mInfo := range info.Messages {
-		if utf8.RuneCountInString(currentMessage) < minMessageLength {
-			return false
-		}
-
-		prevMessage := whitespaceReplacer.Replace(mInfo.Content)
-
-		distance := levenshtein.ComputeDistance(currentMessage, prevMessage)
-		messageLength := float64(utf8.RuneCountInString(currentMessage))
-		maxLevenshteinDistance := int(math.Ceil(messageLength * maxLevenshteinDistancePercent / 100))
-		if distance <= maxLevenshteinDistance {
-			// Return true if these are similar messages
-			slog.Info("Found similar message.", "current_message", details.Content, "previous_message", mInfo.Content, "distance", distance)
-			return true
-		} else {
-			slog.Debug("Messages are not similar enough.", "current_message", details.Content, "previous_message", mInfo.Content, "distance", distance)
-		}
-	}
-	return false
-}
-
-func createMessageInfoForUser(uHash string, m discord.Message, ttl time.Duration) {
-	userMessages.Set(
-		uHash,
-		userMessagesInfo{
-			Messages: []*messageDetails{createMessageDetails(m)},
-		},
-		ttl,
-	)
-}
-
-func createMessageDetails(m discord.Message) *messageDetails {
-	return &messageDetails{
-		Content:   messageWithAttachmentInfo(m),
-		ChannelID: m.ChannelID,
-		MessageID: m.ID,
-	}
-}
-
-func messageWithAttachmentInfo(m discord.Message) string {
-	atts := make([]string, len(m.Attachments))
-	for i, att := range m.Attachments {
-		width := 0
-		height := 0
-		if att.Width != nil {
-			width = *att.Width
-		}
-		if att.Height != nil {
-			height = *att.Height
-		}
-
-		atts[i] = fmt.Sprintf("%s %dx%d %d", att.Filename, width, height, att.Size)
-	}
-
-	if len(atts) == 0 {
-		return m.Content
-	}
-
-	return fmt.Sprintf("%s\nAttachments:\n%s", m.Content, strings.Join(atts, "\n"))
-}
@@ -5,7 +5,13 @@ import (
 	"testing"
 	"unicode/utf8"
 
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/NLLCommunity/heimdallr/antispam"
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
 )
 
 func TestTruncateContent_AsciiUnderLimit(t *testing.T) {
@@ -43,3 +49,101 @@ func TestTruncateContent_MaxRunesSmallerThanMarker(t *testing.T) {
 	assert.LessOrEqual(t, utf8.RuneCountInString(got), 0, "result must not exceed maxRunes")
 	assert.True(t, utf8.ValidString(got), "result must be valid UTF-8")
 }
+
+func TestCoordinatedSpamReport(t *testing.T) {
+	wave := &antispam.Wave{
+		Sample: "free nitro",
+		Authors: []antispam.Post{
+			{AuthorID: 1, AuthorName: "alice"},
+			{AuthorID: 2, AuthorName: "bob"},
+		},
+	}
+	settings := &model.GuildSettings{AntiSpamCoordinatedWindowSeconds: 60}
+
+	report := coordinatedSpamReport(wave, settings)
+	assert.Contains(t, report, "2 accounts posted the same message within 60s")
+	assert.Contains(t, report, "timed out")
+	assert.Contains(t, report, "- <@1> (alice)\n- <@2> (bob)\n")
+	assert.True(t, strings.HasSuffix(report, ">>> free nitro"))
+
+	settings.AntiSpamCoordinatedBan = true
+	assert.Contains(t, coordinatedSpamReport(wave, settings), "banned")
+
+	wave.Failed = map[snowflake.ID]string{2: "Discord rejected the request"}
+	report = coordinatedSpamReport(wave, settings)
+	assert.Contains(t, report, "Their messages were deleted, but 1 of them have not been banned.")
+	assert.Contains(t, report, "- <@1> (alice)\n- <@2> (bob) — not acted on: Discord rejected the request\n")
+}
+
+func TestCoordinatedSpamReport_ManyAuthors(t *testing.T) {
+	wave := &antispam.Wave{Sample: strings.Repeat("spam ", 500)}
+	for i := range 100 {
+		wave.Authors = append(wave.Authors, antispam.Post{
+			AuthorID:   snowflake.ID(175928847299117063 + i),
+			AuthorName: strings.Repeat("x", 32),
+		})
+	}
+
+	report := coordinatedSpamReport(wave, &model.GuildSettings{})
+	assert.Contains(t, report, "… and 80 more.")
+	assert.LessOrEqual(t, utf8.RuneCountInString(report), maxMessageContent)
+}
+
+func TestCoordinatedSpamReport_ObserveOnly(t *testing.T) {
+	wave := &antispam.Wave{Sample: "free nitro", Authors: []antispam.Post{{AuthorID: 1, AuthorName: "alice"}}}
+	settings := &model.GuildSettings{AntiSpamObserveOnly: true, AntiSpamAction: string(antispam.ActionKick)}
+
+	report := coordinatedSpamReport(wave, settings)
+	assert.True(t, strings.HasPrefix(report, "**Observe only:**"))
+	assert.Contains(t, report, "They would have been kicked; nothing was done.")
+}
+
+func TestSpamActionSummary(t *testing.T) {
+	assert.Equal(t, "User spammer has been banned for spamming. Deleted 4 messages.",
+		spamActionSummary(antispam.ActionBan, false, "", "spammer", 4))
+	assert.Equal(t, "User spammer has had their messages deleted for spamming. Deleted 4 messages.",
+		spamActionSummary(antispam.ActionDelete, false, "", "spammer", 4))
+	assert.Equal(t, "User spammer triggered anti-spam but has **not** been quarantined: "+
+		"no quarantine role is configured. Deleted 4 messages.",
+		spamActionSummary(antispam.ActionQuarantine, false, errNoQuarantineRole.Error(), "spammer", 4))
+
+	observed := spamActionSummary(antispam.ActionQuarantine, true, "", "spammer", 4)
+	assert.True(t, strings.HasPrefix(observed, "**Observe only:**"))
+	assert.Contains(t, observed, "would have been quarantined; nothing was done")
+}
+
+func TestAntiSpamEventType(t *testing.T) {
+	assert.Equal(t, audit.EventAntiSpamTimeout, antiSpamEventType(antispam.ActionTimeout, false))
+	assert.Equal(t, audit.EventAntiSpamDelete, antiSpamEventType(antispam.ActionDelete, false))
+	assert.Equal(t, audit.EventAntiSpamQuarantine, antiSpamEventType(antispam.ActionQuarantine, false))
+	assert.Equal(t, audit.EventAntiSpamObserve, antiSpamEventType(antispam.ActionBan, true))
+}
+
+func TestSpamActionDetails(t *testing.T) {
+	settings := &model.GuildSettings{AntiSpamTimeoutMinutes: 30}
+	msgs := []*antispam.Message{
+		{MessageID: 10, ChannelID: 1, Content: "spam"},
+		{MessageID: 11, ChannelID: 2, Content: "spam", Attachments: []string{"a.png 1x1 10"}},
+	}
+	d := spamActionDetails(settings, antispam.ActionTimeout, "", discord.User{ID: 5, Username: "spammer"},
+		[]string{"repeated messages"}, msgs, false)
+
+	assert.Equal(t, "timeout", d["action"])
+	assert.NotContains(t, d, "failed")
+	assert.Equal(t, 30, d["timeout_minutes"])
+	assert.Equal(t, "2", d["channel_id"], "the latest message's channel")
+	assert.Equal(t, []string{"repeated messages"}, d["rules"])
+	messages := d["messages"].([]map[string]any)
+	assert.Len(t, messages, 2)
+	assert.Equal(t, map[string]any{"channel_id": "1", "message_id": "10", "content": "spam"}, messages[0])
+	assert.Equal(t, []string{"a.png 1x1 10"}, messages[1]["attachments"])
+
+	d = spamActionDetails(settings, antispam.ActionBan, "", discord.User{ID: 5}, nil, nil, true)
+	assert.NotContains(t, d, "timeout_minutes")
+	assert.NotContains(t, d, "channel_id")
+
+	d = spamActionDetails(settings, antispam.ActionTimeout, "Discord rejected the request", discord.User{ID: 5}, nil, msgs, false)
+	assert.Equal(t, true, d["failed"])
+	assert.Equal(t, "Discord rejected the request", d["error"])
+	assert.NotContains(t, d, "timeout_minutes", "a failed timeout has no duration")
+}
@@ -0,0 +1,452 @@
+package listeners
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// disgo doesn't cache AutoMod rules, and the gateway's rule update carries
+// only the new rule. The bot keeps its own copy of every guild's rules,
+// seeded when the guild becomes available, so updates can be diffed and
+// executions (which name only the rule ID) can name the rule. Fetching
+// the rules needs Manage Server; without it the copy fills in as rules are
+// created or updated, and updates to rules it hasn't seen are recorded
+// without a diff.
+
+var autoModRules sync.Map // map[snowflake.ID]*guildAutoModRules
+
+type guildAutoModRules struct {
+	mu    sync.Mutex
+	rules map[snowflake.ID]discord.AutoModerationRule
+}
+
+func guildAutoMod(guildID snowflake.ID) *guildAutoModRules {
+	s, _ := autoModRules.LoadOrStore(guildID, &guildAutoModRules{rules: map[snowflake.ID]discord.AutoModerationRule{}})
+	return s.(*guildAutoModRules)
+}
+
+// swap stores rule and returns the copy it replaces, if any.
+func (g *guildAutoModRules) swap(rule discord.AutoModerationRule) (discord.AutoModerationRule, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	old, ok := g.rules[rule.ID]
+	g.rules[rule.ID] = rule
+	return old, ok
+}
+
+func (g *guildAutoModRules) get(id snowflake.ID) (discord.AutoModerationRule, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	rule, ok := g.rules[id]
+	return rule, ok
+}
+
+func (g *guildAutoModRules) remove(id snowflake.ID) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.rules, id)
+}
+
+// OnAutoModGuildReady seeds the AutoMod rule copy for guilds present at
+// startup.
+func OnAutoModGuildReady(e *events.GuildReady) {
+	refreshAutoModRules(e.Client(), e.GuildID)
+}
+
+// OnAutoModGuildJoin seeds the AutoMod rule copy when the bot is added to
+// a guild.
+func OnAutoModGuildJoin(e *events.GuildJoin) {
+	refreshAutoModRules(e.Client(), e.GuildID)
+}
+
+func refreshAutoModRules(client *bot.Client, guildID snowflake.ID) {
+	rules, err := client.Rest.GetAutoModerationRules(guildID)
+	if err != nil {
+		slog.Debug("Failed to fetch AutoMod rules.", "err", err, "guild", guildID)
+		return
+	}
+	g := guildAutoMod(guildID)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.rules = make(map[snowflake.ID]discord.AutoModerationRule, len(rules))
+	for _, r := range rules {
+		g.rules[r.ID] = r
+	}
+}
+
+// OnAuditAutoModRuleCreate records an automod_rule.create entry with the
+// rule's full configuration.
+func OnAuditAutoModRuleCreate(e *events.AutoModerationRuleCreate) {
+	guildAutoMod(e.GuildID).swap(e.AutoModerationRule)
+	logAutoModRuleEvent(e.GuildID, audit.EventAutoModRuleCreate, e.ID, autoModRuleDetails(e.Client(), e.AutoModerationRule))
+}
+
+// OnAuditAutoModRuleDelete records an automod_rule.delete entry. The
+// gateway sends the deleted rule, so its configuration is kept.
+func OnAuditAutoModRuleDelete(e *events.AutoModerationRuleDelete) {
+	guildAutoMod(e.GuildID).remove(e.ID)
+	logAutoModRuleEvent(e.GuildID, audit.EventAutoModRuleDelete, e.ID, autoModRuleDetails(e.Client(), e.AutoModerationRule))
+}
+
+// OnAuditAutoModRuleUpdate records what changed in an AutoMod rule. A
+// rule the bot had no copy of is recorded without a diff rather than
+// skipped, since AutoMod edits are rare and worth knowing about.
+func OnAuditAutoModRuleUpdate(e *events.AutoModerationRuleUpdate) {
+	old, ok := guildAutoMod(e.GuildID).swap(e.AutoModerationRule)
+	details := map[string]any{"automod_rule_name": e.Name}
+	if ok {
+		if !autoModRuleDiff(e.Client(), e.GuildID, old, e.AutoModerationRule, details) {
+			return
+		}
+	}
+	logAutoModRuleEvent(e.GuildID, audit.EventAutoModRuleUpdate, e.ID, details)
+}
+
+func logAutoModRuleEvent(guildID snowflake.ID, eventType audit.EventType, ruleID snowflake.ID, details map[string]any) {
+	target := ruleID
+	audit.LogPending(audit.Entry{
+		GuildID:    guildID,
+		EventType:  eventType,
+		ActorKind:  audit.ActorUnknown, // overwritten by enrichment when available
+		TargetID:   &target,
+		TargetKind: audit.TargetAutoModRule,
+		Source:     audit.SourceGateway,
+		Details:    details,
+	}, []audit.EnrichField{audit.EnrichActor, audit.EnrichReason})
+}
+
+// autoModRuleDetails records a rule's whole configuration, for create and
+// delete entries.
+func autoModRuleDetails(client *bot.Client, rule discord.AutoModerationRule) map[string]any {
+	details := map[string]any{
+		"automod_rule_name": rule.Name,
+		"trigger":           autoModTriggerName(rule.TriggerType),
+		"event":             autoModEventName(rule.EventType),
+		"enabled":           rule.Enabled,
+	}
+	if actions := autoModActionNames(client, rule.Actions); len(actions) > 0 {
+		details["actions"] = actions
+	}
+	meta := rule.TriggerMetadata
+	for key, list := range map[string][]string{
+		"keywords":        meta.KeywordFilter,
+		"regex_patterns":  meta.RegexPatterns,
+		"allow_list":      meta.AllowList,
+		"presets":         autoModPresetNames(meta.Presets),
+		"exempt_roles":    roleNames(client, rule.GuildID, rule.ExemptRoles),
+		"exempt_channels": channelNames(client, rule.ExemptChannels),
+	} {
+		if len(list) > 0 {
+			details[key] = list
+		}
+	}
+	if meta.MentionTotalLimit > 0 {
+		details["mention_limit"] = strconv.Itoa(meta.MentionTotalLimit)
+	}
+	return details
+}
+
+// autoModRuleDiff adds the differences between two versions of a rule to
+// details: keyword, regex and allow-list entries as *_added / *_removed
+// lists, everything else as {field, before, after} changes. Reports
+// whether anything changed.
+func autoModRuleDiff(client *bot.Client, guildID snowflake.ID, old, updated discord.AutoModerationRule, details map[string]any) bool {
+	changed := false
+	for key, lists := range map[string][2][]string{
+		"keywords":       {old.TriggerMetadata.KeywordFilter, updated.TriggerMetadata.KeywordFilter},
+		"regex_patterns": {old.TriggerMetadata.RegexPatterns, updated.TriggerMetadata.RegexPatterns},
+		"allow_list":     {old.TriggerMetadata.AllowList, updated.TriggerMetadata.AllowList},
+		"presets":        {autoModPresetNames(old.TriggerMetadata.Presets), autoModPresetNames(updated.TriggerMetadata.Presets)},
+	} {
+		if added := stringsMissing(lists[1], lists[0]); len(added) > 0 {
+			details[key+"_added"] = added
+			changed = true
+		}
+		if removed := stringsMissing(lists[0], lists[1]); len(removed) > 0 {
+			details[key+"_removed"] = removed
+			changed = true
+		}
+	}
+
+	var changes []map[string]any
+	add := func(field, before, after string) {
+		if before != after {
+			changes = append(changes, map[string]any{"field": field, "before": before, "after": after})
+		}
+	}
+	add("name", old.Name, updated.Name)
+	add("enabled", onOff(old.Enabled), onOff(updated.Enabled))
+	add("event", autoModEventName(old.EventType), autoModEventName(updated.EventType))
+	add("actions", strings.Join(autoModActionNames(client, old.Actions), ", "), strings.Join(autoModActionNames(client, updated.Actions), ", "))
+	add("mention_limit", mentionLimit(old.TriggerMetadata.MentionTotalLimit), mentionLimit(updated.TriggerMetadata.MentionTotalLimit))
+	add("raid_protection", onOff(old.TriggerMetadata.MentionRaidProtectionEnabled), onOff(updated.TriggerMetadata.MentionRaidProtectionEnabled))
+	add("exempt_roles", strings.Join(roleNames(client, guildID, old.ExemptRoles), ", "), strings.Join(roleNames(client, guildID, updated.ExemptRoles), ", "))
+	add("exempt_channels", strings.Join(channelNames(client, old.ExemptChannels), ", "), strings.Join(channelNames(client, updated.ExemptChannels), ", "))
+	if len(changes) > 0 {
+		details["changes"] = changes
+		changed = true
+	}
+	return changed
+}
+
+// stringsMissing returns the entries of a that are not in b, in a's order.
+func stringsMissing(a, b []string) []string {
+	var out []string
+	for _, s := range a {
+		if !slices.Contains(b, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func mentionLimit(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func roleNames(client *bot.Client, guildID snowflake.ID, ids []snowflake.ID) []string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		names = append(names, "@"+strings.TrimPrefix(audit.ResolveRoleName(client, guildID, id), "@"))
+	}
+	return names
+}
+
+func channelNames(client *bot.Client, ids []snowflake.ID) []string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		names = append(names, "#"+audit.ResolveChannelName(client, id))
+	}
+	return names
+}
+
+func autoModTriggerName(t discord.AutoModerationTriggerType) string {
+	switch t {
+	case discord.AutoModerationTriggerTypeKeyword:
+		return "keyword"
+	case discord.AutoModerationTriggerTypeSpam:
+		return "spam"
+	case discord.AutoModerationTriggerTypeKeywordPresent:
+		return "keyword preset"
+	case discord.AutoModerationTriggerTypeMentionSpam:
+		return "mention spam"
+	case discord.AutoModerationTriggerTypeMemberProfile:
+		return "member profile"
+	}
+	return "unknown (" + strconv.Itoa(int(t)) + ")"
+}
+
+func autoModEventName(t discord.AutoModerationEventType) string {
+	switch t {
+	case discord.AutoModerationEventTypeMessageSend:
+		return "messages"
+	case discord.AutoModerationEventTypeMemberUpdate:
+		return "member profiles"
+	}
+	return "unknown (" + strconv.Itoa(int(t)) + ")"
+}
+
+func autoModPresetNames(presets []discord.AutoModerationKeywordPreset) []string {
+	names := make([]string, 0, len(presets))
+	for _, p := range presets {
+		switch p {
+		case discord.AutoModerationKeywordPresetProfanity:
+			names = append(names, "profanity")
+		case discord.AutoModerationKeywordPresetSexualContent:
+			names = append(names, "sexual content")
+		case discord.AutoModerationKeywordPresetSlurs:
+			names = append(names, "slurs")
+		default:
+			names = append(names, "preset "+strconv.Itoa(int(p)))
+		}
+	}
+	return names
+}
+
+// autoModActionNames describes a rule's actions, e.g. "block message",
+// "alert #mod-log", "timeout 10m0s".
+func autoModActionNames(client *bot.Client, actions []discord.AutoModerationAction) []string {
+	names := make([]string, 0, len(actions))
+	for _, a := range actions {
+		switch a.Type {
+		case discord.AutoModerationActionTypeBlockMessage:
+			names = append(names, "block message")
+		case discord.AutoModerationActionTypeSendAlertMessage:
+			name := "alert"
+			if a.Metadata != nil && a.Metadata.ChannelID != 0 {
+				name += " #" + audit.ResolveChannelName(client, a.Metadata.ChannelID)
+			}
+			names = append(names, name)
+		case discord.AutoModerationActionTypeTimeout:
+			name := "timeout"
+			if a.Metadata != nil && a.Metadata.DurationSeconds > 0 {
+				name += " " + (time.Duration(a.Metadata.DurationSeconds) * time.Second).String()
+			}
+			names = append(names, name)
+		case discord.AutoModerationActionTypeBlockMemberInteraction:
+			names = append(names, "block member interaction")
+		default:
+			names = append(names, "action "+strconv.Itoa(int(a.Type)))
+		}
+	}
+	return names
+}
+
+// autoModActionKey is the details.action value for each action type, as
+// the viewer's describe reads it.
+func autoModActionKey(t discord.AutoModerationActionType) string {
+	switch t {
+	case discord.AutoModerationActionTypeBlockMessage:
+		return "block_message"
+	case discord.AutoModerationActionTypeSendAlertMessage:
+		return "send_alert"
+	case discord.AutoModerationActionTypeTimeout:
+		return "timeout"
+	case discord.AutoModerationActionTypeBlockMemberInteraction:
+		return "block_interaction"
+	}
+	return strconv.Itoa(int(t))
+}
+
+// OnAuditAutoModAction records each action AutoMod takes and, if the guild
+// asks for it, records an infraction for the member.
+func OnAuditAutoModAction(e *events.AutoModerationActionExecution) {
+	client := e.Client()
+	guildID := e.GuildID
+
+	details := map[string]any{
+		"rule_id": e.RuleID.String(),
+		"trigger": autoModTriggerName(e.RuleTriggerType),
+		"action":  autoModActionKey(e.Action.Type),
+	}
+	if rule, ok := autoModRule(client, guildID, e.RuleID); ok {
+		details["rule_name"] = rule.Name
+	}
+	if e.ChannelID != nil {
+		details["channel_id"] = e.ChannelID.String()
+	}
+	if e.MessageID != nil {
+		details["message_id"] = e.MessageID.String()
+	}
+	if e.Content != "" {
+		details["content"] = e.Content
+	}
+	if e.MatchedKeywords != nil && *e.MatchedKeywords != "" {
+		details["matched_keyword"] = *e.MatchedKeywords
+	}
+	if e.MatchedContent != nil && *e.MatchedContent != "" {
+		details["matched_content"] = *e.MatchedContent
+	}
+	if m := e.Action.Metadata; m != nil {
+		if m.ChannelID != 0 {
+			details["alert_channel_id"] = m.ChannelID.String()
+		}
+		if m.DurationSeconds > 0 {
+			details["timeout_seconds"] = m.DurationSeconds
+		}
+	}
+	username := ""
+	if member, ok := client.Caches.Member(guildID, e.UserID); ok {
+		username = member.User.Username
+		details["target_username"] = username
+	}
+
+	target := e.UserID
+	audit.Log(audit.Entry{
+		GuildID:    guildID,
+		EventType:  audit.EventAutoModAction,
+		ActorKind:  audit.ActorSystem,
+		TargetID:   &target,
+		TargetKind: audit.TargetUser,
+		Source:     audit.SourceGateway,
+		Details:    details,
+	})
+
+	settings, err := model.GetGuildSettings(guildID)
+	if err != nil {
+		slog.Warn("audit: failed to read guild settings", "err", err, "guild_id", guildID)
+		return
+	}
+	if !settings.AutoModInfractionEnabled || !autoModTriggers.first(guildID, e.UserID, e.RuleID, e.Content, time.Now()) {
+		return
+	}
+	ruleName, _ := details["rule_name"].(string)
+	createAutoModInfraction(client, guildID, settings.AutoModInfractionSeverity, e.UserID, username, ruleName)
+}
+
+// autoModRule returns the rule from the bot's copy, fetching it when the
+// copy doesn't have it yet.
+func autoModRule(client *bot.Client, guildID, ruleID snowflake.ID) (discord.AutoModerationRule, bool) {
+	g := guildAutoMod(guildID)
+	if rule, ok := g.get(ruleID); ok {
+		return rule, true
+	}
+	rule, err := client.Rest.GetAutoModerationRule(guildID, ruleID)
+	if err != nil {
+		slog.Debug("Failed to fetch AutoMod rule.", "err", err, "guild", guildID, "rule", ruleID)
+		return discord.AutoModerationRule{}, false
+	}
+	g.swap(*rule)
+	return *rule, true
+}
+
+// autoModTriggerWindow is how long executions with the same member, rule
+// and content are taken to be one trigger. Discord sends one execution
+// per action of the rule, all at once.
+const autoModTriggerWindow = 10 * time.Second
+
+// autoModTriggerSet remembers recent triggers so a rule with several
+// actions escalates to a single infraction.
+type autoModTriggerSet struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+var autoModTriggers = &autoModTriggerSet{seen: map[string]time.Time{}}
+
+// first reports whether this is the first execution of a trigger, and
+// forgets triggers older than autoModTriggerWindow as it goes; executions
+// are rare enough that a sweep per call costs nothing.
+func (s *autoModTriggerSet) first(guildID, userID, ruleID snowflake.ID, content string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, at := range s.seen {
+		if now.Sub(at) > autoModTriggerWindow {
+			delete(s.seen, k)
+		}
+	}
+	key := fmt.Sprintf("%d:%d:%d:%s", guildID, userID, ruleID, content)
+	if _, ok := s.seen[key]; ok {
+		return false
+	}
+	s.seen[key] = now
+	return true
+}
+
+// createAutoModInfraction records a silent infraction issued by the bot
+// for an AutoMod trigger, the same way the anti-spam does for spammers.
+func createAutoModInfraction(client *bot.Client, guildID snowflake.ID, severity float64, userID snowflake.ID, username, ruleName string) {
+	reason := "AutoMod"
+	if ruleName != "" {
+		reason += ": " + ruleName
+	}
+	if _, err := logBotInfraction(client, guildID, userID, username, reason, severity, true); err != nil {
+		slog.Error("Failed to create AutoMod infraction.", "err", err, "guild", guildID, "user", userID)
+	}
+}
@@ -0,0 +1,85 @@
+package listeners
+
+import (
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/cache"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoModRuleDiff(t *testing.T) {
+	client := &bot.Client{Caches: cache.New(cache.WithCaches(cache.FlagsAll))}
+	old := discord.AutoModerationRule{
+		ID:          1,
+		Name:        "Slurs",
+		EventType:   discord.AutoModerationEventTypeMessageSend,
+		TriggerType: discord.AutoModerationTriggerTypeKeyword,
+		TriggerMetadata: discord.AutoModerationTriggerMetadata{
+			KeywordFilter: []string{"foo", "bar"},
+		},
+		Actions: []discord.AutoModerationAction{{Type: discord.AutoModerationActionTypeBlockMessage}},
+		Enabled: true,
+	}
+
+	details := map[string]any{}
+	assert.False(t, autoModRuleDiff(client, 0, old, old, details))
+	assert.Empty(t, details)
+
+	updated := old
+	updated.TriggerMetadata.KeywordFilter = []string{"bar", "baz"}
+	updated.Enabled = false
+	updated.Actions = append(updated.Actions, discord.AutoModerationAction{
+		Type:     discord.AutoModerationActionTypeTimeout,
+		Metadata: &discord.AutoModerationActionMetadata{DurationSeconds: 600},
+	})
+	assert.True(t, autoModRuleDiff(client, 0, old, updated, details))
+	assert.Equal(t, []string{"baz"}, details["keywords_added"])
+	assert.Equal(t, []string{"foo"}, details["keywords_removed"])
+	assert.Equal(t, []map[string]any{
+		{"field": "enabled", "before": "On", "after": "Off"},
+		{"field": "actions", "before": "block message", "after": "block message, timeout 10m0s"},
+	}, details["changes"])
+}
+
+func TestAutoModRuleDetails(t *testing.T) {
+	client := &bot.Client{Caches: cache.New(cache.WithCaches(cache.FlagsAll))}
+	details := autoModRuleDetails(client, discord.AutoModerationRule{
+		Name:        "Mentions",
+		EventType:   discord.AutoModerationEventTypeMessageSend,
+		TriggerType: discord.AutoModerationTriggerTypeMentionSpam,
+		TriggerMetadata: discord.AutoModerationTriggerMetadata{
+			MentionTotalLimit: 20,
+		},
+		Actions: []discord.AutoModerationAction{{
+			Type:     discord.AutoModerationActionTypeSendAlertMessage,
+			Metadata: &discord.AutoModerationActionMetadata{ChannelID: 5},
+		}},
+		Enabled: true,
+	})
+	assert.Equal(t, map[string]any{
+		"automod_rule_name": "Mentions",
+		"trigger":           "mention spam",
+		"event":             "messages",
+		"enabled":           true,
+		"actions":           []string{"alert #channel:5"},
+		"mention_limit":     "20",
+	}, details)
+}
+
+// A rule with several actions produces one execution per action, all for
+// the same trigger; only the first may escalate to an infraction.
+func TestAutoModTriggerSet(t *testing.T) {
+	s := &autoModTriggerSet{seen: map[string]time.Time{}}
+	now := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	guild, user, rule := snowflake.ID(1), snowflake.ID(2), snowflake.ID(3)
+
+	assert.True(t, s.first(guild, user, rule, "bad word", now))
+	assert.False(t, s.first(guild, user, rule, "bad word", now.Add(time.Second)))
+	assert.True(t, s.first(guild, user, rule, "another bad word", now.Add(time.Second)))
+	assert.True(t, s.first(guild, user, rule, "bad word", now.Add(autoModTriggerWindow+time.Second)))
+	assert.Len(t, s.seen, 2)
+}
@@ -0,0 +1,148 @@
+package listeners
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/bot/handlers"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/gateway"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// disgo splits a MESSAGE_DELETE_BULK into one MessageDelete event per
+// message, and nothing on those events says they were part of a bulk
+// delete. GatewayHandlers replaces disgo's handler for it with one that
+// writes a single message.bulk_delete row first (while the messages are
+// still cached) and marks the IDs so OnAuditMessageDelete skips them,
+// then hands the event to disgo's handler as usual so every other
+// MessageDelete listener still runs.
+
+// GatewayHandlers returns disgo's default gateway handlers with the bulk
+// message delete handler wrapped as described above. Pass it to
+// bot.WithEventManagerConfigOpts(bot.WithGatewayHandlers(...)).
+func GatewayHandlers() map[gateway.EventType]bot.GatewayEventHandler {
+	all := handlers.GetGatewayHandlers()
+	all[gateway.EventTypeMessageDeleteBulk] = &bulkDeleteHandler{next: all[gateway.EventTypeMessageDeleteBulk]}
+	return all
+}
+
+type bulkDeleteHandler struct {
+	next bot.GatewayEventHandler
+}
+
+func (h *bulkDeleteHandler) EventType() gateway.EventType {
+	return gateway.EventTypeMessageDeleteBulk
+}
+
+func (h *bulkDeleteHandler) HandleGatewayEvent(client *bot.Client, sequenceNumber int, shardID int, event gateway.EventData) {
+	if e, ok := event.(gateway.EventMessageDeleteBulk); ok && e.GuildID != nil {
+		logBulkDelete(client, *e.GuildID, e.ChannelID, e.IDs)
+	}
+	h.next.HandleGatewayEvent(client, sequenceNumber, shardID, event)
+}
+
+// logBulkDelete writes the message.bulk_delete row, snapshotting each
+// message from the cache or, failing that, the message store. Routed
+// through LogPending so the native audit log can name who purged the
+// channel.
+func logBulkDelete(client *bot.Client, guildID, channelID snowflake.ID, ids []snowflake.ID) {
+	if !audit.Enabled(guildID) {
+		return
+	}
+	bulkDeleted.add(ids, time.Now())
+
+	cached := make(map[snowflake.ID]discord.Message, len(ids))
+	var misses []snowflake.ID
+	for _, id := range ids {
+		if msg, ok := cached[id]; ok {
+			cached[id] = msg
+		} else {
+			misses = append(misses, id)
+		}
+	}
+	stored, err := model.GetStoredMessages(guildID, misses)
+	if err != nil {
+		slog.Warn("Failed to get stored messages.", "err", err, "guild_id", guildID, "channel_id", channelID)
+	}
+
+	messageIDs := make([]string, 0, len(ids))
+	var messages []map[string]any
+	for _, id := range ids {
+		messageIDs = append(messageIDs, id.String())
+		var authorID snowflake.ID
+		var author, content string
+		if msg, ok := client.Caches.Message(channelID, id); ok {
+			authorID, author, content = msg.Author.ID, msg.Author.Username, msg.Content
+		} else if s := stored[id]; s != nil {
+			authorID, author, content = s.AuthorID, s.AuthorUsername, s.Content
+		} else {
+			continue
+		}
+		messages = append(messages, map[string]any{
+			"id":              id.String(),
+			"author_id":       authorID.String(),
+			"author_username": author,
+			"content":         content,
+		})
+	}
+
+	details := map[string]any{
+		"channel_id":  channelID.String(),
+		"count":       len(ids),
+		"message_ids": messageIDs,
+	}
+	if len(messages) > 0 {
+		details["messages"] = messages
+	}
+	target := channelID
+	audit.LogPending(audit.Entry{
+		GuildID:    guildID,
+		EventType:  audit.EventMessageBulkDelete,
+		ActorKind:  audit.ActorUnknown, // overwritten by enrichment when available
+		TargetID:   &target,
+		TargetKind: audit.TargetChannel,
+		Source:     audit.SourceGateway,
+		Details:    details,
+	}, []audit.EnrichField{audit.EnrichActor, audit.EnrichReason})
+}
+
+// bulkDeleteWindow is how long a bulk-deleted message ID is remembered.
+// disgo dispatches the per-message events straight after the bulk row is
+// written, so this only has to outlast slow listeners.
+const bulkDeleteWindow = time.Minute
+
+// bulkDeleteSet holds the IDs of messages recorded by a bulk delete row.
+type bulkDeleteSet struct {
+	mu  sync.Mutex
+	ids map[snowflake.ID]time.Time
+}
+
+var bulkDeleted = &bulkDeleteSet{ids: map[snowflake.ID]time.Time{}}
+
+func (s *bulkDeleteSet) add(ids []snowflake.ID, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, at := range s.ids {
+		if now.Sub(at) > bulkDeleteWindow {
+			delete(s.ids, id)
+		}
+	}
+	for _, id := range ids {
+		s.ids[id] = now
+	}
+}
+
+// take reports whether id was part of a bulk delete, forgetting it.
+func (s *bulkDeleteSet) take(id snowflake.ID) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.ids[id]
+	delete(s.ids, id)
+	return ok
+}
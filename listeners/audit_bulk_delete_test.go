@@ -0,0 +1,24 @@
+package listeners
+
+import (
+	"testing"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkDeleteSet(t *testing.T) {
+	s := &bulkDeleteSet{ids: map[snowflake.ID]time.Time{}}
+	now := time.Now()
+	s.add([]snowflake.ID{1, 2}, now)
+
+	assert.True(t, s.take(1))
+	assert.False(t, s.take(1), "each ID is skipped once")
+	assert.False(t, s.take(3))
+
+	// A later bulk delete sweeps IDs whose per-message event never came.
+	s.add([]snowflake.ID{4}, now.Add(bulkDeleteWindow+time.Second))
+	assert.False(t, s.take(2))
+	assert.True(t, s.take(4))
+}
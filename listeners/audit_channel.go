@@ -0,0 +1,239 @@
+package listeners
+
+import (
+	"strconv"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/utils"
+)
+
+// OnAuditChannelCreate records a channel.create entry. Like the other
+// configuration events it routes through LogPending so the native audit
+// log can name who created the channel. The initial permission overwrites
+// are recorded as well, since a new private channel is defined by them.
+func OnAuditChannelCreate(e *events.GuildChannelCreate) {
+	details := channelDetails(e.Channel)
+	if overwrites := overwriteChanges(e.Client(), e.GuildID, nil, e.Channel.PermissionOverwrites()); len(overwrites) > 0 {
+		details["overwrites"] = overwrites
+	}
+	logChannelEvent(e.GuildID, audit.EventChannelCreate, e.ChannelID, details)
+}
+
+// OnAuditChannelDelete records a channel.delete entry, keeping the
+// channel's name since it can no longer be resolved from the cache.
+func OnAuditChannelDelete(e *events.GuildChannelDelete) {
+	logChannelEvent(e.GuildID, audit.EventChannelDelete, e.ChannelID, channelDetails(e.Channel))
+}
+
+// OnAuditChannelUpdate records what changed in a channel update. Changes
+// to the channel's own settings produce a channel.update row; changes to
+// its permission overwrites produce a separate
+// channel.permissions_update row, so each can be filtered on and
+// attributed on its own. Updates that only moved the channel (Discord
+// sends one per shifted channel when a moderator drags one) produce
+// nothing.
+func OnAuditChannelUpdate(e *events.GuildChannelUpdate) {
+	// OldChannel comes from the cache; without it there is nothing to
+	// diff against. Same reasoning as OnAuditMemberUpdate's cold-cache
+	// bail-out.
+	if e.OldChannel == nil {
+		return
+	}
+
+	if changes := channelChanges(e.Client(), e.OldChannel, e.Channel); len(changes) > 0 {
+		details := channelDetails(e.Channel)
+		details["changes"] = changes
+		logChannelEvent(e.GuildID, audit.EventChannelUpdate, e.ChannelID, details)
+	}
+
+	overwrites := overwriteChanges(e.Client(), e.GuildID, e.OldChannel.PermissionOverwrites(), e.Channel.PermissionOverwrites())
+	if len(overwrites) > 0 {
+		details := channelDetails(e.Channel)
+		details["overwrites"] = overwrites
+		logChannelEvent(e.GuildID, audit.EventChannelPermissionsUpdate, e.ChannelID, details)
+	}
+}
+
+func logChannelEvent(guildID snowflake.ID, eventType audit.EventType, channelID snowflake.ID, details map[string]any) {
+	target := channelID
+	audit.LogPending(audit.Entry{
+		GuildID:    guildID,
+		EventType:  eventType,
+		ActorKind:  audit.ActorUnknown, // overwritten by enrichment when available
+		TargetID:   &target,
+		TargetKind: audit.TargetChannel,
+		Source:     audit.SourceGateway,
+		Details:    details,
+	}, []audit.EnrichField{audit.EnrichActor, audit.EnrichReason})
+}
+
+// channelDetails is the base details payload for channel configuration
+// events. channel_id lets the viewer's channel filter find the entry.
+func channelDetails(ch discord.GuildChannel) map[string]any {
+	return map[string]any{
+		"channel_id":   ch.ID().String(),
+		"channel_name": ch.Name(),
+		"channel_type": channelTypeName(ch.Type()),
+	}
+}
+
+// channelTypeName names a channel type the way Discord's UI does.
+func channelTypeName(t discord.ChannelType) string {
+	switch t {
+	case discord.ChannelTypeGuildText:
+		return "text channel"
+	case discord.ChannelTypeGuildVoice:
+		return "voice channel"
+	case discord.ChannelTypeGuildCategory:
+		return "category"
+	case discord.ChannelTypeGuildNews:
+		return "announcement channel"
+	case discord.ChannelTypeGuildStageVoice:
+		return "stage channel"
+	case discord.ChannelTypeGuildForum:
+		return "forum channel"
+	case discord.ChannelTypeGuildMedia:
+		return "media channel"
+	}
+	return "channel"
+}
+
+// channelChanges lists the settings that differ between two versions of
+// a channel as {field, before, after} records, values rendered as
+// strings. Position is deliberately not compared.
+func channelChanges(client *bot.Client, old, updated discord.GuildChannel) []map[string]any {
+	var changes []map[string]any
+	add := func(field, before, after string) {
+		if before != after {
+			changes = append(changes, map[string]any{"field": field, "before": before, "after": after})
+		}
+	}
+
+	add("name", old.Name(), updated.Name())
+	add("category", parentName(client, old.ParentID()), parentName(client, updated.ParentID()))
+
+	oldMsg, oldOK := old.(discord.GuildMessageChannel)
+	newMsg, newOK := updated.(discord.GuildMessageChannel)
+	if oldOK && newOK {
+		add("topic", utils.RefDefault(oldMsg.Topic(), ""), utils.RefDefault(newMsg.Topic(), ""))
+		add("nsfw", onOff(oldMsg.NSFW()), onOff(newMsg.NSFW()))
+		add("slowmode", slowmode(oldMsg.RateLimitPerUser()), slowmode(newMsg.RateLimitPerUser()))
+	}
+
+	oldAudio, oldOK := old.(discord.GuildAudioChannel)
+	newAudio, newOK := updated.(discord.GuildAudioChannel)
+	if oldOK && newOK {
+		add("bitrate", strconv.Itoa(oldAudio.Bitrate()/1000)+" kbps", strconv.Itoa(newAudio.Bitrate()/1000)+" kbps")
+	}
+	return changes
+}
+
+func parentName(client *bot.Client, id *snowflake.ID) string {
+	if id == nil {
+		return ""
+	}
+	return audit.ResolveChannelName(client, *id)
+}
+
+func onOff(b bool) string {
+	if b {
+		return "On"
+	}
+	return "Off"
+}
+
+func slowmode(seconds int) string {
+	if seconds == 0 {
+		return "Off"
+	}
+	return strconv.Itoa(seconds) + "s"
+}
+
+// overwriteChanges diffs two sets of permission overwrites. Each changed
+// overwrite becomes a record naming the role or member, whether the
+// overwrite was added, removed or changed, and which permissions moved to
+// allowed, denied or inherited (neither). Pass a nil old set to describe
+// the overwrites of a new channel.
+func overwriteChanges(client *bot.Client, guildID snowflake.ID, old, updated discord.PermissionOverwrites) []map[string]any {
+	type state struct {
+		allow, deny discord.Permissions
+		present     bool
+	}
+	type key struct {
+		kind discord.PermissionOverwriteType
+		id   snowflake.ID
+	}
+	var order []key
+	before := map[key]state{}
+	after := map[key]state{}
+	collect := func(set discord.PermissionOverwrites, into map[key]state) {
+		for _, o := range set {
+			k := key{o.Type(), o.ID()}
+			if _, seen := before[k]; !seen {
+				if _, seen := after[k]; !seen {
+					order = append(order, k)
+				}
+			}
+			s := state{present: true}
+			switch v := o.(type) {
+			case discord.RolePermissionOverwrite:
+				s.allow, s.deny = v.Allow, v.Deny
+			case discord.MemberPermissionOverwrite:
+				s.allow, s.deny = v.Allow, v.Deny
+			}
+			into[k] = s
+		}
+	}
+	collect(old, before)
+	collect(updated, after)
+
+	var out []map[string]any
+	for _, k := range order {
+		b, a := before[k], after[k]
+		if b == a {
+			continue
+		}
+		action := "changed"
+		switch {
+		case !b.present:
+			action = "added"
+		case !a.present:
+			action = "removed"
+		}
+		// A permission's state is allow, deny or inherit; record each
+		// permission whose state differs under its new state.
+		allowed := a.allow &^ b.allow
+		denied := a.deny &^ b.deny
+		inherited := (b.allow | b.deny) &^ (a.allow | a.deny)
+
+		record := map[string]any{
+			"id":     k.id.String(),
+			"action": action,
+		}
+		if k.kind == discord.PermissionOverwriteTypeRole {
+			record["kind"] = "role"
+			record["name"] = audit.ResolveRoleName(client, guildID, k.id)
+		} else {
+			record["kind"] = "member"
+			if name, ok := audit.ResolveMemberUsername(client, guildID, k.id); ok {
+				record["name"] = name
+			}
+		}
+		if names := permissionNames(allowed); len(names) > 0 {
+			record["allowed"] = names
+		}
+		if names := permissionNames(denied); len(names) > 0 {
+			record["denied"] = names
+		}
+		if names := permissionNames(inherited); len(names) > 0 {
+			record["inherited"] = names
+		}
+		out = append(out, record)
+	}
+	return out
+}
@@ -0,0 +1,75 @@
+package listeners
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/cache"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverwriteChanges(t *testing.T) {
+	const guildID, modsID, userID snowflake.ID = 1, 2, 3
+	client := &bot.Client{Caches: cache.New(cache.WithCaches(cache.FlagsAll))}
+	client.Caches.AddRole(discord.Role{ID: modsID, GuildID: guildID, Name: "Mods"})
+
+	old := discord.PermissionOverwrites{
+		discord.RolePermissionOverwrite{
+			RoleID: modsID,
+			Allow:  discord.PermissionSendMessages,
+			Deny:   discord.PermissionAddReactions,
+		},
+		discord.MemberPermissionOverwrite{UserID: userID, Allow: discord.PermissionViewChannel},
+	}
+	updated := discord.PermissionOverwrites{
+		discord.RolePermissionOverwrite{
+			RoleID: modsID,
+			Allow:  discord.PermissionSendMessages | discord.PermissionManageMessages,
+			Deny:   discord.PermissionMentionEveryone,
+		},
+		discord.RolePermissionOverwrite{RoleID: guildID, Deny: discord.PermissionViewChannel},
+	}
+
+	got := overwriteChanges(client, guildID, old, updated)
+	require.Len(t, got, 3)
+
+	assert.Equal(t, "role", got[0]["kind"])
+	assert.Equal(t, "Mods", got[0]["name"])
+	assert.Equal(t, "changed", got[0]["action"])
+	assert.Equal(t, []string{"Manage Messages"}, got[0]["allowed"])
+	assert.Equal(t, []string{"Mention Everyone"}, got[0]["denied"])
+	assert.Equal(t, []string{"Add Reactions"}, got[0]["inherited"])
+
+	assert.Equal(t, "member", got[1]["kind"])
+	assert.Equal(t, "removed", got[1]["action"])
+	assert.Equal(t, []string{"View Channel"}, got[1]["inherited"])
+
+	assert.Equal(t, "added", got[2]["action"])
+	assert.Equal(t, []string{"View Channel"}, got[2]["denied"])
+
+	assert.Empty(t, overwriteChanges(client, guildID, updated, updated))
+}
+
+func textChannel(t *testing.T, raw string) discord.GuildChannel {
+	t.Helper()
+	var ch discord.GuildTextChannel
+	require.NoError(t, json.Unmarshal([]byte(raw), &ch))
+	return ch
+}
+
+func TestChannelChanges_IgnoresPosition(t *testing.T) {
+	client := &bot.Client{Caches: cache.New(cache.WithCaches(cache.FlagsAll))}
+	old := textChannel(t, `{"id":"10","type":0,"name":"general","position":1}`)
+	moved := textChannel(t, `{"id":"10","type":0,"name":"general","position":4}`)
+	assert.Empty(t, channelChanges(client, old, moved))
+
+	edited := textChannel(t, `{"id":"10","type":0,"name":"chat","position":1,"rate_limit_per_user":10}`)
+	assert.Equal(t, []map[string]any{
+		{"field": "name", "before": "general", "after": "chat"},
+		{"field": "slowmode", "before": "Off", "after": "10s"},
+	}, channelChanges(client, old, edited))
+}
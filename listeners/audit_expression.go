@@ -0,0 +1,125 @@
+package listeners
+
+import (
+	"strings"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+)
+
+// OnAuditEmojiCreate records an emoji.create entry. disgo derives the
+// per-emoji events by diffing the guild's emoji list against the cache.
+// Like the channel and role events, the entry routes through LogPending
+// so the native audit log can name who added it.
+func OnAuditEmojiCreate(e *events.EmojiCreate) {
+	details := emojiDetails(e.Emoji)
+	if len(e.Emoji.Roles) > 0 {
+		details["roles"] = emojiRoleNames(e.Client(), e.GuildID, e.Emoji.Roles)
+	}
+	logExpressionEvent(e.GuildID, audit.EventEmojiCreate, e.Emoji.ID, audit.TargetEmoji, details)
+}
+
+// OnAuditEmojiUpdate records a rename or a change to the roles allowed to
+// use the emoji.
+func OnAuditEmojiUpdate(e *events.EmojiUpdate) {
+	var changes []map[string]any
+	add := func(field, before, after string) {
+		if before != after {
+			changes = append(changes, map[string]any{"field": field, "before": before, "after": after})
+		}
+	}
+	add("name", e.OldEmoji.Name, e.Emoji.Name)
+	add("roles",
+		emojiRoleNames(e.Client(), e.GuildID, e.OldEmoji.Roles),
+		emojiRoleNames(e.Client(), e.GuildID, e.Emoji.Roles))
+	if len(changes) == 0 {
+		return
+	}
+	details := emojiDetails(e.Emoji)
+	details["changes"] = changes
+	logExpressionEvent(e.GuildID, audit.EventEmojiUpdate, e.Emoji.ID, audit.TargetEmoji, details)
+}
+
+// OnAuditEmojiDelete records an emoji.delete entry with the removed
+// emoji's name.
+func OnAuditEmojiDelete(e *events.EmojiDelete) {
+	logExpressionEvent(e.GuildID, audit.EventEmojiDelete, e.Emoji.ID, audit.TargetEmoji, emojiDetails(e.Emoji))
+}
+
+// OnAuditStickerCreate records a sticker.create entry.
+func OnAuditStickerCreate(e *events.StickerCreate) {
+	details := stickerDetails(e.Sticker)
+	if e.Sticker.Description != "" {
+		details["description"] = e.Sticker.Description
+	}
+	logExpressionEvent(e.GuildID, audit.EventStickerCreate, e.Sticker.ID, audit.TargetSticker, details)
+}
+
+// OnAuditStickerUpdate records changes to a sticker's name, description
+// or related emoji.
+func OnAuditStickerUpdate(e *events.StickerUpdate) {
+	var changes []map[string]any
+	add := func(field, before, after string) {
+		if before != after {
+			changes = append(changes, map[string]any{"field": field, "before": before, "after": after})
+		}
+	}
+	add("name", e.OldSticker.Name, e.Sticker.Name)
+	add("description", e.OldSticker.Description, e.Sticker.Description)
+	add("emoji", e.OldSticker.Tags, e.Sticker.Tags)
+	if len(changes) == 0 {
+		return
+	}
+	details := stickerDetails(e.Sticker)
+	details["changes"] = changes
+	logExpressionEvent(e.GuildID, audit.EventStickerUpdate, e.Sticker.ID, audit.TargetSticker, details)
+}
+
+// OnAuditStickerDelete records a sticker.delete entry with the removed
+// sticker's name.
+func OnAuditStickerDelete(e *events.StickerDelete) {
+	logExpressionEvent(e.GuildID, audit.EventStickerDelete, e.Sticker.ID, audit.TargetSticker, stickerDetails(e.Sticker))
+}
+
+func logExpressionEvent(guildID snowflake.ID, eventType audit.EventType, id snowflake.ID, kind audit.TargetKind, details map[string]any) {
+	target := id
+	audit.LogPending(audit.Entry{
+		GuildID:    guildID,
+		EventType:  eventType,
+		ActorKind:  audit.ActorUnknown, // overwritten by enrichment when available
+		TargetID:   &target,
+		TargetKind: kind,
+		Source:     audit.SourceGateway,
+		Details:    details,
+	}, []audit.EnrichField{audit.EnrichActor, audit.EnrichReason})
+}
+
+func emojiDetails(emoji discord.Emoji) map[string]any {
+	details := map[string]any{
+		"emoji_name": emoji.Name,
+	}
+	if emoji.Animated {
+		details["animated"] = true
+	}
+	return details
+}
+
+func stickerDetails(sticker discord.Sticker) map[string]any {
+	return map[string]any{
+		"sticker_name": sticker.Name,
+	}
+}
+
+// emojiRoleNames renders the roles an emoji is restricted to as a
+// comma-separated list of names; "" means everyone may use it.
+func emojiRoleNames(client *bot.Client, guildID snowflake.ID, roleIDs []snowflake.ID) string {
+	names := make([]string, 0, len(roleIDs))
+	for _, id := range roleIDs {
+		names = append(names, "@"+audit.ResolveRoleName(client, guildID, id))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,84 @@
+package listeners
+
+import (
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+)
+
+// OnAuditIntegrationCreate records an integration.create entry. For bots
+// this is the row that says which application was added and with which
+// OAuth2 scopes; the native audit log names who authorised it.
+func OnAuditIntegrationCreate(e *events.IntegrationCreate) {
+	details := map[string]any{
+		"integration_type": integrationTypeName(e.Integration.Type()),
+	}
+	switch i := e.Integration.(type) {
+	case discord.BotIntegration:
+		details["integration_name"] = i.Name
+		details["application_id"] = i.Application.ID.String()
+		if i.Application.Client.ID != 0 {
+			details["bot_id"] = i.Application.Client.ID.String()
+			details["bot_username"] = i.Application.Client.Username
+		}
+		if len(i.Scopes) > 0 {
+			scopes := make([]string, 0, len(i.Scopes))
+			for _, s := range i.Scopes {
+				scopes = append(scopes, string(s))
+			}
+			details["scopes"] = scopes
+		}
+	case discord.TwitchIntegration:
+		details["integration_name"] = i.Name
+	case discord.YouTubeIntegration:
+		details["integration_name"] = i.Name
+	case discord.GuildSubscriptionIntegration:
+		details["integration_name"] = i.Name
+	}
+
+	target := e.Integration.ID()
+	audit.LogPending(audit.Entry{
+		GuildID:    e.GuildID,
+		EventType:  audit.EventIntegrationCreate,
+		ActorKind:  audit.ActorUnknown, // overwritten by enrichment when available
+		TargetID:   &target,
+		TargetKind: audit.TargetIntegration,
+		Source:     audit.SourceGateway,
+		Details:    details,
+	}, []audit.EnrichField{audit.EnrichActor, audit.EnrichReason})
+}
+
+// OnAuditIntegrationDelete records an integration.delete entry. The
+// gateway event carries only the IDs, and integrations aren't cached, so
+// the row names the application by ID.
+func OnAuditIntegrationDelete(e *events.IntegrationDelete) {
+	details := map[string]any{}
+	if e.ApplicationID != nil {
+		details["application_id"] = e.ApplicationID.String()
+	}
+	target := e.ID
+	audit.LogPending(audit.Entry{
+		GuildID:    e.GuildID,
+		EventType:  audit.EventIntegrationDelete,
+		ActorKind:  audit.ActorUnknown,
+		TargetID:   &target,
+		TargetKind: audit.TargetIntegration,
+		Source:     audit.SourceGateway,
+		Details:    details,
+	}, []audit.EnrichField{audit.EnrichActor, audit.EnrichReason})
+}
+
+func integrationTypeName(t discord.IntegrationType) string {
+	switch t {
+	case discord.IntegrationTypeBot:
+		return "bot"
+	case discord.IntegrationTypeTwitch:
+		return "Twitch"
+	case discord.IntegrationTypeYouTube:
+		return "YouTube"
+	case discord.IntegrationTypeGuildSubscription:
+		return "server subscription"
+	}
+	return string(t)
+}
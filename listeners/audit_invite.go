@@ -0,0 +1,82 @@
+package listeners
+
+import (
+	"time"
+
+	"github.com/disgoorg/disgo/events"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+)
+
+// OnAuditInviteCreate records an invite.create entry attributed to the
+// inviter, with the invite's use limit and lifetime.
+func OnAuditInviteCreate(e *events.InviteCreate) {
+	if e.GuildID == nil {
+		return
+	}
+	details := map[string]any{
+		"code":            e.Code,
+		"channel_id":      e.ChannelID.String(),
+		"max_uses":        e.MaxUses,
+		"max_age_seconds": e.MaxAge,
+		"temporary":       e.Temporary,
+	}
+	if ch, ok := e.Client().Caches.Channel(e.ChannelID); ok {
+		details["channel_name"] = ch.Name()
+	}
+	if e.ExpiresAt != nil {
+		details["expires_at"] = e.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+
+	entry := audit.Entry{
+		GuildID:    *e.GuildID,
+		EventType:  audit.EventInviteCreate,
+		ActorKind:  audit.ActorUnknown,
+		TargetKind: audit.TargetInvite,
+		Source:     audit.SourceGateway,
+		Details:    details,
+	}
+	if e.Inviter != nil {
+		inviter := e.Inviter.ID
+		entry.ActorID = &inviter
+		entry.ActorKind = audit.ActorUser
+		details["actor_username"] = e.Inviter.Username
+	}
+	audit.Log(entry)
+}
+
+// OnAuditInviteDelete records an invite.delete entry. The gateway only
+// sends the code and channel, so the inviter and use counts come from the
+// invite tracker; this has to be registered before
+// OnInviteTrackerInviteDelete. Routed through LogPending so a moderator
+// revoking the invite is named; invites deleted because they expired or
+// were used up commit unattributed.
+func OnAuditInviteDelete(e *events.InviteDelete) {
+	if e.GuildID == nil {
+		return
+	}
+	details := map[string]any{
+		"code":       e.Code,
+		"channel_id": e.ChannelID.String(),
+	}
+	if ch, ok := e.Client().Caches.Channel(e.ChannelID); ok {
+		details["channel_name"] = ch.Name()
+	}
+	if snap, ok := cachedInvite(*e.GuildID, e.Code); ok {
+		details["uses"] = snap.Uses
+		details["max_uses"] = snap.MaxUses
+		if snap.InviterID != 0 {
+			details["inviter_id"] = snap.InviterID.String()
+			details["inviter_username"] = snap.InviterUsername
+		}
+	}
+
+	audit.LogPending(audit.Entry{
+		GuildID:    *e.GuildID,
+		EventType:  audit.EventInviteDelete,
+		ActorKind:  audit.ActorUnknown, // overwritten by enrichment when available
+		TargetKind: audit.TargetInvite,
+		Source:     audit.SourceGateway,
+		Details:    details,
+	}, []audit.EnrichField{audit.EnrichActor, audit.EnrichReason})
+}
@@ -14,8 +14,9 @@ import (
 // It posts a "User X was kicked by Y" message to the configured moderator
 // channel and does NOT write to the bot's audit log — that row is written
 // by OnAuditNativeEnrichment (audit_native_enrichment.go). The gateway
-// leave event for the kicked member is not audited, so no suppression of
-// a duplicate row is required. Both listeners subscribe to the same
+// leave event for the kicked member also produces a member.leave row; the
+// two are kept apart on purpose, since only the kick row names the
+// moderator. Both listeners subscribe to the same
 // GuildAuditLogEntryCreate event; disgo dispatches to both.
 func OnAuditLogKick(e *events.GuildAuditLogEntryCreate) {
 	entry := e.AuditLogEntry
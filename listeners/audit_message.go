@@ -3,6 +3,7 @@ package listeners
 import (
 	"log/slog"
 
+	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/events"
 	"github.com/disgoorg/snowflake/v2"
 
@@ -10,9 +11,9 @@ import (
 )
 
 // OnAuditMessageUpdate records a message edit. The OldMessage on the event
-// reflects whatever disgo had cached — when the message isn't cached we
-// skip the edit entirely rather than fabricate a misleading
-// "" → new content entry.
+// reflects whatever disgo had cached, falling back to the message store
+// for guilds that keep one — when neither has the message we skip the
+// edit entirely rather than fabricate a misleading "" → new content entry.
 //
 // Self-edits don't have a meaningful "actor different from the author"
 // case, so this is committed via Log (no enrichment expected). Discord
@@ -22,15 +23,19 @@ func OnAuditMessageUpdate(e *events.GuildMessageUpdate) {
 	authorID := msg.Author.ID
 	messageID := e.MessageID
 
+	beforeContent := e.OldMessage.Content
 	if e.OldMessage.ID == 0 {
 		// Cache miss: OldMessage is the zero value, so we can't compare
-		// before/after content. Skip rather than write "" → new content,
-		// which would look identical to a real edit-from-empty in the
-		// viewer.
-		return
+		// before/after content without the store. Skip rather than write
+		// "" → new content, which would look identical to a real
+		// edit-from-empty in the viewer.
+		stored := storedOriginal(e.GuildID, messageID)
+		if stored == nil {
+			return
+		}
+		beforeContent = stored.Content
 	}
 
-	beforeContent := e.OldMessage.Content
 	afterContent := msg.Content
 	if beforeContent == afterContent {
 		// Discord fires MessageUpdate for embed resolution, link unfurl,
@@ -65,26 +70,39 @@ func OnAuditMessageUpdate(e *events.GuildMessageUpdate) {
 // LogPending because Discord's native audit log identifies the moderator
 // when a message is deleted by someone other than the author. If the
 // deletion is self-initiated the pending entry just commits unenriched
-// after the TTL with the author as actor.
+// after the TTL with the author as actor. Messages removed by a bulk
+// delete are covered by its single message.bulk_delete row instead.
 func OnAuditMessageDelete(e *events.GuildMessageDelete) {
 	messageID := e.MessageID
+	if bulkDeleted.take(messageID) {
+		return
+	}
 
 	details := map[string]any{
 		"channel_id": e.ChannelID.String(),
 	}
 
-	// e.Message is the cached pre-delete copy when available.
+	// e.Message is the cached pre-delete copy when available; the message
+	// store fills in for cache misses.
+	author, content := e.Message.Author, e.Message.Content
+	if author.ID == 0 {
+		if stored := storedOriginal(e.GuildID, messageID); stored != nil {
+			author = discord.User{ID: stored.AuthorID, Username: stored.AuthorUsername}
+			content = stored.Content
+		}
+	}
+
 	var authorID *snowflake.ID
-	if e.Message.Author.ID != 0 {
-		id := e.Message.Author.ID
+	if author.ID != 0 {
+		id := author.ID
 		details["author_id"] = id.String()
-		details["author_username"] = e.Message.Author.Username
-		details["before_content"] = e.Message.Content
+		details["author_username"] = author.Username
+		details["before_content"] = content
 		// For self-deletes, actor == author. Store actor_username so the
 		// viewer renders a name on cache miss. For moderator-initiated
 		// deletions, native audit log enrichment overwrites this field
 		// (and ActorID/ActorKind) with the moderator's data.
-		details["actor_username"] = e.Message.Author.Username
+		details["actor_username"] = author.Username
 		authorID = &id
 	} else {
 		// Cache miss: the native enrichment path matches on
@@ -1,6 +1,7 @@
 package listeners
 
 import (
+	"encoding/json"
 	"strconv"
 
 	"github.com/disgoorg/disgo/discord"
@@ -8,6 +9,7 @@ import (
 	"github.com/disgoorg/snowflake/v2"
 
 	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
 )
 
 // OnAuditNativeEnrichment is a second listener for GuildAuditLogEntryCreate
@@ -18,12 +20,12 @@ import (
 //   - writes a fresh row for events Discord only reports through the
 //     native audit log (member kicks, prune-driven removals).
 //
-// Scope is deliberately limited to moderation-relevant action types:
-// message delete/bulk-delete, ban/unban, member update (timeout, nick,
-// role), kick, and prune. Discord's native audit log also reports voice
-// channel moves/disconnects, channel/role/webhook create/delete/update,
-// and similar — these are intentionally not surfaced; the audit log
-// feature targets moderation activity, not full server-config history.
+// Scope is limited to the action types the audit log records: message
+// delete/bulk-delete, ban/unban, member update (timeout, nick, role),
+// kick, prune, webhook create/delete, voice moves and disconnects, and
+// channel, permission overwrite, role, emoji, sticker and integration
+// changes. Discord's native audit log also reports invite changes and
+// similar — these are not surfaced.
 //
 // The existing OnAuditLogKick listener (in audit_log_kick.go) is left
 // untouched — it owns the moderator-channel kick notification flow.
@@ -33,7 +35,7 @@ func OnAuditNativeEnrichment(e *events.GuildAuditLogEntryCreate) {
 	guildID := e.GuildID
 
 	// Discord fires GuildAuditLogEntryCreate for dozens of action types
-	// (channel/role/webhook/emoji CRUD, voice moves, etc.), most of which
+	// (invite CRUD, scheduled events, etc.), many of which
 	// this listener doesn't handle. Gate on action type first so unhandled
 	// types skip the cache lookup + REST round-trip in
 	// ResolveMemberUsernameOrFetch — that fallback is the dominant cost
@@ -86,30 +88,46 @@ func OnAuditNativeEnrichment(e *events.GuildAuditLogEntryCreate) {
 		audit.TryEnrich(guildID, audit.EventMessageDelete, nil, required, actorPtr, audit.ActorUser, actorUsername, reason, match, maxMatches)
 
 	case discord.AuditLogEventMessageBulkDelete:
-		// Bulk-delete entry's TargetID IS the channel (Options carries
-		// the count but isn't required for matching). Sweep every
-		// pending message-delete whose Details.channel_id matches,
-		// capped by Options.Count when known so the enrichment expires
-		// once the burst is fully attributed.
-		//
-		// Fall back to Discord's documented bulk-delete API ceiling
-		// (100 messages per request) when Options/Count is missing —
-		// keeps the misattribution window finite if Discord ever omits
-		// the count, rather than leaving an unlimited sticky enrichment
-		// that any same-channel self-delete within TTL could latch onto.
+		// Bulk-delete entry's TargetID IS the channel, which is also the
+		// target of the single message.bulk_delete row the gateway side
+		// writes for the burst.
 		if entry.TargetID == nil {
 			return
 		}
-		maxMatches := bulkDeleteCeiling
-		if entry.Options != nil {
-			if c := auditEntryCount(entry.Options.Count); c > 0 {
-				maxMatches = c
+		id := *entry.TargetID
+		audit.TryEnrich(guildID, audit.EventMessageBulkDelete, &id, nil, actorPtr, audit.ActorUser, actorUsername, reason, audit.MatchFirst, 0)
+
+	case discord.AuditLogThreadUpdate:
+		// One native entry can archive and lock a thread at once; each
+		// is its own row on the gateway side.
+		if entry.TargetID == nil {
+			return
+		}
+		id := *entry.TargetID
+		for _, c := range entry.Changes {
+			switch c.Key {
+			case discord.AuditLogChangeKeyArchived:
+				audit.TryEnrich(guildID, audit.EventThreadArchive, &id, nil, actorPtr, audit.ActorUser, actorUsername, reason, audit.MatchFirst, 0)
+			case discord.AuditLogChangeKeyLocked:
+				audit.TryEnrich(guildID, audit.EventThreadLock, &id, nil, actorPtr, audit.ActorUser, actorUsername, reason, audit.MatchFirst, 0)
 			}
 		}
-		required := map[string]string{
-			"channel_id": entry.TargetID.String(),
+
+	case discord.AuditLogThreadDelete:
+		if entry.TargetID == nil {
+			return
 		}
-		audit.TryEnrich(guildID, audit.EventMessageDelete, nil, required, actorPtr, audit.ActorUser, actorUsername, reason, audit.MatchAll, maxMatches)
+		id := *entry.TargetID
+		audit.TryEnrich(guildID, audit.EventThreadDelete, &id, nil, actorPtr, audit.ActorUser, actorUsername, reason, audit.MatchFirst, 0)
+
+	case discord.AuditLogEventInviteDelete:
+		// Invites have no snowflake; the native entry carries the code
+		// in its changes.
+		code := inviteCode(entry.Changes)
+		if code == "" {
+			return
+		}
+		audit.TryEnrich(guildID, audit.EventInviteDelete, nil, map[string]string{"code": code}, actorPtr, audit.ActorUser, actorUsername, reason, audit.MatchFirst, 0)
 
 	case discord.AuditLogEventMemberBanAdd:
 		var target *snowflake.ID
@@ -145,6 +163,117 @@ func OnAuditNativeEnrichment(e *events.GuildAuditLogEntryCreate) {
 			audit.TryEnrich(guildID, ev, target, nil, actorPtr, audit.ActorUser, actorUsername, reason, audit.MatchFirst, 0)
 		}
 
+	case discord.AuditLogEventChannelCreate, discord.AuditLogEventChannelUpdate,
+		discord.AuditLogEventChannelDelete,
+		discord.AuditLogEventChannelOverwriteCreate, discord.AuditLogEventChannelOverwriteUpdate,
+		discord.AuditLogEventChannelOverwriteDelete,
+		discord.AuditLogEventRoleCreate, discord.AuditLogEventRoleUpdate,
+		discord.AuditLogEventRoleDelete,
+		discord.AuditLogEventEmojiCreate, discord.AuditLogEventEmojiUpdate,
+		discord.AuditLogEventEmojiDelete,
+		discord.AuditLogEventStickerCreate, discord.AuditLogEventStickerUpdate,
+		discord.AuditLogEventStickerDelete,
+		discord.AuditLogEventIntegrationCreate, discord.AuditLogEventIntegrationDelete,
+		discord.AuditLogAutoModerationRuleCreate, discord.AuditLogAutoModerationRuleUpdate,
+		discord.AuditLogAutoModerationRuleDelete:
+		// TargetID is the changed channel, role, emoji, sticker,
+		// integration or AutoMod rule; overwrite actions name the channel
+		// too. Editing several overwrites in one save yields one
+		// gateway update but a native entry per overwrite, so the extra
+		// enrichments sit buffered until they expire.
+		//
+		// Updates that only moved the channel or role are not recorded
+		// on the gateway side, so their native entries are skipped
+		// rather than left buffered where a later same-target update
+		// could pick them up.
+		if entry.TargetID == nil || !hasNonPositionChange(entry) {
+			return
+		}
+		id := *entry.TargetID
+		audit.TryEnrich(guildID, configEnrichmentTargets[entry.ActionType], &id, nil, actorPtr, audit.ActorUser, actorUsername, reason, audit.MatchFirst, 0)
+
+	case discord.AuditLogEventWebhookCreate, discord.AuditLogEventWebhookDelete:
+		// The gateway's WebhooksUpdate only names the channel, so the
+		// native entry is the record. Its changes carry the webhook's
+		// settings: new values on create, old values on delete.
+		if entry.TargetID == nil {
+			return
+		}
+		id := *entry.TargetID
+		eventType := audit.EventWebhookCreate
+		if entry.ActionType == discord.AuditLogEventWebhookDelete {
+			eventType = audit.EventWebhookDelete
+		}
+		details := webhookDetails(entry.Changes, eventType == audit.EventWebhookCreate)
+		if actorUsername != "" {
+			details["actor_username"] = actorUsername
+		}
+
+		audit.Log(audit.Entry{
+			GuildID:    guildID,
+			EventType:  eventType,
+			ActorID:    actorPtr,
+			ActorKind:  audit.ActorUser,
+			TargetID:   &id,
+			TargetKind: audit.TargetWebhook,
+			Source:     audit.SourceGateway,
+			Reason:     reason,
+			Details:    details,
+		})
+
+	case discord.AuditLogEventMemberMove:
+		// A moderator dragging members into another channel. Discord
+		// names the destination channel and how many were moved, not
+		// who, so match pending moves into that channel, capped at the
+		// count like a message bulk delete.
+		if entry.Options == nil || entry.Options.ChannelID == nil {
+			return
+		}
+		count := auditEntryCount(entry.Options.Count)
+		match := audit.MatchFirst
+		if count > 1 {
+			match = audit.MatchAll
+		}
+		required := map[string]string{"channel_id": entry.Options.ChannelID.String()}
+		audit.TryEnrich(guildID, audit.EventVoiceMove, nil, required, actorPtr, audit.ActorUser, actorUsername, reason, match, count)
+
+	case discord.AuditLogEventMemberDisconnect:
+		// Discord reports only the moderator and how many members were
+		// disconnected. The voice.disconnect row records that; the
+		// pending leave rows pick up the moderator as their actor. With
+		// nothing else to match on, a member leaving on their own in the
+		// same moment could be attributed instead.
+		settings, err := model.GetGuildSettings(guildID)
+		if err != nil || !settings.AuditVoiceEnabled {
+			return
+		}
+		count := 1
+		if entry.Options != nil {
+			if c := auditEntryCount(entry.Options.Count); c > 0 {
+				count = c
+			}
+		}
+		match := audit.MatchFirst
+		if count > 1 {
+			match = audit.MatchAll
+		}
+		audit.TryEnrich(guildID, audit.EventVoiceLeave, nil, nil, actorPtr, audit.ActorUser, actorUsername, reason, match, count)
+
+		details := map[string]any{"count": count}
+		if actorUsername != "" {
+			details["actor_username"] = actorUsername
+		}
+		audit.Log(audit.Entry{
+			GuildID:    guildID,
+			EventType:  audit.EventVoiceDisconnect,
+			ActorID:    actorPtr,
+			ActorKind:  audit.ActorUser,
+			TargetKind: audit.TargetNone,
+			Source:     audit.SourceGateway,
+			Reason:     reason,
+			Details:    details,
+		})
+
 	case discord.AuditLogEventMemberKick:
 		if entry.TargetID == nil {
 			// Kick should always have a target; bail rather than write
@@ -180,10 +309,10 @@ func OnAuditNativeEnrichment(e *events.GuildAuditLogEntryCreate) {
 
 	case discord.AuditLogEventMemberPrune:
 		// Prune fires one native audit log entry for the whole batch
-		// (TargetID is nil) plus per-member gateway leave events. The
-		// gateway leaves are not logged — the audit log only records the
-		// single guild.prune row here, capturing the moderator and
-		// batch metadata.
+		// (TargetID is nil) plus per-member gateway leave events. For
+		// prunes started through the bot the leaves are not logged (the
+		// pending-prune rows mark them); the single guild.prune row here
+		// captures the moderator and batch metadata.
 		details := map[string]any{}
 		if entry.Options != nil {
 			if entry.Options.MembersRemoved != nil && *entry.Options.MembersRemoved != "" {
@@ -250,6 +379,10 @@ func memberUpdateEnrichmentTargets(changes []discord.AuditLogChange) []audit.Eve
 			add(audit.EventMemberNickChange)
 		case discord.AuditLogChangeKeyRoleAdd, discord.AuditLogChangeKeyRoleRemove:
 			add(audit.EventMemberRoleChange)
+		case discord.AuditLogChangeKeyMute:
+			add(audit.EventVoiceServerMute)
+		case discord.AuditLogChangeKeyDeaf:
+			add(audit.EventVoiceServerDeafen)
 		}
 	}
 	if len(out) == 0 {
@@ -258,6 +391,89 @@ func memberUpdateEnrichmentTargets(changes []discord.AuditLogChange) []audit.Eve
 	return out
 }
 
+// configEnrichmentTargets maps the native configuration action types
+// (channels, roles, expressions, integrations, AutoMod rules) to the audit
+// log event they attribute.
+var configEnrichmentTargets = map[discord.AuditLogEvent]audit.EventType{
+	discord.AuditLogEventChannelCreate:          audit.EventChannelCreate,
+	discord.AuditLogEventChannelUpdate:          audit.EventChannelUpdate,
+	discord.AuditLogEventChannelDelete:          audit.EventChannelDelete,
+	discord.AuditLogEventChannelOverwriteCreate: audit.EventChannelPermissionsUpdate,
+	discord.AuditLogEventChannelOverwriteUpdate: audit.EventChannelPermissionsUpdate,
+	discord.AuditLogEventChannelOverwriteDelete: audit.EventChannelPermissionsUpdate,
+	discord.AuditLogEventRoleCreate:             audit.EventRoleCreate,
+	discord.AuditLogEventRoleUpdate:             audit.EventRoleUpdate,
+	discord.AuditLogEventRoleDelete:             audit.EventRoleDelete,
+	discord.AuditLogEventEmojiCreate:            audit.EventEmojiCreate,
+	discord.AuditLogEventEmojiUpdate:            audit.EventEmojiUpdate,
+	discord.AuditLogEventEmojiDelete:            audit.EventEmojiDelete,
+	discord.AuditLogEventStickerCreate:          audit.EventStickerCreate,
+	discord.AuditLogEventStickerUpdate:          audit.EventStickerUpdate,
+	discord.AuditLogEventStickerDelete:          audit.EventStickerDelete,
+	discord.AuditLogEventIntegrationCreate:      audit.EventIntegrationCreate,
+	discord.AuditLogEventIntegrationDelete:      audit.EventIntegrationDelete,
+	discord.AuditLogAutoModerationRuleCreate:    audit.EventAutoModRuleCreate,
+	discord.AuditLogAutoModerationRuleUpdate:    audit.EventAutoModRuleUpdate,
+	discord.AuditLogAutoModerationRuleDelete:    audit.EventAutoModRuleDelete,
+}
+
+// webhookDetails reads a webhook's name, channel and type out of a native
+// webhook entry's changes, taking the new values when created and the old
+// ones when deleted.
+func webhookDetails(changes []discord.AuditLogChange, created bool) map[string]any {
+	details := map[string]any{}
+	for _, c := range changes {
+		raw := c.OldValue
+		if created {
+			raw = c.NewValue
+		}
+		switch c.Key {
+		case discord.AuditLogChangeKeyName:
+			var name string
+			if json.Unmarshal(raw, &name) == nil && name != "" {
+				details["webhook_name"] = name
+			}
+		case discord.AuditLogChangeKeyChannelID:
+			var id snowflake.ID
+			if json.Unmarshal(raw, &id) == nil && id != 0 {
+				details["channel_id"] = id.String()
+			}
+		case discord.AuditLogChangeKeyType:
+			var t discord.WebhookType
+			if json.Unmarshal(raw, &t) == nil && webhookTypeName(t) != "" {
+				details["webhook_type"] = webhookTypeName(t)
+			}
+		}
+	}
+	return details
+}
+
+func webhookTypeName(t discord.WebhookType) string {
+	switch t {
+	case discord.WebhookTypeIncoming:
+		return "incoming"
+	case discord.WebhookTypeChannelFollower:
+		return "channel follower"
+	case discord.WebhookTypeApplication:
+		return "application"
+	}
+	return ""
+}
+
+// hasNonPositionChange reports whether a channel or role update changed
+// anything besides position. Entries for other actions always pass.
+func hasNonPositionChange(entry discord.AuditLogEntry) bool {
+	if entry.ActionType != discord.AuditLogEventChannelUpdate && entry.ActionType != discord.AuditLogEventRoleUpdate {
+		return true
+	}
+	for _, c := range entry.Changes {
+		if c.Key != discord.AuditLogChangeKeyPosition {
+			return true
+		}
+	}
+	return false
+}
+
 // isNullJSON returns true when raw is the JSON literal null or empty.
 // Used to tell a cleared timeout from one being set.
 func isNullJSON(raw []byte) bool {
@@ -265,17 +481,30 @@ func isNullJSON(raw []byte) bool {
 	return s == "" || s == "null"
 }
 
-// bulkDeleteCeiling is Discord's documented per-request cap on bulk
-// message deletes (POST .../messages/bulk-delete accepts at most 100
-// IDs). Used as the cap on bulk-delete enrichment when Options.Count
-// is missing so the buffered enrichment can't latch onto unlimited
-// late-arriving pendings.
-const bulkDeleteCeiling = 100
+// inviteCode reads the invite code out of a native invite entry's
+// changes, which hold it as the old value on delete.
+func inviteCode(changes []discord.AuditLogChange) string {
+	for _, c := range changes {
+		if c.Key != discord.AuditLogChangeKeyCode {
+			continue
+		}
+		var code string
+		raw := c.OldValue
+		if len(raw) == 0 {
+			raw = c.NewValue
+		}
+		if json.Unmarshal(raw, &code) == nil {
+			return code
+		}
+	}
+	return ""
+}
 
 // isHandledAuditAction reports whether the listener has a case for this
 // native audit log action type. Used as an early bail-out so we don't
 // pay the cost of ResolveMemberUsernameOrFetch (cache lookup + REST
-// fallback) on every channel/role/webhook/voice event Discord emits.
+// fallback) on every invite creation and scheduled event change Discord
+// emits.
 // Must stay in sync with the switch in OnAuditNativeEnrichment.
 func isHandledAuditAction(t discord.AuditLogEvent) bool {
 	switch t {
@@ -286,10 +515,18 @@ func isHandledAuditAction(t discord.AuditLogEvent) bool {
 		discord.AuditLogEventMemberUpdate,
 		discord.AuditLogEventMemberRoleUpdate,
 		discord.AuditLogEventMemberKick,
-		discord.AuditLogEventMemberPrune:
+		discord.AuditLogEventMemberPrune,
+		discord.AuditLogEventWebhookCreate,
+		discord.AuditLogEventWebhookDelete,
+		discord.AuditLogEventMemberMove,
+		discord.AuditLogEventMemberDisconnect,
+		discord.AuditLogThreadUpdate,
+		discord.AuditLogThreadDelete,
+		discord.AuditLogEventInviteDelete:
 		return true
 	}
-	return false
+	_, ok := configEnrichmentTargets[t]
+	return ok
 }
 
 // auditEntryCount parses Discord's stringly-typed Options.Count field.
@@ -107,3 +107,48 @@ func TestAuditEntryCount(t *testing.T) {
 		})
 	}
 }
+
+func TestHasNonPositionChange(t *testing.T) {
+	position := discord.AuditLogChange{Key: discord.AuditLogChangeKeyPosition}
+	name := discord.AuditLogChange{Key: discord.AuditLogChangeKeyName}
+
+	assert.False(t, hasNonPositionChange(discord.AuditLogEntry{
+		ActionType: discord.AuditLogEventChannelUpdate, Changes: []discord.AuditLogChange{position},
+	}))
+	assert.True(t, hasNonPositionChange(discord.AuditLogEntry{
+		ActionType: discord.AuditLogEventRoleUpdate, Changes: []discord.AuditLogChange{position, name},
+	}))
+	assert.True(t, hasNonPositionChange(discord.AuditLogEntry{ActionType: discord.AuditLogEventChannelDelete}))
+	assert.True(t, isHandledAuditAction(discord.AuditLogEventChannelOverwriteUpdate))
+	assert.False(t, isHandledAuditAction(discord.AuditLogGuildScheduledEventCreate))
+}
+
+func TestWebhookDetails(t *testing.T) {
+	changes := []discord.AuditLogChange{
+		{Key: discord.AuditLogChangeKeyName, OldValue: []byte(`"Old"`), NewValue: []byte(`"Captain Hook"`)},
+		{Key: discord.AuditLogChangeKeyChannelID, NewValue: []byte(`"123"`)},
+		{Key: discord.AuditLogChangeKeyType, NewValue: []byte(`1`)},
+	}
+	assert.Equal(t, map[string]any{
+		"webhook_name": "Captain Hook",
+		"channel_id":   "123",
+		"webhook_type": "incoming",
+	}, webhookDetails(changes, true))
+	assert.Equal(t, map[string]any{"webhook_name": "Old"}, webhookDetails(changes, false))
+}
+
+func TestMemberUpdateEnrichmentTargets_VoiceMuteDeaf(t *testing.T) {
+	got := memberUpdateEnrichmentTargets([]discord.AuditLogChange{
+		{Key: discord.AuditLogChangeKeyMute, NewValue: []byte(`true`)},
+		{Key: discord.AuditLogChangeKeyDeaf, NewValue: []byte(`true`)},
+	})
+	assert.Equal(t, []audit.EventType{audit.EventVoiceServerMute, audit.EventVoiceServerDeafen}, got)
+}
+
+func TestInviteCode(t *testing.T) {
+	assert.Equal(t, "abc", inviteCode([]discord.AuditLogChange{
+		{Key: discord.AuditLogChangeKeyChannelID, OldValue: []byte(`"5"`)},
+		{Key: discord.AuditLogChangeKeyCode, OldValue: []byte(`"abc"`)},
+	}))
+	assert.Equal(t, "", inviteCode(nil))
+}
@@ -0,0 +1,143 @@
+package listeners
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+)
+
+// OnAuditRoleCreate records a role.create entry with the permissions the
+// role starts with. Routed through LogPending so the native audit log can
+// name who created it.
+func OnAuditRoleCreate(e *events.RoleCreate) {
+	details := roleDetails(e.Role)
+	if names := permissionNames(e.Role.Permissions); len(names) > 0 {
+		details["permissions"] = names
+	}
+	logRoleEvent(e.GuildID, audit.EventRoleCreate, e.RoleID, details)
+}
+
+// OnAuditRoleDelete records a role.delete entry. The role comes from the
+// cache, so its name and permissions are kept when it was cached.
+func OnAuditRoleDelete(e *events.RoleDelete) {
+	details := map[string]any{}
+	if e.Role.ID != 0 {
+		details = roleDetails(e.Role)
+		if names := permissionNames(e.Role.Permissions); len(names) > 0 {
+			details["permissions"] = names
+		}
+	}
+	logRoleEvent(e.GuildID, audit.EventRoleDelete, e.RoleID, details)
+}
+
+// OnAuditRoleUpdate records a role.update entry with the permissions
+// granted and revoked plus any other setting changes. Updates that only
+// changed the role's position (Discord sends one per shifted role when a
+// moderator reorders them) produce nothing.
+func OnAuditRoleUpdate(e *events.RoleUpdate) {
+	old := e.OldRole
+	// OldRole is the zero value when the role wasn't cached; there is
+	// nothing to diff against.
+	if old.ID == 0 {
+		return
+	}
+	updated := e.Role
+
+	details := roleDetails(updated)
+	changed := false
+	if names := permissionNames(updated.Permissions &^ old.Permissions); len(names) > 0 {
+		details["permissions_added"] = names
+		changed = true
+	}
+	if names := permissionNames(old.Permissions &^ updated.Permissions); len(names) > 0 {
+		details["permissions_removed"] = names
+		changed = true
+	}
+	if changes := roleChanges(old, updated); len(changes) > 0 {
+		details["changes"] = changes
+		changed = true
+	}
+	if !changed {
+		return
+	}
+	logRoleEvent(e.GuildID, audit.EventRoleUpdate, e.RoleID, details)
+}
+
+func logRoleEvent(guildID snowflake.ID, eventType audit.EventType, roleID snowflake.ID, details map[string]any) {
+	target := roleID
+	audit.LogPending(audit.Entry{
+		GuildID:    guildID,
+		EventType:  eventType,
+		ActorKind:  audit.ActorUnknown, // overwritten by enrichment when available
+		TargetID:   &target,
+		TargetKind: audit.TargetRole,
+		Source:     audit.SourceGateway,
+		Details:    details,
+	}, []audit.EnrichField{audit.EnrichActor, audit.EnrichReason})
+}
+
+func roleDetails(role discord.Role) map[string]any {
+	return map[string]any{
+		"role_name": role.Name,
+	}
+}
+
+// roleChanges lists the role settings that differ as {field, before,
+// after} records, in the same shape as channelChanges.
+func roleChanges(old, updated discord.Role) []map[string]any {
+	var changes []map[string]any
+	add := func(field, before, after string) {
+		if before != after {
+			changes = append(changes, map[string]any{"field": field, "before": before, "after": after})
+		}
+	}
+	add("name", old.Name, updated.Name)
+	add("color", roleColor(old.Color), roleColor(updated.Color))
+	add("hoist", onOff(old.Hoist), onOff(updated.Hoist))
+	add("mentionable", onOff(old.Mentionable), onOff(updated.Mentionable))
+	return changes
+}
+
+func roleColor(c int) string {
+	if c == 0 {
+		return "Default"
+	}
+	return fmt.Sprintf("#%06x", c)
+}
+
+// permissionNames lists the permissions set in p by their Discord names,
+// in bit order. disgo's Permissions.String covers most bits but panics on
+// a value with no named bit and orders names randomly, so names are
+// looked up one bit at a time.
+func permissionNames(p discord.Permissions) []string {
+	var names []string
+	for p != 0 {
+		bit := discord.Permissions(1) << bits.TrailingZeros64(uint64(p))
+		p &^= bit
+		names = append(names, permissionName(bit))
+	}
+	return names
+}
+
+// unnamedPermissions covers the bits in discord.PermissionsAll that
+// disgo's name table lacks.
+var unnamedPermissions = map[discord.Permissions]string{
+	discord.PermissionCreateGuildExpressions: "Create Expressions",
+	discord.PermissionCreateEvents:           "Create Events",
+}
+
+func permissionName(bit discord.Permissions) string {
+	if name, ok := unnamedPermissions[bit]; ok {
+		return name
+	}
+	if discord.PermissionsAll.Has(bit) {
+		return bit.String()
+	}
+	return "Unknown permission (" + strconv.Itoa(bits.TrailingZeros64(uint64(bit))) + ")"
+}
@@ -0,0 +1,39 @@
+package listeners
+
+import (
+	"testing"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPermissionNames(t *testing.T) {
+	assert.Nil(t, permissionNames(0))
+	assert.Equal(t,
+		[]string{"Kick Members", "Administrator", "Create Expressions"},
+		permissionNames(discord.PermissionAdministrator|discord.PermissionKickMembers|discord.PermissionCreateGuildExpressions),
+	)
+	assert.Equal(t, []string{"Unknown permission (62)"}, permissionNames(discord.Permissions(1)<<62))
+
+	// Every permission disgo knows of must have a name.
+	for _, name := range permissionNames(discord.PermissionsAll) {
+		assert.NotContains(t, name, "Unknown")
+	}
+}
+
+func TestRoleChanges(t *testing.T) {
+	old := discord.Role{Name: "Mods", Color: 0, Position: 3}
+	moved := old
+	moved.Position = 5
+	assert.Empty(t, roleChanges(old, moved))
+
+	edited := old
+	edited.Name = "Moderators"
+	edited.Color = 0x3498db
+	edited.Hoist = true
+	assert.Equal(t, []map[string]any{
+		{"field": "name", "before": "Mods", "after": "Moderators"},
+		{"field": "color", "before": "Default", "after": "#3498db"},
+		{"field": "hoist", "before": "Off", "after": "On"},
+	}, roleChanges(old, edited))
+}
@@ -0,0 +1,107 @@
+package listeners
+
+import (
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+)
+
+// OnAuditThreadCreate records a thread.create entry attributed to the
+// thread's owner, who is its creator. Discord also sends a thread create
+// when the bot is added to an existing private thread; only newly created
+// threads are recorded.
+func OnAuditThreadCreate(e *events.ThreadCreate) {
+	if !e.NewlyCreated {
+		return
+	}
+	details := threadDetails(e.Client(), e.Thread)
+	owner := e.Thread.OwnerID
+	target := e.ThreadID
+	entry := audit.Entry{
+		GuildID:    e.GuildID,
+		EventType:  audit.EventThreadCreate,
+		ActorKind:  audit.ActorUnknown,
+		TargetID:   &target,
+		TargetKind: audit.TargetChannel,
+		Source:     audit.SourceGateway,
+		Details:    details,
+	}
+	if owner != 0 {
+		entry.ActorID = &owner
+		entry.ActorKind = audit.ActorUser
+		if name, ok := audit.ResolveMemberUsername(e.Client(), e.GuildID, owner); ok {
+			details["actor_username"] = name
+		}
+	}
+	audit.Log(entry)
+}
+
+// OnAuditThreadUpdate records archive and lock changes as separate
+// thread.archive / thread.lock rows, each attributed through the native
+// audit log. Other thread edits (renames, slowmode) are not recorded.
+func OnAuditThreadUpdate(e *events.ThreadUpdate) {
+	// OldThread is the zero value when the thread wasn't cached.
+	if e.OldThread.ID() == 0 {
+		return
+	}
+	old, updated := e.OldThread.ThreadMetadata, e.Thread.ThreadMetadata
+	if old.Archived != updated.Archived {
+		details := threadDetails(e.Client(), e.Thread)
+		details["archived"] = updated.Archived
+		logThreadEvent(e.GuildID, audit.EventThreadArchive, e.ThreadID, details)
+	}
+	if old.Locked != updated.Locked {
+		details := threadDetails(e.Client(), e.Thread)
+		details["locked"] = updated.Locked
+		logThreadEvent(e.GuildID, audit.EventThreadLock, e.ThreadID, details)
+	}
+}
+
+// OnAuditThreadDelete records a thread.delete entry. The thread comes
+// from the cache, so its name is kept when it was cached.
+func OnAuditThreadDelete(e *events.ThreadDelete) {
+	details := map[string]any{}
+	if e.Thread.ID() != 0 {
+		details = threadDetails(e.Client(), e.Thread)
+	} else if e.ParentID != 0 {
+		details["channel_id"] = e.ParentID.String()
+	}
+	logThreadEvent(e.GuildID, audit.EventThreadDelete, e.ThreadID, details)
+}
+
+func logThreadEvent(guildID snowflake.ID, eventType audit.EventType, threadID snowflake.ID, details map[string]any) {
+	target := threadID
+	audit.LogPending(audit.Entry{
+		GuildID:    guildID,
+		EventType:  eventType,
+		ActorKind:  audit.ActorUnknown, // overwritten by enrichment when available
+		TargetID:   &target,
+		TargetKind: audit.TargetChannel,
+		Source:     audit.SourceGateway,
+		Details:    details,
+	}, []audit.EnrichField{audit.EnrichActor, audit.EnrichReason})
+}
+
+// threadDetails is the base details payload for thread events.
+// channel_id is the parent channel, so filtering the viewer on a forum or
+// text channel finds the threads in it; the thread itself is the target.
+func threadDetails(client *bot.Client, thread discord.GuildThread) map[string]any {
+	details := map[string]any{
+		"thread_name": thread.Name(),
+	}
+	if p := thread.ParentID(); p != nil && *p != 0 {
+		parentID := *p
+		details["channel_id"] = parentID.String()
+		if parent, ok := client.Caches.Channel(parentID); ok {
+			details["parent_name"] = parent.Name()
+			t := parent.Type()
+			if t == discord.ChannelTypeGuildForum || t == discord.ChannelTypeGuildMedia {
+				details["forum_post"] = true
+			}
+		}
+	}
+	return details
+}
@@ -0,0 +1,41 @@
+package listeners
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/cache"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func guildThread(t *testing.T, raw string) discord.GuildThread {
+	t.Helper()
+	var thread discord.GuildThread
+	require.NoError(t, json.Unmarshal([]byte(raw), &thread))
+	return thread
+}
+
+func TestThreadDetails(t *testing.T) {
+	client := &bot.Client{Caches: cache.New(cache.WithCaches(cache.FlagsAll))}
+	var forum discord.GuildForumChannel
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"2","guild_id":"1","type":15,"name":"support"}`), &forum))
+	client.Caches.AddChannel(forum)
+
+	post := guildThread(t, `{"id":"10","guild_id":"1","type":11,"name":"help","parent_id":"2"}`)
+	assert.Equal(t, map[string]any{
+		"thread_name": "help",
+		"channel_id":  "2",
+		"parent_name": "support",
+		"forum_post":  true,
+	}, threadDetails(client, post))
+
+	// An uncached parent still files the thread under its channel.
+	thread := guildThread(t, `{"id":"11","guild_id":"1","type":11,"name":"chat","parent_id":"3"}`)
+	assert.Equal(t, map[string]any{
+		"thread_name": "chat",
+		"channel_id":  "3",
+	}, threadDetails(client, thread))
+}
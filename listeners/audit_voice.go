@@ -0,0 +1,109 @@
+package listeners
+
+import (
+	"log/slog"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// OnAuditVoiceStateUpdate records voice activity for guilds that have
+// opted in with AuditVoiceEnabled. disgo's own GuildVoiceMove fires for
+// every state change of a connected member (self-mute included), so this
+// diffs the old and new voice state itself:
+//
+//   - joins are written directly, attributed to the member;
+//   - leaves and moves go through LogPending, attributed to the member
+//     unless the native audit log reports a moderator disconnecting or
+//     moving them;
+//   - server mute / deafen changes go through LogPending too, to pick up
+//     the moderator from the native member update entry.
+func OnAuditVoiceStateUpdate(e *events.GuildVoiceStateUpdate) {
+	guildID := e.VoiceState.GuildID
+	settings, err := model.GetGuildSettings(guildID)
+	if err != nil {
+		slog.Warn("audit: failed to read guild settings", "err", err, "guild_id", guildID)
+		return
+	}
+	if !settings.AuditVoiceEnabled {
+		return
+	}
+
+	user := e.VoiceState.UserID
+	username := e.Member.User.Username
+	oldCh := e.OldVoiceState.ChannelID
+	newCh := e.VoiceState.ChannelID
+
+	entry := func(eventType audit.EventType, details map[string]any) audit.Entry {
+		target := user
+		details["target_username"] = username
+		return audit.Entry{
+			GuildID:    guildID,
+			EventType:  eventType,
+			ActorID:    &target,
+			ActorKind:  audit.ActorUser,
+			TargetID:   &target,
+			TargetKind: audit.TargetUser,
+			Source:     audit.SourceGateway,
+			Details:    details,
+		}
+	}
+
+	switch {
+	case oldCh == nil && newCh != nil:
+		details := voiceChannelDetails(e.Client(), "channel", *newCh)
+		details["actor_username"] = username
+		audit.Log(entry(audit.EventVoiceJoin, details))
+		return
+
+	case oldCh != nil && newCh == nil:
+		details := voiceChannelDetails(e.Client(), "channel", *oldCh)
+		details["actor_username"] = username
+		audit.LogPending(entry(audit.EventVoiceLeave, details), []audit.EnrichField{audit.EnrichActor, audit.EnrichReason})
+		return
+
+	case oldCh != nil && newCh != nil && *oldCh != *newCh:
+		details := voiceChannelDetails(e.Client(), "channel", *newCh)
+		for k, v := range voiceChannelDetails(e.Client(), "from_channel", *oldCh) {
+			details[k] = v
+		}
+		details["actor_username"] = username
+		audit.LogPending(entry(audit.EventVoiceMove, details), []audit.EnrichField{audit.EnrichActor, audit.EnrichReason})
+		return
+
+	case newCh == nil:
+		return
+	}
+
+	// Still in the same channel: only server mute / deafen are of
+	// interest. The actor is unknown until enrichment, since only a
+	// moderator can change them.
+	if e.OldVoiceState.GuildMute != e.VoiceState.GuildMute {
+		details := voiceChannelDetails(e.Client(), "channel", *newCh)
+		details["muted"] = e.VoiceState.GuildMute
+		en := entry(audit.EventVoiceServerMute, details)
+		en.ActorID, en.ActorKind = nil, audit.ActorUnknown
+		audit.LogPending(en, []audit.EnrichField{audit.EnrichActor, audit.EnrichReason})
+	}
+	if e.OldVoiceState.GuildDeaf != e.VoiceState.GuildDeaf {
+		details := voiceChannelDetails(e.Client(), "channel", *newCh)
+		details["deafened"] = e.VoiceState.GuildDeaf
+		en := entry(audit.EventVoiceServerDeafen, details)
+		en.ActorID, en.ActorKind = nil, audit.ActorUnknown
+		audit.LogPending(en, []audit.EnrichField{audit.EnrichActor, audit.EnrichReason})
+	}
+}
+
+// voiceChannelDetails records a channel as prefix+"_id" and
+// prefix+"_name", so the viewer can name it after it is deleted.
+func voiceChannelDetails(client *bot.Client, prefix string, channelID snowflake.ID) map[string]any {
+	details := map[string]any{prefix + "_id": channelID.String()}
+	if ch, ok := client.Caches.Channel(channelID); ok {
+		details[prefix+"_name"] = ch.Name()
+	}
+	return details
+}
@@ -0,0 +1,42 @@
+package listeners
+
+import (
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// logBotInfraction records an infraction issued by the bot itself (anti-spam,
+// AutoMod, word filter) and audits it as an automatic bot warning. username
+// may be empty when the caller doesn't know it.
+func logBotInfraction(client *bot.Client, guildID, userID snowflake.ID, username, reason string, severity float64, silent bool) (*model.Infraction, error) {
+	botID := client.ID()
+	inf, err := model.CreateInfraction(guildID, userID, botID, reason, severity, silent)
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]any{
+		"infraction_id": inf.Sqid(),
+		"weight":        inf.Weight,
+		"silent":        inf.Silent,
+		"automatic":     true,
+	}
+	if username != "" {
+		details["target_username"] = username
+	}
+	audit.Log(audit.Entry{
+		GuildID:    guildID,
+		EventType:  audit.EventBotWarn,
+		ActorID:    &botID,
+		ActorKind:  audit.ActorBot,
+		TargetID:   &userID,
+		TargetKind: audit.TargetUser,
+		Source:     audit.SourceGateway,
+		Reason:     inf.Reason,
+		Details:    details,
+	})
+	return inf, nil
+}
@@ -8,15 +8,23 @@ import (
 	"github.com/NLLCommunity/heimdallr/model"
 )
 
+// OnGatekeepUserJoin gives new members the pending role when the guild
+// auto-assigns it, and always while the guild is in a raid lockdown.
 func OnGatekeepUserJoin(e *events.GuildMemberJoin) {
 	settings, err := model.GetGuildSettings(e.GuildID)
 	if err != nil {
 		return
 	}
 
-	if !settings.GatekeepAddPendingRoleOnJoin || settings.GatekeepPendingRole == 0 {
+	if settings.GatekeepPendingRole == 0 {
 		return
 	}
+	if !settings.GatekeepAddPendingRoleOnJoin {
+		lockdown, err := model.GetGuildLockdown(e.GuildID)
+		if err != nil || lockdown == nil {
+			return
+		}
+	}
 
 	err = e.Client().Rest.AddMemberRole(e.GuildID, e.Member.User.ID, settings.GatekeepPendingRole)
 	if err != nil {
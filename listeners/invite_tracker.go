@@ -0,0 +1,253 @@
+package listeners
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/jellydator/ttlcache/v3"
+)
+
+// Invite tracking works the way Discord leaves it to bots: keep the use
+// count of every guild invite (plus the vanity URL), re-fetch them when a
+// member joins, and attribute the join to whichever count went up. Discord
+// deletes an invite the moment it reaches max_uses, so an invite that was
+// one use away from its limit and has since disappeared counts too.
+//
+// Attribution is skipped rather than guessed whenever more than one
+// candidate changed — e.g. two members joining through different invites
+// between our fetches. Both endpoints need Manage Server; without it the
+// fetch fails and every join in that guild stays unattributed.
+
+// JoinInvite is the invite a member join was attributed to.
+type JoinInvite struct {
+	Code            string
+	InviterID       snowflake.ID
+	InviterUsername string
+	Vanity          bool
+}
+
+// inviteSnapshot is the cached state of one guild invite.
+type inviteSnapshot struct {
+	Uses            int
+	MaxUses         int
+	InviterID       snowflake.ID
+	InviterUsername string
+}
+
+type guildInviteState struct {
+	mu         sync.Mutex
+	loaded     bool
+	invites    map[string]inviteSnapshot
+	vanityCode string
+	vanityUses int
+}
+
+var inviteStates sync.Map // map[snowflake.ID]*guildInviteState
+
+// joinInvites memoizes the attribution per guild:user so every join
+// listener (history, join message, mod notice) sees the same answer
+// without each re-fetching the invite list. A nil value means "resolved,
+// but unknown".
+var joinInvites = ttlcache.New[string, *JoinInvite](
+	ttlcache.WithTTL[string, *JoinInvite](time.Minute),
+)
+
+func RemoveExpiredJoinInvites() {
+	joinInvites.DeleteExpired()
+}
+
+func guildInvites(guildID snowflake.ID) *guildInviteState {
+	s, _ := inviteStates.LoadOrStore(guildID, &guildInviteState{})
+	return s.(*guildInviteState)
+}
+
+// OnInviteTrackerGuildReady seeds the invite cache for guilds present at
+// startup.
+func OnInviteTrackerGuildReady(e *events.GuildReady) {
+	refreshGuildInvites(e.Client(), e.GuildID)
+}
+
+// OnInviteTrackerGuildJoin seeds the invite cache when the bot is added to
+// a guild.
+func OnInviteTrackerGuildJoin(e *events.GuildJoin) {
+	refreshGuildInvites(e.Client(), e.GuildID)
+}
+
+func OnInviteTrackerInviteCreate(e *events.InviteCreate) {
+	if e.GuildID == nil {
+		return
+	}
+	state := guildInvites(*e.GuildID)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	if !state.loaded {
+		return
+	}
+	snap := inviteSnapshot{Uses: e.Uses, MaxUses: e.MaxUses}
+	if e.Inviter != nil {
+		snap.InviterID = e.Inviter.ID
+		snap.InviterUsername = e.Inviter.Username
+	}
+	state.invites[e.Code] = snap
+}
+
+// OnInviteTrackerInviteDelete drops deleted invites from the cache —
+// except ones a single use away from max_uses. Those are most likely
+// being deleted because a member just used them up, and the join event
+// that follows still needs the old count to attribute the join.
+func OnInviteTrackerInviteDelete(e *events.InviteDelete) {
+	if e.GuildID == nil {
+		return
+	}
+	state := guildInvites(*e.GuildID)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	snap, ok := state.invites[e.Code]
+	if !ok || (snap.MaxUses > 0 && snap.Uses+1 >= snap.MaxUses) {
+		return
+	}
+	delete(state.invites, e.Code)
+}
+
+// cachedInvite returns what the tracker knows about an invite. Listeners
+// that need it on delete must run before OnInviteTrackerInviteDelete,
+// which forgets it.
+func cachedInvite(guildID snowflake.ID, code string) (inviteSnapshot, bool) {
+	state := guildInvites(guildID)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	snap, ok := state.invites[code]
+	return snap, ok
+}
+
+// refreshGuildInvites replaces the cached invite counts with a fresh
+// fetch. Caller must not hold state.mu.
+func refreshGuildInvites(client *bot.Client, guildID snowflake.ID) {
+	state := guildInvites(guildID)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+	invites, vanityCode, vanityUses, err := fetchGuildInvites(client, guildID)
+	if err != nil {
+		slog.Debug("invite tracker: failed to fetch guild invites", "guild_id", guildID, "err", err)
+		return
+	}
+	state.invites, state.vanityCode, state.vanityUses = invites, vanityCode, vanityUses
+	state.loaded = true
+}
+
+func fetchGuildInvites(client *bot.Client, guildID snowflake.ID) (map[string]inviteSnapshot, string, int, error) {
+	list, err := client.Rest.GetGuildInvites(guildID)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	invites := make(map[string]inviteSnapshot, len(list))
+	for _, inv := range list {
+		snap := inviteSnapshot{Uses: inv.Uses, MaxUses: inv.MaxUses}
+		if inv.Inviter != nil {
+			snap.InviterID = inv.Inviter.ID
+			snap.InviterUsername = inv.Inviter.Username
+		}
+		invites[inv.Code] = snap
+	}
+
+	// Only guilds with a vanity URL answer the vanity endpoint usefully;
+	// skip the round-trip for the rest.
+	var vanityCode string
+	var vanityUses int
+	if guild, ok := client.Caches.Guild(guildID); ok && guild.VanityURLCode != nil {
+		vanity, err := client.Rest.GetGuildVanityURL(guildID)
+		if err == nil && vanity != nil && vanity.Code != nil {
+			vanityCode = *vanity.Code
+			vanityUses = vanity.Uses
+		}
+	}
+	return invites, vanityCode, vanityUses, nil
+}
+
+// ResolveJoinInvite returns the invite the given member joined through, or
+// nil when it can't be determined. Call it only from GuildMemberJoin
+// handling: the first call per join re-fetches the guild's invites and
+// advances the cached counts; later calls for the same join within a
+// minute return the memoized answer.
+func ResolveJoinInvite(client *bot.Client, guildID, userID snowflake.ID) *JoinInvite {
+	key := fmt.Sprintf("%d:%d", guildID, userID)
+	if item := joinInvites.Get(key); item != nil {
+		return item.Value()
+	}
+
+	state := guildInvites(guildID)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	// Another listener may have resolved this join while we waited.
+	if item := joinInvites.Get(key); item != nil {
+		return item.Value()
+	}
+
+	invites, vanityCode, vanityUses, err := fetchGuildInvites(client, guildID)
+	if err != nil {
+		slog.Debug("invite tracker: failed to fetch guild invites", "guild_id", guildID, "err", err)
+		joinInvites.Set(key, nil, ttlcache.DefaultTTL)
+		return nil
+	}
+
+	var used *JoinInvite
+	if state.loaded {
+		used = detectUsedInvite(state.invites, invites, state.vanityUses, vanityCode, vanityUses)
+	}
+	state.invites, state.vanityCode, state.vanityUses = invites, vanityCode, vanityUses
+	state.loaded = true
+
+	joinInvites.Set(key, used, ttlcache.DefaultTTL)
+	return used
+}
+
+// detectUsedInvite compares cached invite counts against a fresh fetch and
+// returns the single invite whose use count went up. Returns nil if no
+// candidate or several candidates changed.
+func detectUsedInvite(
+	before, after map[string]inviteSnapshot,
+	vanityUsesBefore int,
+	vanityCode string,
+	vanityUsesAfter int,
+) *JoinInvite {
+	var candidates []*JoinInvite
+
+	for code, cur := range after {
+		prev, existed := before[code]
+		if cur.Uses > prev.Uses || (!existed && cur.Uses > 0) {
+			candidates = append(candidates, &JoinInvite{
+				Code:            code,
+				InviterID:       cur.InviterID,
+				InviterUsername: cur.InviterUsername,
+			})
+		}
+	}
+	// An invite that vanished after being one use short of its limit was
+	// consumed by this join.
+	for code, prev := range before {
+		if _, still := after[code]; still {
+			continue
+		}
+		if prev.MaxUses > 0 && prev.Uses+1 >= prev.MaxUses {
+			candidates = append(candidates, &JoinInvite{
+				Code:            code,
+				InviterID:       prev.InviterID,
+				InviterUsername: prev.InviterUsername,
+			})
+		}
+	}
+	if vanityCode != "" && vanityUsesAfter > vanityUsesBefore {
+		candidates = append(candidates, &JoinInvite{Code: vanityCode, Vanity: true})
+	}
+
+	if len(candidates) != 1 {
+		return nil
+	}
+	return candidates[0]
+}
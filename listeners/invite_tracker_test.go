@@ -0,0 +1,93 @@
+package listeners
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectUsedInvite(t *testing.T) {
+	base := map[string]inviteSnapshot{
+		"abc":    {Uses: 3, InviterID: 1, InviterUsername: "alice"},
+		"single": {Uses: 0, MaxUses: 1, InviterID: 2, InviterUsername: "bob"},
+	}
+
+	cases := []struct {
+		name        string
+		after       map[string]inviteSnapshot
+		vanityAfter int
+		wantCode    string
+		wantVanity  bool
+	}{
+		{
+			name: "use count increased",
+			after: map[string]inviteSnapshot{
+				"abc":    {Uses: 4, InviterID: 1, InviterUsername: "alice"},
+				"single": {Uses: 0, MaxUses: 1, InviterID: 2, InviterUsername: "bob"},
+			},
+			wantCode: "abc",
+		},
+		{
+			name: "invite exhausted and deleted",
+			after: map[string]inviteSnapshot{
+				"abc": {Uses: 3, InviterID: 1, InviterUsername: "alice"},
+			},
+			wantCode: "single",
+		},
+		{
+			name:        "vanity URL used",
+			after:       base,
+			vanityAfter: 11,
+			wantCode:    "vanity",
+			wantVanity:  true,
+		},
+		{
+			name: "new invite used before it was cached",
+			after: map[string]inviteSnapshot{
+				"abc":    {Uses: 3},
+				"single": {Uses: 0, MaxUses: 1},
+				"fresh":  {Uses: 1, InviterID: 3, InviterUsername: "carol"},
+			},
+			wantCode: "fresh",
+		},
+		{
+			name: "two invites changed is ambiguous",
+			after: map[string]inviteSnapshot{
+				"abc": {Uses: 4},
+			},
+		},
+		{
+			name:  "nothing changed",
+			after: base,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			vanityAfter := tc.vanityAfter
+			if vanityAfter == 0 {
+				vanityAfter = 10
+			}
+			got := detectUsedInvite(base, tc.after, 10, "vanity", vanityAfter)
+			if tc.wantCode == "" {
+				assert.Nil(t, got)
+				return
+			}
+			if assert.NotNil(t, got) {
+				assert.Equal(t, tc.wantCode, got.Code)
+				assert.Equal(t, tc.wantVanity, got.Vanity)
+			}
+		})
+	}
+}
+
+func TestDetectUsedInvite_CarriesInviter(t *testing.T) {
+	before := map[string]inviteSnapshot{"abc": {Uses: 1, InviterID: 42, InviterUsername: "alice"}}
+	after := map[string]inviteSnapshot{"abc": {Uses: 2, InviterID: 42, InviterUsername: "alice"}}
+
+	got := detectUsedInvite(before, after, 0, "", 0)
+	if assert.NotNil(t, got) {
+		assert.EqualValues(t, 42, got.InviterID)
+		assert.Equal(t, "alice", got.InviterUsername)
+	}
+}
@@ -2,6 +2,7 @@ package listeners
 
 import (
 	"log/slog"
+	"time"
 
 	"github.com/cbroglie/mustache"
 	"github.com/disgoorg/disgo/bot"
@@ -15,6 +16,9 @@ import (
 
 func OnUserJoin(e *events.GuildMemberJoin) {
 	guildID := e.GuildID
+	invite := ResolveJoinInvite(e.Client(), guildID, e.Member.User.ID)
+	visit := recordMemberJoin(e, invite)
+
 	guild, err := e.Client().Rest.GetGuild(guildID, false)
 	if err != nil {
 		return
@@ -38,6 +42,13 @@ func OnUserJoin(e *events.GuildMemberJoin) {
 	}
 
 	joinleaveInfo := utils.NewMessageTemplateData(e.Member, guild.Guild)
+	joinleaveInfo.History = historyTemplateData(visit, time.Now())
+	if invite != nil {
+		joinleaveInfo.Invite = utils.TemplateInviteData{
+			Code:    invite.Code,
+			Inviter: invite.InviterUsername,
+		}
+	}
 
 	hasV2 := guildSettings.JoinMessageV2 && guildSettings.JoinMessageV2Json != ""
 
@@ -51,6 +62,9 @@ func OnUserJoin(e *events.GuildMemberJoin) {
 
 func OnUserLeave(e *events.GuildMemberLeave) {
 	guildID := e.GuildID
+	pruned, _ := model.IsMemberPruned(guildID, e.User.ID)
+	visit := recordMemberLeave(e, pruned)
+
 	guild, err := e.Client().Rest.GetGuild(guildID, false)
 	if err != nil {
 		return
@@ -73,12 +87,13 @@ func OnUserLeave(e *events.GuildMemberLeave) {
 		return
 	}
 
-	if pruned, _ := model.IsMemberPruned(guildID, e.User.ID); pruned {
+	if pruned {
 		return
 	}
 
 	e.Member.User = e.User
 	joinleaveInfo := utils.NewMessageTemplateData(e.Member, guild.Guild)
+	joinleaveInfo.History = historyTemplateData(visit, time.Now())
 
 	hasV2 := guildSettings.LeaveMessageV2 && guildSettings.LeaveMessageV2Json != ""
 
@@ -0,0 +1,161 @@
+package listeners
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/utils"
+)
+
+// recordMemberJoin is called first thing by OnUserJoin. It records the
+// join in the member history table together with the invite that was
+// used, writes a member.join audit entry, and — when enabled — tells the
+// moderator channel which invite brought the member in.
+//
+// Returns the recorded visit so the join message can use it; nil if the
+// row could not be written.
+func recordMemberJoin(e *events.GuildMemberJoin, invite *JoinInvite) *model.MemberJoin {
+	guildID := e.GuildID
+	user := e.Member.User
+
+	joinedAt := time.Now()
+	if e.Member.JoinedAt != nil {
+		joinedAt = *e.Member.JoinedAt
+	}
+	join := &model.MemberJoin{
+		GuildID:          guildID,
+		UserID:           user.ID,
+		JoinedAt:         joinedAt,
+		AccountCreatedAt: user.CreatedAt(),
+	}
+	if invite != nil {
+		join.InviteCode = invite.Code
+		join.InviterID = invite.InviterID
+		join.InviterUsername = invite.InviterUsername
+		join.Vanity = invite.Vanity
+	}
+	if err := model.CreateMemberJoin(join); err != nil {
+		slog.Warn("Failed to record member join.", "guild_id", guildID, "user_id", user.ID, "err", err)
+		join = nil
+	}
+
+	details := map[string]any{
+		"target_username":  user.Username,
+		"account_age_days": int(joinedAt.Sub(user.CreatedAt()).Hours() / 24),
+	}
+	if join != nil {
+		details["visit"] = join.Visit
+	}
+	if invite != nil {
+		details["invite_code"] = invite.Code
+		if invite.Vanity {
+			details["vanity"] = true
+		}
+		if invite.InviterID != 0 {
+			details["inviter_id"] = invite.InviterID.String()
+			details["inviter_username"] = invite.InviterUsername
+		}
+	}
+	userID := user.ID
+	audit.Log(audit.Entry{
+		GuildID:    guildID,
+		EventType:  audit.EventMemberJoin,
+		ActorID:    &userID,
+		ActorKind:  audit.ActorUser,
+		TargetID:   &userID,
+		TargetKind: audit.TargetUser,
+		Source:     audit.SourceGateway,
+		Details:    details,
+	})
+
+	guildSettings, err := model.GetGuildSettings(guildID)
+	if err != nil || !guildSettings.NotifyJoinInvite || guildSettings.ModeratorChannel == 0 {
+		return join
+	}
+
+	_, err = e.Client().Rest.CreateMessage(
+		guildSettings.ModeratorChannel,
+		discord.NewMessageCreate().
+			WithContent(fmt.Sprintf("%s (`%d`) joined %s.", user.Mention(), user.ID, describeJoinInvite(invite))).
+			WithAllowedMentions(&discord.AllowedMentions{}),
+	)
+	if err != nil {
+		slog.Warn("Failed to send join invite notice.", "guild_id", guildID, "err", err)
+	}
+	return join
+}
+
+// recordMemberLeave is called first thing by OnUserLeave. It closes the
+// member's visit in the history table and writes a member.leave audit
+// entry. Members removed by a bot-driven prune are not audited: the
+// single guild.prune row already covers the batch.
+//
+// Returns the closed visit so the leave message can show tenure; nil if
+// the row could not be written.
+func recordMemberLeave(e *events.GuildMemberLeave, pruned bool) *model.MemberJoin {
+	guildID := e.GuildID
+	user := e.User
+
+	join, err := model.RecordMemberLeave(guildID, user.ID, time.Now(), e.Member.JoinedAt)
+	if err != nil {
+		slog.Warn("Failed to record member leave.", "guild_id", guildID, "user_id", user.ID, "err", err)
+	}
+
+	if pruned {
+		return join
+	}
+
+	details := map[string]any{
+		"target_username": user.Username,
+	}
+	if join != nil {
+		details["visit"] = join.Visit
+		details["joined_at"] = join.JoinedAt.UTC().Format(time.RFC3339)
+		details["tenure_seconds"] = int64(join.Tenure(time.Now()).Seconds())
+	}
+	userID := user.ID
+	audit.Log(audit.Entry{
+		GuildID:    guildID,
+		EventType:  audit.EventMemberLeave,
+		ActorID:    &userID,
+		ActorKind:  audit.ActorUser,
+		TargetID:   &userID,
+		TargetKind: audit.TargetUser,
+		Source:     audit.SourceGateway,
+		Details:    details,
+	})
+	return join
+}
+
+// historyTemplateData builds the History placeholders from a visit row.
+func historyTemplateData(join *model.MemberJoin, now time.Time) utils.TemplateHistoryData {
+	if join == nil {
+		return utils.TemplateHistoryData{}
+	}
+	return utils.TemplateHistoryData{
+		Visit:        join.Visit,
+		VisitOrdinal: utils.Ordinal(join.Visit),
+		Returning:    join.Visit > 1,
+		Tenure:       utils.ApproxDuration(join.Tenure(now)),
+		AccountAge:   utils.ApproxDuration(join.AccountAgeAtJoin()),
+	}
+}
+
+// describeJoinInvite renders the invite for the moderator channel notice.
+func describeJoinInvite(invite *JoinInvite) string {
+	switch {
+	case invite == nil:
+		return "through an unknown invite"
+	case invite.Vanity:
+		return fmt.Sprintf("through the vanity URL `%s`", invite.Code)
+	case invite.InviterID != 0:
+		return fmt.Sprintf("through invite `%s` created by <@%d>", invite.Code, invite.InviterID)
+	}
+	return fmt.Sprintf("through invite `%s`", invite.Code)
+}
@@ -0,0 +1,105 @@
+package listeners
+
+import (
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// OnMessageStoreCreate keeps a copy of new messages for guilds with the
+// message store turned on. Bot messages aren't stored.
+func OnMessageStoreCreate(e *events.GuildMessageCreate) {
+	if e.Message.Author.Bot {
+		return
+	}
+	guildSettings, err := model.GetGuildSettings(e.GuildID)
+	if err != nil {
+		slog.Warn("Failed to get guild settings.", "err", err, "guild_id", e.GuildID)
+		return
+	}
+	if !guildSettings.MessageStoreEnabled || !messageStoreMonitors(e.Client(), e.GuildID, e.ChannelID) {
+		return
+	}
+
+	if err := model.SaveStoredMessage(storedMessageFrom(e.GuildID, e.Message)); err != nil {
+		slog.Error("Failed to store message.", "err", err, "guild_id", e.GuildID, "message_id", e.MessageID)
+	}
+}
+
+// OnMessageStoreUpdate applies edits to stored messages for guilds with
+// the message store turned on; other guilds skip the write, and any rows
+// left behind by a failed cleanup stay untouched. It must be registered
+// after OnAuditMessageUpdate, which reads the stored content as the
+// edit's "before" when disgo's cache misses.
+func OnMessageStoreUpdate(e *events.GuildMessageUpdate) {
+	if e.OldMessage.ID != 0 && e.OldMessage.Content == e.Message.Content {
+		return
+	}
+	editedAt := time.Now()
+	if e.Message.EditedTimestamp != nil {
+		editedAt = *e.Message.EditedTimestamp
+	}
+	guildSettings, err := model.GetGuildSettings(e.GuildID)
+	if err != nil {
+		slog.Warn("Failed to get guild settings.", "err", err, "guild_id", e.GuildID)
+		return
+	}
+	if !guildSettings.MessageStoreEnabled {
+		return
+	}
+	if _, err := model.UpdateStoredMessageContent(e.GuildID, e.MessageID, e.Message.Content, editedAt); err != nil {
+		slog.Error("Failed to update stored message.", "err", err, "guild_id", e.GuildID, "message_id", e.MessageID)
+	}
+}
+
+// messageStoreMonitors reports whether the guild's message store covers
+// channelID. Threads follow their parent channel.
+func messageStoreMonitors(client *bot.Client, guildID, channelID snowflake.ID) bool {
+	channels, err := model.GetMessageStoreChannels(guildID)
+	if err != nil {
+		slog.Warn("Failed to get message store channels.", "err", err, "guild_id", guildID)
+		return false
+	}
+	return len(channels) == 0 || slices.Contains(channels, overrideChannel(client, channelID))
+}
+
+func storedMessageFrom(guildID snowflake.ID, m discord.Message) *model.StoredMessage {
+	stored := &model.StoredMessage{
+		MessageID:      m.ID,
+		GuildID:        guildID,
+		ChannelID:      m.ChannelID,
+		AuthorID:       m.Author.ID,
+		AuthorUsername: m.Author.Username,
+		Content:        m.Content,
+		CreatedAt:      m.ID.Time(),
+		EditedAt:       m.EditedTimestamp,
+	}
+	attachments := make([]model.StoredAttachment, 0, len(m.Attachments))
+	for _, a := range m.Attachments {
+		sa := model.StoredAttachment{ID: a.ID, Filename: a.Filename, Size: a.Size, URL: a.URL}
+		if a.ContentType != nil {
+			sa.ContentType = *a.ContentType
+		}
+		attachments = append(attachments, sa)
+	}
+	stored.SetAttachments(attachments)
+	return stored
+}
+
+// storedOriginal returns the stored copy of a message that disgo's cache
+// missed, or nil if the guild doesn't store it.
+func storedOriginal(guildID, messageID snowflake.ID) *model.StoredMessage {
+	stored, err := model.GetStoredMessage(guildID, messageID)
+	if err != nil {
+		slog.Warn("Failed to get stored message.", "err", err, "guild_id", guildID, "message_id", messageID)
+		return nil
+	}
+	return stored
+}
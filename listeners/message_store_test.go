@@ -0,0 +1,39 @@
+package listeners
+
+import (
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+func TestStoredMessageFrom(t *testing.T) {
+	sent := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	contentType := "image/png"
+	m := discord.Message{
+		ID:        snowflake.New(sent),
+		ChannelID: 5,
+		Author:    discord.User{ID: 7, Username: "alice"},
+		Content:   "look",
+		Attachments: []discord.Attachment{
+			{ID: 9, Filename: "cat.png", ContentType: &contentType, Size: 100, URL: "https://cdn.example/cat.png"},
+		},
+	}
+
+	stored := storedMessageFrom(1, m)
+	assert.Equal(t, snowflake.ID(1), stored.GuildID)
+	assert.Equal(t, snowflake.ID(7), stored.AuthorID)
+	assert.Equal(t, "alice", stored.AuthorUsername)
+	assert.Equal(t, "look", stored.Content)
+	assert.True(t, stored.CreatedAt.Equal(sent), "CreatedAt is the send time")
+	assert.Equal(t, []model.StoredAttachment{
+		{ID: 9, Filename: "cat.png", ContentType: "image/png", Size: 100, URL: "https://cdn.example/cat.png"},
+	}, stored.AttachmentList())
+
+	m.Attachments = nil
+	assert.Empty(t, storedMessageFrom(1, m).Attachments)
+}
@@ -0,0 +1,189 @@
+package listeners
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/agnivade/levenshtein"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/interactions/lockdown"
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// Raid detection keeps the recent joins of each guild in memory and starts
+// a lockdown when either signal fires:
+//
+//   - join rate: more than RaidJoinThreshold joins within
+//     RaidJoinWindowSeconds;
+//   - similar names: RaidSimilarNameThreshold accounts younger than
+//     raidNewAccountAge, joining within raidNameWindow, whose usernames
+//     are the same once digits and punctuation are stripped (or within a
+//     small edit distance of each other).
+//
+// The history is dropped whenever a lockdown starts, so one raid triggers
+// one lockdown rather than one per join.
+const (
+	raidNameWindow      = 5 * time.Minute
+	raidNewAccountAge   = 7 * 24 * time.Hour
+	raidMaxTrackedJoins = 200
+	// raidMinNameLength keeps short names like "alex" / "alex2" from
+	// counting as a cluster — they collide far too often by chance.
+	raidMinNameLength = 5
+)
+
+type raidJoin struct {
+	UserID     snowflake.ID
+	Username   string
+	At         time.Time
+	NewAccount bool
+}
+
+type raidConfig struct {
+	JoinThreshold        int
+	JoinWindow           time.Duration
+	SimilarNameThreshold int
+}
+
+type raidTracker struct {
+	mu    sync.Mutex
+	joins []raidJoin
+}
+
+var raidTrackers sync.Map // map[snowflake.ID]*raidTracker
+
+func OnRaidDetectionJoin(e *events.GuildMemberJoin) {
+	settings, err := model.GetGuildSettings(e.GuildID)
+	if err != nil || !settings.RaidDetectionEnabled {
+		return
+	}
+	user := e.Member.User
+	if user.Bot {
+		return
+	}
+
+	cfg := raidConfig{
+		JoinThreshold:        settings.RaidJoinThreshold,
+		JoinWindow:           time.Duration(settings.RaidJoinWindowSeconds) * time.Second,
+		SimilarNameThreshold: settings.RaidSimilarNameThreshold,
+	}
+	now := time.Now()
+
+	t, _ := raidTrackers.LoadOrStore(e.GuildID, &raidTracker{})
+	tracker := t.(*raidTracker)
+	tracker.mu.Lock()
+	tracker.joins = append(pruneRaidJoins(tracker.joins, now, cfg.JoinWindow), raidJoin{
+		UserID:     user.ID,
+		Username:   user.Username,
+		At:         now,
+		NewAccount: now.Sub(user.CreatedAt()) < raidNewAccountAge,
+	})
+	trigger := detectRaid(tracker.joins, now, cfg)
+	if trigger != nil {
+		tracker.joins = nil
+	}
+	tracker.mu.Unlock()
+
+	if trigger == nil {
+		return
+	}
+
+	slog.Warn("Raid detected; starting lockdown.", "guild_id", e.GuildID, "reason", trigger.Reason)
+	err = lockdown.Start(e.Client(), e.GuildID, *trigger, nil, audit.SourceGateway)
+	if err != nil && !errors.Is(err, lockdown.ErrLockdownActive) {
+		slog.Error("Failed to start lockdown.", "guild_id", e.GuildID, "err", err)
+	}
+}
+
+// pruneRaidJoins drops joins that are too old to matter to either signal
+// and caps the history at raidMaxTrackedJoins.
+func pruneRaidJoins(joins []raidJoin, now time.Time, joinWindow time.Duration) []raidJoin {
+	keep := max(joinWindow, raidNameWindow)
+	i := 0
+	for i < len(joins) && now.Sub(joins[i].At) > keep {
+		i++
+	}
+	joins = joins[i:]
+	if len(joins) >= raidMaxTrackedJoins {
+		joins = joins[len(joins)-raidMaxTrackedJoins+1:]
+	}
+	return joins
+}
+
+// detectRaid checks the join history (oldest first) for either raid signal
+// and returns the lockdown trigger, or nil.
+func detectRaid(joins []raidJoin, now time.Time, cfg raidConfig) *lockdown.Trigger {
+	if cfg.JoinThreshold > 0 && cfg.JoinWindow > 0 {
+		var recent []snowflake.ID
+		for _, j := range joins {
+			if now.Sub(j.At) <= cfg.JoinWindow {
+				recent = append(recent, j.UserID)
+			}
+		}
+		if len(recent) > cfg.JoinThreshold {
+			return &lockdown.Trigger{
+				Reason:  fmt.Sprintf("%d joins in %s", len(recent), cfg.JoinWindow),
+				UserIDs: recent,
+			}
+		}
+	}
+
+	if cfg.SimilarNameThreshold > 0 {
+		var candidates []raidJoin
+		for _, j := range joins {
+			if j.NewAccount && now.Sub(j.At) <= raidNameWindow {
+				candidates = append(candidates, j)
+			}
+		}
+		for _, c := range candidates {
+			var cluster []snowflake.ID
+			for _, other := range candidates {
+				if similarRaidNames(c.Username, other.Username) {
+					cluster = append(cluster, other.UserID)
+				}
+			}
+			if len(cluster) >= cfg.SimilarNameThreshold {
+				return &lockdown.Trigger{
+					Reason:  fmt.Sprintf("%d new accounts with names like %q", len(cluster), c.Username),
+					UserIDs: cluster,
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// similarRaidNames reports whether two usernames look like they came from
+// the same generator: equal after normalizeRaidName, or within one edit
+// per five characters of each other.
+func similarRaidNames(a, b string) bool {
+	a, b = normalizeRaidName(a), normalizeRaidName(b)
+	if utf8.RuneCountInString(a) < raidMinNameLength || utf8.RuneCountInString(b) < raidMinNameLength {
+		return false
+	}
+	if a == b {
+		return true
+	}
+	maxDistance := min(utf8.RuneCountInString(a), utf8.RuneCountInString(b)) / 5
+	return levenshtein.ComputeDistance(a, b) <= maxDistance
+}
+
+// normalizeRaidName lowercases a username and keeps only its letters, so
+// "spam_bot123" and "spambot_77" compare equal.
+func normalizeRaidName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
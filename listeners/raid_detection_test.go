@@ -0,0 +1,92 @@
+package listeners
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectRaid_JoinRate(t *testing.T) {
+	now := time.Now()
+	cfg := raidConfig{JoinThreshold: 3, JoinWindow: 10 * time.Second}
+
+	joins := []raidJoin{
+		{UserID: 1, Username: "old", At: now.Add(-time.Minute)},
+		{UserID: 2, Username: "alice", At: now.Add(-8 * time.Second)},
+		{UserID: 3, Username: "bob", At: now.Add(-5 * time.Second)},
+		{UserID: 4, Username: "carol", At: now},
+	}
+	assert.Nil(t, detectRaid(joins, now, cfg), "exactly the threshold is not a raid")
+
+	joins = append(joins, raidJoin{UserID: 5, Username: "dave", At: now})
+	trigger := detectRaid(joins, now, cfg)
+	require.NotNil(t, trigger)
+	assert.Equal(t, []snowflake.ID{2, 3, 4, 5}, trigger.UserIDs, "only joins inside the window are included")
+	assert.Equal(t, "4 joins in 10s", trigger.Reason)
+}
+
+func TestDetectRaid_SimilarNames(t *testing.T) {
+	now := time.Now()
+	cfg := raidConfig{SimilarNameThreshold: 3}
+
+	joins := []raidJoin{
+		{UserID: 1, Username: "freenitro_01", At: now.Add(-4 * time.Minute), NewAccount: true},
+		{UserID: 2, Username: "jane", At: now.Add(-3 * time.Minute), NewAccount: true},
+		{UserID: 3, Username: "FreeNitro22", At: now.Add(-2 * time.Minute), NewAccount: false},
+		{UserID: 4, Username: "free.nitro.7", At: now.Add(-time.Minute), NewAccount: true},
+	}
+	assert.Nil(t, detectRaid(joins, now, cfg), "established accounts don't count towards a cluster")
+
+	joins = append(joins, raidJoin{UserID: 5, Username: "freenitr0", At: now, NewAccount: true})
+	trigger := detectRaid(joins, now, cfg)
+	require.NotNil(t, trigger)
+	assert.Equal(t, []snowflake.ID{1, 4, 5}, trigger.UserIDs)
+}
+
+func TestDetectRaid_Disabled(t *testing.T) {
+	now := time.Now()
+	var joins []raidJoin
+	for i := range 20 {
+		joins = append(joins, raidJoin{UserID: snowflake.ID(i + 1), Username: fmt.Sprintf("raider%d", i), At: now, NewAccount: true})
+	}
+	assert.Nil(t, detectRaid(joins, now, raidConfig{}))
+}
+
+func TestSimilarRaidNames(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"spam_bot123", "spambot_77", true},
+		{"SpamBot", "spambot", true},
+		{"spambotty", "spambottx", true},
+		{"alex", "alex2", false},
+		{"alice_smith", "bob_jones", false},
+		{"12345", "67890", false},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, similarRaidNames(tc.a, tc.b), "%q vs %q", tc.a, tc.b)
+	}
+}
+
+func TestPruneRaidJoins(t *testing.T) {
+	now := time.Now()
+	joins := []raidJoin{
+		{UserID: 1, At: now.Add(-10 * time.Minute)},
+		{UserID: 2, At: now.Add(-time.Minute)},
+	}
+	pruned := pruneRaidJoins(joins, now, 10*time.Second)
+	require.Len(t, pruned, 1, "joins older than the name window are dropped")
+	assert.Equal(t, snowflake.ID(2), pruned[0].UserID)
+
+	var many []raidJoin
+	for i := range raidMaxTrackedJoins + 10 {
+		many = append(many, raidJoin{UserID: snowflake.ID(i), At: now})
+	}
+	assert.Len(t, pruneRaidJoins(many, now, 10*time.Second), raidMaxTrackedJoins-1,
+		"the cap leaves room for the join about to be appended")
+}
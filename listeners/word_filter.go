@@ -0,0 +1,165 @@
+package listeners
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/wordfilter"
+)
+
+func OnWordFilterMessageCreate(e *events.GuildMessageCreate) {
+	checkWordFilter(e.Client(), e.GuildID, e.Message, false)
+}
+
+// OnWordFilterMessageUpdate re-checks edited messages, so a message can't
+// be posted clean and edited afterwards. Updates that don't change the
+// content (embed unfurls, pins) are skipped: those of a message that was
+// never edited always, the rest when the old message is cached.
+func OnWordFilterMessageUpdate(e *events.GuildMessageUpdate) {
+	if e.Message.EditedTimestamp == nil {
+		return
+	}
+	if e.OldMessage.ID != 0 && e.OldMessage.Content == e.Message.Content {
+		return
+	}
+	checkWordFilter(e.Client(), e.GuildID, e.Message, true)
+}
+
+func checkWordFilter(client *bot.Client, guildID snowflake.ID, message discord.Message, edited bool) {
+	if message.Author.Bot || message.WebhookID != nil || message.Content == "" {
+		return
+	}
+
+	guildSettings, err := model.GetGuildSettings(guildID)
+	if err != nil {
+		slog.Warn("Failed to get guild settings.", "err", err, "guild_id", guildID)
+		return
+	}
+	if !guildSettings.WordFilterEnabled {
+		return
+	}
+
+	filter, err := wordfilter.For(guildID)
+	if err != nil {
+		slog.Error("Failed to load word filter.", "err", err, "guild_id", guildID)
+		return
+	}
+	matches := filter.Match(message.Content)
+	if len(matches) == 0 {
+		return
+	}
+	match := wordfilter.Strongest(matches)
+	action := match.Action()
+
+	if action == wordfilter.ActionDelete || action == wordfilter.ActionWarn {
+		err := client.Rest.DeleteMessage(message.ChannelID, message.ID, rest.WithReason("Message matched the word filter."))
+		if err != nil {
+			slog.Error("Failed to delete filtered message.", "err", err, "guild", guildID, "channel", message.ChannelID, "message", message.ID)
+		}
+	}
+	if action == wordfilter.ActionWarn {
+		warnFilteredUser(client, guildID, match.Entry.Severity, message.Author)
+	}
+
+	botID := client.ID()
+	authorID := message.Author.ID
+	audit.Log(audit.Entry{
+		GuildID:    guildID,
+		EventType:  audit.EventFilterMatch,
+		ActorID:    &botID,
+		ActorKind:  audit.ActorBot,
+		TargetID:   &authorID,
+		TargetKind: audit.TargetUser,
+		Source:     audit.SourceGateway,
+		Details: map[string]any{
+			"channel_id":      message.ChannelID.String(),
+			"message_id":      message.ID.String(),
+			"content":         message.Content,
+			"matched":         match.Text,
+			"entry_id":        match.Entry.ID,
+			"pattern":         match.Entry.Pattern,
+			"action":          string(action),
+			"severity":        match.Entry.Severity,
+			"edited":          edited,
+			"target_username": message.Author.Username,
+		},
+	})
+
+	if guildSettings.ModeratorChannel == 0 {
+		return
+	}
+	_, err = client.Rest.CreateMessage(guildSettings.ModeratorChannel, discord.NewMessageCreate().
+		WithContent(wordFilterReport(message, match, edited)).
+		WithAllowedMentions(&discord.AllowedMentions{}))
+	if err != nil {
+		slog.Error(
+			"Failed to send word filter report to moderator channel.",
+			"err", err,
+			"guild", guildID,
+			"channel", guildSettings.ModeratorChannel,
+		)
+	}
+}
+
+// warnFilteredUser records an infraction for a filtered message and
+// sends the member the same warning DM as /warn. The reason deliberately
+// doesn't repeat the matched word.
+func warnFilteredUser(client *bot.Client, guildID snowflake.ID, severity float64, user discord.User) {
+	inf, err := logBotInfraction(client, guildID, user.ID, user.Username, "Your message was removed by the word filter.", severity, false)
+	if err != nil {
+		slog.Error("Failed to create word filter infraction.", "err", err, "guild", guildID, "user", user.ID)
+		return
+	}
+
+	guildName := guildID.String()
+	if guild, ok := client.Caches.Guild(guildID); ok {
+		guildName = guild.Name
+	}
+	channel, err := client.Rest.CreateDMChannel(user.ID)
+	if err != nil {
+		slog.Warn("Failed to open DM for word filter warning.", "err", err, "user", user.ID)
+		return
+	}
+	_, err = client.Rest.CreateMessage(channel.ID(), discord.NewMessageCreate().WithEmbeds(
+		discord.NewEmbedBuilder().
+			SetTitlef(`Warning in "%s"`, guildName).
+			SetDescription(inf.Reason).
+			SetTimestamp(inf.Timestamp).
+			Build(),
+	))
+	if err != nil {
+		slog.Warn("Failed to send word filter warning.", "err", err, "user", user.ID)
+	}
+}
+
+func wordFilterReport(message discord.Message, match wordfilter.Match, edited bool) string {
+	var outcome string
+	switch match.Action() {
+	case wordfilter.ActionDelete:
+		outcome = "The message was deleted."
+	case wordfilter.ActionWarn:
+		outcome = "The message was deleted and they were warned (severity " +
+			strconv.FormatFloat(match.Entry.Severity, 'f', -1, 64) + ")."
+	default:
+		outcome = "Nothing was done."
+	}
+
+	what := "A message"
+	if edited {
+		what = "An edited message"
+	}
+	report := fmt.Sprintf("🚫 **Word filter:** %s by <@%d> (%s) in <#%d> matched `%s`. %s\n>>> %s",
+		what, message.Author.ID, message.Author.Username, message.ChannelID, strings.ReplaceAll(match.Entry.Pattern, "`", "'"), outcome,
+		truncateContent(message.Content, maxPerMessageContent))
+	return truncateContent(report, maxMessageContent)
+}
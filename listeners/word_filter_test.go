@@ -0,0 +1,35 @@
+package listeners
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/wordfilter"
+)
+
+func TestWordFilterReport(t *testing.T) {
+	message := discord.Message{
+		ChannelID: 20,
+		Content:   "this is b4d",
+		Author:    discord.User{ID: 10, Username: "alice"},
+	}
+	match := wordfilter.Match{
+		Entry: model.WordFilterEntry{Pattern: "bad", Action: string(wordfilter.ActionWarn), Severity: 1.5},
+		Text:  "b4d",
+	}
+
+	report := wordFilterReport(message, match, false)
+	assert.Contains(t, report, "A message by <@10> (alice) in <#20> matched `bad`.")
+	assert.Contains(t, report, "they were warned (severity 1.5)")
+	assert.True(t, strings.HasSuffix(report, ">>> this is b4d"))
+
+	match.Entry.Action = string(wordfilter.ActionAlert)
+	match.Entry.Pattern = "a`b"
+	report = wordFilterReport(message, match, true)
+	assert.Contains(t, report, "An edited message")
+	assert.Contains(t, report, "matched `a'b`. Nothing was done.")
+}
@@ -0,0 +1,156 @@
+// Package logchannel posts embeds to the bot's log channels in batches.
+//
+// The first embed for a channel starts a short timer, and everything that
+// arrives for that channel before it fires goes out together, several
+// embeds to a message. A batch holds at most MaxBatch embeds; a burst that
+// produces more is summarised with a count instead of flooding the
+// channel.
+package logchannel
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+)
+
+const (
+	// flushDelay is how long a channel's first embed waits for others to
+	// batch with.
+	flushDelay = 3 * time.Second
+	// MaxBatch is the most embeds posted per flush; the rest are counted.
+	MaxBatch = 30
+	// Discord's limits for the embeds of one message.
+	maxEmbedsPerMessage = 10
+	maxEmbedLength      = 6000
+)
+
+// Sender posts a message to a channel.
+type Sender func(channelID snowflake.ID, message discord.MessageCreate) error
+
+// Batcher batches embeds per channel and posts them.
+type Batcher struct {
+	name     string
+	send     Sender
+	overflow func(dropped int) string
+
+	mu      sync.Mutex
+	batches map[snowflake.ID]*batch
+	stopped bool
+}
+
+type batch struct {
+	embeds []discord.Embed
+	// dropped counts embeds past MaxBatch.
+	dropped int
+	timer   *time.Timer
+}
+
+// NewBatcher returns a batcher that posts with send. overflow words the
+// notice posted after a batch that dropped embeds; name identifies the
+// batcher in logs.
+func NewBatcher(name string, send Sender, overflow func(dropped int) string) *Batcher {
+	return &Batcher{
+		name:     name,
+		send:     send,
+		overflow: overflow,
+		batches:  map[snowflake.ID]*batch{},
+	}
+}
+
+// Add queues embed for channelID.
+func (b *Batcher) Add(channelID snowflake.ID, embed discord.Embed) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.stopped {
+		return
+	}
+
+	bt, ok := b.batches[channelID]
+	if !ok {
+		bt = &batch{}
+		b.batches[channelID] = bt
+		bt.timer = time.AfterFunc(flushDelay, func() { b.flush(channelID) })
+	}
+	if len(bt.embeds) >= MaxBatch {
+		bt.dropped++
+		return
+	}
+	bt.embeds = append(bt.embeds, embed)
+}
+
+// flush posts the channel's pending batch.
+func (b *Batcher) flush(channelID snowflake.ID) {
+	b.mu.Lock()
+	bt, ok := b.batches[channelID]
+	delete(b.batches, channelID)
+	b.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	for _, embeds := range pack(bt.embeds) {
+		msg := discord.NewMessageCreate().WithEmbeds(embeds...).WithAllowedMentions(&discord.AllowedMentions{})
+		if err := b.send(channelID, msg); err != nil {
+			slog.Warn(b.name+": failed to post embeds", "err", err, "channel_id", channelID)
+			return
+		}
+	}
+	if bt.dropped > 0 {
+		msg := discord.NewMessageCreate().WithContent(b.overflow(bt.dropped))
+		if err := b.send(channelID, msg); err != nil {
+			slog.Warn(b.name+": failed to post overflow notice", "err", err, "channel_id", channelID)
+		}
+	}
+}
+
+// Stop posts every pending batch and drops embeds queued afterwards.
+func (b *Batcher) Stop() {
+	b.mu.Lock()
+	b.stopped = true
+	var channels []snowflake.ID
+	for channelID, bt := range b.batches {
+		bt.timer.Stop()
+		channels = append(channels, channelID)
+	}
+	b.mu.Unlock()
+
+	for _, channelID := range channels {
+		b.flush(channelID)
+	}
+}
+
+// pack groups embeds into messages within Discord's embed limits.
+func pack(embeds []discord.Embed) [][]discord.Embed {
+	var messages [][]discord.Embed
+	var current []discord.Embed
+	length := 0
+	for _, e := range embeds {
+		n := embedLength(e)
+		if len(current) == maxEmbedsPerMessage || (len(current) > 0 && length+n > maxEmbedLength) {
+			messages = append(messages, current)
+			current, length = nil, 0
+		}
+		current = append(current, e)
+		length += n
+	}
+	if len(current) > 0 {
+		messages = append(messages, current)
+	}
+	return messages
+}
+
+// embedLength is what Discord counts toward its 6000 character limit for
+// the embeds of a message.
+func embedLength(e discord.Embed) int {
+	n := len([]rune(e.Title)) + len([]rune(e.Description))
+	for _, f := range e.Fields {
+		n += len([]rune(f.Name)) + len([]rune(f.Value))
+	}
+	if e.Footer != nil {
+		n += len([]rune(e.Footer.Text))
+	}
+	return n
+}
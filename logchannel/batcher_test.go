@@ -0,0 +1,71 @@
+package logchannel
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sent struct {
+	channelID snowflake.ID
+	message   discord.MessageCreate
+}
+
+func testBatcher() (*Batcher, *[]sent) {
+	var mu sync.Mutex
+	var out []sent
+	b := NewBatcher("test", func(channelID snowflake.ID, message discord.MessageCreate) error {
+		mu.Lock()
+		defer mu.Unlock()
+		out = append(out, sent{channelID, message})
+		return nil
+	}, func(dropped int) string {
+		return strings.Repeat("+", dropped)
+	})
+	return b, &out
+}
+
+func TestBatcher_PerChannel(t *testing.T) {
+	b, out := testBatcher()
+	b.Add(1, discord.Embed{Title: "a"})
+	b.Add(2, discord.Embed{Title: "b"})
+	b.Add(1, discord.Embed{Title: "c"})
+	b.Stop()
+
+	require.Len(t, *out, 2)
+	byChannel := map[snowflake.ID]int{}
+	for _, s := range *out {
+		byChannel[s.channelID] = len(s.message.Embeds)
+	}
+	assert.Equal(t, map[snowflake.ID]int{1: 2, 2: 1}, byChannel)
+
+	b.Add(1, discord.Embed{Title: "after stop"})
+	assert.Len(t, *out, 2)
+}
+
+func TestBatcher_Overflow(t *testing.T) {
+	b, out := testBatcher()
+	for range MaxBatch + 3 {
+		b.Add(1, discord.Embed{Title: "x"})
+	}
+	b.Stop()
+
+	last := (*out)[len(*out)-1]
+	assert.Equal(t, "+++", last.message.Content)
+}
+
+func TestPack(t *testing.T) {
+	small := discord.Embed{Description: "x"}
+	big := discord.Embed{Description: strings.Repeat("x", 2500)}
+
+	assert.Len(t, pack(make([]discord.Embed, 25)), 3, "at most 10 embeds a message")
+	msgs := pack([]discord.Embed{big, big, big, small})
+	require.Len(t, msgs, 2, "at most 6000 characters a message")
+	assert.Len(t, msgs[0], 2)
+	assert.Len(t, msgs[1], 2)
+}
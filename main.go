@@ -20,7 +20,10 @@ import (
 	"github.com/disgoorg/snowflake/v2"
 	"github.com/spf13/viper"
 
+	"github.com/NLLCommunity/heimdallr/antispam"
 	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/auditstream"
+	"github.com/NLLCommunity/heimdallr/auditwebhook"
 	_ "github.com/NLLCommunity/heimdallr/config"
 	"github.com/NLLCommunity/heimdallr/interactions"
 	"github.com/NLLCommunity/heimdallr/interactions/admin"
@@ -29,6 +32,8 @@ import (
 	"github.com/NLLCommunity/heimdallr/interactions/gatekeep"
 	"github.com/NLLCommunity/heimdallr/interactions/infractions"
 	"github.com/NLLCommunity/heimdallr/interactions/kick"
+	"github.com/NLLCommunity/heimdallr/interactions/lock"
+	"github.com/NLLCommunity/heimdallr/interactions/lockdown"
 	"github.com/NLLCommunity/heimdallr/interactions/modmail"
 	"github.com/NLLCommunity/heimdallr/interactions/ping"
 	"github.com/NLLCommunity/heimdallr/interactions/prune"
@@ -36,6 +41,7 @@ import (
 	"github.com/NLLCommunity/heimdallr/interactions/role_button"
 	"github.com/NLLCommunity/heimdallr/interactions/timeout"
 	"github.com/NLLCommunity/heimdallr/listeners"
+	"github.com/NLLCommunity/heimdallr/messagelog"
 	"github.com/NLLCommunity/heimdallr/model"
 	"github.com/NLLCommunity/heimdallr/scheduled_tasks"
 	"github.com/NLLCommunity/heimdallr/web"
@@ -43,6 +49,11 @@ import (
 
 var rmGlobalCommands = flag.Bool("rm-global-commands", false, "Remove global commands")
 var rmGuildCommands = flag.Uint64("rm-guild-commands", 0, "Remove guild commands for guild specified by ID")
+var verifyAuditLogFlag = flag.Bool("verify-audit-log", false, "Verify the audit log hash chain of every guild, then exit")
+var searchAuditArchiveFlag = flag.Uint64("search-audit-archive", 0, "Print archived audit log entries for guild specified by ID as JSON lines, then exit")
+var archiveFromFlag = flag.String("archive-from", "", "First day (YYYY-MM-DD) to include with -search-audit-archive")
+var archiveToFlag = flag.String("archive-to", "", "Last day (YYYY-MM-DD) to include with -search-audit-archive")
+var archiveQueryFlag = flag.String("archive-query", "", "Text to match with -search-audit-archive")
 
 var intents = gateway.IntentGuilds |
 	gateway.IntentGuildMembers |
@@ -65,6 +76,17 @@ var intents = gateway.IntentGuilds |
 
 func main() {
 	flag.Parse()
+	if *verifyAuditLogFlag {
+		if err := openDatabase(); err != nil {
+			panic(fmt.Errorf("failed to initialize database: %w", err))
+		}
+		os.Exit(verifyAuditLog(os.Stdout))
+	}
+	if *searchAuditArchiveFlag != 0 {
+		os.Exit(searchAuditArchive(os.Stdout, scheduled_tasks.ArchiveDir(),
+			snowflake.ID(*searchAuditArchiveFlag), *archiveFromFlag, *archiveToFlag, *archiveQueryFlag))
+	}
+
 	token := viper.GetString("bot.token")
 	if token == "" {
 		panic("No bot token found in config file. Please set 'bot.token'.")
@@ -76,22 +98,7 @@ func main() {
 		return
 	}
 
-	// glebarez/sqlite only honours pragmas passed via the `_pragma` DSN
-	// parameter — `_journal_mode=WAL` (the older nokia-style form) is
-	// silently dropped, leaving the DB in rollback-journal mode. Verified
-	// empirically: the wal_checkpoint pragma in the prune task only does
-	// anything once WAL is actually enabled.
-	//
-	// Use & if the configured path already carries a query string (someone
-	// runs a URI-style DSN), ? otherwise. Without this, a configured
-	// "file:bot.db?cache=shared" would end up with two ? separators and
-	// the journal_mode pragma would be silently ignored.
-	dbPath := viper.GetString("bot.db")
-	sep := "?"
-	if strings.Contains(dbPath, "?") {
-		sep = "&"
-	}
-	_, err := model.InitDB(dbPath + sep + "_pragma=journal_mode(WAL)&_pragma=analysis_limit(400)")
+	err := openDatabase()
 	if err != nil {
 		panic(fmt.Errorf("failed to initialize database: %w", err))
 	}
@@ -113,6 +120,8 @@ func main() {
 		gatekeep.Register,
 		infractions.Register,
 		kick.Register,
+		lock.Register,
+		lockdown.Register,
 		ping.Register,
 		prune.Register,
 		quote.Register,
@@ -146,19 +155,58 @@ func main() {
 			},
 		),
 		bot.WithEventListenerFunc(listeners.OnWarnedUserJoin),
+		bot.WithEventListenerFunc(listeners.OnRaidDetectionJoin),
 		bot.WithEventListenerFunc(listeners.OnGatekeepUserJoin),
+		// Before the invite tracker, which forgets deleted invites.
+		bot.WithEventListenerFunc(listeners.OnAuditInviteCreate),
+		bot.WithEventListenerFunc(listeners.OnAuditInviteDelete),
+		bot.WithEventListenerFunc(listeners.OnInviteTrackerGuildReady),
+		bot.WithEventListenerFunc(listeners.OnInviteTrackerGuildJoin),
+		bot.WithEventListenerFunc(listeners.OnInviteTrackerInviteCreate),
+		bot.WithEventListenerFunc(listeners.OnInviteTrackerInviteDelete),
 		bot.WithEventListenerFunc(listeners.OnUserJoin),
 		bot.WithEventListenerFunc(listeners.OnUserLeave),
 		bot.WithEventListenerFunc(listeners.OnMemberBan),
 		bot.WithEventListenerFunc(listeners.OnAuditLogKick),
 		bot.WithEventListenerFunc(listeners.OnAntispamMessageCreate),
+		bot.WithEventListenerFunc(listeners.OnCoordinatedSpamMessageCreate),
+		bot.WithEventListenerFunc(listeners.OnWordFilterMessageCreate),
+		bot.WithEventListenerFunc(listeners.OnWordFilterMessageUpdate),
 		bot.WithEventListenerFunc(listeners.OnAuditMemberUpdate),
 		bot.WithEventListenerFunc(listeners.OnAuditMessageUpdate),
 		bot.WithEventListenerFunc(listeners.OnAuditMessageDelete),
+		// After OnAuditMessageUpdate, which reads the pre-edit content.
+		bot.WithEventListenerFunc(listeners.OnMessageStoreCreate),
+		bot.WithEventListenerFunc(listeners.OnMessageStoreUpdate),
 		bot.WithEventListenerFunc(listeners.OnAuditMemberBan),
 		bot.WithEventListenerFunc(listeners.OnAuditGuildUnban),
+		bot.WithEventListenerFunc(listeners.OnAuditChannelCreate),
+		bot.WithEventListenerFunc(listeners.OnAuditChannelUpdate),
+		bot.WithEventListenerFunc(listeners.OnAuditChannelDelete),
+		bot.WithEventListenerFunc(listeners.OnAuditRoleCreate),
+		bot.WithEventListenerFunc(listeners.OnAuditRoleUpdate),
+		bot.WithEventListenerFunc(listeners.OnAuditRoleDelete),
+		bot.WithEventListenerFunc(listeners.OnAuditEmojiCreate),
+		bot.WithEventListenerFunc(listeners.OnAuditEmojiUpdate),
+		bot.WithEventListenerFunc(listeners.OnAuditEmojiDelete),
+		bot.WithEventListenerFunc(listeners.OnAuditStickerCreate),
+		bot.WithEventListenerFunc(listeners.OnAuditStickerUpdate),
+		bot.WithEventListenerFunc(listeners.OnAuditStickerDelete),
+		bot.WithEventListenerFunc(listeners.OnAuditIntegrationCreate),
+		bot.WithEventListenerFunc(listeners.OnAuditIntegrationDelete),
+		bot.WithEventListenerFunc(listeners.OnAuditVoiceStateUpdate),
+		bot.WithEventListenerFunc(listeners.OnAutoModGuildReady),
+		bot.WithEventListenerFunc(listeners.OnAutoModGuildJoin),
+		bot.WithEventListenerFunc(listeners.OnAuditAutoModRuleCreate),
+		bot.WithEventListenerFunc(listeners.OnAuditAutoModRuleUpdate),
+		bot.WithEventListenerFunc(listeners.OnAuditAutoModRuleDelete),
+		bot.WithEventListenerFunc(listeners.OnAuditAutoModAction),
+		bot.WithEventListenerFunc(listeners.OnAuditThreadCreate),
+		bot.WithEventListenerFunc(listeners.OnAuditThreadUpdate),
+		bot.WithEventListenerFunc(listeners.OnAuditThreadDelete),
 		bot.WithEventListenerFunc(listeners.OnAuditNativeEnrichment),
 		bot.WithGatewayConfigOpts(gateway.WithIntents(intents)),
+		bot.WithEventManagerConfigOpts(bot.WithGatewayHandlers(listeners.GatewayHandlers())),
 		bot.WithCacheConfigOpts(
 			cache.WithCaches(cache.FlagsAll),
 		),
@@ -183,6 +231,18 @@ func main() {
 		panic(fmt.Errorf("failed to sync commands: %w", err))
 	}
 
+	messagelog.Start(client)
+	auditstream.Start(client)
+	if err := auditwebhook.Start(); err != nil {
+		slog.Error("Failed to start audit webhooks.", "err", err)
+	}
+
+	// Load the blocklist before messages start arriving, so none are
+	// checked against an empty list.
+	if err := antispam.PhishingBlocklist.SetPath(viper.GetString("antispam.phishing_blocklist")); err != nil {
+		slog.Error("Failed to load phishing blocklist.", "err", err)
+	}
+
 	err = client.OpenGateway(context.Background())
 	if err != nil {
 		panic(fmt.Errorf("failed to open gateway: %w", err))
@@ -192,6 +252,9 @@ func main() {
 	removeStalePrunesTask := scheduled_tasks.RemoveStalePendingPrunes()
 	pruneAuditLogTask := scheduled_tasks.PruneAuditLogScheduledTask()
 	removeExpiredMessagesTask := scheduled_tasks.RemoveExpiredMessagesInTTLCache()
+	removeExpiredJoinInvitesTask := scheduled_tasks.RemoveExpiredJoinInvites()
+	removeExpiredChannelLocksTask := scheduled_tasks.RemoveExpiredChannelLocksScheduledTask(client)
+	reloadPhishingBlocklistTask := scheduled_tasks.ReloadPhishingBlocklistScheduledTask()
 
 	webCtx, cancelWeb := context.WithCancel(context.Background())
 	defer cancelWeb()
@@ -225,6 +288,9 @@ func main() {
 	removeStalePrunesTask.Stop()
 	pruneAuditLogTask.Stop()
 	removeExpiredMessagesTask.Stop()
+	removeExpiredJoinInvitesTask.Stop()
+	removeExpiredChannelLocksTask.Stop()
+	reloadPhishingBlocklistTask.Stop()
 	// Close ONLY the gateway first so listeners stop firing and can't
 	// refill the audit buffer after the flush below. We deliberately keep
 	// the REST client and caches alive: in-flight web requests still need
@@ -235,6 +301,13 @@ func main() {
 	// before the process exits. Best-effort: failures inside FlushPending
 	// are logged at warn but don't block shutdown.
 	audit.FlushPending()
+	// The flush above can queue message log and audit stream cards; post
+	// them while the REST client is still open.
+	messagelog.Stop()
+	auditstream.Stop()
+	// Entries the flush queued for webhooks are delivered on the next
+	// start.
+	auditwebhook.Stop()
 	cancelWeb()
 	<-webDone
 	// All writers stopped; refresh the SQLite query planner stats per the
@@ -265,3 +338,25 @@ func getLogLevel(level string) slog.Level {
 		return slog.LevelInfo
 	}
 }
+
+// openDatabase opens the configured database.
+//
+// glebarez/sqlite only honours pragmas passed via the `_pragma` DSN
+// parameter — `_journal_mode=WAL` (the older nokia-style form) is
+// silently dropped, leaving the DB in rollback-journal mode. Verified
+// empirically: the wal_checkpoint pragma in the prune task only does
+// anything once WAL is actually enabled.
+//
+// Use & if the configured path already carries a query string (someone
+// runs a URI-style DSN), ? otherwise. Without this, a configured
+// "file:bot.db?cache=shared" would end up with two ? separators and
+// the journal_mode pragma would be silently ignored.
+func openDatabase() error {
+	dbPath := viper.GetString("bot.db")
+	sep := "?"
+	if strings.Contains(dbPath, "?") {
+		sep = "&"
+	}
+	_, err := model.InitDB(dbPath + sep + "_pragma=journal_mode(WAL)&_pragma=analysis_limit(400)")
+	return err
+}
@@ -1,9 +1,16 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
 )
 
 func TestGetLogLevel(t *testing.T) {
@@ -56,3 +63,55 @@ func TestGetLogLevel(t *testing.T) {
 		})
 	}
 }
+
+func TestVerifyAuditLog(t *testing.T) {
+	_, err := model.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	assert.Equal(t, 0, verifyAuditLog(&out))
+	assert.Contains(t, out.String(), "audit log is empty")
+
+	for range 2 {
+		require.NoError(t, model.CreateAuditLogEntry(&model.AuditLogEntry{
+			GuildID: 1, Category: "guild", EventType: "guild.ban", ActorKind: "user", TargetKind: "user", Source: "gateway",
+		}))
+	}
+	out.Reset()
+	assert.Equal(t, 0, verifyAuditLog(&out))
+	assert.Contains(t, out.String(), "guild 1: ok, 2 entries verified")
+
+	require.NoError(t, model.DB.Model(&model.AuditLogEntry{}).Where("1 = 1").Update("reason", "rewritten").Error)
+	out.Reset()
+	assert.Equal(t, 1, verifyAuditLog(&out))
+	assert.Contains(t, out.String(), "guild 1: BROKEN (2 problems)")
+	assert.Contains(t, out.String(), "edited")
+}
+
+func TestSearchAuditArchive(t *testing.T) {
+	_, err := model.InitDB(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	dir := t.TempDir()
+
+	var out bytes.Buffer
+	assert.Equal(t, 2, searchAuditArchive(&out, "", 1, "", "", ""))
+	out.Reset()
+	assert.Equal(t, 2, searchAuditArchive(&out, dir, 1, "yesterday", "", ""))
+
+	entry := &model.AuditLogEntry{
+		GuildID: 1, Category: "guild", EventType: "guild.ban", ActorKind: "user", TargetKind: "user",
+		Source: "gateway", Reason: "raid account",
+	}
+	require.NoError(t, model.CreateAuditLogEntry(entry))
+	n, err := audit.Archive(context.Background(), dir, 1, "guild", entry.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	out.Reset()
+	assert.Equal(t, 0, searchAuditArchive(&out, dir, 1, "", "", "RAID"))
+	assert.Contains(t, out.String(), `"reason":"raid account"`)
+
+	out.Reset()
+	assert.Equal(t, 0, searchAuditArchive(&out, dir, 1, "", "", "nothing like it"))
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,127 @@
+package messagelog
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+)
+
+// maxCardText is the most runes of message content a card shows. It
+// keeps a full batch of cards under Discord's 6000 character limit for a
+// message's embeds.
+const maxCardText = 1000
+
+const (
+	colorDeleted = 0xED4245
+	colorEdited  = 0xFEE75C
+)
+
+// card builds the feed card for an audit entry, and returns the channel
+// the message was in. ok is false for entries the feed doesn't show.
+func card(entry audit.Entry, now time.Time) (embed discord.Embed, channelID snowflake.ID, ok bool) {
+	d := entry.Details
+	channelID, err := snowflake.Parse(detail(d, "channel_id"))
+	if err != nil {
+		return discord.Embed{}, 0, false
+	}
+	authorID, _ := snowflake.Parse(detail(d, "author_id"))
+
+	b := discord.NewEmbedBuilder().SetTimestamp(now)
+	switch entry.EventType {
+	case audit.EventMessageDelete:
+		content := truncate(escapeMarkdown(detail(d, "before_content")), maxCardText)
+		if content == "" {
+			content = "*Content unknown: the message was not cached or stored.*"
+		}
+		b.SetTitle("Message deleted").
+			SetColor(colorDeleted).
+			SetDescription(content).
+			AddField("Author", userMention(authorID, detail(d, "author_username")), true).
+			AddField("Channel", fmt.Sprintf("<#%d>", channelID), true)
+		if mod := moderator(entry, authorID); mod != "" {
+			b.AddField("Deleted by", mod, true)
+		}
+
+	case audit.EventMessageBulkDelete:
+		b.SetTitle("Messages bulk deleted").
+			SetColor(colorDeleted).
+			SetDescription(bulkDeleteText(d)).
+			AddField("Messages", fmt.Sprint(d["count"]), true).
+			AddField("Channel", fmt.Sprintf("<#%d>", channelID), true)
+		if mod := moderator(entry, 0); mod != "" {
+			b.AddField("Deleted by", mod, true)
+		}
+		// The target is the channel, not a message.
+		return b.Build(), channelID, true
+
+	case audit.EventMessageEdit:
+		if entry.TargetID == nil {
+			return discord.Embed{}, 0, false
+		}
+		b.SetTitle("Message edited").
+			SetColor(colorEdited).
+			SetURL(fmt.Sprintf("https://discord.com/channels/%d/%d/%d", entry.GuildID, channelID, *entry.TargetID)).
+			SetDescription(WordDiff(detail(d, "before_content"), detail(d, "after_content"), maxCardText)).
+			AddField("Author", userMention(authorID, detail(d, "actor_username")), true).
+			AddField("Channel", fmt.Sprintf("<#%d>", channelID), true)
+
+	default:
+		return discord.Embed{}, 0, false
+	}
+
+	if entry.TargetID != nil {
+		b.SetFooterText(fmt.Sprintf("Message ID: %d", *entry.TargetID))
+	}
+	return b.Build(), channelID, true
+}
+
+// moderator names who deleted a message, when native audit enrichment
+// found someone other than the author.
+func moderator(entry audit.Entry, authorID snowflake.ID) string {
+	if entry.ActorKind != audit.ActorUser || entry.ActorID == nil || *entry.ActorID == authorID {
+		return ""
+	}
+	return userMention(*entry.ActorID, detail(entry.Details, "actor_username"))
+}
+
+func userMention(id snowflake.ID, username string) string {
+	if id == 0 {
+		return "Unknown"
+	}
+	if username == "" {
+		return fmt.Sprintf("<@%d>", id)
+	}
+	return fmt.Sprintf("<@%d> (%s)", id, escapeMarkdown(username))
+}
+
+// bulkDeleteText lists the bulk-deleted messages the bot had content for,
+// one line each, cut off at maxCardText.
+func bulkDeleteText(d map[string]any) string {
+	messages, _ := d["messages"].([]map[string]any)
+	var lines []string
+	for _, m := range messages {
+		lines = append(lines, "**"+escapeMarkdown(detail(m, "author_username"))+"**: "+escapeMarkdown(detail(m, "content")))
+	}
+	if len(lines) == 0 {
+		return "*Content unknown: the messages were not cached or stored.*"
+	}
+	return truncate(strings.Join(lines, "\n"), maxCardText)
+}
+
+func detail(d map[string]any, key string) string {
+	s, _ := d[key].(string)
+	return s
+}
+
+func truncate(s string, maxRunes int) string {
+	r := []rune(s)
+	if len(r) <= maxRunes {
+		return s
+	}
+	return string(r[:maxRunes-1]) + "…"
+}
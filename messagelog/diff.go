@@ -0,0 +1,139 @@
+package messagelog
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+type diffOp int
+
+const (
+	opEqual diffOp = iota
+	opRemove
+	opAdd
+)
+
+type diffPart struct {
+	op   diffOp
+	text string
+}
+
+var tokenRe = regexp.MustCompile(`\s+|\S+`)
+
+// maxDiffCells bounds the LCS table. Longer edits are shown as the whole
+// old text removed and the whole new text added.
+const maxDiffCells = 1 << 20
+
+// WordDiff renders the change from before to after in Discord markdown:
+// removed words struck through, added words in bold. The result is cut
+// at a word boundary to at most maxRunes runes, ending with "…" if
+// anything was left out. Markdown in the messages themselves is escaped
+// so it can't be confused with the markup.
+func WordDiff(before, after string, maxRunes int) string {
+	var b strings.Builder
+	runes := 0
+	for _, p := range diffWords(before, after) {
+		var piece string
+		switch p.op {
+		case opEqual:
+			piece = escapeMarkdown(p.text)
+		case opRemove:
+			piece = wrap(p.text, "~~")
+		case opAdd:
+			piece = wrap(p.text, "**")
+		}
+		n := utf8.RuneCountInString(piece)
+		if runes+n > maxRunes-1 {
+			b.WriteString("…")
+			break
+		}
+		b.WriteString(piece)
+		runes += n
+	}
+	return b.String()
+}
+
+// wrap puts marker around text, keeping leading and trailing whitespace
+// outside it, since Discord ignores "** word**".
+func wrap(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	start := strings.Index(text, trimmed)
+	return text[:start] + marker + escapeMarkdown(trimmed) + marker + text[start+len(trimmed):]
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`, ">", `\>`,
+)
+
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// diffWords splits both texts into words and whitespace and returns the
+// edit script between them, with adjacent parts of the same kind merged.
+func diffWords(before, after string) []diffPart {
+	a := tokenRe.FindAllString(before, -1)
+	b := tokenRe.FindAllString(after, -1)
+
+	var parts []diffPart
+	add := func(op diffOp, text string) {
+		if n := len(parts); n > 0 && parts[n-1].op == op {
+			parts[n-1].text += text
+			return
+		}
+		parts = append(parts, diffPart{op, text})
+	}
+
+	if (len(a)+1)*(len(b)+1) > maxDiffCells {
+		if before != "" {
+			add(opRemove, before)
+		}
+		if after != "" {
+			add(opAdd, after)
+		}
+		return parts
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			add(opEqual, a[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			add(opRemove, a[i])
+			i++
+		default:
+			add(opAdd, b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		add(opRemove, a[i])
+	}
+	for ; j < len(b); j++ {
+		add(opAdd, b[j])
+	}
+	return parts
+}
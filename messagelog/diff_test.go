@@ -0,0 +1,46 @@
+package messagelog
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWordDiff(t *testing.T) {
+	cases := []struct {
+		name          string
+		before, after string
+		want          string
+	}{
+		{"unchanged", "hello there", "hello there", "hello there"},
+		{"word replaced", "the quick fox", "the slow fox", "the ~~quick~~**slow** fox"},
+		{"word added", "hello", "hello world", "hello **world**"},
+		{"word removed", "hello big world", "hello world", "hello ~~big~~ world"},
+		{"from empty", "", "new", "**new**"},
+		{"markdown escaped", "a *b*", "a *c*", `a ~~\*b\*~~**\*c\***`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, WordDiff(c.before, c.after, 1000))
+		})
+	}
+}
+
+func TestWordDiff_Truncates(t *testing.T) {
+	before := strings.Repeat("word ", 100)
+	after := strings.Repeat("other ", 100)
+	got := WordDiff(before, after, 50)
+	assert.LessOrEqual(t, utf8.RuneCountInString(got), 50)
+	assert.True(t, strings.HasSuffix(got, "…"))
+}
+
+func TestDiffWords_LongTextFallsBack(t *testing.T) {
+	before := strings.Repeat("a ", 2000)
+	after := strings.Repeat("b ", 2000)
+	parts := diffWords(before, after)
+	assert.Len(t, parts, 2)
+	assert.Equal(t, opRemove, parts[0].op)
+	assert.Equal(t, opAdd, parts[1].op)
+}
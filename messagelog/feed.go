@@ -0,0 +1,115 @@
+// Package messagelog posts a card to a guild's message log channel for
+// each message edit and deletion written to the audit log. A bulk delete
+// is one card listing the messages. Cards are batched with
+// logchannel.Batcher, so a burst is summarised with a count instead of
+// flooding the channel.
+package messagelog
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/logchannel"
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// Settings returns the guild's log channel and the channels it ignores,
+// with threads resolved to their parent channel.
+type Settings func(guildID, channelID snowflake.ID) (logChannel snowflake.ID, ignored bool)
+
+// Feed turns audit entries into cards and batches them per log channel.
+type Feed struct {
+	batcher  *logchannel.Batcher
+	settings Settings
+	now      func() time.Time
+}
+
+// NewFeed returns a feed that posts with send and looks up each guild's
+// settings with settings.
+func NewFeed(send logchannel.Sender, settings Settings) *Feed {
+	return &Feed{
+		batcher:  logchannel.NewBatcher("message log", send, overflowNotice),
+		settings: settings,
+		now:      time.Now,
+	}
+}
+
+func overflowNotice(dropped int) string {
+	return fmt.Sprintf("-# %d more edits and deletions were left out to keep the feed readable. See the audit log for all of them.", dropped)
+}
+
+// Handle is an audit.Sink. It queues a card for message edits and
+// deletions in guilds with a log channel.
+func (f *Feed) Handle(entry audit.Entry) {
+	switch entry.EventType {
+	case audit.EventMessageEdit, audit.EventMessageDelete, audit.EventMessageBulkDelete:
+	default:
+		return
+	}
+	embed, channelID, ok := card(entry, f.now())
+	if !ok {
+		return
+	}
+	logChannel, ignored := f.settings(entry.GuildID, channelID)
+	// Edits in the log channel itself are the feed's own business.
+	if logChannel == 0 || ignored || channelID == logChannel {
+		return
+	}
+	f.batcher.Add(logChannel, embed)
+}
+
+// Stop posts every pending batch and drops cards queued afterwards.
+func (f *Feed) Stop() {
+	f.batcher.Stop()
+}
+
+var feed *Feed
+
+// Start posts the message log feed with client and subscribes it to the
+// audit log.
+func Start(client *bot.Client) {
+	feed = NewFeed(
+		func(channelID snowflake.ID, message discord.MessageCreate) error {
+			_, err := client.Rest.CreateMessage(channelID, message)
+			return err
+		},
+		func(guildID, channelID snowflake.ID) (snowflake.ID, bool) {
+			return guildSettings(client, guildID, channelID)
+		},
+	)
+	audit.AddSink(feed.Handle)
+}
+
+// Stop posts the cards still waiting in their batches. Call it after the
+// audit log's pending entries have been flushed.
+func Stop() {
+	if feed != nil {
+		feed.Stop()
+	}
+}
+
+func guildSettings(client *bot.Client, guildID, channelID snowflake.ID) (snowflake.ID, bool) {
+	settings, err := model.GetGuildSettings(guildID)
+	if err != nil {
+		slog.Warn("message log: failed to read guild settings", "err", err, "guild_id", guildID)
+		return 0, false
+	}
+	if settings.MessageLogChannel == 0 {
+		return 0, false
+	}
+	ignored, err := model.GetMessageLogIgnoredChannels(guildID)
+	if err != nil {
+		slog.Warn("message log: failed to read ignored channels", "err", err, "guild_id", guildID)
+	}
+	if thread, ok := client.Caches.GuildThread(channelID); ok && thread.ParentID() != nil {
+		channelID = *thread.ParentID()
+	}
+	return settings.MessageLogChannel, slices.Contains(ignored, channelID)
+}
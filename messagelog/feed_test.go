@@ -0,0 +1,173 @@
+package messagelog
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/logchannel"
+)
+
+type sent struct {
+	channelID snowflake.ID
+	message   discord.MessageCreate
+}
+
+func testFeed(logChannel snowflake.ID, ignored ...snowflake.ID) (*Feed, *[]sent) {
+	var mu sync.Mutex
+	var out []sent
+	f := NewFeed(
+		func(channelID snowflake.ID, message discord.MessageCreate) error {
+			mu.Lock()
+			defer mu.Unlock()
+			out = append(out, sent{channelID, message})
+			return nil
+		},
+		func(_, channelID snowflake.ID) (snowflake.ID, bool) {
+			for _, c := range ignored {
+				if c == channelID {
+					return logChannel, true
+				}
+			}
+			return logChannel, false
+		},
+	)
+	f.now = func() time.Time { return time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC) }
+	return f, &out
+}
+
+func deleteEntry(channelID, messageID snowflake.ID, actorID snowflake.ID, content string) audit.Entry {
+	return audit.Entry{
+		GuildID:   1,
+		EventType: audit.EventMessageDelete,
+		TargetID:  &messageID,
+		ActorID:   &actorID,
+		ActorKind: audit.ActorUser,
+		Details: map[string]any{
+			"channel_id":      channelID.String(),
+			"author_id":       "7",
+			"author_username": "alice",
+			"actor_username":  "mod",
+			"before_content":  content,
+		},
+	}
+}
+
+func TestCard_Delete(t *testing.T) {
+	embed, channelID, ok := card(deleteEntry(5, 10, 9, "bye"), time.Now())
+	require.True(t, ok)
+	assert.Equal(t, snowflake.ID(5), channelID)
+	assert.Equal(t, "Message deleted", embed.Title)
+	assert.Equal(t, "bye", embed.Description)
+	require.Len(t, embed.Fields, 3)
+	assert.Equal(t, "<@7> (alice)", embed.Fields[0].Value)
+	assert.Equal(t, "Deleted by", embed.Fields[2].Name)
+	assert.Equal(t, "<@9> (mod)", embed.Fields[2].Value)
+
+	embed, _, ok = card(deleteEntry(5, 10, 7, "bye"), time.Now())
+	require.True(t, ok)
+	assert.Len(t, embed.Fields, 2, "self-deletes name no moderator")
+}
+
+func TestCard_Edit(t *testing.T) {
+	messageID := snowflake.ID(10)
+	authorID := snowflake.ID(7)
+	embed, _, ok := card(audit.Entry{
+		GuildID:   1,
+		EventType: audit.EventMessageEdit,
+		TargetID:  &messageID,
+		ActorID:   &authorID,
+		ActorKind: audit.ActorUser,
+		Details: map[string]any{
+			"channel_id":     "5",
+			"author_id":      "7",
+			"actor_username": "alice",
+			"before_content": "see you soon",
+			"after_content":  "see you later",
+		},
+	}, time.Now())
+	require.True(t, ok)
+	assert.Equal(t, "Message edited", embed.Title)
+	assert.Equal(t, "see you ~~soon~~**later**", embed.Description)
+	assert.Equal(t, "https://discord.com/channels/1/5/10", embed.URL)
+
+	_, _, ok = card(audit.Entry{EventType: audit.EventGuildBan}, time.Now())
+	assert.False(t, ok)
+}
+
+func TestCard_BulkDelete(t *testing.T) {
+	channelID := snowflake.ID(5)
+	entry := audit.Entry{
+		GuildID:    1,
+		EventType:  audit.EventMessageBulkDelete,
+		TargetID:   &channelID,
+		TargetKind: audit.TargetChannel,
+		ActorKind:  audit.ActorUnknown,
+		Details: map[string]any{
+			"channel_id":  "5",
+			"count":       2,
+			"message_ids": []string{"10", "11"},
+			"messages": []map[string]any{
+				{"id": "10", "author_username": "alice", "content": "hi *there*"},
+			},
+		},
+	}
+	embed, got, ok := card(entry, time.Now())
+	require.True(t, ok)
+	assert.Equal(t, channelID, got)
+	assert.Equal(t, "Messages bulk deleted", embed.Title)
+	assert.Equal(t, `**alice**: hi \*there\*`, embed.Description)
+	require.Len(t, embed.Fields, 2)
+	assert.Equal(t, "2", embed.Fields[0].Value)
+
+	delete(entry.Details, "messages")
+	embed, _, _ = card(entry, time.Now())
+	assert.Contains(t, embed.Description, "Content unknown")
+}
+
+func TestFeed_BatchesAndSkips(t *testing.T) {
+	f, out := testFeed(100, 6)
+	f.Handle(deleteEntry(5, 10, 7, "one"))
+	f.Handle(deleteEntry(5, 11, 7, "two"))
+	f.Handle(deleteEntry(6, 12, 7, "ignored channel"))
+	f.Handle(deleteEntry(100, 13, 7, "in the log channel"))
+	f.Handle(audit.Entry{GuildID: 1, EventType: audit.EventGuildBan})
+	f.Stop()
+
+	require.Len(t, *out, 1, "both cards go out in one message")
+	assert.Equal(t, snowflake.ID(100), (*out)[0].channelID)
+	assert.Len(t, (*out)[0].message.Embeds, 2)
+
+	f.Handle(deleteEntry(5, 14, 7, "after stop"))
+	assert.Len(t, *out, 1)
+}
+
+func TestFeed_Overflow(t *testing.T) {
+	f, out := testFeed(100)
+	for i := range logchannel.MaxBatch + 5 {
+		f.Handle(deleteEntry(5, snowflake.ID(1000+i), 7, "purged"))
+	}
+	f.Stop()
+
+	cards := 0
+	for _, s := range (*out)[:len(*out)-1] {
+		cards += len(s.message.Embeds)
+	}
+	assert.Equal(t, logchannel.MaxBatch, cards)
+	last := (*out)[len(*out)-1]
+	assert.True(t, strings.HasPrefix(last.message.Content, "-# 5 more edits and deletions"), last.message.Content)
+}
+
+func TestFeed_NoLogChannel(t *testing.T) {
+	f, out := testFeed(0)
+	f.Handle(deleteEntry(5, 10, 7, "one"))
+	f.Stop()
+	assert.Empty(t, *out)
+}
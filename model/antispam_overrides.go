@@ -0,0 +1,115 @@
+package model
+
+import (
+	"errors"
+
+	"github.com/disgoorg/snowflake/v2"
+	"gorm.io/gorm"
+)
+
+// AntiSpamExemptRole is a role whose members the anti-spam ignores
+// entirely.
+type AntiSpamExemptRole struct {
+	GuildID snowflake.ID `gorm:"primaryKey;autoIncrement:false"`
+	RoleID  snowflake.ID `gorm:"primaryKey;autoIncrement:false"`
+}
+
+// AntiSpamChannelOverride changes how the anti-spam treats one channel
+// (and the threads in it). Exempt channels are ignored entirely; otherwise
+// a non-zero Count or CooldownSeconds replaces the guild's AntiSpamCount or
+// AntiSpamCooldownSeconds for messages sent there.
+type AntiSpamChannelOverride struct {
+	GuildID   snowflake.ID `gorm:"primaryKey;autoIncrement:false"`
+	ChannelID snowflake.ID `gorm:"primaryKey;autoIncrement:false"`
+
+	Exempt          bool
+	Count           int
+	CooldownSeconds int
+}
+
+// Apply returns a copy of settings with the override's thresholds in
+// place of the guild's.
+func (o *AntiSpamChannelOverride) Apply(settings *GuildSettings) *GuildSettings {
+	s := *settings
+	if o.Count > 0 {
+		s.AntiSpamCount = o.Count
+	}
+	if o.CooldownSeconds > 0 {
+		s.AntiSpamCooldownSeconds = o.CooldownSeconds
+	}
+	return &s
+}
+
+func GetAntiSpamExemptRoles(guildID snowflake.ID) ([]snowflake.ID, error) {
+	var roles []snowflake.ID
+	res := DB.Model(&AntiSpamExemptRole{}).Where("guild_id = ?", guildID).Order("role_id").Pluck("role_id", &roles)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return roles, nil
+}
+
+// SetAntiSpamExemptions replaces the guild's exempt roles with roles and
+// its channel overrides with overrides, in one transaction so a failure
+// leaves both as they were. The GuildID of each override is set to
+// guildID.
+func SetAntiSpamExemptions(guildID snowflake.ID, roles []snowflake.ID, overrides []AntiSpamChannelOverride) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := replaceAntiSpamExemptRoles(tx, guildID, roles); err != nil {
+			return err
+		}
+		return replaceAntiSpamChannelOverrides(tx, guildID, overrides)
+	})
+}
+
+func replaceAntiSpamExemptRoles(tx *gorm.DB, guildID snowflake.ID, roles []snowflake.ID) error {
+	if err := tx.Where("guild_id = ?", guildID).Delete(&AntiSpamExemptRole{}).Error; err != nil {
+		return err
+	}
+	if len(roles) == 0 {
+		return nil
+	}
+	rows := make([]AntiSpamExemptRole, 0, len(roles))
+	for _, r := range roles {
+		rows = append(rows, AntiSpamExemptRole{GuildID: guildID, RoleID: r})
+	}
+	return tx.Create(&rows).Error
+}
+
+func GetAntiSpamChannelOverrides(guildID snowflake.ID) ([]AntiSpamChannelOverride, error) {
+	var overrides []AntiSpamChannelOverride
+	res := DB.Where("guild_id = ?", guildID).Order("channel_id").Find(&overrides)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return overrides, nil
+}
+
+// GetAntiSpamChannelOverride returns the override for the channel, or nil
+// if it has none.
+func GetAntiSpamChannelOverride(guildID, channelID snowflake.ID) (*AntiSpamChannelOverride, error) {
+	var override AntiSpamChannelOverride
+	err := DB.Where("guild_id = ? AND channel_id = ?", guildID, channelID).First(&override).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
+
+func replaceAntiSpamChannelOverrides(tx *gorm.DB, guildID snowflake.ID, overrides []AntiSpamChannelOverride) error {
+	if err := tx.Where("guild_id = ?", guildID).Delete(&AntiSpamChannelOverride{}).Error; err != nil {
+		return err
+	}
+	if len(overrides) == 0 {
+		return nil
+	}
+	rows := make([]AntiSpamChannelOverride, len(overrides))
+	for i, o := range overrides {
+		o.GuildID = guildID
+		rows[i] = o
+	}
+	return tx.Create(&rows).Error
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *ModelTestSuite) TestAntiSpamExemptRoles_Replace() {
+	t := suite.T()
+	require.NoError(t, SetAntiSpamExemptions(1, []snowflake.ID{30, 20}, nil))
+	require.NoError(t, SetAntiSpamExemptions(2, []snowflake.ID{40}, nil))
+
+	roles, err := GetAntiSpamExemptRoles(1)
+	require.NoError(t, err)
+	assert.Equal(t, []snowflake.ID{20, 30}, roles)
+
+	require.NoError(t, SetAntiSpamExemptions(1, nil, nil))
+	roles, err = GetAntiSpamExemptRoles(1)
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+
+	roles, err = GetAntiSpamExemptRoles(2)
+	require.NoError(t, err)
+	assert.Equal(t, []snowflake.ID{40}, roles, "other guilds are untouched")
+}
+
+func (suite *ModelTestSuite) TestAntiSpamChannelOverrides_Replace() {
+	t := suite.T()
+	require.NoError(t, SetAntiSpamExemptions(1, nil, []AntiSpamChannelOverride{
+		{ChannelID: 10, Exempt: true},
+		{ChannelID: 11, Count: 8, CooldownSeconds: 5},
+	}))
+
+	overrides, err := GetAntiSpamChannelOverrides(1)
+	require.NoError(t, err)
+	require.Len(t, overrides, 2)
+	assert.Equal(t, snowflake.ID(1), overrides[0].GuildID)
+
+	o, err := GetAntiSpamChannelOverride(1, 11)
+	require.NoError(t, err)
+	require.NotNil(t, o)
+	assert.Equal(t, 8, o.Count)
+
+	require.NoError(t, SetAntiSpamExemptions(1, nil, []AntiSpamChannelOverride{{ChannelID: 12, Count: 3}}))
+	o, err = GetAntiSpamChannelOverride(1, 11)
+	require.NoError(t, err)
+	assert.Nil(t, o, "saving replaces every override")
+}
+
+func (suite *ModelTestSuite) TestAntiSpamExemptions_Atomic() {
+	t := suite.T()
+	require.NoError(t, SetAntiSpamExemptions(1, []snowflake.ID{20}, []AntiSpamChannelOverride{{ChannelID: 10, Exempt: true}}))
+
+	// A duplicate channel fails the override insert after the roles were
+	// replaced; neither change may stick.
+	err := SetAntiSpamExemptions(1, []snowflake.ID{30}, []AntiSpamChannelOverride{{ChannelID: 11}, {ChannelID: 11}})
+	require.Error(t, err)
+
+	roles, err := GetAntiSpamExemptRoles(1)
+	require.NoError(t, err)
+	assert.Equal(t, []snowflake.ID{20}, roles)
+	overrides, err := GetAntiSpamChannelOverrides(1)
+	require.NoError(t, err)
+	require.Len(t, overrides, 1)
+	assert.Equal(t, snowflake.ID(10), overrides[0].ChannelID)
+}
+
+func (suite *ModelTestSuite) TestAntiSpamChannelOverride_Apply() {
+	t := suite.T()
+	settings := &GuildSettings{AntiSpamCount: 5, AntiSpamCooldownSeconds: 20}
+
+	got := (&AntiSpamChannelOverride{Count: 9}).Apply(settings)
+	assert.Equal(t, 9, got.AntiSpamCount)
+	assert.Equal(t, 20, got.AntiSpamCooldownSeconds, "zero keeps the guild's value")
+	assert.Equal(t, 5, settings.AntiSpamCount, "the guild's settings are not modified")
+}
@@ -15,13 +15,15 @@ import (
 // the audit package; queries for the viewer page live in this file.
 //
 // Rows are immutable once committed and pruned by the scheduled retention
-// task — there's no soft-delete, no UpdatedAt.
+// task — there's no soft-delete, no UpdatedAt. Write them with
+// CreateAuditLogEntry, which links them into the guild's hash chain; see
+// audit_log_chain.go.
 type AuditLogEntry struct {
 	ID        uint         `gorm:"primaryKey"`
-	GuildID   snowflake.ID `gorm:"index:idx_audit_guild_created;index:idx_audit_guild_actor;index:idx_audit_guild_target;index:idx_audit_guild_event;not null"`
+	GuildID   snowflake.ID `gorm:"index:idx_audit_guild_created;index:idx_audit_guild_actor;index:idx_audit_guild_target;index:idx_audit_guild_event;index:idx_audit_guild_category;not null"`
 	CreatedAt time.Time    `gorm:"index:idx_audit_guild_created,sort:desc;autoCreateTime"`
 
-	Category  string `gorm:"not null;index"`
+	Category  string `gorm:"not null;index;index:idx_audit_guild_category"`
 	EventType string `gorm:"not null;index:idx_audit_guild_event"`
 
 	ActorID    *snowflake.ID `gorm:"index:idx_audit_guild_actor"`
@@ -35,6 +37,12 @@ type AuditLogEntry struct {
 	// rather than using a typed JSON column to match the existing pattern
 	// in model.Post (ComponentsJSON) and avoid a new dependency.
 	Details string `gorm:"type:text"`
+
+	// PrevHash is the Hash of the entry before this one in the chain, and
+	// Hash covers this entry's content and PrevHash. Both are empty on
+	// entries written before the chain existed.
+	PrevHash string
+	Hash     string
 }
 
 // AuditLogFilter narrows AuditLogEntry queries. Zero-valued fields are
@@ -55,8 +63,10 @@ type AuditLogEntry struct {
 // From is inclusive, To is exclusive — matching the convention that an
 // empty To means "now or later" while an empty From means "any time".
 type AuditLogFilter struct {
-	Category         string
-	EventType        string
+	Category  string
+	EventType string
+	// EventTypes limits the query to any of the listed event types.
+	EventTypes       []string
 	ActorIDs         []snowflake.ID
 	ActorQuery       string
 	TargetIDs        []snowflake.ID
@@ -65,17 +75,29 @@ type AuditLogFilter struct {
 	Source           string
 	From             time.Time
 	To               time.Time
+	// Text is a full-text query over the entry's reason and the string
+	// values in its details; see auditSearchQuery for the syntax.
+	Text string
+	// ThroughID, when set, limits the query to entries with an ID at or
+	// below it.
+	ThroughID uint
 }
 
 // applyTo mutates a *gorm.DB with the filter's WHERE clauses. Caller is
 // responsible for setting the guild scope before calling this.
 func (f AuditLogFilter) applyTo(tx *gorm.DB) *gorm.DB {
+	if f.ThroughID != 0 {
+		tx = tx.Where("id <= ?", f.ThroughID)
+	}
 	if f.Category != "" {
 		tx = tx.Where("category = ?", f.Category)
 	}
 	if f.EventType != "" {
 		tx = tx.Where("event_type = ?", f.EventType)
 	}
+	if len(f.EventTypes) > 0 {
+		tx = tx.Where("event_type IN ?", f.EventTypes)
+	}
 	if clause, args := buildPersonClause("actor_id", "actor_username", f.ActorIDs, nil, f.ActorQuery); clause != "" {
 		tx = tx.Where(clause, args...)
 	}
@@ -91,6 +113,9 @@ func (f AuditLogFilter) applyTo(tx *gorm.DB) *gorm.DB {
 	if !f.To.IsZero() {
 		tx = tx.Where("created_at < ?", f.To)
 	}
+	if q := auditSearchQuery(f.Text); q != "" {
+		tx = tx.Where("id IN (SELECT rowid FROM audit_log_fts WHERE audit_log_fts MATCH ?)", q)
+	}
 	return tx
 }
 
@@ -172,6 +197,32 @@ func ListAuditLogEntries(guildID snowflake.ID, filter AuditLogFilter, limit, off
 	return entries, count, nil
 }
 
+// EachAuditLogEntry calls fn for every entry matching the filter, oldest
+// first, loading batchSize rows at a time so an export of a busy guild's
+// whole retention window never sits in memory at once. Batches are keyed
+// on ID rather than OFFSET so each page is an index seek. Stops at the
+// first error from fn or the query.
+func EachAuditLogEntry(ctx context.Context, guildID snowflake.ID, filter AuditLogFilter, batchSize int, fn func(AuditLogEntry) error) error {
+	var lastID uint
+	for {
+		var batch []AuditLogEntry
+		q := DB.WithContext(ctx).Where("guild_id = ? AND id > ?", guildID, lastID)
+		q = filter.applyTo(q)
+		if err := q.Order("id").Limit(batchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+		for _, e := range batch {
+			if err := fn(e); err != nil {
+				return err
+			}
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
 // GetAuditLogEntry fetches a single entry by ID, scoped to a guild so a
 // caller from one guild can't view another's rows even if they guess an ID.
 func GetAuditLogEntry(guildID snowflake.ID, id uint) (*AuditLogEntry, error) {
@@ -192,13 +243,65 @@ func GetAuditLogEntry(guildID snowflake.ID, id uint) (*AuditLogEntry, error) {
 // Used by the retention scheduled task. Pass a zero cutoff to no-op (matching
 // the "0 = forever" config convention).
 func PruneAuditLogEntriesBefore(ctx context.Context, guildID snowflake.ID, category string, cutoff time.Time) (int64, error) {
+	through, err := AuditLogPruneBoundary(ctx, guildID, category, cutoff)
+	if err != nil || through == 0 {
+		return 0, err
+	}
+	return PruneAuditLogEntriesThrough(ctx, guildID, category, through)
+}
+
+// AuditLogPruneBoundary returns the ID of the newest entry in the guild and
+// category that pruning at cutoff removes, or 0 when there is none.
+//
+// Only the oldest part of the category's chain is pruned: the boundary
+// stops before the first entry inside the window, even if a later one
+// carries an older timestamp after a clock change. Entries are never
+// updated and new ones get higher IDs, so everything at or below the
+// boundary stays the same until it is pruned.
+func AuditLogPruneBoundary(ctx context.Context, guildID snowflake.ID, category string, cutoff time.Time) (uint, error) {
 	if cutoff.IsZero() {
 		return 0, nil
 	}
-	res := DB.WithContext(ctx).
-		Where("guild_id = ? AND category = ? AND created_at < ?", guildID, category, cutoff).
-		Delete(&AuditLogEntry{})
-	return res.RowsAffected, res.Error
+	var boundary *uint
+	if err := DB.WithContext(ctx).Model(&AuditLogEntry{}).
+		Where("guild_id = ? AND category = ? AND created_at >= ?", guildID, category, cutoff).
+		Select("MIN(id)").Scan(&boundary).Error; err != nil {
+		return 0, err
+	}
+	q := DB.WithContext(ctx).Model(&AuditLogEntry{}).Where("guild_id = ? AND category = ?", guildID, category)
+	if boundary != nil {
+		q = q.Where("id < ?", *boundary)
+	}
+	var last *uint
+	if err := q.Select("MAX(id)").Scan(&last).Error; err != nil || last == nil {
+		return 0, err
+	}
+	return *last, nil
+}
+
+// PruneAuditLogEntriesThrough deletes the guild's entries in category with
+// an ID at or below through, as found by AuditLogPruneBoundary, and returns
+// how many it deleted. The newest pruned entry becomes the chain's
+// checkpoint so the remainder still verifies.
+func PruneAuditLogEntriesThrough(ctx context.Context, guildID snowflake.ID, category string, through uint) (int64, error) {
+	var deleted int64
+	err := DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var last AuditLogEntry
+		res := tx.Where("guild_id = ? AND category = ? AND id <= ?", guildID, category, through).
+			Order("id desc").Limit(1).Find(&last)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+
+		res = tx.Where("guild_id = ? AND category = ? AND id <= ?", guildID, category, last.ID).
+			Delete(&AuditLogEntry{})
+		if res.Error != nil {
+			return res.Error
+		}
+		deleted = res.RowsAffected
+		return setAuditLogChainCheckpoint(tx, last)
+	})
+	return deleted, err
 }
 
 // DistinctAuditLogGuilds returns every guild ID with at least one audit log
@@ -0,0 +1,246 @@
+package model
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// The audit log is hash-chained so edits made straight to the database
+// are detectable. Each entry stores the hash of its content together with
+// the hash of the entry before it, so changing, removing or reordering an
+// entry breaks the chain from that point on.
+//
+// There is one chain per guild and category rather than one per guild:
+// retention is per category, and pruning a category then always removes
+// the oldest part of its chain. AuditLogChainCheckpoint records the hash
+// of the last pruned entry so the chain still verifies afterwards.
+//
+// Entries written before the chain existed have no hash. They are
+// reported as unchained; a hash missing after the first chained entry is
+// a break.
+
+// AuditLogChainCheckpoint is where a guild's chain for one category
+// resumes after pruning: the ID and hash of the newest pruned entry.
+type AuditLogChainCheckpoint struct {
+	GuildID   snowflake.ID `gorm:"primaryKey;autoIncrement:false"`
+	Category  string       `gorm:"primaryKey"`
+	EntryID   uint         `gorm:"not null"`
+	Hash      string       `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+// CreateAuditLogEntry writes entry as the new head of its chain, setting
+// CreatedAt, PrevHash and Hash. The database write lock is held from
+// reading the old head to the insert, so concurrent writers can't fork
+// the chain.
+func CreateAuditLogEntry(entry *AuditLogEntry) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		prev, err := auditLogChainHead(tx, entry.GuildID, entry.Category)
+		if err != nil {
+			return err
+		}
+		if entry.CreatedAt.IsZero() {
+			entry.CreatedAt = time.Now()
+		}
+		// Microseconds survive the round trip through SQLite's text
+		// timestamps, so the hash can be recomputed from the stored row.
+		entry.CreatedAt = entry.CreatedAt.UTC().Truncate(time.Microsecond)
+		entry.PrevHash = prev
+		entry.Hash = auditLogEntryHash(entry)
+		return tx.Create(entry).Error
+	})
+}
+
+// auditLogChainHead returns the hash the next entry in the chain links
+// to: the newest entry's, else the checkpoint's when everything has been
+// pruned, else "".
+func auditLogChainHead(tx *gorm.DB, guildID snowflake.ID, category string) (string, error) {
+	var last AuditLogEntry
+	res := tx.Select("id", "hash").
+		Where("guild_id = ? AND category = ?", guildID, category).
+		Order("id desc").Limit(1).Find(&last)
+	if res.Error != nil {
+		return "", res.Error
+	}
+	if res.RowsAffected > 0 {
+		return last.Hash, nil
+	}
+	cp, err := auditLogChainCheckpoint(tx, guildID, category)
+	if err != nil {
+		return "", err
+	}
+	return cp.Hash, nil
+}
+
+func auditLogChainCheckpoint(tx *gorm.DB, guildID snowflake.ID, category string) (AuditLogChainCheckpoint, error) {
+	var cp AuditLogChainCheckpoint
+	res := tx.Where("guild_id = ? AND category = ?", guildID, category).Limit(1).Find(&cp)
+	return cp, res.Error
+}
+
+// auditLogEntryHash hashes the entry's content and PrevHash. The ID isn't
+// known before the insert and isn't needed: order is already fixed by
+// each entry naming its predecessor.
+func auditLogEntryHash(e *AuditLogEntry) string {
+	fields, _ := json.Marshal([]string{
+		e.PrevHash,
+		e.GuildID.String(),
+		strconv.FormatInt(e.CreatedAt.UnixMicro(), 10),
+		e.Category,
+		e.EventType,
+		chainID(e.ActorID),
+		e.ActorKind,
+		chainID(e.TargetID),
+		e.TargetKind,
+		e.Source,
+		e.Reason,
+		e.Details,
+	})
+	sum := sha256.Sum256(fields)
+	return hex.EncodeToString(sum[:])
+}
+
+func chainID(id *snowflake.ID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+// setAuditLogChainCheckpoint records last as the newest pruned entry of
+// its chain.
+func setAuditLogChainCheckpoint(tx *gorm.DB, last AuditLogEntry) error {
+	return tx.Clauses(clause.OnConflict{UpdateAll: true}).Create(&AuditLogChainCheckpoint{
+		GuildID:  last.GuildID,
+		Category: last.Category,
+		EntryID:  last.ID,
+		Hash:     last.Hash,
+	}).Error
+}
+
+// maxReportedBreaks caps AuditLogChainReport.Breaks; BreakCount still
+// counts them all.
+const maxReportedBreaks = 50
+
+// AuditLogChainBreak is an entry where verification failed.
+type AuditLogChainBreak struct {
+	Category string
+	EntryID  uint
+	Problem  string
+}
+
+// AuditLogChainReport is the result of verifying a guild's audit log.
+type AuditLogChainReport struct {
+	GuildID snowflake.ID
+	// Verified counts chained entries checked, Unchained the entries
+	// written before the chain existed.
+	Verified   int
+	Unchained  int
+	BreakCount int
+	Breaks     []AuditLogChainBreak
+}
+
+func (r AuditLogChainReport) OK() bool {
+	return r.BreakCount == 0
+}
+
+func (r *AuditLogChainReport) addBreak(category string, id uint, problem string) {
+	r.BreakCount++
+	if len(r.Breaks) < maxReportedBreaks {
+		r.Breaks = append(r.Breaks, AuditLogChainBreak{Category: category, EntryID: id, Problem: problem})
+	}
+}
+
+// VerifyAuditLogChain walks every chain in the guild, oldest first,
+// recomputing each entry's hash and checking it links to the entry
+// before it.
+func VerifyAuditLogChain(ctx context.Context, guildID snowflake.ID) (AuditLogChainReport, error) {
+	report := AuditLogChainReport{GuildID: guildID}
+
+	var categories []string
+	err := DB.WithContext(ctx).Raw(`SELECT category FROM audit_log_entries WHERE guild_id = ?
+		UNION SELECT category FROM audit_log_chain_checkpoints WHERE guild_id = ?
+		ORDER BY category`, guildID, guildID).Scan(&categories).Error
+	if err != nil {
+		return report, err
+	}
+	for _, category := range categories {
+		if err := verifyAuditLogCategory(ctx, &report, guildID, category); err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+const verifyBatchSize = 500
+
+func verifyAuditLogCategory(ctx context.Context, report *AuditLogChainReport, guildID snowflake.ID, category string) error {
+	cp, err := auditLogChainCheckpoint(DB.WithContext(ctx), guildID, category)
+	if err != nil {
+		return err
+	}
+	expected := cp.Hash
+	chained := cp.Hash != ""
+
+	lastID := cp.EntryID
+	if lastID > 0 {
+		var stale int64
+		if err := DB.WithContext(ctx).Model(&AuditLogEntry{}).
+			Where("guild_id = ? AND category = ? AND id <= ?", guildID, category, cp.EntryID).
+			Count(&stale).Error; err != nil {
+			return err
+		}
+		if stale > 0 {
+			report.addBreak(category, cp.EntryID, fmt.Sprintf("%d entries are older than the last pruning checkpoint", stale))
+		}
+	}
+
+	for {
+		var batch []AuditLogEntry
+		if err := DB.WithContext(ctx).
+			Where("guild_id = ? AND category = ? AND id > ?", guildID, category, lastID).
+			Order("id").Limit(verifyBatchSize).Find(&batch).Error; err != nil {
+			return err
+		}
+		for i := range batch {
+			e := &batch[i]
+			switch {
+			case e.Hash == "" && !chained:
+				report.Unchained++
+				continue
+			case e.Hash == "":
+				report.addBreak(category, e.ID, "hash is missing")
+			case e.PrevHash != expected:
+				report.addBreak(category, e.ID, "does not link to the entry before it; an entry was removed, added or reordered")
+			case auditLogEntryHash(e) != e.Hash:
+				report.addBreak(category, e.ID, "content does not match its hash; the entry was edited")
+			default:
+				report.Verified++
+			}
+			chained = true
+			expected = e.Hash
+		}
+		if len(batch) < verifyBatchSize {
+			return nil
+		}
+		lastID = batch[len(batch)-1].ID
+	}
+}
+
+// AuditLogChainGuilds returns every guild with a chain to verify.
+func AuditLogChainGuilds() ([]snowflake.ID, error) {
+	var ids []snowflake.ID
+	err := DB.Raw(`SELECT guild_id FROM audit_log_entries
+		UNION SELECT guild_id FROM audit_log_chain_checkpoints
+		ORDER BY guild_id`).Scan(&ids).Error
+	return ids, err
+}
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *ModelTestSuite) chainEntries(guildID snowflake.ID, category string, createdAt ...time.Time) []AuditLogEntry {
+	actor := snowflake.ID(7777)
+	entries := make([]AuditLogEntry, 0, len(createdAt))
+	for i, at := range createdAt {
+		e := AuditLogEntry{
+			GuildID: guildID, Category: category, EventType: category + ".test",
+			ActorID: &actor, ActorKind: "user", TargetKind: "none", Source: "gateway",
+			Reason: "reason", Details: fmt.Sprintf(`{"n":%d}`, i), CreatedAt: at,
+		}
+		require.NoError(suite.T(), CreateAuditLogEntry(&e))
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func (suite *ModelTestSuite) verifyChain(guildID snowflake.ID) AuditLogChainReport {
+	report, err := VerifyAuditLogChain(context.Background(), guildID)
+	require.NoError(suite.T(), err)
+	return report
+}
+
+func (suite *ModelTestSuite) TestAuditLogChain_Links() {
+	t := suite.T()
+	now := time.Now()
+	msg := suite.chainEntries(1, "message", now, now)
+	guild := suite.chainEntries(1, "guild", now)
+
+	assert.Empty(t, msg[0].PrevHash, "first entry starts the chain")
+	assert.Equal(t, msg[0].Hash, msg[1].PrevHash)
+	assert.Empty(t, guild[0].PrevHash, "each category has its own chain")
+
+	report := suite.verifyChain(1)
+	assert.True(t, report.OK(), "%+v", report.Breaks)
+	assert.Equal(t, 3, report.Verified)
+}
+
+func (suite *ModelTestSuite) TestAuditLogChain_DetectsEdit() {
+	t := suite.T()
+	now := time.Now()
+	entries := suite.chainEntries(1, "guild", now, now, now)
+	require.NoError(t, suite.db.Model(&AuditLogEntry{}).Where("id = ?", entries[1].ID).Update("reason", "changed").Error)
+
+	report := suite.verifyChain(1)
+	require.Equal(t, 1, report.BreakCount)
+	assert.Equal(t, entries[1].ID, report.Breaks[0].EntryID)
+	assert.Contains(t, report.Breaks[0].Problem, "edited")
+	assert.Equal(t, 2, report.Verified)
+}
+
+func (suite *ModelTestSuite) TestAuditLogChain_DetectsGap() {
+	t := suite.T()
+	now := time.Now()
+	entries := suite.chainEntries(1, "guild", now, now, now)
+	require.NoError(t, suite.db.Delete(&AuditLogEntry{}, entries[1].ID).Error)
+
+	report := suite.verifyChain(1)
+	require.Equal(t, 1, report.BreakCount)
+	assert.Equal(t, entries[2].ID, report.Breaks[0].EntryID)
+	assert.Contains(t, report.Breaks[0].Problem, "does not link")
+}
+
+func (suite *ModelTestSuite) TestAuditLogChain_DetectsWipedHash() {
+	t := suite.T()
+	now := time.Now()
+	entries := suite.chainEntries(1, "guild", now, now)
+	require.NoError(t, suite.db.Model(&AuditLogEntry{}).Where("id = ?", entries[1].ID).Update("hash", "").Error)
+
+	report := suite.verifyChain(1)
+	require.Equal(t, 1, report.BreakCount)
+	assert.Contains(t, report.Breaks[0].Problem, "missing")
+}
+
+func (suite *ModelTestSuite) TestAuditLogChain_LegacyRowsUnchained() {
+	t := suite.T()
+	require.NoError(t, suite.db.Create(&AuditLogEntry{
+		GuildID: 1, Category: "guild", EventType: "guild.ban", ActorKind: "user", TargetKind: "user", Source: "gateway",
+	}).Error)
+	suite.chainEntries(1, "guild", time.Now())
+
+	report := suite.verifyChain(1)
+	assert.True(t, report.OK(), "%+v", report.Breaks)
+	assert.Equal(t, 1, report.Unchained)
+	assert.Equal(t, 1, report.Verified)
+}
+
+func (suite *ModelTestSuite) TestAuditLogChain_PruneCheckpoints() {
+	t := suite.T()
+	now := time.Now().UTC()
+	entries := suite.chainEntries(1, "message", now.Add(-3*time.Hour), now.Add(-2*time.Hour), now)
+
+	deleted, err := PruneAuditLogEntriesBefore(context.Background(), 1, "message", now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, deleted)
+
+	report := suite.verifyChain(1)
+	assert.True(t, report.OK(), "%+v", report.Breaks)
+	assert.Equal(t, 1, report.Verified)
+
+	// Pruning everything leaves the checkpoint for the next entry to
+	// link to.
+	_, err = PruneAuditLogEntriesBefore(context.Background(), 1, "message", now.Add(time.Hour))
+	require.NoError(t, err)
+	next := suite.chainEntries(1, "message", now)
+	assert.Equal(t, entries[2].Hash, next[0].PrevHash)
+	assert.True(t, suite.verifyChain(1).OK())
+}
+
+func (suite *ModelTestSuite) TestAuditLogChain_PruneStopsAtOutOfOrderTimestamp() {
+	t := suite.T()
+	now := time.Now().UTC()
+	// The middle entry is inside the window; the last one's clock was
+	// behind. Pruning must not leave a hole in the chain.
+	suite.chainEntries(1, "message", now.Add(-3*time.Hour), now, now.Add(-2*time.Hour))
+
+	deleted, err := PruneAuditLogEntriesBefore(context.Background(), 1, "message", now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, deleted)
+	assert.True(t, suite.verifyChain(1).OK())
+}
+
+func (suite *ModelTestSuite) TestAuditLogChainGuilds() {
+	now := time.Now().UTC()
+	suite.chainEntries(1, "message", now.Add(-time.Hour))
+	suite.chainEntries(2, "message", now)
+	_, err := PruneAuditLogEntriesBefore(context.Background(), 1, "message", now)
+	require.NoError(suite.T(), err)
+
+	ids, err := AuditLogChainGuilds()
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []snowflake.ID{1, 2}, ids, "a fully pruned guild still has a checkpoint to verify")
+}
@@ -0,0 +1,142 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// Full-text search over the audit log lives in audit_log_fts, an FTS5
+// table keyed by audit_log_entries.id. Its one column holds the entry's
+// reason plus every string value in Details except IDs, which covers
+// message content (before / after, antispam snapshots), usernames and
+// settings values without indexing the JSON keys themselves.
+//
+// Triggers keep it in step with audit_log_entries, so rows written by
+// audit.commit, pruned by the retention task or deleted by hand are all
+// covered without the callers knowing about the index.
+
+// auditSearchBody is the SQL expression for an entry's indexed text. The
+// json_valid guard keeps rows with empty or malformed Details insertable;
+// CASE only evaluates json_tree when it passes.
+const auditSearchBody = `COALESCE(%[1]s.reason, '') || ' ' || COALESCE(CASE WHEN json_valid(%[1]s.details) THEN (
+	SELECT group_concat(value, ' ') FROM json_tree(%[1]s.details)
+	WHERE type = 'text' AND (key IS NULL OR key NOT LIKE '%%\_id' ESCAPE '\')
+) END, '')`
+
+// SnippetStart and SnippetEnd delimit matched terms in the snippets
+// returned by AuditLogSearchSnippets. Control characters rather than
+// markup, so the caller decides how to render them and entry content is
+// never mistaken for HTML.
+const (
+	SnippetStart = "\x02"
+	SnippetEnd   = "\x03"
+)
+
+// setupAuditLogSearch creates the FTS table and its triggers. When the
+// table is new, existing entries are indexed so search covers history.
+func setupAuditLogSearch(db *gorm.DB) error {
+	var exists int64
+	if err := db.Raw(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'audit_log_fts'`).Scan(&exists).Error; err != nil {
+		return err
+	}
+
+	stmts := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS audit_log_fts USING fts5(body, tokenize = 'unicode61 remove_diacritics 2')`,
+		`CREATE TRIGGER IF NOT EXISTS audit_log_fts_insert AFTER INSERT ON audit_log_entries BEGIN
+			INSERT INTO audit_log_fts(rowid, body) VALUES (NEW.id, ` + fmt.Sprintf(auditSearchBody, "NEW") + `);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS audit_log_fts_delete AFTER DELETE ON audit_log_entries BEGIN
+			DELETE FROM audit_log_fts WHERE rowid = OLD.id;
+		END`,
+	}
+	if exists == 0 {
+		stmts = append(stmts,
+			`INSERT INTO audit_log_fts(rowid, body) SELECT id, `+fmt.Sprintf(auditSearchBody, "audit_log_entries")+` FROM audit_log_entries`)
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, s := range stmts {
+			if err := tx.Exec(s).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// auditSearchQuery turns free text into an FTS5 query matching entries
+// that contain every word, in any order. Quoted phrases stay phrases, and
+// each term is quoted so FTS5 operators and column filters typed by the
+// user are searched for literally; splitSearchTerms has already removed
+// any double quotes from inside them. A trailing * on a word keeps prefix
+// matching. Returns "" when there is nothing to search for.
+func auditSearchQuery(text string) string {
+	var terms []string
+	for _, raw := range splitSearchTerms(text) {
+		prefix := strings.HasSuffix(raw, "*")
+		word := strings.TrimRight(raw, "*")
+		if !strings.ContainsFunc(word, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) {
+			continue
+		}
+		term := `"` + word + `"`
+		if prefix {
+			term += "*"
+		}
+		terms = append(terms, term)
+	}
+	return strings.Join(terms, " ")
+}
+
+// splitSearchTerms splits on whitespace outside double quotes, so
+// `"free nitro" gift` is two terms.
+func splitSearchTerms(text string) []string {
+	var terms []string
+	var cur strings.Builder
+	quoted := false
+	flush := func() {
+		if cur.Len() > 0 {
+			terms = append(terms, cur.String())
+			cur.Reset()
+		}
+	}
+	for _, r := range text {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			flush()
+		case unicode.IsSpace(r) && !quoted:
+			flush()
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	flush()
+	return terms
+}
+
+// AuditLogSearchSnippets returns, for each of ids that matches text, a
+// short excerpt of its indexed text around the match, with matched terms
+// wrapped in SnippetStart / SnippetEnd.
+func AuditLogSearchSnippets(ids []uint, text string) (map[uint]string, error) {
+	query := auditSearchQuery(text)
+	if query == "" || len(ids) == 0 {
+		return nil, nil
+	}
+	var rows []struct {
+		RowID   uint
+		Snippet string
+	}
+	res := DB.Raw(`SELECT rowid AS row_id, snippet(audit_log_fts, 0, ?, ?, '…', 16) AS snippet
+		FROM audit_log_fts WHERE audit_log_fts MATCH ? AND rowid IN ?`,
+		SnippetStart, SnippetEnd, query, ids).Scan(&rows)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	out := make(map[uint]string, len(rows))
+	for _, r := range rows {
+		out[r.RowID] = r.Snippet
+	}
+	return out, nil
+}
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditSearchQuery(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"discord.gift", `"discord.gift"`},
+		{"free nitro", `"free" "nitro"`},
+		{`"free nitro" gift`, `"free nitro" "gift"`},
+		{"nitr*", `"nitr"*`},
+		{"content:spam OR NOT", `"content:spam" "OR" "NOT"`},
+		{"- * ()", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, auditSearchQuery(tt.in), tt.in)
+	}
+}
+
+func (suite *ModelTestSuite) seedSearchEntries() (snowflake.ID, []uint) {
+	guildID := snowflake.ID(123)
+	now := time.Now().UTC()
+	entries := []AuditLogEntry{
+		{GuildID: guildID, Category: "message", EventType: "message.delete", ActorKind: "unknown", TargetKind: "message", Source: "gateway", CreatedAt: now.Add(-3 * time.Minute),
+			Details: `{"content":"claim your free nitro at discord.gift/abc","channel_id":"555"}`},
+		{GuildID: guildID, Category: "message", EventType: "message.edit", ActorKind: "user", TargetKind: "message", Source: "gateway", CreatedAt: now.Add(-2 * time.Minute),
+			Details: `{"before":"hello there","after":"hello everyone"}`},
+		{GuildID: guildID, Category: "guild", EventType: "guild.ban", ActorKind: "user", TargetKind: "user", Source: "gateway", CreatedAt: now.Add(-1 * time.Minute),
+			Reason: "Spamming gift links", Details: "not json"},
+		{GuildID: snowflake.ID(456), Category: "message", EventType: "message.delete", ActorKind: "unknown", TargetKind: "message", Source: "gateway", CreatedAt: now,
+			Details: `{"content":"discord.gift in another guild"}`},
+	}
+	ids := make([]uint, 0, len(entries))
+	for i := range entries {
+		require.NoError(suite.T(), suite.db.Create(&entries[i]).Error)
+		ids = append(ids, entries[i].ID)
+	}
+	return guildID, ids
+}
+
+func (suite *ModelTestSuite) TestListAuditLogEntries_Text() {
+	guildID, ids := suite.seedSearchEntries()
+
+	entries, count, err := ListAuditLogEntries(guildID, AuditLogFilter{Text: "discord.gift", Category: "message"}, 50, 0)
+	require.NoError(suite.T(), err)
+	assert.EqualValues(suite.T(), 1, count)
+	require.Len(suite.T(), entries, 1)
+	assert.Equal(suite.T(), ids[0], entries[0].ID)
+
+	// Reasons are indexed; malformed details don't stop the row indexing.
+	entries, _, err = ListAuditLogEntries(guildID, AuditLogFilter{Text: "gift"}, 50, 0)
+	require.NoError(suite.T(), err)
+	assert.Len(suite.T(), entries, 2)
+
+	// IDs in details are not indexed.
+	_, count, err = ListAuditLogEntries(guildID, AuditLogFilter{Text: "555"}, 50, 0)
+	require.NoError(suite.T(), err)
+	assert.EqualValues(suite.T(), 0, count)
+
+	_, count, err = ListAuditLogEntries(guildID, AuditLogFilter{Text: "hello everyone"}, 50, 0)
+	require.NoError(suite.T(), err)
+	assert.EqualValues(suite.T(), 1, count)
+}
+
+func (suite *ModelTestSuite) TestAuditLogSearch_FollowsPrune() {
+	guildID, _ := suite.seedSearchEntries()
+
+	_, err := PruneAuditLogEntriesBefore(context.Background(), guildID, "message", time.Now().UTC())
+	require.NoError(suite.T(), err)
+
+	var indexed int64
+	require.NoError(suite.T(), suite.db.Raw(`SELECT COUNT(*) FROM audit_log_fts`).Scan(&indexed).Error)
+	assert.EqualValues(suite.T(), 2, indexed, "pruned rows leave the index")
+
+	_, count, err := ListAuditLogEntries(guildID, AuditLogFilter{Text: "nitro"}, 50, 0)
+	require.NoError(suite.T(), err)
+	assert.EqualValues(suite.T(), 0, count)
+}
+
+func (suite *ModelTestSuite) TestAuditLogSearchSnippets() {
+	_, ids := suite.seedSearchEntries()
+
+	snippets, err := AuditLogSearchSnippets(ids[:2], "nitro")
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), snippets, 1)
+	assert.Contains(suite.T(), snippets[ids[0]], SnippetStart+"nitro"+SnippetEnd)
+}
+
+func (suite *ModelTestSuite) TestSetupAuditLogSearch_IndexesExistingRows() {
+	suite.db.Exec(`DROP TRIGGER audit_log_fts_insert`)
+	suite.db.Exec(`DROP TRIGGER audit_log_fts_delete`)
+	suite.db.Exec(`DROP TABLE audit_log_fts`)
+	guildID, _ := suite.seedSearchEntries()
+
+	require.NoError(suite.T(), setupAuditLogSearch(suite.db))
+
+	_, count, err := ListAuditLogEntries(guildID, AuditLogFilter{Text: "nitro"}, 50, 0)
+	require.NoError(suite.T(), err)
+	assert.EqualValues(suite.T(), 1, count)
+}
+
+func (suite *ModelTestSuite) TestListAuditLogEntries_EventTypes() {
+	guildID, ids := suite.seedSearchEntries()
+
+	entries, count, err := ListAuditLogEntries(guildID, AuditLogFilter{EventTypes: []string{"message.edit", "guild.ban"}}, 50, 0)
+	require.NoError(suite.T(), err)
+	assert.EqualValues(suite.T(), 2, count)
+	require.Len(suite.T(), entries, 2)
+	assert.ElementsMatch(suite.T(), []uint{ids[1], ids[2]}, []uint{entries[0].ID, entries[1].ID})
+}
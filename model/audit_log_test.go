@@ -129,6 +129,26 @@ func (suite *ModelTestSuite) TestListAuditLogEntries_Pagination() {
 	assert.Len(suite.T(), entries, 1)
 }
 
+func (suite *ModelTestSuite) TestEachAuditLogEntry() {
+	guildID, ids := suite.seedAuditEntries()
+
+	var got []uint
+	err := EachAuditLogEntry(context.Background(), guildID, AuditLogFilter{}, 2, func(e AuditLogEntry) error {
+		got = append(got, e.ID)
+		return nil
+	})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), ids[:3], got, "oldest first, guild-scoped, across batches")
+
+	got = nil
+	err = EachAuditLogEntry(context.Background(), guildID, AuditLogFilter{Category: "guild"}, 2, func(e AuditLogEntry) error {
+		got = append(got, e.ID)
+		return nil
+	})
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), []uint{ids[2]}, got)
+}
+
 func (suite *ModelTestSuite) TestPruneAuditLogEntriesBefore() {
 	guildID, _ := suite.seedAuditEntries()
 
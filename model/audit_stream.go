@@ -0,0 +1,41 @@
+package model
+
+import (
+	"github.com/disgoorg/snowflake/v2"
+	"gorm.io/gorm"
+)
+
+// AuditStreamMutedEvent is an audit log event type left out of the
+// guild's AuditStream*Channel streams. It is still recorded in the audit
+// log.
+type AuditStreamMutedEvent struct {
+	GuildID   snowflake.ID `gorm:"primaryKey;autoIncrement:false"`
+	EventType string       `gorm:"primaryKey"`
+}
+
+func GetAuditStreamMutedEvents(guildID snowflake.ID) ([]string, error) {
+	var events []string
+	res := DB.Model(&AuditStreamMutedEvent{}).Where("guild_id = ?", guildID).Order("event_type").Pluck("event_type", &events)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return events, nil
+}
+
+// SetAuditStreamMutedEvents replaces the guild's muted event types with
+// events.
+func SetAuditStreamMutedEvents(guildID snowflake.ID, events []string) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("guild_id = ?", guildID).Delete(&AuditStreamMutedEvent{}).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		rows := make([]AuditStreamMutedEvent, 0, len(events))
+		for _, e := range events {
+			rows = append(rows, AuditStreamMutedEvent{GuildID: guildID, EventType: e})
+		}
+		return tx.Create(&rows).Error
+	})
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *ModelTestSuite) TestAuditStreamMutedEvents_Replace() {
+	t := suite.T()
+	require.NoError(t, SetAuditStreamMutedEvents(1, []string{"message.edit", "member.join"}))
+	require.NoError(t, SetAuditStreamMutedEvents(2, []string{"guild.ban"}))
+
+	events, err := GetAuditStreamMutedEvents(1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"member.join", "message.edit"}, events)
+
+	require.NoError(t, SetAuditStreamMutedEvents(1, nil))
+	events, err = GetAuditStreamMutedEvents(1)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	events, err = GetAuditStreamMutedEvents(2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"guild.ban"}, events, "other guilds are untouched")
+}
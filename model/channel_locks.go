@@ -0,0 +1,85 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ChannelLock is a channel locked with /lock. The row holds a snapshot of
+// the channel's @everyone permission overwrite from before the lock, so
+// /unlock (or expiry) can put it back exactly — including removing the
+// overwrite again if the channel had none.
+type ChannelLock struct {
+	ChannelID snowflake.ID `gorm:"primaryKey;autoIncrement:false"`
+	GuildID   snowflake.ID `gorm:"index"`
+	CreatedAt time.Time    `gorm:"autoCreateTime"`
+
+	LockedBy snowflake.ID
+	Reason   string
+	// Until is when the lock expires; nil for locks that last until
+	// /unlock.
+	Until *time.Time `gorm:"index"`
+
+	// HadOverwrite is false when the channel had no @everyone overwrite
+	// before the lock; Allow and Deny are then zero.
+	HadOverwrite bool
+	Allow        discord.Permissions
+	Deny         discord.Permissions
+}
+
+// CreateChannelLock stores the lock and its snapshot. Returns false without
+// an error if the channel is already locked, so a second /lock can't
+// replace the original snapshot with the locked state.
+func CreateChannelLock(lock *ChannelLock) (bool, error) {
+	res := DB.Clauses(clause.OnConflict{DoNothing: true}).Create(lock)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// GetChannelLock returns the channel's lock, or nil if it isn't locked.
+func GetChannelLock(channelID snowflake.ID) (*ChannelLock, error) {
+	var lock ChannelLock
+	err := DB.Where("channel_id = ?", channelID).First(&lock).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+func GetChannelLocks(guildID snowflake.ID) ([]ChannelLock, error) {
+	var locks []ChannelLock
+	res := DB.Where("guild_id = ?", guildID).Order("created_at").Find(&locks)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return locks, nil
+}
+
+func GetExpiredChannelLocks() ([]ChannelLock, error) {
+	var locks []ChannelLock
+	res := DB.Where("until IS NOT NULL AND until < ?", time.Now()).Find(&locks)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return locks, nil
+}
+
+// DeleteChannelLock removes the lock. Returns false without an error if
+// there was no lock, so concurrent unlocks only restore once.
+func DeleteChannelLock(channelID snowflake.ID) (bool, error) {
+	res := DB.Where("channel_id = ?", channelID).Delete(&ChannelLock{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *ModelTestSuite) TestChannelLock_Snapshot() {
+	t := suite.T()
+	lock := &ChannelLock{
+		ChannelID:    10,
+		GuildID:      1,
+		HadOverwrite: true,
+		Allow:        discord.PermissionSendMessages,
+		Deny:         discord.PermissionAddReactions,
+	}
+	created, err := CreateChannelLock(lock)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	created, err = CreateChannelLock(&ChannelLock{ChannelID: 10, GuildID: 1})
+	require.NoError(t, err)
+	assert.False(t, created, "relocking must keep the original snapshot")
+
+	stored, err := GetChannelLock(10)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.True(t, stored.HadOverwrite)
+	assert.Equal(t, discord.PermissionSendMessages, stored.Allow)
+	assert.Equal(t, discord.PermissionAddReactions, stored.Deny)
+
+	deleted, err := DeleteChannelLock(10)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = DeleteChannelLock(10)
+	require.NoError(t, err)
+	assert.False(t, deleted)
+
+	stored, err = GetChannelLock(10)
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+func (suite *ModelTestSuite) TestGetExpiredChannelLocks() {
+	t := suite.T()
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+	for _, lock := range []ChannelLock{
+		{ChannelID: 1, GuildID: 1, Until: &past},
+		{ChannelID: 2, GuildID: 1, Until: &future},
+		{ChannelID: 3, GuildID: 1},
+	} {
+		_, err := CreateChannelLock(&lock)
+		require.NoError(t, err)
+	}
+
+	expired, err := GetExpiredChannelLocks()
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, snowflake.ID(1), expired[0].ChannelID)
+
+	locks, err := GetChannelLocks(1)
+	require.NoError(t, err)
+	assert.Len(t, locks, 3)
+}
@@ -15,6 +15,9 @@ type GuildSettings struct {
 	// ModeratorChannel is the channel where notifications and other
 	// information for moderators and administrators are sent.
 	ModeratorChannel snowflake.ID
+	// NotifyJoinInvite posts the invite each joining member used to the
+	// moderator channel.
+	NotifyJoinInvite bool
 
 	// InfractionHalfLifeDays is the half-life time of infractions in days.
 	InfractionHalfLifeDays      float64
@@ -43,6 +46,84 @@ type GuildSettings struct {
 	AntiSpamCount           int `gorm:"default:5"`
 	AntiSpamCooldownSeconds int `gorm:"default:20"`
 	AntiSpamTimeoutMinutes  int `gorm:"default:720"` //12 hours
+	// AntiSpamAction is what happens to a member who reaches
+	// AntiSpamCount; an antispam.Action value. Quarantine assigns
+	// AntiSpamQuarantineRole.
+	AntiSpamAction         string `gorm:"default:timeout"`
+	AntiSpamQuarantineRole snowflake.ID
+	// AntiSpamInfractionEnabled records a silent infraction of
+	// AntiSpamInfractionSeverity for every member the anti-spam acts on,
+	// so repeat spammers build up infraction weight like warned members.
+	AntiSpamInfractionEnabled  bool
+	AntiSpamInfractionSeverity float64 `gorm:"default:1.0"`
+	// AntiSpamObserveOnly reports what the anti-spam would have done to
+	// the moderator channel without deleting, punishing or recording
+	// anything, for tuning thresholds.
+	AntiSpamObserveOnly bool
+
+	// Anti-spam rules beyond repeated messages. Each adds to the same
+	// score as similar messages; see the antispam package for how many
+	// points a match is worth.
+	//
+	// AntiSpamMaxMentions is the number of distinct user/role mentions
+	// (plus @everyone/@here) a single message may contain.
+	AntiSpamMentionsEnabled bool
+	AntiSpamMaxMentions     int `gorm:"default:5"`
+	// AntiSpamMaxInvites is the number of Discord invite links a single
+	// message may contain.
+	AntiSpamInvitesEnabled bool
+	AntiSpamMaxInvites     int
+	AntiSpamMediaEnabled   bool
+	AntiSpamMaxAttachments int `gorm:"default:4"`
+	AntiSpamMaxEmoji       int `gorm:"default:15"`
+	// AntiSpamMaxCombiningMarks is the longest run of combining or
+	// invisible characters allowed on a single character (zalgo text).
+	AntiSpamZalgoEnabled      bool
+	AntiSpamMaxCombiningMarks int `gorm:"default:3"`
+	// A burst is more than AntiSpamBurstCount messages within
+	// AntiSpamBurstSeconds, whatever their content.
+	AntiSpamBurstEnabled bool
+	AntiSpamBurstCount   int `gorm:"default:5"`
+	AntiSpamBurstSeconds int `gorm:"default:3"`
+	// Coordinated spam is the same message posted by at least
+	// AntiSpamCoordinatedAuthors members within
+	// AntiSpamCoordinatedWindowSeconds. Every author gets AntiSpamAction,
+	// or is banned with AntiSpamCoordinatedBan, at once.
+	AntiSpamCoordinatedEnabled       bool
+	AntiSpamCoordinatedAuthors       int `gorm:"default:4"`
+	AntiSpamCoordinatedWindowSeconds int `gorm:"default:60"`
+	AntiSpamCoordinatedBan           bool
+	// AntiSpamPhishingEnabled acts on a member as soon as they post a link
+	// to a domain on the bot's phishing blocklist or the guild's
+	// PhishingDomain deny list, without waiting for AntiSpamCount.
+	AntiSpamPhishingEnabled bool
+
+	// AutoModInfractionEnabled records a silent infraction of
+	// AutoModInfractionSeverity whenever one of the guild's Discord AutoMod
+	// rules acts on a member, once per trigger however many actions the
+	// rule has.
+	AutoModInfractionEnabled  bool
+	AutoModInfractionSeverity float64 `gorm:"default:1.0"`
+
+	// WordFilterEnabled checks new and edited messages against the
+	// guild's WordFilterEntry list.
+	WordFilterEnabled bool
+
+	// RaidDetectionEnabled starts a lockdown when more than
+	// RaidJoinThreshold members join within RaidJoinWindowSeconds, or when
+	// RaidSimilarNameThreshold new accounts with near-identical names join
+	// within a few minutes of each other.
+	RaidDetectionEnabled     bool
+	RaidJoinThreshold        int `gorm:"default:10"`
+	RaidJoinWindowSeconds    int `gorm:"default:10"`
+	RaidSimilarNameThreshold int `gorm:"default:5"`
+	// RaidVerificationLevel is the guild verification level applied
+	// during a lockdown (a discord.VerificationLevel value).
+	RaidVerificationLevel int `gorm:"default:3"`
+	// RaidPauseInvites pauses the guild's invites for the lockdown.
+	// Discord caps a pause at 24 hours, after which invites resume on
+	// their own even if the lockdown is still active.
+	RaidPauseInvites bool
 
 	BanFooter           string
 	AlwaysSendBanFooter bool
@@ -68,6 +149,34 @@ type GuildSettings struct {
 	AuditMessageRetentionDays *uint
 	AuditMemberRetentionDays  *uint
 	AuditGuildRetentionDays   *uint
+
+	// AuditArchiveEnabled writes entries to the bot's archive directory
+	// (audit_log.archive_dir) before retention prunes them. Has no effect
+	// when the operator hasn't configured one.
+	AuditArchiveEnabled bool
+
+	// AuditVoiceEnabled records voice channel joins, leaves, moves and
+	// server mutes / deafens. Off by default: voice activity is high
+	// volume and only some guilds need it.
+	AuditVoiceEnabled bool
+
+	// MessageStoreEnabled keeps a copy of new messages (see StoredMessage)
+	// so edit and delete entries carry the original content even when
+	// disgo's cache misses. Limited to MessageStoreChannel rows when there
+	// are any.
+	MessageStoreEnabled bool
+
+	// MessageLogChannel receives a card for every message edit and
+	// deletion the audit log records, except in MessageLogIgnoredChannel
+	// channels. Zero turns the feed off.
+	MessageLogChannel snowflake.ID
+
+	// AuditStream*Channel mirror each audit log entry of that category to
+	// a channel, except event types listed as AuditStreamMutedEvent rows.
+	// Zero turns the category's stream off.
+	AuditStreamMessageChannel snowflake.ID
+	AuditStreamMemberChannel  snowflake.ID
+	AuditStreamGuildChannel   snowflake.ID
 }
 
 func GetGuildSettings(guildID snowflake.ID) (*GuildSettings, error) {
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GuildLockdown is the persisted state of an active raid lockdown. A row
+// exists only while the guild is locked down; ending the lockdown deletes
+// it. Keeping the state in the database means a restart neither forgets
+// the lockdown nor loses the verification level to restore afterwards.
+type GuildLockdown struct {
+	GuildID   snowflake.ID `gorm:"primaryKey;autoIncrement:false"`
+	CreatedAt time.Time    `gorm:"autoCreateTime"`
+
+	// Reason is a short human-readable description of what triggered the
+	// lockdown, e.g. "12 joins in 10 seconds".
+	Reason string
+	// StartedBy is the moderator who started the lockdown by hand; zero
+	// when raid detection started it.
+	StartedBy snowflake.ID
+
+	// PreviousVerificationLevel is the guild's verification level before
+	// the lockdown. It is only restored if VerificationRaised is set — a
+	// guild already at or above the lockdown level is left alone.
+	PreviousVerificationLevel int
+	VerificationRaised        bool
+	InvitesPaused             bool
+
+	// AlertChannelID / AlertMessageID locate the moderator channel alert
+	// with the "End lockdown" button, so it can be updated when the
+	// lockdown ends.
+	AlertChannelID snowflake.ID
+	AlertMessageID snowflake.ID
+}
+
+// GetGuildLockdown returns the guild's active lockdown, or nil if the guild
+// is not locked down.
+func GetGuildLockdown(guildID snowflake.ID) (*GuildLockdown, error) {
+	var lockdown GuildLockdown
+	err := DB.Where("guild_id = ?", guildID).First(&lockdown).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &lockdown, nil
+}
+
+// CreateGuildLockdown inserts the lockdown row. Returns false without an
+// error if the guild is already locked down, so concurrent triggers only
+// start one lockdown.
+func CreateGuildLockdown(lockdown *GuildLockdown) (bool, error) {
+	res := DB.Clauses(clause.OnConflict{DoNothing: true}).Create(lockdown)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// SaveGuildLockdown writes back the actions taken on start (verification
+// level, invites, alert message) once they are known. It only updates an
+// existing row: returns false without an error if the lockdown was ended
+// in the meantime, rather than bringing it back.
+func SaveGuildLockdown(lockdown *GuildLockdown) (bool, error) {
+	res := DB.Model(&GuildLockdown{}).Where("guild_id = ?", lockdown.GuildID).
+		Select("PreviousVerificationLevel", "VerificationRaised", "InvitesPaused", "AlertChannelID", "AlertMessageID").
+		Updates(lockdown)
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+// DeleteGuildLockdown ends the lockdown. Returns false without an error if
+// there was no lockdown to end, so concurrent "End lockdown" clicks only
+// undo it once.
+func DeleteGuildLockdown(guildID snowflake.ID) (bool, error) {
+	res := DB.Where("guild_id = ?", guildID).Delete(&GuildLockdown{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *ModelTestSuite) TestGuildLockdown_Lifecycle() {
+	t := suite.T()
+	guildID := snowflake.ID(123)
+
+	lockdown, err := GetGuildLockdown(guildID)
+	require.NoError(t, err)
+	assert.Nil(t, lockdown, "no lockdown before one is started")
+
+	created, err := CreateGuildLockdown(&GuildLockdown{GuildID: guildID, Reason: "12 joins in 10 seconds"})
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	created, err = CreateGuildLockdown(&GuildLockdown{GuildID: guildID, Reason: "again"})
+	require.NoError(t, err)
+	assert.False(t, created, "a second trigger must not replace the active lockdown")
+
+	lockdown, err = GetGuildLockdown(guildID)
+	require.NoError(t, err)
+	require.NotNil(t, lockdown)
+	assert.Equal(t, "12 joins in 10 seconds", lockdown.Reason)
+
+	lockdown.PreviousVerificationLevel = 1
+	lockdown.VerificationRaised = true
+	lockdown.AlertMessageID = 456
+	saved, err := SaveGuildLockdown(lockdown)
+	require.NoError(t, err)
+	assert.True(t, saved)
+
+	lockdown, err = GetGuildLockdown(guildID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, lockdown.PreviousVerificationLevel)
+	assert.True(t, lockdown.VerificationRaised)
+	assert.Equal(t, snowflake.ID(456), lockdown.AlertMessageID)
+
+	deleted, err := DeleteGuildLockdown(guildID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = DeleteGuildLockdown(guildID)
+	require.NoError(t, err)
+	assert.False(t, deleted, "ending twice only ends once")
+
+	saved, err = SaveGuildLockdown(lockdown)
+	require.NoError(t, err)
+	assert.False(t, saved, "saving after the lockdown ended must not bring it back")
+	lockdown, err = GetGuildLockdown(guildID)
+	require.NoError(t, err)
+	assert.Nil(t, lockdown)
+}
@@ -0,0 +1,167 @@
+package model
+
+import (
+	"sort"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"gorm.io/gorm"
+)
+
+// MemberJoin records a single visit of a user to a guild: one row per
+// join, closed by LeftAt when the member leaves. A user who leaves and
+// rejoins gets a new row with the next Visit number.
+//
+// InviteCode is empty when the invite could not be determined (the bot
+// lacks Manage Server, several joins raced on the same invites, or the
+// member came in through server discovery). Vanity is true when the
+// guild's vanity URL was used; InviteCode then holds the vanity code.
+type MemberJoin struct {
+	ID       uint         `gorm:"primaryKey"`
+	GuildID  snowflake.ID `gorm:"index:idx_member_joins_guild_user,priority:1;index:idx_member_joins_guild_invite,priority:1"`
+	UserID   snowflake.ID `gorm:"index:idx_member_joins_guild_user,priority:2"`
+	JoinedAt time.Time
+	// LeftAt is nil while the member is still in the guild (or if the
+	// leave happened while the bot was offline).
+	LeftAt *time.Time
+	// Visit is 1 for the first recorded join, 2 for the first rejoin, etc.
+	Visit int `gorm:"default:1"`
+	// AccountCreatedAt is derived from the user's snowflake at join time,
+	// so account age at join is JoinedAt - AccountCreatedAt.
+	AccountCreatedAt time.Time
+
+	InviteCode      string `gorm:"index:idx_member_joins_guild_invite,priority:2"`
+	InviterID       snowflake.ID
+	InviterUsername string
+	Vanity          bool
+}
+
+// Tenure is how long the visit lasted, or has lasted so far.
+func (j *MemberJoin) Tenure(now time.Time) time.Duration {
+	if j.LeftAt != nil {
+		return j.LeftAt.Sub(j.JoinedAt)
+	}
+	return now.Sub(j.JoinedAt)
+}
+
+// AccountAgeAtJoin is how old the account was when the member joined.
+func (j *MemberJoin) AccountAgeAtJoin() time.Duration {
+	return j.JoinedAt.Sub(j.AccountCreatedAt)
+}
+
+// CreateMemberJoin inserts a join row, numbering it as the member's next
+// visit to the guild.
+func CreateMemberJoin(join *MemberJoin) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		var visits int64
+		if err := tx.Model(&MemberJoin{}).
+			Where("guild_id = ? AND user_id = ?", join.GuildID, join.UserID).
+			Count(&visits).Error; err != nil {
+			return err
+		}
+		join.Visit = int(visits) + 1
+		return tx.Create(join).Error
+	})
+}
+
+// RecordMemberLeave closes the member's open visit. When there is none —
+// the member joined before history was recorded, or the bot was offline
+// for the join — a row is created from joinedAt (the cached member's join
+// time, if known) so tenure is still available. Returns the closed row.
+func RecordMemberLeave(guildID, userID snowflake.ID, leftAt time.Time, joinedAt *time.Time) (*MemberJoin, error) {
+	var join MemberJoin
+	err := DB.Where("guild_id = ? AND user_id = ? AND left_at IS NULL", guildID, userID).
+		Order("id DESC").
+		Limit(1).
+		Find(&join).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if join.ID == 0 {
+		join = MemberJoin{
+			GuildID:          guildID,
+			UserID:           userID,
+			JoinedAt:         leftAt,
+			AccountCreatedAt: userID.Time(),
+			LeftAt:           &leftAt,
+		}
+		if joinedAt != nil {
+			join.JoinedAt = *joinedAt
+		}
+		if err := CreateMemberJoin(&join); err != nil {
+			return nil, err
+		}
+		return &join, nil
+	}
+
+	join.LeftAt = &leftAt
+	if err := DB.Model(&join).Update("left_at", leftAt).Error; err != nil {
+		return nil, err
+	}
+	return &join, nil
+}
+
+// InviteStat aggregates joins per invite code for the dashboard.
+type InviteStat struct {
+	InviteCode      string
+	InviterID       snowflake.ID
+	InviterUsername string
+	Vanity          bool
+	Joins           int64
+	LastJoinedAt    time.Time
+}
+
+// ListInviteStats returns per-invite join counts for the guild, most used
+// first. Joins with no detected invite are excluded. Inviter and last-join
+// time come from the newest row for each code, loaded in a second query:
+// SQLite returns MAX() over a datetime column as text, which gorm can't
+// scan back into a time.Time.
+func ListInviteStats(guildID snowflake.ID) ([]InviteStat, error) {
+	var groups []struct {
+		InviteCode string
+		Joins      int64
+		LastID     uint
+	}
+	err := DB.Model(&MemberJoin{}).
+		Select("invite_code, COUNT(*) AS joins, MAX(id) AS last_id").
+		Where("guild_id = ? AND invite_code <> ''", guildID).
+		Group("invite_code").
+		Scan(&groups).Error
+	if err != nil || len(groups) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uint, 0, len(groups))
+	for _, g := range groups {
+		ids = append(ids, g.LastID)
+	}
+	var latest []MemberJoin
+	if err := DB.Where("id IN ?", ids).Find(&latest).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]MemberJoin, len(latest))
+	for _, j := range latest {
+		byID[j.ID] = j
+	}
+
+	stats := make([]InviteStat, 0, len(groups))
+	for _, g := range groups {
+		last := byID[g.LastID]
+		stats = append(stats, InviteStat{
+			InviteCode:      g.InviteCode,
+			InviterID:       last.InviterID,
+			InviterUsername: last.InviterUsername,
+			Vanity:          last.Vanity,
+			Joins:           g.Joins,
+			LastJoinedAt:    last.JoinedAt,
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Joins != stats[j].Joins {
+			return stats[i].Joins > stats[j].Joins
+		}
+		return stats[i].LastJoinedAt.After(stats[j].LastJoinedAt)
+	})
+	return stats, nil
+}
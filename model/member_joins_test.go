@@ -0,0 +1,97 @@
+package model
+
+import (
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *ModelTestSuite) TestListInviteStats() {
+	guildID := snowflake.ID(123)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	joins := []MemberJoin{
+		{GuildID: guildID, UserID: 1, JoinedAt: now.Add(-3 * time.Hour), InviteCode: "abc", InviterID: 99, InviterUsername: "inviter"},
+		{GuildID: guildID, UserID: 2, JoinedAt: now.Add(-1 * time.Hour), InviteCode: "abc", InviterID: 99, InviterUsername: "inviter"},
+		{GuildID: guildID, UserID: 3, JoinedAt: now.Add(-2 * time.Hour), InviteCode: "vanity", Vanity: true},
+		{GuildID: guildID, UserID: 4, JoinedAt: now},
+		{GuildID: snowflake.ID(456), UserID: 5, JoinedAt: now, InviteCode: "abc"},
+	}
+	for i := range joins {
+		require.NoError(suite.T(), CreateMemberJoin(&joins[i]))
+	}
+
+	stats, err := ListInviteStats(guildID)
+	require.NoError(suite.T(), err)
+	require.Len(suite.T(), stats, 2, "unknown-invite joins and other guilds are excluded")
+
+	assert.Equal(suite.T(), "abc", stats[0].InviteCode)
+	assert.Equal(suite.T(), int64(2), stats[0].Joins)
+	assert.Equal(suite.T(), snowflake.ID(99), stats[0].InviterID)
+	assert.Equal(suite.T(), "inviter", stats[0].InviterUsername)
+	assert.False(suite.T(), stats[0].Vanity)
+	assert.True(suite.T(), stats[0].LastJoinedAt.Equal(now.Add(-1*time.Hour)), "got %v", stats[0].LastJoinedAt)
+
+	assert.Equal(suite.T(), "vanity", stats[1].InviteCode)
+	assert.True(suite.T(), stats[1].Vanity)
+}
+
+func (suite *ModelTestSuite) TestCreateMemberJoin_NumbersVisits() {
+	guildID := snowflake.ID(123)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	first := MemberJoin{GuildID: guildID, UserID: 1, JoinedAt: now.Add(-time.Hour)}
+	require.NoError(suite.T(), CreateMemberJoin(&first))
+	second := MemberJoin{GuildID: guildID, UserID: 1, JoinedAt: now}
+	require.NoError(suite.T(), CreateMemberJoin(&second))
+	otherGuild := MemberJoin{GuildID: snowflake.ID(456), UserID: 1, JoinedAt: now}
+	require.NoError(suite.T(), CreateMemberJoin(&otherGuild))
+
+	assert.Equal(suite.T(), 1, first.Visit)
+	assert.Equal(suite.T(), 2, second.Visit)
+	assert.Equal(suite.T(), 1, otherGuild.Visit, "visits are counted per guild")
+}
+
+func (suite *ModelTestSuite) TestRecordMemberLeave_ClosesOpenVisit() {
+	guildID := snowflake.ID(123)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	join := MemberJoin{GuildID: guildID, UserID: 1, JoinedAt: now.Add(-48 * time.Hour)}
+	require.NoError(suite.T(), CreateMemberJoin(&join))
+
+	closed, err := RecordMemberLeave(guildID, 1, now, nil)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), join.ID, closed.ID)
+	require.NotNil(suite.T(), closed.LeftAt)
+	assert.Equal(suite.T(), 48*time.Hour, closed.Tenure(time.Now()))
+
+	var stored MemberJoin
+	require.NoError(suite.T(), DB.First(&stored, join.ID).Error)
+	require.NotNil(suite.T(), stored.LeftAt)
+	assert.True(suite.T(), stored.LeftAt.Equal(now))
+
+	// A rejoin starts a new, open visit.
+	rejoin := MemberJoin{GuildID: guildID, UserID: 1, JoinedAt: now.Add(time.Hour)}
+	require.NoError(suite.T(), CreateMemberJoin(&rejoin))
+	assert.Equal(suite.T(), 2, rejoin.Visit)
+	assert.Nil(suite.T(), rejoin.LeftAt)
+}
+
+func (suite *ModelTestSuite) TestRecordMemberLeave_WithoutRecordedJoin() {
+	guildID := snowflake.ID(123)
+	now := time.Now().UTC().Truncate(time.Second)
+	joinedAt := now.Add(-30 * 24 * time.Hour)
+
+	closed, err := RecordMemberLeave(guildID, 1, now, &joinedAt)
+	require.NoError(suite.T(), err)
+	assert.NotZero(suite.T(), closed.ID)
+	assert.Equal(suite.T(), 1, closed.Visit)
+	assert.Equal(suite.T(), 30*24*time.Hour, closed.Tenure(now))
+
+	// Without a cached join time the visit is recorded with zero tenure.
+	closed, err = RecordMemberLeave(guildID, 2, now, nil)
+	require.NoError(suite.T(), err)
+	assert.Equal(suite.T(), time.Duration(0), closed.Tenure(now))
+}
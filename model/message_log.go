@@ -0,0 +1,41 @@
+package model
+
+import (
+	"github.com/disgoorg/snowflake/v2"
+	"gorm.io/gorm"
+)
+
+// MessageLogIgnoredChannel is a channel (and the threads in it) whose
+// edits and deletions are left out of the guild's MessageLogChannel feed.
+// They are still recorded in the audit log.
+type MessageLogIgnoredChannel struct {
+	GuildID   snowflake.ID `gorm:"primaryKey;autoIncrement:false"`
+	ChannelID snowflake.ID `gorm:"primaryKey;autoIncrement:false"`
+}
+
+func GetMessageLogIgnoredChannels(guildID snowflake.ID) ([]snowflake.ID, error) {
+	var channels []snowflake.ID
+	res := DB.Model(&MessageLogIgnoredChannel{}).Where("guild_id = ?", guildID).Order("channel_id").Pluck("channel_id", &channels)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return channels, nil
+}
+
+// SetMessageLogIgnoredChannels replaces the guild's ignored channels with
+// channels.
+func SetMessageLogIgnoredChannels(guildID snowflake.ID, channels []snowflake.ID) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("guild_id = ?", guildID).Delete(&MessageLogIgnoredChannel{}).Error; err != nil {
+			return err
+		}
+		if len(channels) == 0 {
+			return nil
+		}
+		rows := make([]MessageLogIgnoredChannel, 0, len(channels))
+		for _, c := range channels {
+			rows = append(rows, MessageLogIgnoredChannel{GuildID: guildID, ChannelID: c})
+		}
+		return tx.Create(&rows).Error
+	})
+}
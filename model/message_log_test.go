@@ -0,0 +1,26 @@
+package model
+
+import (
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *ModelTestSuite) TestMessageLogIgnoredChannels_Replace() {
+	t := suite.T()
+	require.NoError(t, SetMessageLogIgnoredChannels(1, []snowflake.ID{30, 20}))
+	require.NoError(t, SetMessageLogIgnoredChannels(2, []snowflake.ID{40}))
+
+	channels, err := GetMessageLogIgnoredChannels(1)
+	require.NoError(t, err)
+	assert.Equal(t, []snowflake.ID{20, 30}, channels)
+
+	require.NoError(t, SetMessageLogIgnoredChannels(1, nil))
+	channels, err = GetMessageLogIgnoredChannels(1)
+	require.NoError(t, err)
+	assert.Empty(t, channels)
+
+	channels, err = GetMessageLogIgnoredChannels(2)
+	require.NoError(t, err)
+	assert.Equal(t, []snowflake.ID{40}, channels, "other guilds are untouched")
+}
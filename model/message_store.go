@@ -0,0 +1,173 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"gorm.io/gorm"
+)
+
+// StoredMessage is a copy of a message kept for guilds with
+// MessageStoreEnabled, so edit and delete audit entries can carry the
+// original content after a restart or for messages that have fallen out of
+// disgo's cache. Rows are pruned with the guild's message retention.
+type StoredMessage struct {
+	MessageID snowflake.ID `gorm:"primaryKey;autoIncrement:false"`
+	GuildID   snowflake.ID `gorm:"index:idx_stored_messages_guild_created,priority:1"`
+	ChannelID snowflake.ID
+	AuthorID  snowflake.ID
+	// AuthorUsername is captured at write time so the author can be named
+	// after they leave the guild.
+	AuthorUsername string
+	Content        string `gorm:"type:text"`
+	// Attachments is the JSON-encoded []StoredAttachment; see
+	// AttachmentList and SetAttachments.
+	Attachments string `gorm:"type:text"`
+	// CreatedAt is when the message was sent, not when the row was
+	// written, so retention counts from the message's age.
+	CreatedAt time.Time `gorm:"index:idx_stored_messages_guild_created,priority:2"`
+	EditedAt  *time.Time
+}
+
+// StoredAttachment is the metadata kept for a stored message's
+// attachments. The file itself is not copied; URL stops working once
+// Discord expires it.
+type StoredAttachment struct {
+	ID          snowflake.ID `json:"id,string"`
+	Filename    string       `json:"filename"`
+	ContentType string       `json:"content_type,omitempty"`
+	Size        int          `json:"size"`
+	URL         string       `json:"url"`
+}
+
+// AttachmentList decodes Attachments. A malformed value decodes as none.
+func (m *StoredMessage) AttachmentList() []StoredAttachment {
+	if m.Attachments == "" {
+		return nil
+	}
+	var list []StoredAttachment
+	if err := json.Unmarshal([]byte(m.Attachments), &list); err != nil {
+		return nil
+	}
+	return list
+}
+
+// SetAttachments encodes list into Attachments.
+func (m *StoredMessage) SetAttachments(list []StoredAttachment) {
+	if len(list) == 0 {
+		m.Attachments = ""
+		return
+	}
+	b, _ := json.Marshal(list)
+	m.Attachments = string(b)
+}
+
+// MessageStoreChannel limits a guild's message store to the listed
+// channels (and the threads in them). A guild with no rows stores
+// messages from every channel.
+type MessageStoreChannel struct {
+	GuildID   snowflake.ID `gorm:"primaryKey;autoIncrement:false"`
+	ChannelID snowflake.ID `gorm:"primaryKey;autoIncrement:false"`
+}
+
+// SaveStoredMessage inserts or replaces the stored copy of a message.
+func SaveStoredMessage(m *StoredMessage) error {
+	return DB.Save(m).Error
+}
+
+// GetStoredMessage returns the stored copy of a message, or nil if there
+// is none.
+func GetStoredMessage(guildID, messageID snowflake.ID) (*StoredMessage, error) {
+	var m StoredMessage
+	res := DB.Where("guild_id = ? AND message_id = ?", guildID, messageID).First(&m)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return &m, nil
+}
+
+// GetStoredMessages returns the stored copies of the given messages, keyed
+// by message ID. Messages that aren't stored are left out.
+func GetStoredMessages(guildID snowflake.ID, messageIDs []snowflake.ID) (map[snowflake.ID]*StoredMessage, error) {
+	found := map[snowflake.ID]*StoredMessage{}
+	if len(messageIDs) == 0 {
+		return found, nil
+	}
+	var rows []StoredMessage
+	if err := DB.Where("guild_id = ? AND message_id IN ?", guildID, messageIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for i := range rows {
+		found[rows[i].MessageID] = &rows[i]
+	}
+	return found, nil
+}
+
+// UpdateStoredMessageContent records an edit to a stored message. It
+// reports whether the message was stored.
+func UpdateStoredMessageContent(guildID, messageID snowflake.ID, content string, editedAt time.Time) (bool, error) {
+	res := DB.Model(&StoredMessage{}).
+		Where("guild_id = ? AND message_id = ?", guildID, messageID).
+		Updates(map[string]any{"content": content, "edited_at": editedAt})
+	return res.RowsAffected > 0, res.Error
+}
+
+// PruneStoredMessagesBefore deletes the guild's stored messages sent
+// before cutoff and returns how many were removed.
+func PruneStoredMessagesBefore(ctx context.Context, guildID snowflake.ID, cutoff time.Time) (int64, error) {
+	if cutoff.IsZero() {
+		return 0, nil
+	}
+	res := DB.WithContext(ctx).
+		Where("guild_id = ? AND created_at < ?", guildID, cutoff).
+		Delete(&StoredMessage{})
+	return res.RowsAffected, res.Error
+}
+
+// DeleteStoredMessages deletes every stored message of the guild, for
+// when it turns the store off.
+func DeleteStoredMessages(guildID snowflake.ID) (int64, error) {
+	res := DB.Where("guild_id = ?", guildID).Delete(&StoredMessage{})
+	return res.RowsAffected, res.Error
+}
+
+// DistinctStoredMessageGuilds returns every guild ID with at least one
+// stored message.
+func DistinctStoredMessageGuilds() ([]snowflake.ID, error) {
+	var ids []snowflake.ID
+	res := DB.Model(&StoredMessage{}).Distinct("guild_id").Pluck("guild_id", &ids)
+	return ids, res.Error
+}
+
+func GetMessageStoreChannels(guildID snowflake.ID) ([]snowflake.ID, error) {
+	var channels []snowflake.ID
+	res := DB.Model(&MessageStoreChannel{}).Where("guild_id = ?", guildID).Order("channel_id").Pluck("channel_id", &channels)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return channels, nil
+}
+
+// SetMessageStoreChannels replaces the guild's monitored channels with
+// channels.
+func SetMessageStoreChannels(guildID snowflake.ID, channels []snowflake.ID) error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("guild_id = ?", guildID).Delete(&MessageStoreChannel{}).Error; err != nil {
+			return err
+		}
+		if len(channels) == 0 {
+			return nil
+		}
+		rows := make([]MessageStoreChannel, 0, len(channels))
+		for _, c := range channels {
+			rows = append(rows, MessageStoreChannel{GuildID: guildID, ChannelID: c})
+		}
+		return tx.Create(&rows).Error
+	})
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"context"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *ModelTestSuite) TestStoredMessage_SaveUpdatePrune() {
+	t := suite.T()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	old := &StoredMessage{MessageID: 10, GuildID: 1, ChannelID: 5, AuthorID: 7, AuthorUsername: "alice", Content: "old", CreatedAt: now.Add(-48 * time.Hour)}
+	recent := &StoredMessage{MessageID: 11, GuildID: 1, ChannelID: 5, AuthorID: 7, Content: "hello", CreatedAt: now}
+	recent.SetAttachments([]StoredAttachment{{ID: 99, Filename: "cat.png", Size: 123, URL: "https://cdn.example/cat.png"}})
+	require.NoError(t, SaveStoredMessage(old))
+	require.NoError(t, SaveStoredMessage(recent))
+	require.NoError(t, SaveStoredMessage(&StoredMessage{MessageID: 12, GuildID: 2, CreatedAt: now.Add(-48 * time.Hour)}))
+
+	m, err := GetStoredMessage(1, 11)
+	require.NoError(t, err)
+	require.NotNil(t, m)
+	assert.Equal(t, "hello", m.Content)
+	assert.Equal(t, []StoredAttachment{{ID: 99, Filename: "cat.png", Size: 123, URL: "https://cdn.example/cat.png"}}, m.AttachmentList())
+
+	m, err = GetStoredMessage(2, 11)
+	require.NoError(t, err)
+	assert.Nil(t, m, "lookups are scoped to the guild")
+
+	many, err := GetStoredMessages(1, []snowflake.ID{10, 11, 12, 404})
+	require.NoError(t, err)
+	require.Len(t, many, 2, "unstored and other guilds' messages are left out")
+	assert.Equal(t, "old", many[10].Content)
+	assert.Equal(t, "hello", many[11].Content)
+
+	ok, err := UpdateStoredMessageContent(1, 11, "hello, edited", now)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = UpdateStoredMessageContent(1, 404, "x", now)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	m, err = GetStoredMessage(1, 11)
+	require.NoError(t, err)
+	assert.Equal(t, "hello, edited", m.Content)
+	require.NotNil(t, m.EditedAt)
+
+	deleted, err := PruneStoredMessagesBefore(context.Background(), 1, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	guilds, err := DistinctStoredMessageGuilds()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []snowflake.ID{1, 2}, guilds)
+
+	deleted, err = DeleteStoredMessages(1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
+
+func (suite *ModelTestSuite) TestMessageStoreChannels_Replace() {
+	t := suite.T()
+	require.NoError(t, SetMessageStoreChannels(1, []snowflake.ID{30, 20}))
+
+	channels, err := GetMessageStoreChannels(1)
+	require.NoError(t, err)
+	assert.Equal(t, []snowflake.ID{20, 30}, channels)
+
+	require.NoError(t, SetMessageStoreChannels(1, nil))
+	channels, err = GetMessageStoreChannels(1)
+	require.NoError(t, err)
+	assert.Empty(t, channels)
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"log/slog"
+	"strings"
 
 	"github.com/glebarez/sqlite"
 	"github.com/sqids/sqids-go"
@@ -20,8 +21,20 @@ func init() {
 	}
 }
 
+// writeLockParams make every transaction take the write lock up front and
+// wait up to 5s for it. SQLite can't upgrade a read lock to a write lock
+// while another connection is writing; it fails with SQLITE_BUSY at once
+// rather than waiting. gorm wraps each Create in a transaction, and the
+// audit log's search triggers make those inserts long enough for
+// concurrent gateway writes to hit that regularly.
+const writeLockParams = "_txlock=immediate&_pragma=busy_timeout(5000)"
+
 func InitDB(path string) (*gorm.DB, error) {
-	db, err := gorm.Open(sqlite.Open(path))
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	db, err := gorm.Open(sqlite.Open(path + sep + writeLockParams))
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +50,19 @@ func InitDB(path string) (*gorm.DB, error) {
 		&Post{},
 		&PostMessage{},
 		&AuditLogEntry{},
+		&MemberJoin{},
+		&GuildLockdown{},
+		&ChannelLock{},
+		&AntiSpamExemptRole{},
+		&AntiSpamChannelOverride{},
+		&WordFilterEntry{},
+		&PhishingDomain{},
+		&StoredMessage{},
+		&MessageStoreChannel{},
+		&MessageLogIgnoredChannel{},
+		&AuditStreamMutedEvent{},
+		&WebhookDelivery{},
+		&AuditLogChainCheckpoint{},
 	)
 	if err == nil {
 		// Drop the legacy login-code table left over from the magic-link
@@ -64,6 +90,10 @@ func InitDB(path string) (*gorm.DB, error) {
 		slog.Warn("failed to create audit_log channel_id expression index", "error", err)
 	}
 
+	if err := setupAuditLogSearch(db); err != nil {
+		slog.Warn("failed to set up audit log full-text search", "error", err)
+	}
+
 	DB = db
 	return db, nil
 }
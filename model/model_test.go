@@ -53,6 +53,19 @@ func (suite *ModelTestSuite) SetupTest() {
 	suite.db.Exec("DELETE FROM post_messages")
 	suite.db.Exec("DELETE FROM audit_log_entries")
 	suite.db.Exec("DELETE FROM member_pending_prunes")
+	suite.db.Exec("DELETE FROM member_joins")
+	suite.db.Exec("DELETE FROM guild_lockdowns")
+	suite.db.Exec("DELETE FROM channel_locks")
+	suite.db.Exec("DELETE FROM anti_spam_exempt_roles")
+	suite.db.Exec("DELETE FROM anti_spam_channel_overrides")
+	suite.db.Exec("DELETE FROM word_filter_entries")
+	suite.db.Exec("DELETE FROM phishing_domains")
+	suite.db.Exec("DELETE FROM stored_messages")
+	suite.db.Exec("DELETE FROM message_store_channels")
+	suite.db.Exec("DELETE FROM message_log_ignored_channels")
+	suite.db.Exec("DELETE FROM audit_stream_muted_events")
+	suite.db.Exec("DELETE FROM webhook_deliveries")
+	suite.db.Exec("DELETE FROM audit_log_chain_checkpoints")
 }
 
 func TestModelSuite(t *testing.T) {
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"github.com/disgoorg/snowflake/v2"
+	"gorm.io/gorm"
+)
+
+// PhishingDomain is a guild's addition to the bot-wide phishing
+// blocklist: a domain to block as well (Allow false), or one to never
+// block even if the blocklist has it (Allow true). Domains are stored
+// normalized, as antispam.NormalizeDomain returns them.
+type PhishingDomain struct {
+	GuildID snowflake.ID `gorm:"primaryKey;autoIncrement:false"`
+	Domain  string       `gorm:"primaryKey"`
+	Allow   bool
+}
+
+// GetPhishingDomains returns the guild's allowed and blocked domains.
+func GetPhishingDomains(guildID snowflake.ID) (allow, deny []string, err error) {
+	var rows []PhishingDomain
+	res := DB.Where("guild_id = ?", guildID).Order("domain").Find(&rows)
+	if res.Error != nil {
+		return nil, nil, res.Error
+	}
+	for _, r := range rows {
+		if r.Allow {
+			allow = append(allow, r.Domain)
+		} else {
+			deny = append(deny, r.Domain)
+		}
+	}
+	return allow, deny, nil
+}
+
+// SetPhishingDomains replaces the guild's allowed and blocked domains. A
+// domain in both lists is allowed.
+func SetPhishingDomains(guildID snowflake.ID, allow, deny []string) error {
+	rows := map[string]PhishingDomain{}
+	for _, d := range deny {
+		rows[d] = PhishingDomain{GuildID: guildID, Domain: d}
+	}
+	for _, d := range allow {
+		rows[d] = PhishingDomain{GuildID: guildID, Domain: d, Allow: true}
+	}
+
+	return DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("guild_id = ?", guildID).Delete(&PhishingDomain{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		list := make([]PhishingDomain, 0, len(rows))
+		for _, r := range rows {
+			list = append(list, r)
+		}
+		return tx.Create(&list).Error
+	})
+}
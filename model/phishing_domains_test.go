@@ -0,0 +1,22 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *ModelTestSuite) TestPhishingDomains_Replace() {
+	t := suite.T()
+	require.NoError(t, SetPhishingDomains(1, []string{"example.com", "both.com"}, []string{"scam.com", "both.com"}))
+
+	allow, deny, err := GetPhishingDomains(1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"both.com", "example.com"}, allow, "a domain in both lists is allowed")
+	assert.Equal(t, []string{"scam.com"}, deny)
+
+	require.NoError(t, SetPhishingDomains(1, nil, []string{"other.com"}))
+	allow, deny, err = GetPhishingDomains(1)
+	require.NoError(t, err)
+	assert.Empty(t, allow)
+	assert.Equal(t, []string{"other.com"}, deny)
+}
@@ -0,0 +1,70 @@
+package model
+
+import (
+	"time"
+)
+
+// WebhookDelivery is an event waiting to be POSTed to an outgoing webhook
+// endpoint. Rows are the delivery queue: they are written when the event
+// happens, deleted once the endpoint accepts them, and rescheduled with
+// NextAttemptAt when it doesn't, so pending events survive restarts.
+//
+// Endpoint is the endpoint's configured name; Payload is the exact body
+// to send, so a retry signs and sends the same bytes.
+type WebhookDelivery struct {
+	ID            uint      `gorm:"primarykey"`
+	CreatedAt     time.Time `gorm:"not null"`
+	Endpoint      string    `gorm:"not null;index:idx_webhook_delivery_due,priority:1"`
+	EventID       string    `gorm:"not null"`
+	EventType     string    `gorm:"not null"`
+	Payload       string    `gorm:"not null"`
+	Attempts      int       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"not null;index:idx_webhook_delivery_due,priority:2"`
+	LastError     string
+}
+
+// EnqueueWebhookDeliveries adds deliveries to the queue.
+func EnqueueWebhookDeliveries(deliveries []WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return DB.Create(&deliveries).Error
+}
+
+// DueWebhookDeliveries returns up to limit of the endpoint's deliveries
+// whose next attempt is at or before now, oldest first.
+func DueWebhookDeliveries(endpoint string, now time.Time, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	res := DB.Where("endpoint = ? AND next_attempt_at <= ?", endpoint, now).Order("id").Limit(limit).Find(&deliveries)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return deliveries, nil
+}
+
+// RescheduleWebhookDelivery records a failed attempt and when to try again.
+func RescheduleWebhookDelivery(id uint, attempts int, next time.Time, lastError string) error {
+	return DB.Model(&WebhookDelivery{}).Where("id = ?", id).Updates(map[string]any{
+		"attempts":        attempts,
+		"next_attempt_at": next,
+		"last_error":      lastError,
+	}).Error
+}
+
+func DeleteWebhookDelivery(id uint) error {
+	return DB.Delete(&WebhookDelivery{}, id).Error
+}
+
+// DeleteWebhookDeliveriesExcept drops queued deliveries for endpoints not
+// in endpoints, e.g. ones removed from the config, and returns how many
+// were dropped.
+func DeleteWebhookDeliveriesExcept(endpoints []string) (int64, error) {
+	q := DB.Model(&WebhookDelivery{})
+	if len(endpoints) > 0 {
+		q = q.Where("endpoint NOT IN ?", endpoints)
+	} else {
+		q = q.Where("1 = 1")
+	}
+	res := q.Delete(&WebhookDelivery{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *ModelTestSuite) TestWebhookDeliveries_Queue() {
+	t := suite.T()
+	now := time.Now()
+	require.NoError(t, EnqueueWebhookDeliveries([]WebhookDelivery{
+		{Endpoint: "a", EventID: "1", EventType: "guild.ban", Payload: "{}", NextAttemptAt: now.Add(-time.Second)},
+		{Endpoint: "b", EventID: "1", EventType: "guild.ban", Payload: "{}", NextAttemptAt: now.Add(time.Hour)},
+		{Endpoint: "a", EventID: "2", EventType: "guild.kick", Payload: "{}", NextAttemptAt: now.Add(-time.Second)},
+	}))
+
+	due, err := DueWebhookDeliveries("b", now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due, "b's delivery isn't due yet")
+
+	due, err = DueWebhookDeliveries("a", now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 2)
+	assert.Equal(t, "1", due[0].EventID)
+	assert.Equal(t, "2", due[1].EventID)
+
+	require.NoError(t, RescheduleWebhookDelivery(due[0].ID, 1, now.Add(time.Minute), "boom"))
+	require.NoError(t, DeleteWebhookDelivery(due[1].ID))
+
+	due, err = DueWebhookDeliveries("a", now.Add(2*time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, 1, due[0].Attempts)
+	assert.Equal(t, "boom", due[0].LastError)
+
+	dropped, err := DeleteWebhookDeliveriesExcept([]string{"a"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), dropped, "endpoint b is no longer configured")
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+)
+
+// WordFilterEntry is one word or regular expression on a guild's content
+// filter. Action is a wordfilter.Action value; Severity is the infraction
+// weight when Action is "warn".
+type WordFilterEntry struct {
+	ID        uint         `gorm:"primaryKey"`
+	GuildID   snowflake.ID `gorm:"index"`
+	CreatedAt time.Time    `gorm:"autoCreateTime"`
+	CreatedBy snowflake.ID
+
+	Pattern string
+	// Regex entries are matched as Go regular expressions. Other entries
+	// are words or phrases, matched after leetspeak normalization,
+	// anywhere in the message unless WholeWord is set.
+	Regex     bool
+	WholeWord bool
+
+	Action   string
+	Severity float64
+}
+
+func GetWordFilterEntries(guildID snowflake.ID) ([]WordFilterEntry, error) {
+	var entries []WordFilterEntry
+	res := DB.Where("guild_id = ?", guildID).Order("id").Find(&entries)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return entries, nil
+}
+
+func CreateWordFilterEntry(entry *WordFilterEntry) error {
+	return DB.Create(entry).Error
+}
+
+// DeleteWordFilterEntry removes the guild's entry with the given ID.
+// Returns false without an error if the guild has no such entry.
+func DeleteWordFilterEntry(guildID snowflake.ID, id uint) (bool, error) {
+	res := DB.Where("guild_id = ? AND id = ?", guildID, id).Delete(&WordFilterEntry{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (suite *ModelTestSuite) TestWordFilterEntries() {
+	t := suite.T()
+	require.NoError(t, CreateWordFilterEntry(&WordFilterEntry{GuildID: 1, Pattern: "spam", Action: "delete"}))
+	require.NoError(t, CreateWordFilterEntry(&WordFilterEntry{GuildID: 1, Pattern: `free\s+nitro`, Regex: true, Action: "warn", Severity: 2}))
+	require.NoError(t, CreateWordFilterEntry(&WordFilterEntry{GuildID: 2, Pattern: "other", Action: "alert"}))
+
+	entries, err := GetWordFilterEntries(1)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "spam", entries[0].Pattern)
+	assert.True(t, entries[1].Regex)
+
+	deleted, err := DeleteWordFilterEntry(2, entries[0].ID)
+	require.NoError(t, err)
+	assert.False(t, deleted, "entries can only be deleted from their own guild")
+
+	deleted, err = DeleteWordFilterEntry(1, entries[0].ID)
+	require.NoError(t, err)
+	assert.True(t, deleted)
+
+	entries, err = GetWordFilterEntries(1)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,20 @@
+package scheduled_tasks
+
+import (
+	"context"
+	"time"
+
+	"github.com/NLLCommunity/heimdallr/listeners"
+	"github.com/NLLCommunity/heimdallr/task"
+)
+
+func RemoveExpiredJoinInvites() task.Task {
+	t := task.New("remove-expired-join-invites", removeExpiredJoinInvites, nil, 1*time.Minute, true)
+	t.StartNoWait()
+
+	return t
+}
+
+func removeExpiredJoinInvites(ctx context.Context) {
+	listeners.RemoveExpiredJoinInvites()
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/disgoorg/snowflake/v2"
@@ -21,6 +22,9 @@ import (
 //
 // 0 (in either bot config or guild override) means "forever"; the pruner
 // skips those (guild, category) pairs entirely.
+//
+// Guilds with AuditArchiveEnabled have their expiring entries written to
+// the archive directory first; see audit.Archive.
 func PruneAuditLogScheduledTask() task.Task {
 	interval := time.Duration(viper.GetInt("audit_log.prune_interval_hours")) * time.Hour
 	if interval <= 0 {
@@ -51,12 +55,17 @@ func pruneAuditLog(ctx context.Context) {
 			continue
 		}
 
+		archiveDir := ""
+		if settings.AuditArchiveEnabled {
+			archiveDir = ArchiveDir()
+		}
+
 		for _, c := range []categoryRetention{
 			{audit.CategoryMessage, "audit_log.message_retention_days", settings.AuditMessageRetentionDays},
 			{audit.CategoryMember, "audit_log.member_retention_days", settings.AuditMemberRetentionDays},
 			{audit.CategoryGuild, "audit_log.guild_retention_days", settings.AuditGuildRetentionDays},
 		} {
-			deleted, err := pruneCategory(ctx, guildID, c)
+			deleted, err := pruneCategory(ctx, guildID, c, archiveDir)
 			if err != nil {
 				slog.Warn("audit pruner: prune failed",
 					"err", err, "guild_id", guildID, "category", c.category)
@@ -66,15 +75,19 @@ func pruneAuditLog(ctx context.Context) {
 		}
 	}
 
+	storedDeleted := pruneStoredMessages(ctx)
+
 	totalDeleted := totals[string(audit.CategoryMessage)] +
 		totals[string(audit.CategoryMember)] +
-		totals[string(audit.CategoryGuild)]
+		totals[string(audit.CategoryGuild)] +
+		storedDeleted
 
 	slog.Info("audit pruner finished",
 		"guilds", len(guildIDs),
 		"deleted_message", totals[string(audit.CategoryMessage)],
 		"deleted_member", totals[string(audit.CategoryMember)],
 		"deleted_guild", totals[string(audit.CategoryGuild)],
+		"deleted_stored_messages", storedDeleted,
 	)
 
 	if failed := audit.DrainCommitFailures(); failed > 0 {
@@ -105,20 +118,69 @@ func pruneAuditLog(ctx context.Context) {
 	}
 }
 
+// pruneStoredMessages deletes stored messages past the guild's message
+// retention, the same window that applies to message audit entries.
+func pruneStoredMessages(ctx context.Context) int64 {
+	guildIDs, err := model.DistinctStoredMessageGuilds()
+	if err != nil {
+		slog.Warn("audit pruner: failed to list message store guilds", "err", err)
+		return 0
+	}
+
+	var total int64
+	for _, guildID := range guildIDs {
+		settings, err := model.GetGuildSettings(guildID)
+		if err != nil {
+			slog.Warn("audit pruner: failed to read guild settings", "err", err, "guild_id", guildID)
+			continue
+		}
+		days, ok := EffectiveRetentionDays("audit_log.message_retention_days", settings.AuditMessageRetentionDays)
+		if !ok {
+			continue
+		}
+		cutoff := time.Now().UTC().Add(-time.Duration(days) * 24 * time.Hour)
+		deleted, err := model.PruneStoredMessagesBefore(ctx, guildID, cutoff)
+		if err != nil {
+			slog.Warn("audit pruner: stored message prune failed", "err", err, "guild_id", guildID)
+			continue
+		}
+		total += deleted
+	}
+	return total
+}
+
 type categoryRetention struct {
 	category   audit.Category
 	configKey  string
 	guildValue *uint
 }
 
-func pruneCategory(ctx context.Context, guildID snowflake.ID, c categoryRetention) (int64, error) {
+// pruneCategory prunes one category, archiving the entries to archiveDir
+// first unless it is empty. A failed archive write leaves the entries in
+// place for the next run.
+func pruneCategory(ctx context.Context, guildID snowflake.ID, c categoryRetention, archiveDir string) (int64, error) {
 	days, ok := EffectiveRetentionDays(c.configKey, c.guildValue)
 	if !ok {
 		// Forever: do not prune.
 		return 0, nil
 	}
 	cutoff := time.Now().UTC().Add(-time.Duration(days) * 24 * time.Hour)
-	return model.PruneAuditLogEntriesBefore(ctx, guildID, string(c.category), cutoff)
+	through, err := model.AuditLogPruneBoundary(ctx, guildID, string(c.category), cutoff)
+	if err != nil || through == 0 {
+		return 0, err
+	}
+	if archiveDir != "" {
+		if _, err := audit.Archive(ctx, archiveDir, guildID, string(c.category), through); err != nil {
+			return 0, fmt.Errorf("archive before prune: %w", err)
+		}
+	}
+	return model.PruneAuditLogEntriesThrough(ctx, guildID, string(c.category), through)
+}
+
+// ArchiveDir is the configured audit log archive directory, or "" when
+// archiving is unavailable.
+func ArchiveDir() string {
+	return strings.TrimSpace(viper.GetString("audit_log.archive_dir"))
 }
 
 // EffectiveRetentionDays resolves the effective retention window in days for
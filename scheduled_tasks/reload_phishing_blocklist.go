@@ -0,0 +1,24 @@
+package scheduled_tasks
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/NLLCommunity/heimdallr/antispam"
+	"github.com/NLLCommunity/heimdallr/task"
+)
+
+// ReloadPhishingBlocklistScheduledTask rereads the phishing blocklist when
+// its files change, so operators can update it without a restart.
+func ReloadPhishingBlocklistScheduledTask() task.Task {
+	t := task.New("reload-phishing-blocklist", reloadPhishingBlocklist, nil, 30*time.Second, true)
+	t.StartNoWait()
+	return t
+}
+
+func reloadPhishingBlocklist(ctx context.Context) {
+	if _, err := antispam.PhishingBlocklist.Reload(); err != nil {
+		slog.Warn("Failed to reload phishing blocklist.", "err", err)
+	}
+}
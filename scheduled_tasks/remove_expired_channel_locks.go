@@ -0,0 +1,52 @@
+package scheduled_tasks
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/interactions/lock"
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/task"
+)
+
+func RemoveExpiredChannelLocksScheduledTask(client *bot.Client) task.Task {
+	values := task.ContextKeyMap{
+		task.ContextKeyBotClientRef: client,
+	}
+
+	t := task.New("remove-expired-channel-locks", removeExpiredChannelLocks, values, time.Minute, true)
+	t.StartNoWait()
+
+	return t
+}
+
+func removeExpiredChannelLocks(ctx context.Context) {
+	client, hasClient := ctx.Value(task.ContextKeyBotClientRef).(*bot.Client)
+	if !hasClient {
+		slog.Error("could not retrieve client for removing expired channel locks")
+		return
+	}
+
+	locks, err := model.GetExpiredChannelLocks()
+	if err != nil {
+		slog.Error("Failed to get expired channel locks.", "error", err)
+		return
+	}
+
+	for _, l := range locks {
+		// A failed unlock keeps its row, so the next run retries it.
+		err := lock.UnlockChannel(client, l, nil, "", audit.SourceGateway)
+		if err != nil {
+			slog.Error(
+				"Failed to remove expired channel lock.",
+				"guild_id", l.GuildID,
+				"channel_id", l.ChannelID,
+				"error", err,
+			)
+		}
+	}
+}
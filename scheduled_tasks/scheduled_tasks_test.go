@@ -12,6 +12,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/NLLCommunity/heimdallr/audit"
 	"github.com/NLLCommunity/heimdallr/model"
 )
 
@@ -141,3 +142,54 @@ func TestEffectiveRetentionDays(t *testing.T) {
 		})
 	}
 }
+
+func (suite *ScheduledTasksTestSuite) TestPruneStoredMessages() {
+	t := suite.T()
+	const key = "audit_log.message_retention_days"
+	viper.Set(key, 7)
+	t.Cleanup(func() { viper.Set(key, 0) })
+	model.DB.Exec("DELETE FROM stored_messages")
+
+	now := time.Now().UTC()
+	require.NoError(t, model.SaveStoredMessage(&model.StoredMessage{MessageID: 1, GuildID: 100, CreatedAt: now.Add(-8 * 24 * time.Hour)}))
+	require.NoError(t, model.SaveStoredMessage(&model.StoredMessage{MessageID: 2, GuildID: 100, CreatedAt: now.Add(-time.Hour)}))
+
+	assert.Equal(t, int64(1), pruneStoredMessages(context.Background()))
+
+	m, err := model.GetStoredMessage(100, 2)
+	require.NoError(t, err)
+	assert.NotNil(t, m, "messages within retention are kept")
+}
+
+func (suite *ScheduledTasksTestSuite) TestPruneCategory_ArchivesFirst() {
+	t := suite.T()
+	const key = "audit_log.message_retention_days"
+	viper.Set(key, 7)
+	t.Cleanup(func() { viper.Set(key, 0) })
+
+	guildID := snowflake.ID(200)
+	now := time.Now().UTC()
+	for _, at := range []time.Time{now.Add(-8 * 24 * time.Hour), now.Add(-time.Hour)} {
+		require.NoError(t, model.CreateAuditLogEntry(&model.AuditLogEntry{
+			GuildID: guildID, Category: "message", EventType: "message.delete",
+			ActorKind: "user", TargetKind: "message", Source: "gateway", CreatedAt: at,
+		}))
+	}
+
+	dir := t.TempDir()
+	c := categoryRetention{audit.CategoryMessage, key, nil}
+	deleted, err := pruneCategory(context.Background(), guildID, c, dir)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, deleted)
+
+	var archived int
+	require.NoError(t, audit.SearchArchive(context.Background(), dir, guildID, audit.ArchiveQuery{}, func(audit.ArchiveRecord) error {
+		archived++
+		return nil
+	}))
+	assert.Equal(t, 1, archived, "the pruned entry was archived")
+
+	report, err := model.VerifyAuditLogChain(context.Background(), guildID)
+	require.NoError(t, err)
+	assert.True(t, report.OK())
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+)
+
+// searchAuditArchive prints the archived audit log entries of a guild that
+// match the query as JSON lines, oldest first. from and to are inclusive
+// YYYY-MM-DD dates and may be empty. It returns the process exit code:
+// 0 on success, 2 when the arguments are invalid or the search failed.
+func searchAuditArchive(w io.Writer, dir string, guildID snowflake.ID, from, to, text string) int {
+	if dir == "" {
+		fmt.Fprintln(w, "audit_log.archive_dir is not set")
+		return 2
+	}
+	q := audit.ArchiveQuery{Text: text}
+	if from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			fmt.Fprintf(w, "invalid -archive-from date: %v\n", err)
+			return 2
+		}
+		q.From = t
+	}
+	if to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			fmt.Fprintf(w, "invalid -archive-to date: %v\n", err)
+			return 2
+		}
+		q.To = t.Add(24 * time.Hour)
+	}
+
+	enc := json.NewEncoder(w)
+	err := audit.SearchArchive(context.Background(), dir, guildID, q, func(rec audit.ArchiveRecord) error {
+		return enc.Encode(rec)
+	})
+	if err != nil {
+		fmt.Fprintf(w, "search failed: %v\n", err)
+		return 2
+	}
+	return 0
+}
@@ -11,6 +11,12 @@ import (
 type MessageTemplateData struct {
 	User   TemplateUserData
 	Server TemplateGuildData
+	// Invite is only populated for join messages, and only when the
+	// invite tracker could attribute the join.
+	Invite TemplateInviteData
+	// History describes the member's visit: the one starting for join
+	// messages, the one ending for leave messages.
+	History TemplateHistoryData
 }
 
 type TemplateUserData struct {
@@ -29,6 +35,19 @@ type TemplateGuildData struct {
 	ID   snowflake.ID
 }
 
+type TemplateInviteData struct {
+	Code    string
+	Inviter string
+}
+
+type TemplateHistoryData struct {
+	Visit        int
+	VisitOrdinal string
+	Returning    bool
+	Tenure       string
+	AccountAge   string
+}
+
 func NewMessageTemplateData(user discord.Member, guild discord.Guild) MessageTemplateData {
 	return MessageTemplateData{
 		User: TemplateUserData{
@@ -66,6 +85,13 @@ var MessageTemplatePlaceholders = []MessageTemplatePlaceholder{
 	{"{{User.ID}}", "The user's ID"},
 	{"{{Server.Name}}", "The server name"},
 	{"{{Server.ID}}", "The server ID"},
+	{"{{Invite.Code}}", "Invite code the member joined with (join messages only; empty if unknown)"},
+	{"{{Invite.Inviter}}", "Username of the invite's creator (join messages only; empty for the vanity URL)"},
+	{"{{History.Visit}}", "How many times the member has joined, counting this one (e.g. 3)"},
+	{"{{History.VisitOrdinal}}", `The visit count as an ordinal (e.g. "3rd")`},
+	{"{{#History.Returning}}…{{/History.Returning}}", "Shown only if the member has been here before"},
+	{"{{History.Tenure}}", `How long the member stayed (leave messages only; e.g. "3 months")`},
+	{"{{History.AccountAge}}", `Account age when the member joined (e.g. "2 years")`},
 }
 
 // MessageTemplateInfo returns a formatted listing of available join/leave/
@@ -151,6 +151,47 @@ func DurationToHumanReadable(duration time.Duration) string {
 	return HumanList(parts)
 }
 
+// ApproxDuration renders a duration as its single largest unit, e.g.
+// "2 years" or "5 days". Meant for tenure-style phrasing in member-facing
+// messages, where DurationToHumanReadable's full breakdown is too precise.
+func ApproxDuration(duration time.Duration) string {
+	units := []struct {
+		size             time.Duration
+		singular, plural string
+	}{
+		{365 * 24 * time.Hour, "%d year", "%d years"},
+		{30 * 24 * time.Hour, "%d month", "%d months"},
+		{7 * 24 * time.Hour, "%d week", "%d weeks"},
+		{24 * time.Hour, "%d day", "%d days"},
+		{time.Hour, "%d hour", "%d hours"},
+		{time.Minute, "%d minute", "%d minutes"},
+	}
+	for _, u := range units {
+		if n := int(duration / u.size); n > 0 {
+			return Pluralizef(n, u.singular, u.plural)
+		}
+	}
+	return "less than a minute"
+}
+
+// Ordinal returns n with its English ordinal suffix: 1st, 2nd, 3rd, 11th.
+func Ordinal(n int) string {
+	suffix := "th"
+	switch n % 100 {
+	case 11, 12, 13:
+	default:
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
+}
+
 func Pluralize(count int, singular, plural string) string {
 	if count == 1 {
 		return singular
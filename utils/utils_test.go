@@ -519,3 +519,33 @@ func TestDurationToHumanReadable(t *testing.T) {
 		})
 	}
 }
+
+func TestApproxDuration(t *testing.T) {
+	tests := []struct {
+		in       time.Duration
+		expected string
+	}{
+		{30 * time.Second, "less than a minute"},
+		{90 * time.Second, "1 minute"},
+		{5 * time.Hour, "5 hours"},
+		{3*24*time.Hour + 5*time.Hour, "3 days"},
+		{45 * 24 * time.Hour, "1 month"},
+		{800 * 24 * time.Hour, "2 years"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ApproxDuration(tt.in))
+		})
+	}
+}
+
+func TestOrdinal(t *testing.T) {
+	tests := map[int]string{
+		1: "1st", 2: "2nd", 3: "3rd", 4: "4th",
+		11: "11th", 12: "12th", 13: "13th",
+		21: "21st", 22: "22nd", 101: "101st", 111: "111th",
+	}
+	for in, expected := range tests {
+		assert.Equal(t, expected, Ordinal(in))
+	}
+}
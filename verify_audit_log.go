@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// verifyAuditLog checks every guild's audit log hash chain and prints a
+// line per guild, plus one per break found. It returns the process exit
+// code: 0 when every chain verifies, 1 when any is broken, 2 when the
+// check itself failed.
+func verifyAuditLog(w io.Writer) int {
+	guildIDs, err := model.AuditLogChainGuilds()
+	if err != nil {
+		fmt.Fprintf(w, "failed to list guilds: %v\n", err)
+		return 2
+	}
+	code := 0
+	for _, guildID := range guildIDs {
+		report, err := model.VerifyAuditLogChain(context.Background(), guildID)
+		if err != nil {
+			fmt.Fprintf(w, "guild %d: verification failed: %v\n", guildID, err)
+			return 2
+		}
+		status := "ok"
+		if !report.OK() {
+			status = fmt.Sprintf("BROKEN (%d problems)", report.BreakCount)
+			code = 1
+		}
+		fmt.Fprintf(w, "guild %d: %s, %d entries verified, %d written before the chain\n",
+			guildID, status, report.Verified, report.Unchained)
+		for _, b := range report.Breaks {
+			fmt.Fprintf(w, "  %s entry %d: %s\n", b.Category, b.EntryID, b.Problem)
+		}
+		if extra := report.BreakCount - len(report.Breaks); extra > 0 {
+			fmt.Fprintf(w, "  … and %d more\n", extra)
+		}
+	}
+	if len(guildIDs) == 0 {
+		fmt.Fprintln(w, "audit log is empty")
+	}
+	return code
+}
@@ -0,0 +1,187 @@
+package web
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/web/templates/partials"
+)
+
+// maxAntiSpamChannelOverrides caps the override table so a form can't
+// insert an unbounded number of rows.
+const maxAntiSpamChannelOverrides = 50
+
+func buildAntiSpamExemptionsData(client *bot.Client, guildIDStr string, guildID snowflake.ID) partials.AntiSpamExemptionsData {
+	data := partials.AntiSpamExemptionsData{
+		GuildID:  guildIDStr,
+		Roles:    rolesWithoutEveryone(guildRoles(client, guildID), guildIDStr),
+		Channels: guildChannels(client, guildID),
+	}
+
+	roles, err := model.GetAntiSpamExemptRoles(guildID)
+	if err != nil {
+		slog.Warn("anti-spam: failed to load exempt roles", "guild_id", guildID, "err", err)
+		data.SaveError = "Failed to load exemptions."
+	}
+	for _, r := range roles {
+		data.ExemptRoles = append(data.ExemptRoles, r.String())
+	}
+
+	overrides, err := model.GetAntiSpamChannelOverrides(guildID)
+	if err != nil {
+		slog.Warn("anti-spam: failed to load channel overrides", "guild_id", guildID, "err", err)
+		data.SaveError = "Failed to load exemptions."
+	}
+	for _, o := range overrides {
+		data.Overrides = append(data.Overrides, partials.AntiSpamOverrideRow{
+			ChannelID:       o.ChannelID.String(),
+			Exempt:          o.Exempt,
+			Count:           o.Count,
+			CooldownSeconds: o.CooldownSeconds,
+		})
+	}
+	// A blank row for adding another override.
+	data.Overrides = append(data.Overrides, partials.AntiSpamOverrideRow{})
+	return data
+}
+
+func handleSaveAntiSpamExemptions(client *bot.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		guildIDStr := r.PathValue("id")
+		guildID, ok := checkGuildAdmin(w, r, client, guildIDStr)
+		if !ok {
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "invalid form data", http.StatusBadRequest)
+			return
+		}
+
+		renderError := func(message string) {
+			data := buildAntiSpamExemptionsData(client, guildIDStr, guildID)
+			data.SaveError = message
+			renderSafe(w, r, partials.SettingsAntiSpamExemptions(data))
+		}
+
+		var roles []snowflake.ID
+		for _, v := range r.Form["exempt_roles"] {
+			roleID, err := snowflake.Parse(v)
+			if err != nil || roleID == guildID {
+				renderError("Invalid role.")
+				return
+			}
+			if !slices.Contains(roles, roleID) {
+				roles = append(roles, roleID)
+			}
+		}
+
+		overrides, message := parseAntiSpamChannelOverrides(r)
+		if message != "" {
+			renderError(message)
+			return
+		}
+
+		if err := model.SetAntiSpamExemptions(guildID, roles, overrides); err != nil {
+			slog.Error("failed to save anti-spam exemptions", "error", err)
+			renderError("Failed to save settings.")
+			return
+		}
+
+		roleIDs := make([]string, 0, len(roles))
+		for _, id := range roles {
+			roleIDs = append(roleIDs, id.String())
+		}
+		loggedOverrides := make([]map[string]any, 0, len(overrides))
+		for _, o := range overrides {
+			loggedOverrides = append(loggedOverrides, map[string]any{
+				"channel_id":       o.ChannelID.String(),
+				"exempt":           o.Exempt,
+				"count":            o.Count,
+				"cooldown_seconds": o.CooldownSeconds,
+			})
+		}
+		logSettingsUpdate(sessionFromContext(r.Context()), guildID, "anti_spam_exemptions", map[string]any{
+			"exempt_roles":      roleIDs,
+			"channel_overrides": loggedOverrides,
+		})
+
+		data := buildAntiSpamExemptionsData(client, guildIDStr, guildID)
+		data.SaveSuccess = true
+		renderSafe(w, r, partials.SettingsAntiSpamExemptions(data))
+	}
+}
+
+// parseAntiSpamChannelOverrides reads the override table's rows, which
+// arrive as parallel override_channel / override_mode / override_count /
+// override_cooldown values. Rows with no channel are skipped. On invalid
+// input it returns a user-facing message.
+func parseAntiSpamChannelOverrides(r *http.Request) ([]model.AntiSpamChannelOverride, string) {
+	channels := r.Form["override_channel"]
+	modes := r.Form["override_mode"]
+	counts := r.Form["override_count"]
+	cooldowns := r.Form["override_cooldown"]
+	if len(modes) != len(channels) || len(counts) != len(channels) || len(cooldowns) != len(channels) {
+		return nil, "Invalid channel overrides."
+	}
+
+	var overrides []model.AntiSpamChannelOverride
+	for i, v := range channels {
+		if v == "" {
+			continue
+		}
+		channelID, err := snowflake.Parse(v)
+		if err != nil {
+			return nil, "Invalid channel ID."
+		}
+		if slices.ContainsFunc(overrides, func(o model.AntiSpamChannelOverride) bool { return o.ChannelID == channelID }) {
+			return nil, "Each channel can only have one override."
+		}
+
+		o := model.AntiSpamChannelOverride{ChannelID: channelID}
+		switch modes[i] {
+		case "exempt":
+			o.Exempt = true
+		case "limits":
+			var ok bool
+			if o.Count, ok = parseOptionalInt(counts[i], minAntiSpamCount, maxAntiSpamCount); !ok {
+				return nil, fmt.Sprintf("Channel spam score threshold must be between %d and %d.", minAntiSpamCount, maxAntiSpamCount)
+			}
+			if o.CooldownSeconds, ok = parseOptionalInt(cooldowns[i], minAntiSpamCooldownSeconds, maxAntiSpamCooldownSeconds); !ok {
+				return nil, fmt.Sprintf("Channel cooldown must be between %d and %d seconds.", minAntiSpamCooldownSeconds, maxAntiSpamCooldownSeconds)
+			}
+			if o.Count == 0 && o.CooldownSeconds == 0 {
+				return nil, "Set a threshold or cooldown for each channel override, or make it exempt."
+			}
+		default:
+			return nil, "Invalid channel override mode."
+		}
+		overrides = append(overrides, o)
+	}
+	if len(overrides) > maxAntiSpamChannelOverrides {
+		return nil, fmt.Sprintf("At most %d channel overrides are allowed.", maxAntiSpamChannelOverrides)
+	}
+	return overrides, ""
+}
+
+// parseOptionalInt parses an optional number field. Empty is 0, meaning
+// "not set"; anything else must be an integer within [lo, hi].
+func parseOptionalInt(s string, lo, hi int) (int, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, true
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < lo || v > hi {
+		return 0, false
+	}
+	return v, true
+}
@@ -0,0 +1,67 @@
+package web
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+func overrideForm(t *testing.T, rows ...[4]string) url.Values {
+	t.Helper()
+	form := url.Values{}
+	for _, row := range rows {
+		form.Add("override_channel", row[0])
+		form.Add("override_mode", row[1])
+		form.Add("override_count", row[2])
+		form.Add("override_cooldown", row[3])
+	}
+	return form
+}
+
+func parseOverrideForm(t *testing.T, form url.Values) ([]model.AntiSpamChannelOverride, string) {
+	t.Helper()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	require.NoError(t, r.ParseForm())
+	return parseAntiSpamChannelOverrides(r)
+}
+
+func TestParseAntiSpamChannelOverrides(t *testing.T) {
+	overrides, msg := parseOverrideForm(t, overrideForm(t,
+		[4]string{"10", "exempt", "", ""},
+		[4]string{"11", "limits", "8", ""},
+		[4]string{"", "limits", "", ""},
+	))
+	require.Empty(t, msg)
+	assert.Equal(t, []model.AntiSpamChannelOverride{
+		{ChannelID: 10, Exempt: true},
+		{ChannelID: 11, Count: 8},
+	}, overrides, "the blank row is skipped")
+}
+
+func TestParseAntiSpamChannelOverrides_Invalid(t *testing.T) {
+	cases := []struct {
+		name string
+		form url.Values
+	}{
+		{"duplicate channel", overrideForm(t, [4]string{"10", "exempt", "", ""}, [4]string{"10", "limits", "5", ""})},
+		{"threshold out of range", overrideForm(t, [4]string{"10", "limits", "11", ""})},
+		{"cooldown out of range", overrideForm(t, [4]string{"10", "limits", "", "0"})},
+		{"limits without values", overrideForm(t, [4]string{"10", "limits", "", ""})},
+		{"unknown mode", overrideForm(t, [4]string{"10", "ignore", "", ""})},
+		{"bad channel", overrideForm(t, [4]string{"general", "exempt", "", ""})},
+		{"mismatched rows", url.Values{"override_channel": {"10"}}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, msg := parseOverrideForm(t, tc.form)
+			assert.NotEmpty(t, msg)
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/snowflake/v2"
 
 	"github.com/NLLCommunity/heimdallr/audit"
 	"github.com/NLLCommunity/heimdallr/model"
@@ -18,7 +19,7 @@ import (
 // settings + config-derived ceilings. The "override" fields show the
 // guild's current explicit value (or empty when using defaults), so that
 // re-rendering preserves what the user has saved.
-func buildAuditLogSettingsData(guildID string, settings *model.GuildSettings) partials.AuditLogSettingsData {
+func buildAuditLogSettingsData(client *bot.Client, guildID string, settings *model.GuildSettings) partials.AuditLogSettingsData {
 	maxMessage := scheduled_tasks.RetentionCeilingDays("audit_log.message_retention_days")
 	maxMember := scheduled_tasks.RetentionCeilingDays("audit_log.member_retention_days")
 	maxGuild := scheduled_tasks.RetentionCeilingDays("audit_log.guild_retention_days")
@@ -38,9 +39,31 @@ func buildAuditLogSettingsData(guildID string, settings *model.GuildSettings) pa
 		EffectiveMessageRetentionDays: effectiveRetentionLabel("audit_log.message_retention_days", settings.AuditMessageRetentionDays),
 		EffectiveMemberRetentionDays:  effectiveRetentionLabel("audit_log.member_retention_days", settings.AuditMemberRetentionDays),
 		EffectiveGuildRetentionDays:   effectiveRetentionLabel("audit_log.guild_retention_days", settings.AuditGuildRetentionDays),
+
+		ArchiveAvailable: scheduled_tasks.ArchiveDir() != "",
+		ArchiveEnabled:   settings.AuditArchiveEnabled,
+
+		VoiceEnabled: settings.AuditVoiceEnabled,
+
+		MessageStoreEnabled:  settings.MessageStoreEnabled,
+		MessageStoreChannels: messageStoreChannelStrings(settings.GuildID),
+		Channels:             guildChannels(client, settings.GuildID),
 	}
 }
 
+func messageStoreChannelStrings(guildID snowflake.ID) []string {
+	channels, err := model.GetMessageStoreChannels(guildID)
+	if err != nil {
+		slog.Warn("audit settings: failed to load message store channels", "guild_id", guildID, "err", err)
+		return nil
+	}
+	out := make([]string, 0, len(channels))
+	for _, c := range channels {
+		out = append(out, c.String())
+	}
+	return out
+}
+
 // retentionOverrideDisplay returns the value the override input should
 // pre-fill. Stored 0 is rendered as "" when the ceiling is now finite —
 // 0 was a legitimate "forever" override when the bot ceiling was also 0
@@ -109,13 +132,26 @@ func handleSaveAuditLog(client *bot.Client) http.HandlerFunc {
 		// otherwise an invalid retention number wipes the user's typing
 		// and forces them to start over.
 		submittedEnabled := r.FormValue("enabled") == "true"
+		submittedVoice := r.FormValue("voice_enabled") == "true"
 		submittedMessage := strings.TrimSpace(r.FormValue("message_retention_days"))
 		submittedMember := strings.TrimSpace(r.FormValue("member_retention_days"))
 		submittedGuild := strings.TrimSpace(r.FormValue("guild_retention_days"))
+		submittedStoreEnabled := r.FormValue("message_store_enabled") == "true"
+		// The toggle isn't rendered without an archive directory; keep
+		// the stored value rather than reading its absence as "off".
+		submittedArchive := settings.AuditArchiveEnabled
+		if scheduled_tasks.ArchiveDir() != "" {
+			submittedArchive = r.FormValue("archive_enabled") == "true"
+		}
+		submittedStoreChannels := r.Form["message_store_channels"]
 
 		renderErr := func(message string) {
-			data := buildAuditLogSettingsData(guildIDStr, settings)
+			data := buildAuditLogSettingsData(client, guildIDStr, settings)
 			data.Enabled = submittedEnabled
+			data.ArchiveEnabled = submittedArchive
+			data.VoiceEnabled = submittedVoice
+			data.MessageStoreEnabled = submittedStoreEnabled
+			data.MessageStoreChannels = submittedStoreChannels
 			data.MessageRetentionDaysOverride = submittedMessage
 			data.MemberRetentionDaysOverride = submittedMember
 			data.GuildRetentionDaysOverride = submittedGuild
@@ -139,19 +175,41 @@ func handleSaveAuditLog(client *bot.Client) http.HandlerFunc {
 			return
 		}
 
+		storeChannels, err := parseChannelIDs(submittedStoreChannels)
+		if err != nil {
+			renderErr("Invalid message store channel.")
+			return
+		}
+
 		settings.AuditLogEnabled = submittedEnabled
+		settings.MessageStoreEnabled = submittedStoreEnabled
 		settings.AuditMessageRetentionDays = messageDays
 		settings.AuditMemberRetentionDays = memberDays
 		settings.AuditGuildRetentionDays = guildDays
+		settings.AuditArchiveEnabled = submittedArchive
+		settings.AuditVoiceEnabled = submittedVoice
 
 		if err := model.UpdateGuildSettingsColumns(settings,
 			"AuditLogEnabled", "AuditMessageRetentionDays",
 			"AuditMemberRetentionDays", "AuditGuildRetentionDays",
+			"MessageStoreEnabled", "AuditArchiveEnabled", "AuditVoiceEnabled",
 		); err != nil {
 			slog.Error("failed to save audit log settings", "error", err)
 			renderErr("Failed to save settings.")
 			return
 		}
+		if err := model.SetMessageStoreChannels(guildID, storeChannels); err != nil {
+			slog.Error("failed to save message store channels", "error", err)
+			renderErr("Failed to save settings.")
+			return
+		}
+		// Turning the store off deletes what it kept rather than leaving
+		// it to retention; nothing reads it any more.
+		if !settings.MessageStoreEnabled {
+			if _, err := model.DeleteStoredMessages(guildID); err != nil {
+				slog.Error("failed to delete stored messages", "error", err, "guild_id", guildID)
+			}
+		}
 		// Clear the cached AuditLogEnabled flag so the new value takes
 		// effect on the very next gateway event without waiting for TTL.
 		audit.InvalidateShouldLogCache(guildID)
@@ -160,9 +218,13 @@ func handleSaveAuditLog(client *bot.Client) http.HandlerFunc {
 			"message_retention_days": ptrUintToString(settings.AuditMessageRetentionDays),
 			"member_retention_days":  ptrUintToString(settings.AuditMemberRetentionDays),
 			"guild_retention_days":   ptrUintToString(settings.AuditGuildRetentionDays),
+			"archive_enabled":        settings.AuditArchiveEnabled,
+			"voice_enabled":          settings.AuditVoiceEnabled,
+			"message_store_enabled":  settings.MessageStoreEnabled,
+			"message_store_channels": storeChannels,
 		})
 
-		data := buildAuditLogSettingsData(guildIDStr, settings)
+		data := buildAuditLogSettingsData(client, guildIDStr, settings)
 		data.SaveSuccess = true
 		renderSafe(w, r, partials.SettingsAuditLog(data))
 	}
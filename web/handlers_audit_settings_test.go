@@ -150,43 +150,3 @@ func TestRetentionOverrideDisplay(t *testing.T) {
 		})
 	}
 }
-
-func TestFormatSettingsUpdate_SkipsMetadata(t *testing.T) {
-	// formatSettingsUpdate calls formatSettingsValue, which for plain
-	// scalar values (no channel/role suffix) doesn't touch the disgo
-	// cache — we can pass a nil client + zero guildID and still exercise
-	// the metadata-filter path. The "Changed values" section body should
-	// contain only the real-setting keys, not actor_username/section.
-	details := map[string]any{
-		"section":        "audit_log",
-		"actor_username": "@admin",
-		"enabled":        true,
-		"half_life_days": float64(14),
-	}
-
-	summary, sections := formatSettingsUpdate(nil, 0, details)
-	assert.Equal(t, "Audit log", summary)
-	require.Len(t, sections, 1)
-	body := sections[0].Body
-
-	// Must contain the real settings.
-	assert.Contains(t, body, "Enabled:")
-	assert.Contains(t, body, "Half life days:")
-	// Must NOT contain attribution metadata.
-	assert.NotContains(t, body, "Actor username")
-	assert.NotContains(t, body, "Section:")
-}
-
-func TestFormatSettingsUpdate_OnlyMetadata_ReturnsNoSections(t *testing.T) {
-	// A degenerate payload that only carries metadata (no settings were
-	// actually included) should still render the section header but
-	// produce no "Changed values" disclosure.
-	details := map[string]any{
-		"section":        "modmail",
-		"actor_username": "@admin",
-	}
-
-	summary, sections := formatSettingsUpdate(nil, 0, details)
-	assert.Equal(t, "Modmail", summary)
-	assert.Nil(t, sections)
-}
@@ -0,0 +1,149 @@
+package web
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/disgoorg/disgo/bot"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/web/templates/partials"
+)
+
+// auditStreamCategories orders the muted event groups like the viewer's
+// category filter.
+var auditStreamCategories = []struct {
+	Category audit.Category
+	Name     string
+}{
+	{audit.CategoryMessage, "Message"},
+	{audit.CategoryMember, "Member"},
+	{audit.CategoryGuild, "Guild / Bot"},
+}
+
+func auditStreamEventGroups() []partials.AuditStreamEventGroup {
+	groups := make([]partials.AuditStreamEventGroup, 0, len(auditStreamCategories))
+	for _, c := range auditStreamCategories {
+		group := partials.AuditStreamEventGroup{Name: c.Name}
+		for _, opt := range audit.EventOptions {
+			if audit.EventCategory(opt.Type) == c.Category {
+				group.Events = append(group.Events, partials.AuditStreamEvent{Type: string(opt.Type), Label: opt.Label})
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func buildAuditStreamData(client *bot.Client, guildID string, settings *model.GuildSettings) partials.AuditStreamData {
+	muted, err := model.GetAuditStreamMutedEvents(settings.GuildID)
+	if err != nil {
+		slog.Warn("audit stream settings: failed to load muted events", "guild_id", settings.GuildID, "err", err)
+	}
+	return partials.AuditStreamData{
+		GuildID:        guildID,
+		MessageChannel: idStr(settings.AuditStreamMessageChannel),
+		MemberChannel:  idStr(settings.AuditStreamMemberChannel),
+		GuildChannel:   idStr(settings.AuditStreamGuildChannel),
+		MutedEvents:    muted,
+		EventGroups:    auditStreamEventGroups(),
+		Channels:       guildChannels(client, settings.GuildID),
+	}
+}
+
+// parseMutedEvents keeps the submitted event types that are offered in
+// the form, dropping duplicates. ok is false for an unknown type.
+func parseMutedEvents(values []string) (events []string, ok bool) {
+	for _, v := range values {
+		known := slices.ContainsFunc(audit.EventOptions, func(opt audit.EventOption) bool {
+			return string(opt.Type) == v
+		})
+		if !known {
+			return nil, false
+		}
+		if !slices.Contains(events, v) {
+			events = append(events, v)
+		}
+	}
+	return events, true
+}
+
+func handleSaveAuditStream(client *bot.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		guildIDStr := r.PathValue("id")
+		guildID, ok := checkGuildAdmin(w, r, client, guildIDStr)
+		if !ok {
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "invalid form data", http.StatusBadRequest)
+			return
+		}
+		settings, err := model.GetGuildSettings(guildID)
+		if err != nil {
+			renderSafe(w, r, partials.SettingsAuditStream(partials.AuditStreamData{
+				GuildID: guildIDStr, SaveError: "Failed to load settings.",
+			}))
+			return
+		}
+
+		renderErr := func(message string) {
+			data := buildAuditStreamData(client, guildIDStr, settings)
+			data.MessageChannel = r.FormValue("message_channel")
+			data.MemberChannel = r.FormValue("member_channel")
+			data.GuildChannel = r.FormValue("guild_channel")
+			data.MutedEvents = r.Form["muted_events"]
+			data.SaveError = message
+			renderSafe(w, r, partials.SettingsAuditStream(data))
+		}
+
+		messageChannel, err := parseSnowflakeOrZero(r.FormValue("message_channel"))
+		if err != nil {
+			renderErr("Invalid message events channel.")
+			return
+		}
+		memberChannel, err := parseSnowflakeOrZero(r.FormValue("member_channel"))
+		if err != nil {
+			renderErr("Invalid member events channel.")
+			return
+		}
+		guildChannel, err := parseSnowflakeOrZero(r.FormValue("guild_channel"))
+		if err != nil {
+			renderErr("Invalid guild events channel.")
+			return
+		}
+		muted, ok := parseMutedEvents(r.Form["muted_events"])
+		if !ok {
+			renderErr("Unknown event type.")
+			return
+		}
+
+		settings.AuditStreamMessageChannel = messageChannel
+		settings.AuditStreamMemberChannel = memberChannel
+		settings.AuditStreamGuildChannel = guildChannel
+		if err := model.UpdateGuildSettingsColumns(settings,
+			"AuditStreamMessageChannel", "AuditStreamMemberChannel", "AuditStreamGuildChannel"); err != nil {
+			slog.Error("failed to save audit stream settings", "error", err)
+			renderErr("Failed to save settings.")
+			return
+		}
+		if err := model.SetAuditStreamMutedEvents(guildID, muted); err != nil {
+			slog.Error("failed to save audit stream muted events", "error", err)
+			renderErr("Failed to save settings.")
+			return
+		}
+		logSettingsUpdate(sessionFromContext(r.Context()), guildID, "audit_stream", map[string]any{
+			"audit_stream_message_channel": idStr(settings.AuditStreamMessageChannel),
+			"audit_stream_member_channel":  idStr(settings.AuditStreamMemberChannel),
+			"audit_stream_guild_channel":   idStr(settings.AuditStreamGuildChannel),
+			"muted_events":                 muted,
+		})
+
+		data := buildAuditStreamData(client, guildIDStr, settings)
+		data.SaveSuccess = true
+		renderSafe(w, r, partials.SettingsAuditStream(data))
+	}
+}
@@ -0,0 +1,33 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+)
+
+func TestParseMutedEvents(t *testing.T) {
+	t.Run("duplicates are dropped", func(t *testing.T) {
+		events, ok := parseMutedEvents([]string{"member.join", "message.edit", "member.join"})
+		assert.True(t, ok)
+		assert.Equal(t, []string{"member.join", "message.edit"}, events)
+	})
+
+	t.Run("unknown types are rejected", func(t *testing.T) {
+		_, ok := parseMutedEvents([]string{"member.join", "nope"})
+		assert.False(t, ok)
+	})
+}
+
+func TestAuditStreamEventGroups_CoverEveryOption(t *testing.T) {
+	n := 0
+	for _, g := range auditStreamEventGroups() {
+		for _, ev := range g.Events {
+			assert.NotEmpty(t, ev.Label)
+			n++
+		}
+	}
+	assert.Equal(t, len(audit.EventOptions), n, "every event option belongs to a listed category")
+}
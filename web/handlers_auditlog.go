@@ -2,11 +2,9 @@ package web
 
 import (
 	"encoding/json"
-	"fmt"
 	"log/slog"
 	"net/http"
 	"net/url"
-	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -16,6 +14,7 @@ import (
 
 	"github.com/NLLCommunity/heimdallr/audit"
 	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/scheduled_tasks"
 	"github.com/NLLCommunity/heimdallr/web/templates/layouts"
 	"github.com/NLLCommunity/heimdallr/web/templates/pages"
 	"github.com/NLLCommunity/heimdallr/web/templates/partials"
@@ -63,6 +62,7 @@ func handleAuditLog(client *bot.Client) http.HandlerFunc {
 		}
 
 		rows := buildAuditLogRows(client, guildID, entries)
+		addSearchMatches(rows, entries, filters.Text)
 		filterQuery := auditLogFilterQuery(filters)
 
 		// HTMX form submits target the table only — return just the partial
@@ -99,6 +99,9 @@ func handleAuditLog(client *bot.Client) http.HandlerFunc {
 			Page:         page,
 			PageSize:     auditLogPageSize,
 			EventOptions: auditLogEventOptions(),
+			ArchiveEnabled: settings.AuditArchiveEnabled &&
+				scheduled_tasks.ArchiveDir() != "",
+			VoiceEnabled: settings.AuditVoiceEnabled,
 		}))
 	}
 }
@@ -109,7 +112,7 @@ func handleAuditLog(client *bot.Client) http.HandlerFunc {
 // blocked on a live bot client.
 //
 // Each entry's Details JSON is decoded exactly once per row, then passed
-// to FormatActor / FormatTarget / summariseDetail / refineMemberUpdateLabel
+// to FormatActor / FormatTarget / audit.Describe / RefineMemberUpdateLabel
 // as the same map. Previously each of those helpers unmarshalled the
 // payload itself — on a 50-row page that meant up to 200 redundant parses.
 func buildAuditLogRows(client *bot.Client, guildID snowflake.ID, entries []model.AuditLogEntry) []partials.AuditLogRow {
@@ -128,14 +131,14 @@ func buildAuditLogRows(client *bot.Client, guildID snowflake.ID, entries []model
 			}
 		}
 
-		label := eventLabel(e.EventType)
+		label := audit.EventLabel(e.EventType)
 		// Legacy rows written before member.update was split: re-derive a
 		// specific label from the stored details so old entries don't
 		// just say "Member updated".
 		if e.EventType == string(audit.EventMemberUpdate) {
-			label = refineMemberUpdateLabel(label, details)
+			label = audit.RefineMemberUpdateLabel(label, details)
 		}
-		summary, sections := summariseDetail(client, guildID, e.EventType, details)
+		summary, sections := audit.Describe(client, guildID, e.EventType, details)
 		rows[i] = partials.AuditLogRow{
 			CreatedAt:      e.CreatedAt,
 			EventType:      e.EventType,
@@ -144,380 +147,66 @@ func buildAuditLogRows(client *bot.Client, guildID snowflake.ID, entries []model
 			Target:         audit.FormatTarget(client, guildID, audit.TargetKind(e.TargetKind), e.TargetID, details),
 			Reason:         e.Reason,
 			DetailSummary:  summary,
-			DetailSections: sections,
+			DetailSections: detailSections(sections),
 		}
 	}
 	return rows
 }
 
-// summariseDetail extracts a one-line human summary plus optional
-// sections from the already-decoded details map for a given event type.
-//
-// Sections are headed text blocks that render inside an expandable
-// <details> disclosure when present. Events that produce only a short
-// summary (no sections) render the summary as a small subtitle under
-// the event label rather than as a sub-row, since a sub-row containing
-// just a label feels visually disconnected.
-//
-// client + guildID are used to resolve embedded channel/role IDs in the
-// details payload (web settings updates can carry these as referenced
-// values).
-//
-// Returns ("", nil) when there's nothing useful to surface. A nil details
-// map (legacy or malformed-JSON row) is treated as "nothing to surface".
-func summariseDetail(client *bot.Client, guildID snowflake.ID, eventType string, d map[string]any) (summary string, sections []partials.DetailSection) {
-	if d == nil {
-		return "", nil
-	}
-
-	switch eventType {
-	case string(audit.EventMessageDelete):
-		full := stringField(d, "before_content")
-		author := messageAuthorFromDetails(d)
-		if full == "" && author == "" {
-			return "", nil
-		}
-
-		sections := make([]partials.DetailSection, 0, 2)
-		if author != "" {
-			sections = append(sections, partials.DetailSection{Heading: "Message author", Body: author})
-		}
-		if full != "" {
-			sections = append(sections, partials.DetailSection{Heading: "Deleted message", Body: full})
-		}
-
-		summary := truncate(full, 120)
-		if author != "" {
-			if summary == "" {
-				summary = "Message by " + author
-			} else {
-				summary = author + ": " + summary
-			}
-		}
-		return summary, sections
-
-	case string(audit.EventMessageEdit):
-		before := stringField(d, "before_content")
-		after := stringField(d, "after_content")
-		if before == "" && after == "" {
-			return "", nil
-		}
-		summary := truncate(before, 60) + "  →  " + truncate(after, 60)
-		return summary, []partials.DetailSection{
-			{Heading: "Before", Body: before},
-			{Heading: "After", Body: after},
-		}
-
-	case string(audit.EventMemberNickChange):
-		before := stringField(d, "nick_before")
-		after := stringField(d, "nick_after")
-		if before == "" {
-			before = "—"
-		}
-		if after == "" {
-			after = "—"
-		}
-		return before + " → " + after, nil
-
-	case string(audit.EventMemberRoleChange):
-		return roleChangeSummary(d), nil
-
-	case string(audit.EventMemberTimeoutAdd):
-		if v, ok := d["timeout_until"].(string); ok {
-			return "until " + v, nil
-		}
-
-	case string(audit.EventBotWarn):
-		if w, ok := d["weight"].(float64); ok {
-			return "severity " + strconv.FormatFloat(w, 'f', -1, 64), nil
-		}
-
-	case string(audit.EventGuildPrune):
-		removed := stringField(d, "members_removed")
-		days := stringField(d, "delete_member_days")
-		switch {
-		case removed != "" && days != "":
-			return removed + " removed (inactive ≥ " + days + " days)", nil
-		case removed != "":
-			return removed + " removed", nil
-		case days != "":
-			return "inactive ≥ " + days + " days", nil
-		}
-
-	case string(audit.EventSettingsUpdate),
-		string(audit.EventWebSettingsUpdate):
-		return formatSettingsUpdate(client, guildID, d)
-
-	case string(audit.EventWebPostCreate),
-		string(audit.EventWebPostUpdate),
-		string(audit.EventWebPostDelete):
-		return stringField(d, "post_name"), nil
-	}
-	return "", nil
-}
-
-// messageAuthorFromDetails returns the original author captured when the
-// gateway delivers a message-delete event. This is distinct from the audit
-// entry actor, which is replaced with the moderator during native enrichment.
-func messageAuthorFromDetails(d map[string]any) string {
-	if username := stringField(d, "author_username"); username != "" {
-		return "@" + username
+func detailSections(sections []audit.DetailSection) []partials.DetailSection {
+	if len(sections) == 0 {
+		return nil
 	}
-	if id := stringField(d, "author_id"); id != "" {
-		return "@" + id
+	out := make([]partials.DetailSection, len(sections))
+	for i, sec := range sections {
+		out[i] = partials.DetailSection{Heading: sec.Heading, Body: sec.Body}
 	}
-	return ""
-}
-
-// settingsUpdateMetadataKeys are keys present in a settings.update
-// Details payload that are NOT user-changed settings — they're
-// attribution / routing metadata co-located in the same map (e.g.
-// actor_username for cache-miss rendering, section for the form group).
-// formatSettingsUpdate skips these when building the "Changed values"
-// section so the viewer doesn't claim "Actor username: alice" is a
-// setting that was modified.
-var settingsUpdateMetadataKeys = map[string]bool{
-	"section":         true,
-	"actor_username":  true,
-	"target_username": true,
+	return out
 }
 
-// formatSettingsUpdate renders a web.settings.update entry's details into
-// a humanized summary + a "Changed values" section listing each field as
-// "Field name: value". Channel and role IDs are resolved via the cache
-// so the disclosure shows "#general" rather than the raw snowflake.
-//
-// Keys in settingsUpdateMetadataKeys are skipped — they're attribution
-// metadata, not actual settings.
-func formatSettingsUpdate(client *bot.Client, guildID snowflake.ID, d map[string]any) (string, []partials.DetailSection) {
-	section := stringField(d, "section")
-	summary := settingsSectionLabel(section)
-
-	type field struct {
-		key, label, value string
+// addSearchMatches sets each row's Match to the excerpt of its entry that
+// the text search hit, so the viewer can show why a row is in the result.
+// A failed snippet lookup only costs the highlighting.
+func addSearchMatches(rows []partials.AuditLogRow, entries []model.AuditLogEntry, text string) {
+	if text == "" || len(entries) == 0 {
+		return
 	}
-	var fields []field
-	for k, raw := range d {
-		if settingsUpdateMetadataKeys[k] {
-			continue
-		}
-		v := formatSettingsValue(client, guildID, k, raw)
-		fields = append(fields, field{key: k, label: humanizeFieldName(k), value: v})
-	}
-	if len(fields) == 0 {
-		return summary, nil
-	}
-	sort.Slice(fields, func(i, j int) bool { return fields[i].label < fields[j].label })
-
-	var b strings.Builder
-	for i, f := range fields {
-		if i > 0 {
-			b.WriteString("\n")
-		}
-		b.WriteString(f.label)
-		b.WriteString(": ")
-		b.WriteString(f.value)
+	ids := make([]uint, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ID
 	}
-	return summary, []partials.DetailSection{
-		{Heading: "Changed values", Body: b.String()},
+	snippets, err := model.AuditLogSearchSnippets(ids, text)
+	if err != nil {
+		slog.Warn("audit log: failed to load search snippets", "err", err)
+		return
 	}
-}
-
-// settingsSectionLabel maps the internal section identifier (snake_case
-// matching the form route, e.g. "audit_log") to the label moderators see
-// in the audit log viewer.
-func settingsSectionLabel(section string) string {
-	switch section {
-	case "mod_channel":
-		return "Moderator channel"
-	case "infractions":
-		return "Infractions"
-	case "anti_spam":
-		return "Anti-spam"
-	case "ban_footer":
-		return "Ban footer"
-	case "modmail":
-		return "Modmail"
-	case "gatekeep":
-		return "Gatekeep"
-	case "join_leave":
-		return "Join/leave messages"
-	case "audit_log":
-		return "Audit log"
-	}
-	if section == "" {
-		return "Settings"
-	}
-	return section
-}
-
-// humanizeFieldName converts a snake_case settings key into Sentence case.
-// Used as the label inside the changed-values disclosure body. Empty
-// parts (from leading / trailing / consecutive underscores) are dropped
-// so we never index into "" and never emit leading/duplicate spaces.
-func humanizeFieldName(key string) string {
-	if key == "" {
-		return ""
-	}
-	parts := strings.Split(key, "_")
-	out := make([]string, 0, len(parts))
-	for _, p := range parts {
-		if p == "" {
-			continue
-		}
-		if len(out) == 0 {
-			p = strings.ToUpper(p[:1]) + p[1:]
+	for i, e := range entries {
+		if snip, ok := snippets[e.ID]; ok {
+			rows[i].Match = snippetSegments(snip)
 		}
-		out = append(out, p)
 	}
-	return strings.Join(out, " ")
 }
 
-// formatSettingsValue renders a setting's stored value for display.
-// Booleans become On/Off, channel/role IDs resolve to readable names via
-// the cache. Cleared channel/role fields render as "(none)" — the web
-// dashboard serializes a cleared snowflake as "" via idStr() while
-// command-side updates emit "0", so both shapes funnel to the same label.
-// True override-style fields (retention days, etc.) keep "(default)" for
-// empty values; an explicit "0" for those fields renders as the literal
-// "0". parseRetentionField now collapses 0-when-ceiling-is-0 to nil at
-// save time, so a literal "0" in the audit Details payload should only
-// appear on legacy rows written before that normalization landed.
-func formatSettingsValue(client *bot.Client, guildID snowflake.ID, key string, raw any) string {
-	isSnowflakeKey := strings.HasSuffix(key, "_channel") || strings.HasSuffix(key, "_role")
-	switch v := raw.(type) {
-	case bool:
-		if v {
-			return "On"
-		}
-		return "Off"
-	case string:
-		if v == "" {
-			if isSnowflakeKey {
-				return "(none)"
-			}
-			return "(default)"
-		}
-		if v == "0" && isSnowflakeKey {
-			return "(none)"
+// snippetSegments splits a search snippet on the model's match markers
+// into plain and highlighted runs. An unterminated marker highlights to
+// the end.
+func snippetSegments(snippet string) []partials.MatchSegment {
+	var segs []partials.MatchSegment
+	for snippet != "" {
+		before, rest, found := strings.Cut(snippet, model.SnippetStart)
+		if before != "" {
+			segs = append(segs, partials.MatchSegment{Text: before})
 		}
-		if strings.HasSuffix(key, "_channel") {
-			if id, err := snowflake.Parse(v); err == nil {
-				return "#" + audit.ResolveChannelName(client, id)
-			}
-		}
-		if strings.HasSuffix(key, "_role") {
-			if id, err := snowflake.Parse(v); err == nil {
-				return "@" + audit.ResolveRoleName(client, guildID, id)
-			}
-		}
-		return v
-	case float64:
-		if v == float64(int64(v)) {
-			return strconv.FormatInt(int64(v), 10)
-		}
-		return strconv.FormatFloat(v, 'f', -1, 64)
-	case nil:
-		if isSnowflakeKey {
-			return "(none)"
+		if !found {
+			break
 		}
-		return "(default)"
-	}
-	return fmt.Sprintf("%v", raw)
-}
-
-// stringField pulls a string out of a generic decoded JSON object,
-// returning "" for both missing and non-string keys.
-func stringField(d map[string]any, key string) string {
-	if v, ok := d[key].(string); ok {
-		return v
-	}
-	return ""
-}
-
-// truncate shortens long strings to width, appending an ellipsis. width
-// is in runes so we don't mid-truncate a UTF-8 sequence.
-func truncate(s string, width int) string {
-	r := []rune(s)
-	if len(r) <= width {
-		return s
-	}
-	return string(r[:width]) + "…"
-}
-
-// roleChangeSummary builds "+@RoleA, -@RoleB" from a member.role_change
-// details payload. The slices are stored as [{id, name}, ...] by the
-// listener so we can render readable names without re-fetching.
-func roleChangeSummary(d map[string]any) string {
-	added := roleNames(d, "roles_added")
-	removed := roleNames(d, "roles_removed")
-	parts := make([]string, 0, len(added)+len(removed))
-	for _, name := range added {
-		parts = append(parts, "+@"+name)
-	}
-	for _, name := range removed {
-		parts = append(parts, "-@"+name)
-	}
-	return strings.Join(parts, ", ")
-}
-
-func roleNames(d map[string]any, key string) []string {
-	raw, ok := d[key].([]any)
-	if !ok {
-		return nil
-	}
-	out := make([]string, 0, len(raw))
-	for _, item := range raw {
-		if obj, ok := item.(map[string]any); ok {
-			if name, ok := obj["name"].(string); ok && name != "" {
-				out = append(out, name)
-			}
+		match, after, _ := strings.Cut(rest, model.SnippetEnd)
+		if match != "" {
+			segs = append(segs, partials.MatchSegment{Text: match, Highlight: true})
 		}
+		snippet = after
 	}
-	return out
-}
-
-// eventLabel returns the human-friendly label for an event type. Falls
-// back to the raw type for unmapped values so adding a new event type
-// degrades gracefully even before its label is registered here.
-func eventLabel(t string) string {
-	if label, ok := auditLogEventLabels[t]; ok {
-		return label
-	}
-	return t
-}
-
-// refineMemberUpdateLabel returns a more specific label for legacy
-// member.update rows by inspecting which keys are in the already-decoded
-// details map. Current listeners write split event types (member.nick_change,
-// member.role_change, member.timeout_add, member.timeout_clear), so the
-// only member.update rows that reach this function are either pre-split
-// historical rows or the fallback emitted when memberUpdateEnrichmentTargets
-// doesn't recognise any change key — neither path writes timeout fields.
-func refineMemberUpdateLabel(fallback string, d map[string]any) string {
-	if d == nil {
-		return fallback
-	}
-	// Type-assert against string so a JSON `null` value is treated as
-	// absent — matches the old pointer-based decode where `null` decoded
-	// to a nil *string. Current writers don't emit null nicks (they go
-	// to EventMemberNickChange), but legacy/hand-edited rows might.
-	_, hasNickBefore := d["nick_before"].(string)
-	_, hasNickAfter := d["nick_after"].(string)
-	rolesAdded, _ := d["roles_added"].([]any)
-	rolesRemoved, _ := d["roles_removed"].([]any)
-
-	switch {
-	case len(rolesAdded) > 0 && len(rolesRemoved) > 0:
-		return "Roles changed"
-	case len(rolesAdded) > 0:
-		return "Role added"
-	case len(rolesRemoved) > 0:
-		return "Role removed"
-	case hasNickAfter || hasNickBefore:
-		return "Nickname changed"
-	}
-	return fallback
+	return segs
 }
 
 // auditLogFilterQuery URL-encodes the active filters as a query string
@@ -549,6 +238,9 @@ func auditLogFilterQuery(f pages.AuditLogFilters) string {
 	if f.To != "" {
 		v.Set("to", f.To)
 	}
+	if f.Text != "" {
+		v.Set("q", f.Text)
+	}
 	return v.Encode()
 }
 
@@ -556,7 +248,7 @@ func auditLogFilterQuery(f pages.AuditLogFilters) string {
 // the form-state struct used by the template. Empty values mean "no filter".
 //
 // `from` defaults to today minus auditLogDefaultLookback if missing — but
-// only when no actor/target/event/time filter narrows the scope. Category
+// only when no actor/target/event/time/text filter narrows the scope. Category
 // alone doesn't suppress the default because it splits the dataset only
 // 3 ways; a category-only query on a busy guild would still scan most of
 // retention. Actor/target/event are precise enough to bound results.
@@ -569,10 +261,11 @@ func parseAuditLogFilters(r *http.Request) pages.AuditLogFilters {
 		Target:    strings.TrimSpace(q.Get("target")),
 		From:      strings.TrimSpace(q.Get("from")),
 		To:        strings.TrimSpace(q.Get("to")),
+		Text:      strings.TrimSpace(q.Get("q")),
 	}
 	if filters.From == "" && filters.To == "" &&
 		filters.Actor == "" && filters.Target == "" &&
-		filters.EventType == "" {
+		filters.EventType == "" && filters.Text == "" {
 		filters.From = time.Now().Add(-auditLogDefaultLookback).UTC().Format("2006-01-02")
 	}
 	return filters
@@ -596,6 +289,7 @@ func buildAuditLogFilter(client *bot.Client, guildID snowflake.ID, f pages.Audit
 	mf := model.AuditLogFilter{
 		Category:  f.Category,
 		EventType: f.EventType,
+		Text:      f.Text,
 	}
 	if f.Actor != "" {
 		ids, query := resolveActorQuery(client, guildID, f.Actor)
@@ -767,46 +461,22 @@ func parsePage(s string) int {
 
 // auditLogEventOptions returns the dropdown options for the EventType
 // filter. Listed centrally here rather than hardcoded in the template so
-// adding a new audit event type only requires updating the audit package
-// and auditLogEventOptionList.
+// adding a new audit event type only requires updating audit.EventOptions.
 func auditLogEventOptions() []pages.AuditLogEventOption {
 	return auditLogEventOptionList
 }
 
-// auditLogEventOptionList is built once at package init. eventLabel runs
-// per row, so a per-call slice allocation + linear scan would do real
-// work on busy pages; auditLogEventLabels backs that lookup as an O(1)
-// map derived from this same source of truth.
-var auditLogEventOptionList = []pages.AuditLogEventOption{
-	{Value: string(audit.EventMessageEdit), Label: "Message edited", Category: string(audit.CategoryMessage)},
-	{Value: string(audit.EventMessageDelete), Label: "Message deleted", Category: string(audit.CategoryMessage)},
-	{Value: string(audit.EventMemberNickChange), Label: "Nickname changed", Category: string(audit.CategoryMember)},
-	{Value: string(audit.EventMemberRoleChange), Label: "Roles changed", Category: string(audit.CategoryMember)},
-	{Value: string(audit.EventMemberTimeoutAdd), Label: "Member timed out", Category: string(audit.CategoryMember)},
-	{Value: string(audit.EventMemberTimeoutClear), Label: "Timeout cleared", Category: string(audit.CategoryMember)},
-	{Value: string(audit.EventGuildBan), Label: "Member banned", Category: string(audit.CategoryGuild)},
-	{Value: string(audit.EventGuildUnban), Label: "Member unbanned", Category: string(audit.CategoryGuild)},
-	{Value: string(audit.EventGuildKick), Label: "Member kicked", Category: string(audit.CategoryGuild)},
-	{Value: string(audit.EventGuildPrune), Label: "Members pruned", Category: string(audit.CategoryGuild)},
-	{Value: string(audit.EventBotWarn), Label: "Bot warning issued", Category: string(audit.CategoryGuild)},
-	{Value: string(audit.EventSettingsUpdate), Label: "Settings updated", Category: string(audit.CategoryGuild)},
-	{Value: string(audit.EventWebPostCreate), Label: "Post created", Category: string(audit.CategoryGuild)},
-	{Value: string(audit.EventWebPostUpdate), Label: "Post updated", Category: string(audit.CategoryGuild)},
-	{Value: string(audit.EventWebPostDelete), Label: "Post deleted", Category: string(audit.CategoryGuild)},
-}
-
-var auditLogEventLabels = func() map[string]string {
-	m := make(map[string]string, len(auditLogEventOptionList)+2)
-	for _, opt := range auditLogEventOptionList {
-		m[opt.Value] = opt.Label
-	}
-	// Legacy event types — kept out of the filter dropdown (new entries
-	// use the renamed types) but still possible on DB rows written
-	// before the rename, so they get a readable label rather than
-	// falling through to the raw event string. refineMemberUpdateLabel
-	// may further refine the member.update label when the details
-	// payload identifies the specific change.
-	m[string(audit.EventMemberUpdate)] = "Member updated"
-	m[string(audit.EventWebSettingsUpdate)] = "Settings updated"
-	return m
+// auditLogEventOptionList is built once at package init from the audit
+// package's catalog, adding each type's category so the template can
+// group the dropdown.
+var auditLogEventOptionList = func() []pages.AuditLogEventOption {
+	out := make([]pages.AuditLogEventOption, len(audit.EventOptions))
+	for i, opt := range audit.EventOptions {
+		out[i] = pages.AuditLogEventOption{
+			Value:    string(opt.Type),
+			Label:    opt.Label,
+			Category: string(audit.EventCategory(opt.Type)),
+		}
+	}
+	return out
 }()
@@ -0,0 +1,97 @@
+package web
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/scheduled_tasks"
+	"github.com/NLLCommunity/heimdallr/web/templates/layouts"
+	"github.com/NLLCommunity/heimdallr/web/templates/pages"
+)
+
+// auditLogArchiveLimit caps how many archived entries one search renders.
+// The archive is read file by file, so stopping early also bounds the work.
+const auditLogArchiveLimit = 200
+
+// handleAuditLogArchive searches the gzipped archive files written when
+// audit log entries pass retention. Nothing is read until the form has been
+// submitted, since an unbounded search walks every file for the guild.
+func handleAuditLogArchive(client *bot.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		guildIDStr := r.PathValue("id")
+		guildID, ok := checkGuildAdmin(w, r, client, guildIDStr)
+		if !ok {
+			return
+		}
+
+		q := r.URL.Query()
+		filters := pages.AuditLogFilters{
+			Category: strings.ToLower(strings.TrimSpace(q.Get("category"))),
+			From:     strings.TrimSpace(q.Get("from")),
+			To:       strings.TrimSpace(q.Get("to")),
+			Text:     strings.TrimSpace(q.Get("q")),
+		}
+		data := pages.AuditLogArchiveData{
+			GuildID:  guildIDStr,
+			Filters:  filters,
+			Searched: q.Get("search") != "",
+			Limit:    auditLogArchiveLimit,
+		}
+
+		dir := scheduled_tasks.ArchiveDir()
+		if dir == "" {
+			data.Error = "No archive directory is configured for this bot."
+		} else if data.Searched {
+			var entries []model.AuditLogEntry
+			err := audit.SearchArchive(r.Context(), dir, guildID, archiveQuery(filters), func(rec audit.ArchiveRecord) error {
+				if len(entries) == auditLogArchiveLimit {
+					data.Truncated = true
+					return audit.ErrStopSearch
+				}
+				entry, err := rec.Entry()
+				if err != nil {
+					return err
+				}
+				entries = append(entries, entry)
+				return nil
+			})
+			if err != nil && !errors.Is(err, audit.ErrStopSearch) {
+				slog.Error("failed to search audit log archive", "error", err, "guild_id", guildID)
+				data.Error = "Searching the archive failed."
+			} else {
+				data.Rows = buildAuditLogRows(client, guildID, entries)
+			}
+		}
+
+		session := sessionFromContext(r.Context())
+		guild, _ := client.Caches.Guild(guildID)
+		nav := layouts.NavData{
+			User:      session,
+			GuildID:   guildIDStr,
+			GuildName: guild.Name,
+			IsAdmin:   true,
+			IsPostMod: true,
+		}
+		renderSafe(w, r, pages.AuditLogArchive(nav, data))
+	}
+}
+
+// archiveQuery converts the form values into an archive query, with the
+// same inclusive To date as the live audit log filter.
+func archiveQuery(f pages.AuditLogFilters) audit.ArchiveQuery {
+	aq := audit.ArchiveQuery{Category: f.Category, Text: f.Text}
+	if t, err := time.Parse("2006-01-02", f.From); err == nil {
+		aq.From = t.UTC()
+	}
+	if t, err := time.Parse("2006-01-02", f.To); err == nil {
+		aq.To = t.Add(24 * time.Hour).UTC()
+	}
+	return aq
+}
@@ -0,0 +1,28 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NLLCommunity/heimdallr/web/templates/pages"
+)
+
+func TestArchiveQuery(t *testing.T) {
+	q := archiveQuery(pages.AuditLogFilters{
+		Category: "member",
+		From:     "2026-03-01",
+		To:       "2026-03-02",
+		Text:     "spam",
+	})
+	assert.Equal(t, "member", q.Category)
+	assert.Equal(t, "spam", q.Text)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), q.From)
+	// To is inclusive in the form, so the query runs to the next midnight.
+	assert.Equal(t, time.Date(2026, 3, 3, 0, 0, 0, 0, time.UTC), q.To)
+
+	q = archiveQuery(pages.AuditLogFilters{From: "not a date"})
+	assert.True(t, q.From.IsZero())
+	assert.True(t, q.To.IsZero())
+}
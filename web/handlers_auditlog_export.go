@@ -0,0 +1,69 @@
+package web
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/web/templates/pages"
+)
+
+// handleAuditLogExport streams every entry matching the viewer's filters
+// as a CSV or JSONL download. The filters are read exactly as the viewer
+// reads them, so the export holds what the page paginates through.
+//
+// Rows are written as they are loaded; a query error part-way through can
+// only be logged, since the response status has already gone out.
+func handleAuditLogExport(client *bot.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		guildIDStr := r.PathValue("id")
+		guildID, ok := checkGuildAdmin(w, r, client, guildIDStr)
+		if !ok {
+			return
+		}
+
+		format, err := audit.ParseExportFormat(r.URL.Query().Get("format"))
+		if err != nil {
+			http.Error(w, "unknown export format", http.StatusBadRequest)
+			return
+		}
+
+		filters := parseAuditLogFilters(r)
+		modelFilter := buildAuditLogFilter(client, guildID, filters)
+
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition",
+			`attachment; filename="`+audit.ExportFilename(guildID, format, time.Now())+`"`)
+
+		count, err := audit.Export(r.Context(), w, client, guildID, modelFilter, format)
+		if err != nil {
+			slog.Error("audit log export failed", "err", err, "guild_id", guildID, "written", count)
+			return
+		}
+
+		var actorID snowflake.ID
+		var actorName string
+		if session := sessionFromContext(r.Context()); session != nil {
+			actorID, actorName = session.UserID, session.Username
+		}
+		audit.LogExport(guildID, actorID, actorName, audit.SourceWeb, format, count, exportFilterDetails(filters))
+	}
+}
+
+// exportFilterDetails is the filter map recorded on the export's own
+// audit log entry.
+func exportFilterDetails(f pages.AuditLogFilters) map[string]string {
+	return map[string]string{
+		"category":   f.Category,
+		"event_type": f.EventType,
+		"actor":      f.Actor,
+		"target":     f.Target,
+		"from":       f.From,
+		"to":         f.To,
+		"text":       f.Text,
+	}
+}
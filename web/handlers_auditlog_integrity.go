@@ -0,0 +1,45 @@
+package web
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/disgoorg/disgo/bot"
+
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/web/templates/partials"
+)
+
+// handleAuditLogIntegrity verifies the guild's audit log hash chain and
+// renders the badge. The page loads it separately, since walking a busy
+// guild's whole log takes longer than rendering a page of it.
+func handleAuditLogIntegrity(client *bot.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		guildIDStr := r.PathValue("id")
+		guildID, ok := checkGuildAdmin(w, r, client, guildIDStr)
+		if !ok {
+			return
+		}
+
+		report, err := model.VerifyAuditLogChain(r.Context(), guildID)
+		if err != nil {
+			slog.Warn("audit log integrity: verification failed", "guild_id", guildID, "err", err)
+			renderSafe(w, r, partials.AuditLogIntegrity(partials.AuditLogIntegrityData{Failed: true}))
+			return
+		}
+		renderSafe(w, r, partials.AuditLogIntegrity(integrityData(report)))
+	}
+}
+
+func integrityData(report model.AuditLogChainReport) partials.AuditLogIntegrityData {
+	data := partials.AuditLogIntegrityData{
+		Verified:   report.Verified,
+		Unchained:  report.Unchained,
+		BreakCount: report.BreakCount,
+	}
+	if len(report.Breaks) > 0 {
+		data.FirstBreak = report.Breaks[0].EntryID
+		data.Problem = report.Breaks[0].Problem
+	}
+	return data
+}
@@ -0,0 +1,32 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+func TestIntegrityData(t *testing.T) {
+	t.Run("verified", func(t *testing.T) {
+		data := integrityData(model.AuditLogChainReport{Verified: 10, Unchained: 2})
+		assert.Equal(t, 10, data.Verified)
+		assert.Equal(t, 2, data.Unchained)
+		assert.Zero(t, data.BreakCount)
+		assert.Zero(t, data.FirstBreak)
+	})
+
+	t.Run("broken shows the first break", func(t *testing.T) {
+		data := integrityData(model.AuditLogChainReport{
+			BreakCount: 3,
+			Breaks: []model.AuditLogChainBreak{
+				{Category: "guild", EntryID: 12, Problem: "hash is missing"},
+				{Category: "guild", EntryID: 15, Problem: "other"},
+			},
+		})
+		assert.Equal(t, 3, data.BreakCount)
+		assert.Equal(t, uint(12), data.FirstBreak)
+		assert.Equal(t, "hash is missing", data.Problem)
+	})
+}
@@ -9,24 +9,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
-	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/web/templates/pages"
+	"github.com/NLLCommunity/heimdallr/web/templates/partials"
 )
 
-func TestSummariseDetail_MessageDeleteIncludesOriginalAuthor(t *testing.T) {
-	summary, sections := summariseDetail(nil, 0, string(audit.EventMessageDelete), map[string]any{
-		"author_id":       "123",
-		"author_username": "messageAuthor",
-		"before_content":  "Deleted by a moderator.",
-	})
-
-	assert.Equal(t, "@messageAuthor: Deleted by a moderator.", summary)
-	require.Len(t, sections, 2)
-	assert.Equal(t, "Message author", sections[0].Heading)
-	assert.Equal(t, "@messageAuthor", sections[0].Body)
-	assert.Equal(t, "Deleted message", sections[1].Heading)
-	assert.Equal(t, "Deleted by a moderator.", sections[1].Body)
-}
-
 func TestParsePage(t *testing.T) {
 	cases := []struct {
 		in   string
@@ -85,6 +71,7 @@ func TestParseAuditLogFilters_NoDefaultWhenNarrowingFilterPresent(t *testing.T)
 		"target=%23general",
 		"from=2025-01-01",
 		"to=2025-12-31",
+		"q=discord.gift",
 	}
 	for _, qs := range cases {
 		t.Run(qs, func(t *testing.T) {
@@ -214,3 +201,19 @@ func TestResolveTargetQuery_NoCachePaths(t *testing.T) {
 		assert.Equal(t, "", text)
 	})
 }
+
+func TestSnippetSegments(t *testing.T) {
+	assert.Nil(t, snippetSegments(""))
+	assert.Equal(t, []partials.MatchSegment{
+		{Text: "…claim your "},
+		{Text: "free", Highlight: true},
+		{Text: " "},
+		{Text: "nitro", Highlight: true},
+		{Text: " at…"},
+	}, snippetSegments("…claim your \x02free\x03 \x02nitro\x03 at…"))
+	assert.Equal(t, []partials.MatchSegment{{Text: "gift", Highlight: true}}, snippetSegments("\x02gift"))
+}
+
+func TestAuditLogFilterQuery_IncludesText(t *testing.T) {
+	assert.Equal(t, "category=message&q=free+nitro", auditLogFilterQuery(pages.AuditLogFilters{Category: "message", Text: "free nitro"}))
+}
@@ -0,0 +1,141 @@
+package web
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/web/templates/layouts"
+	"github.com/NLLCommunity/heimdallr/web/templates/pages"
+)
+
+// voiceFormLayout is the value format of a datetime-local input.
+const voiceFormLayout = "2006-01-02T15:04"
+
+// voiceSessionLookback is how far before the window voice entries are read,
+// so members who joined before it are still shown with their join time.
+const voiceSessionLookback = 24 * time.Hour
+
+// maxVoiceWindow is the longest window the page accepts. Every voice entry
+// in the window is read into memory to rebuild the sessions.
+const maxVoiceWindow = 7 * 24 * time.Hour
+
+// handleAuditLogVoice shows who was in a voice channel during a time
+// window. Sessions are rebuilt from the voice join / leave / move entries,
+// which are only recorded while AuditVoiceEnabled is on.
+func handleAuditLogVoice(client *bot.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		guildIDStr := r.PathValue("id")
+		guildID, ok := checkGuildAdmin(w, r, client, guildIDStr)
+		if !ok {
+			return
+		}
+
+		q := r.URL.Query()
+		now := time.Now().UTC()
+		from, to := parseVoiceWindow(q.Get("from"), q.Get("to"), now)
+		data := pages.AuditLogVoiceData{
+			GuildID:   guildIDStr,
+			Channels:  guildVoiceChannels(client, guildID),
+			ChannelID: strings.TrimSpace(q.Get("channel")),
+			From:      from.Format(voiceFormLayout),
+			To:        to.Format(voiceFormLayout),
+		}
+
+		if data.ChannelID != "" {
+			data.Searched = true
+			channelID, err := snowflake.Parse(data.ChannelID)
+			if err != nil {
+				data.Error = "Invalid channel."
+			} else if msg := voiceWindowError(from, to); msg != "" {
+				data.Error = msg
+			} else {
+				filter := model.AuditLogFilter{
+					EventTypes: audit.VoiceEventTypes,
+					From:       from.Add(-voiceSessionLookback),
+					To:         to,
+				}
+				var entries []model.AuditLogEntry
+				err := model.EachAuditLogEntry(r.Context(), guildID, filter, 500, func(e model.AuditLogEntry) error {
+					entries = append(entries, e)
+					return nil
+				})
+				if err != nil {
+					slog.Error("failed to read voice audit log entries", "error", err, "guild_id", guildID)
+					data.Error = "Reading the audit log failed."
+				} else {
+					data.Rows = voiceSessionRows(audit.VoiceSessions(entries, channelID), from, to)
+				}
+			}
+		}
+
+		session := sessionFromContext(r.Context())
+		guild, _ := client.Caches.Guild(guildID)
+		nav := layouts.NavData{
+			User:      session,
+			GuildID:   guildIDStr,
+			GuildName: guild.Name,
+			IsAdmin:   true,
+			IsPostMod: true,
+		}
+		renderSafe(w, r, pages.AuditLogVoice(nav, data))
+	}
+}
+
+// parseVoiceWindow reads the from / to form values as UTC, defaulting to
+// the hour before now for whichever is missing or malformed.
+func parseVoiceWindow(fromStr, toStr string, now time.Time) (time.Time, time.Time) {
+	to, err := time.Parse(voiceFormLayout, strings.TrimSpace(toStr))
+	if err != nil {
+		to = now.Truncate(time.Minute)
+	}
+	from, err := time.Parse(voiceFormLayout, strings.TrimSpace(fromStr))
+	if err != nil {
+		from = to.Add(-time.Hour)
+	}
+	return from, to
+}
+
+// voiceWindowError is the page's error for a window it won't search, or
+// "" if the window is fine.
+func voiceWindowError(from, to time.Time) string {
+	switch {
+	case !to.After(from):
+		return "The end of the window must be after its start."
+	case to.Sub(from) > maxVoiceWindow:
+		return "The window can be at most 7 days long."
+	}
+	return ""
+}
+
+// voiceSessionRows keeps the sessions overlapping [from, to) and formats
+// them for the page. Sessions with an unknown start or end get no duration.
+func voiceSessionRows(sessions []audit.VoiceSession, from, to time.Time) []pages.VoiceSessionRow {
+	var rows []pages.VoiceSessionRow
+	for _, s := range sessions {
+		if !s.Overlaps(from, to) {
+			continue
+		}
+		row := pages.VoiceSessionRow{Member: s.Username}
+		if row.Member == "" {
+			row.Member = s.UserID.String()
+		}
+		if !s.Start.IsZero() {
+			row.Joined = s.Start.UTC().Format("2006-01-02 15:04:05")
+		}
+		if !s.End.IsZero() {
+			row.Left = s.End.UTC().Format("2006-01-02 15:04:05")
+		}
+		if !s.Start.IsZero() && !s.End.IsZero() {
+			row.Duration = s.End.Sub(s.Start).Round(time.Second).String()
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
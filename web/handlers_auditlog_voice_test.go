@@ -0,0 +1,49 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+)
+
+func TestParseVoiceWindow(t *testing.T) {
+	now := time.Date(2026, 3, 1, 21, 30, 45, 0, time.UTC)
+	from, to := parseVoiceWindow("2026-03-01T20:00", "2026-03-01T21:00", now)
+	assert.Equal(t, time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2026, 3, 1, 21, 0, 0, 0, time.UTC), to)
+
+	// Missing values default to the hour before now.
+	from, to = parseVoiceWindow("", "bad", now)
+	assert.Equal(t, time.Date(2026, 3, 1, 20, 30, 0, 0, time.UTC), from)
+	assert.Equal(t, time.Date(2026, 3, 1, 21, 30, 0, 0, time.UTC), to)
+}
+
+func TestVoiceWindowError(t *testing.T) {
+	from := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	assert.Empty(t, voiceWindowError(from, from.Add(time.Hour)))
+	assert.Empty(t, voiceWindowError(from, from.Add(maxVoiceWindow)))
+	assert.NotEmpty(t, voiceWindowError(from, from))
+	assert.NotEmpty(t, voiceWindowError(from, from.Add(maxVoiceWindow+time.Minute)))
+	assert.NotEmpty(t, voiceWindowError(from, from.AddDate(3, 0, 0)), "multi-year windows are rejected")
+}
+
+func TestVoiceSessionRows(t *testing.T) {
+	from := time.Date(2026, 3, 1, 20, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	rows := voiceSessionRows([]audit.VoiceSession{
+		{UserID: 1, Username: "alice", Start: from.Add(-10 * time.Minute), End: from.Add(20 * time.Minute)},
+		{UserID: 2, Start: from.Add(30 * time.Minute)},
+		{UserID: 3, Username: "carol", Start: to.Add(time.Minute)},
+	}, from, to)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "alice", rows[0].Member)
+	assert.Equal(t, "2026-03-01 19:50:00", rows[0].Joined)
+	assert.Equal(t, "30m0s", rows[0].Duration)
+	assert.Equal(t, "2", rows[1].Member)
+	assert.Empty(t, rows[1].Left)
+	assert.Empty(t, rows[1].Duration)
+}
@@ -0,0 +1,317 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/interactions/gatekeep"
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/utils"
+	"github.com/NLLCommunity/heimdallr/web/templates/layouts"
+	"github.com/NLLCommunity/heimdallr/web/templates/pages"
+	"github.com/NLLCommunity/heimdallr/web/templates/partials"
+)
+
+// gatekeepBulkMax caps how many members a single bulk action may touch.
+// Each member costs one or two Discord REST calls; a cap keeps one click
+// from monopolising the bot's rate-limit budget.
+const gatekeepBulkMax = 25
+
+// gatekeepNewAccountAge marks accounts younger than this in the queue so
+// throwaway accounts stand out.
+const gatekeepNewAccountAge = 7 * 24 * time.Hour
+
+// handleGatekeepQueue lists members that currently hold the gatekeep
+// pending role. The list is read from the member cache — the bot requests
+// the full member list at startup, so this avoids paging REST ListMembers.
+func handleGatekeepQueue(client *bot.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := sessionFromContext(r.Context())
+		guildIDStr := r.PathValue("id")
+		guildID, ok := checkGuildAdmin(w, r, client, guildIDStr)
+		if !ok {
+			return
+		}
+
+		settings, err := model.GetGuildSettings(guildID)
+		if err != nil {
+			http.Error(w, "failed to load settings", http.StatusInternalServerError)
+			return
+		}
+
+		guild, _ := client.Caches.Guild(guildID)
+		nav := layouts.NavData{
+			User:      session,
+			GuildID:   guildIDStr,
+			GuildName: guild.Name,
+			IsAdmin:   true,
+			IsPostMod: true,
+		}
+
+		renderSafe(w, r, pages.GatekeepQueue(nav, gatekeepQueueData(client, guildIDStr, settings, nil, nil, "")))
+	}
+}
+
+// handleGatekeepAction applies approve / deny / kick to the selected
+// members. Every action goes through the same gatekeep package functions
+// the slash commands use, so role changes, approval messages and audit
+// entries are identical apart from Source.
+func handleGatekeepAction(client *bot.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := sessionFromContext(r.Context())
+		guildIDStr := r.PathValue("id")
+		guildID, ok := checkGuildAdmin(w, r, client, guildIDStr)
+		if !ok {
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "invalid form data", http.StatusBadRequest)
+			return
+		}
+
+		settings, err := model.GetGuildSettings(guildID)
+		if err != nil {
+			http.Error(w, "failed to load settings", http.StatusInternalServerError)
+			return
+		}
+
+		// handled holds members acted on successfully. The gateway member
+		// update / leave for them usually hasn't reached the cache by the
+		// time we re-render, so they're filtered out explicitly.
+		handled := map[snowflake.ID]bool{}
+		renderQueue := func(results []partials.GatekeepActionResult, errMsg string) {
+			renderSafe(w, r, partials.GatekeepQueue(gatekeepQueueData(client, guildIDStr, settings, handled, results, errMsg)))
+		}
+
+		action := r.FormValue("action")
+		if action != "approve" && action != "deny" && action != "kick" {
+			renderQueue(nil, "Unknown action.")
+			return
+		}
+		reason := strings.TrimSpace(r.FormValue("reason"))
+		if len(reason) > 400 {
+			renderQueue(nil, "Reason must be 400 characters or fewer.")
+			return
+		}
+
+		userIDs, err := parseGatekeepUserIDs(r.Form["user_id"])
+		if err != nil {
+			renderQueue(nil, "Could not apply action: "+err.Error()+".")
+			return
+		}
+
+		guild, _ := client.Caches.Guild(guildID)
+		moderator := discord.User{ID: session.UserID, Username: session.Username}
+
+		results := make([]partials.GatekeepActionResult, 0, len(userIDs))
+		for _, userID := range userIDs {
+			member, ok := client.Caches.Member(guildID, userID)
+			if !ok {
+				results = append(results, partials.GatekeepActionResult{
+					Username: userID.String(),
+					Message:  "no longer in the server",
+				})
+				continue
+			}
+			// Re-check the pending role server-side: the queue page may be
+			// stale, and the form could be submitted with any member ID.
+			if settings.GatekeepPendingRole == 0 || !utils.HasRole(member, settings.GatekeepPendingRole) {
+				results = append(results, partials.GatekeepActionResult{
+					Username: member.User.Username,
+					Message:  "not pending approval",
+				})
+				continue
+			}
+
+			res := applyGatekeepAction(client, guild, member, moderator, action, reason)
+			if res.OK {
+				handled[userID] = true
+			}
+			results = append(results, res)
+		}
+
+		renderQueue(results, "")
+	}
+}
+
+func applyGatekeepAction(
+	client *bot.Client,
+	guild discord.Guild,
+	member discord.Member,
+	moderator discord.User,
+	action, reason string,
+) partials.GatekeepActionResult {
+	res := partials.GatekeepActionResult{Username: member.User.Username}
+
+	var err error
+	switch action {
+	case "approve":
+		var outcome gatekeep.ApproveOutcome
+		outcome, err = gatekeep.ApproveMember(client, guild, member, moderator, audit.SourceWeb)
+		if err == nil {
+			// The member is approved even if the welcome message failed;
+			// the outcome's text carries that warning.
+			res.OK = true
+			res.Message = outcome.String()
+			return res
+		}
+	case "deny":
+		err = gatekeep.DenyMember(client, guild, member, moderator, reason, audit.SourceWeb)
+		res.Message = "denied"
+	case "kick":
+		err = gatekeep.KickMember(client, guild, member, moderator, reason, audit.SourceWeb)
+		res.Message = "kicked"
+	}
+
+	if err != nil {
+		slog.Warn("gatekeep: dashboard action failed",
+			"action", action, "guild_id", guild.ID, "user_id", member.User.ID, "err", err)
+		res.Message = gatekeepErrorMessage(err)
+		return res
+	}
+	res.OK = true
+	return res
+}
+
+// gatekeepErrorMessage maps the gatekeep package's sentinel errors to the
+// same wording the slash commands use; anything else is a Discord failure.
+func gatekeepErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, gatekeep.ErrActionInProgress):
+		return "already being processed"
+	case errors.Is(err, gatekeep.ErrSettingsUnavailable):
+		return "failed to get guild information"
+	case errors.Is(err, gatekeep.ErrGatekeepDisabled):
+		return "gatekeep is not enabled in this server"
+	case errors.Is(err, gatekeep.ErrAlreadyApproved):
+		return "already approved"
+	case errors.Is(err, gatekeep.ErrNotPending):
+		return "not pending approval"
+	}
+	return "Discord rejected the request"
+}
+
+// parseGatekeepUserIDs validates the submitted member IDs, dropping
+// duplicates and enforcing gatekeepBulkMax.
+func parseGatekeepUserIDs(raw []string) ([]snowflake.ID, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("no members selected")
+	}
+	seen := make(map[snowflake.ID]bool, len(raw))
+	ids := make([]snowflake.ID, 0, len(raw))
+	for _, s := range raw {
+		id, err := snowflake.Parse(s)
+		if err != nil || id == 0 {
+			return nil, errors.New("invalid member ID")
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	if len(ids) > gatekeepBulkMax {
+		return nil, fmt.Errorf("at most %d members can be selected per action", gatekeepBulkMax)
+	}
+	return ids, nil
+}
+
+func gatekeepQueueData(
+	client *bot.Client,
+	guildIDStr string,
+	settings *model.GuildSettings,
+	exclude map[snowflake.ID]bool,
+	results []partials.GatekeepActionResult,
+	errMsg string,
+) partials.GatekeepQueueData {
+	data := partials.GatekeepQueueData{
+		GuildID:    guildIDStr,
+		Configured: settings.GatekeepEnabled && settings.GatekeepPendingRole != 0,
+		Results:    results,
+		Error:      errMsg,
+	}
+	if !data.Configured {
+		return data
+	}
+
+	var members []discord.Member
+	for m := range client.Caches.Members(settings.GuildID) {
+		if exclude[m.User.ID] {
+			continue
+		}
+		members = append(members, m)
+	}
+
+	data.Rows = buildGatekeepQueueRows(members, settings.GatekeepPendingRole, time.Now(), func(userID snowflake.ID) float64 {
+		score, err := model.GetUserTotalInfractionWeight(settings.GuildID, userID, settings.InfractionHalfLifeDays)
+		if err != nil {
+			slog.Warn("gatekeep: failed to compute infraction score", "guild_id", settings.GuildID, "user_id", userID, "err", err)
+			return 0
+		}
+		return score
+	})
+	return data
+}
+
+// buildGatekeepQueueRows filters members down to those holding the
+// pending role and converts them to render rows, oldest join first so the
+// longest-waiting members are at the top. score is injected so the
+// function stays testable without a database.
+func buildGatekeepQueueRows(
+	members []discord.Member,
+	pendingRole snowflake.ID,
+	now time.Time,
+	score func(snowflake.ID) float64,
+) []partials.GatekeepQueueRow {
+	var pending []discord.Member
+	for _, m := range members {
+		if m.User.Bot || !utils.HasRole(m, pendingRole) {
+			continue
+		}
+		pending = append(pending, m)
+	}
+
+	sort.SliceStable(pending, func(i, j int) bool {
+		a, b := pending[i].JoinedAt, pending[j].JoinedAt
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return a.Before(*b)
+	})
+
+	rows := make([]partials.GatekeepQueueRow, 0, len(pending))
+	for _, m := range pending {
+		age := now.Sub(m.User.CreatedAt())
+		rows = append(rows, partials.GatekeepQueueRow{
+			UserID:           m.User.ID.String(),
+			Username:         m.User.Username,
+			DisplayName:      m.EffectiveName(),
+			JoinedAt:         m.JoinedAt,
+			AccountAge:       formatAccountAge(age),
+			NewAccount:       age < gatekeepNewAccountAge,
+			InfractionScore:  score(m.User.ID),
+			ScreeningPending: m.Pending,
+		})
+	}
+	return rows
+}
+
+// formatAccountAge renders an account age at day granularity; finer
+// precision is noise for a moderation decision.
+func formatAccountAge(age time.Duration) string {
+	if age < 24*time.Hour {
+		return "less than a day"
+	}
+	return utils.DurationToHumanReadable(age.Truncate(24 * time.Hour))
+}
@@ -0,0 +1,94 @@
+package web
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildGatekeepQueueRows_FiltersAndSortsByJoin(t *testing.T) {
+	pending := snowflake.ID(10)
+	other := snowflake.ID(20)
+	now := time.Now()
+	early := now.Add(-2 * time.Hour)
+	late := now.Add(-1 * time.Hour)
+
+	// Account created ~30 days ago vs ~1 day ago.
+	oldUser := snowflake.New(now.Add(-30 * 24 * time.Hour))
+	newUser := snowflake.New(now.Add(-25 * time.Hour))
+
+	members := []discord.Member{
+		{User: discord.User{ID: newUser, Username: "late"}, RoleIDs: []snowflake.ID{pending}, JoinedAt: &late, Pending: true},
+		{User: discord.User{ID: 3, Username: "approved"}, RoleIDs: []snowflake.ID{other}, JoinedAt: &early},
+		{User: discord.User{ID: 4, Username: "bot", Bot: true}, RoleIDs: []snowflake.ID{pending}, JoinedAt: &early},
+		{User: discord.User{ID: oldUser, Username: "early"}, RoleIDs: []snowflake.ID{other, pending}, JoinedAt: &early},
+	}
+
+	scores := map[snowflake.ID]float64{oldUser: 1.5}
+	rows := buildGatekeepQueueRows(members, pending, now, func(id snowflake.ID) float64 { return scores[id] })
+
+	require.Len(t, rows, 2, "only non-bot members holding the pending role are queued")
+	assert.Equal(t, "early", rows[0].Username, "oldest join first")
+	assert.Equal(t, "late", rows[1].Username)
+
+	assert.Equal(t, 1.5, rows[0].InfractionScore)
+	assert.False(t, rows[0].NewAccount)
+	assert.False(t, rows[0].ScreeningPending)
+
+	assert.True(t, rows[1].NewAccount)
+	assert.True(t, rows[1].ScreeningPending)
+	assert.Equal(t, "1 day", rows[1].AccountAge)
+	assert.Equal(t, strconv.FormatUint(uint64(newUser), 10), rows[1].UserID)
+}
+
+func TestBuildGatekeepQueueRows_NilJoinedAtSortsLast(t *testing.T) {
+	pending := snowflake.ID(10)
+	now := time.Now()
+	joined := now.Add(-time.Hour)
+	members := []discord.Member{
+		{User: discord.User{ID: 1, Username: "unknown"}, RoleIDs: []snowflake.ID{pending}},
+		{User: discord.User{ID: 2, Username: "known"}, RoleIDs: []snowflake.ID{pending}, JoinedAt: &joined},
+	}
+
+	rows := buildGatekeepQueueRows(members, pending, now, func(snowflake.ID) float64 { return 0 })
+	require.Len(t, rows, 2)
+	assert.Equal(t, "known", rows[0].Username)
+	assert.Equal(t, "unknown", rows[1].Username)
+}
+
+func TestParseGatekeepUserIDs(t *testing.T) {
+	t.Run("empty selection is rejected", func(t *testing.T) {
+		_, err := parseGatekeepUserIDs(nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid ID is rejected", func(t *testing.T) {
+		_, err := parseGatekeepUserIDs([]string{"123", "abc"})
+		assert.Error(t, err)
+	})
+
+	t.Run("duplicates are collapsed", func(t *testing.T) {
+		ids, err := parseGatekeepUserIDs([]string{"123", "123", "456"})
+		require.NoError(t, err)
+		assert.Equal(t, []snowflake.ID{123, 456}, ids)
+	})
+
+	t.Run("more than the bulk cap is rejected", func(t *testing.T) {
+		raw := make([]string, 0, gatekeepBulkMax+1)
+		for i := range gatekeepBulkMax + 1 {
+			raw = append(raw, strconv.Itoa(i+1))
+		}
+		_, err := parseGatekeepUserIDs(raw)
+		assert.Error(t, err)
+	})
+}
+
+func TestFormatAccountAge(t *testing.T) {
+	assert.Equal(t, "less than a day", formatAccountAge(3*time.Hour))
+	assert.Equal(t, "2 days", formatAccountAge(2*24*time.Hour+5*time.Hour))
+}
@@ -0,0 +1,45 @@
+package web
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/disgoorg/disgo/bot"
+
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/web/templates/layouts"
+	"github.com/NLLCommunity/heimdallr/web/templates/pages"
+)
+
+// handleInvites renders per-invite join counts from the join history.
+func handleInvites(client *bot.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := sessionFromContext(r.Context())
+		guildIDStr := r.PathValue("id")
+		guildID, ok := checkGuildAdmin(w, r, client, guildIDStr)
+		if !ok {
+			return
+		}
+
+		stats, err := model.ListInviteStats(guildID)
+		if err != nil {
+			slog.Error("ListInviteStats failed", "error", err, "guild_id", guildID)
+			http.Error(w, "failed to load invite stats", http.StatusInternalServerError)
+			return
+		}
+
+		guild, _ := client.Caches.Guild(guildID)
+		nav := layouts.NavData{
+			User:      session,
+			GuildID:   guildIDStr,
+			GuildName: guild.Name,
+			IsAdmin:   true,
+			IsPostMod: true,
+		}
+
+		renderSafe(w, r, pages.Invites(nav, pages.InvitesData{
+			GuildID: guildIDStr,
+			Stats:   stats,
+		}))
+	}
+}
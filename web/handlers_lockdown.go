@@ -0,0 +1,218 @@
+package web
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/audit"
+	"github.com/NLLCommunity/heimdallr/interactions/lockdown"
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/web/templates/partials"
+)
+
+// Bounds for the raid protection settings. Mirror the min/max passed to
+// NumberField in settings_raid.templ.
+const (
+	minRaidJoinThreshold        = 3
+	maxRaidJoinThreshold        = 100
+	minRaidJoinWindowSeconds    = 5
+	maxRaidJoinWindowSeconds    = 300
+	maxRaidSimilarNameThreshold = 50
+	// A similar-name threshold of 1 or 2 would lock the server down on
+	// ordinary coincidences; 0 turns the check off.
+	minRaidSimilarNameThreshold = 3
+)
+
+// raidVerificationLevels are the levels a lockdown may raise the guild to.
+// "None" is left out: a lockdown that lowers nothing and raises nothing
+// would be pointless.
+var raidVerificationLevels = []discord.VerificationLevel{
+	discord.VerificationLevelLow,
+	discord.VerificationLevelMedium,
+	discord.VerificationLevelHigh,
+	discord.VerificationLevelVeryHigh,
+}
+
+func handleSaveRaid(client *bot.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		guildIDStr := r.PathValue("id")
+		guildID, ok := checkGuildAdmin(w, r, client, guildIDStr)
+		if !ok {
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "invalid form data", http.StatusBadRequest)
+			return
+		}
+		settings, err := model.GetGuildSettings(guildID)
+		if err != nil {
+			renderSafe(w, r, partials.SettingsRaid(partials.RaidData{
+				GuildID: guildIDStr, SaveError: "Failed to load settings.",
+			}))
+			return
+		}
+
+		renderRaidError := func(message string) {
+			data := buildRaidData(client, guildIDStr, settings)
+			data.SaveError = message
+			renderSafe(w, r, partials.SettingsRaid(data))
+		}
+
+		joinThreshold := parseInt(r.FormValue("join_threshold"), 10)
+		if joinThreshold < minRaidJoinThreshold || joinThreshold > maxRaidJoinThreshold {
+			renderRaidError("Join threshold must be between 3 and 100.")
+			return
+		}
+		joinWindow := parseInt(r.FormValue("join_window_seconds"), 10)
+		if joinWindow < minRaidJoinWindowSeconds || joinWindow > maxRaidJoinWindowSeconds {
+			renderRaidError("Join window must be between 5 and 300 seconds.")
+			return
+		}
+		similarNames := parseInt(r.FormValue("similar_name_threshold"), 5)
+		if similarNames != 0 && (similarNames < minRaidSimilarNameThreshold || similarNames > maxRaidSimilarNameThreshold) {
+			renderRaidError("Similar-name threshold must be 0 (off) or between 3 and 50.")
+			return
+		}
+		level := parseInt(r.FormValue("verification_level"), -1)
+		if !validRaidVerificationLevel(level) {
+			renderRaidError("Invalid verification level.")
+			return
+		}
+
+		settings.RaidDetectionEnabled = r.FormValue("enabled") == "true"
+		settings.RaidJoinThreshold = joinThreshold
+		settings.RaidJoinWindowSeconds = joinWindow
+		settings.RaidSimilarNameThreshold = similarNames
+		settings.RaidVerificationLevel = level
+		settings.RaidPauseInvites = r.FormValue("pause_invites") == "true"
+
+		if err := model.UpdateGuildSettingsColumns(settings,
+			"RaidDetectionEnabled", "RaidJoinThreshold", "RaidJoinWindowSeconds",
+			"RaidSimilarNameThreshold", "RaidVerificationLevel", "RaidPauseInvites",
+		); err != nil {
+			slog.Error("failed to save raid settings", "error", err)
+			renderRaidError("Failed to save settings.")
+			return
+		}
+		logSettingsUpdate(sessionFromContext(r.Context()), guildID, "raid", map[string]any{
+			"enabled":                settings.RaidDetectionEnabled,
+			"join_threshold":         settings.RaidJoinThreshold,
+			"join_window_seconds":    settings.RaidJoinWindowSeconds,
+			"similar_name_threshold": settings.RaidSimilarNameThreshold,
+			"verification_level":     settings.RaidVerificationLevel,
+			"pause_invites":          settings.RaidPauseInvites,
+		})
+
+		data := buildRaidData(client, guildIDStr, settings)
+		data.SaveSuccess = true
+		renderSafe(w, r, partials.SettingsRaid(data))
+	}
+}
+
+// handleLockdownStart starts a lockdown by hand from the dashboard. It
+// goes through the same lockdown.Start as raid detection, so the alert,
+// verification level and invite pause are identical.
+func handleLockdownStart(client *bot.Client) http.HandlerFunc {
+	return handleLockdownAction(client, func(guildID snowflake.ID, moderator discord.User) error {
+		return lockdown.Start(client, guildID, lockdown.Trigger{Reason: "Started from the dashboard"}, &moderator, audit.SourceWeb)
+	})
+}
+
+func handleLockdownEnd(client *bot.Client) http.HandlerFunc {
+	return handleLockdownAction(client, func(guildID snowflake.ID, moderator discord.User) error {
+		return lockdown.End(client, guildID, moderator, audit.SourceWeb)
+	})
+}
+
+func handleLockdownAction(client *bot.Client, action func(snowflake.ID, discord.User) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := sessionFromContext(r.Context())
+		guildIDStr := r.PathValue("id")
+		guildID, ok := checkGuildAdmin(w, r, client, guildIDStr)
+		if !ok {
+			return
+		}
+
+		settings, err := model.GetGuildSettings(guildID)
+		if err != nil {
+			http.Error(w, "failed to load settings", http.StatusInternalServerError)
+			return
+		}
+
+		moderator := discord.User{ID: session.UserID, Username: session.Username}
+		err = action(guildID, moderator)
+
+		data := buildRaidData(client, guildIDStr, settings)
+		switch {
+		case errors.Is(err, lockdown.ErrLockdownActive), errors.Is(err, lockdown.ErrNoLockdown):
+			// Someone else got there first; the re-rendered panel shows
+			// the current state.
+		case err != nil:
+			slog.Error("lockdown: dashboard action failed", "guild_id", guildID, "err", err)
+			data.LockdownError = "Failed to update the lockdown."
+		}
+		renderSafe(w, r, partials.SettingsRaid(data))
+	}
+}
+
+func validRaidVerificationLevel(level int) bool {
+	for _, l := range raidVerificationLevels {
+		if int(l) == level {
+			return true
+		}
+	}
+	return false
+}
+
+func buildRaidData(client *bot.Client, guildIDStr string, settings *model.GuildSettings) partials.RaidData {
+	data := partials.RaidData{
+		GuildID:              guildIDStr,
+		Enabled:              settings.RaidDetectionEnabled,
+		JoinThreshold:        settings.RaidJoinThreshold,
+		JoinWindowSeconds:    settings.RaidJoinWindowSeconds,
+		SimilarNameThreshold: settings.RaidSimilarNameThreshold,
+		VerificationLevel:    settings.RaidVerificationLevel,
+		PauseInvites:         settings.RaidPauseInvites,
+	}
+	for _, l := range raidVerificationLevels {
+		data.VerificationLevels = append(data.VerificationLevels, partials.VerificationLevelOption{
+			Value: int(l),
+			Label: lockdown.VerificationLevelName(l),
+		})
+	}
+
+	state, err := model.GetGuildLockdown(settings.GuildID)
+	if err != nil {
+		slog.Warn("lockdown: failed to load lockdown state", "guild_id", settings.GuildID, "err", err)
+		data.LockdownError = "Failed to load the lockdown state."
+		return data
+	}
+	if state != nil {
+		data.Lockdown = lockdownStatus(client, state)
+	}
+	return data
+}
+
+func lockdownStatus(client *bot.Client, state *model.GuildLockdown) *partials.LockdownStatus {
+	status := &partials.LockdownStatus{
+		Since:              state.CreatedAt,
+		Reason:             state.Reason,
+		VerificationRaised: state.VerificationRaised,
+		InvitesPaused:      state.InvitesPaused,
+	}
+	if state.StartedBy != 0 {
+		status.StartedBy = state.StartedBy.String()
+		if member, ok := client.Caches.Member(state.GuildID, state.StartedBy); ok {
+			status.StartedBy = "@" + member.User.Username
+		}
+	} else {
+		status.StartedBy = "raid detection"
+	}
+	return status
+}
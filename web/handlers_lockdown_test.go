@@ -0,0 +1,16 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidRaidVerificationLevel(t *testing.T) {
+	assert.False(t, validRaidVerificationLevel(0), "None would make the lockdown a no-op")
+	for level := 1; level <= 4; level++ {
+		assert.True(t, validRaidVerificationLevel(level), "level %d", level)
+	}
+	assert.False(t, validRaidVerificationLevel(5))
+	assert.False(t, validRaidVerificationLevel(-1))
+}
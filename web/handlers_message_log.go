@@ -0,0 +1,106 @@
+package web
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/web/templates/partials"
+)
+
+func buildMessageLogData(client *bot.Client, guildID string, settings *model.GuildSettings) partials.MessageLogData {
+	ignored, err := model.GetMessageLogIgnoredChannels(settings.GuildID)
+	if err != nil {
+		slog.Warn("message log settings: failed to load ignored channels", "guild_id", settings.GuildID, "err", err)
+	}
+	ignoredStrs := make([]string, 0, len(ignored))
+	for _, c := range ignored {
+		ignoredStrs = append(ignoredStrs, c.String())
+	}
+	return partials.MessageLogData{
+		GuildID:         guildID,
+		LogChannel:      idStr(settings.MessageLogChannel),
+		IgnoredChannels: ignoredStrs,
+		Channels:        guildChannels(client, settings.GuildID),
+	}
+}
+
+// parseChannelIDs parses submitted checkbox values, dropping duplicates.
+func parseChannelIDs(values []string) ([]snowflake.ID, error) {
+	var out []snowflake.ID
+	for _, v := range values {
+		id, err := snowflake.Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(out, id) {
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
+
+func handleSaveMessageLog(client *bot.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		guildIDStr := r.PathValue("id")
+		guildID, ok := checkGuildAdmin(w, r, client, guildIDStr)
+		if !ok {
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "invalid form data", http.StatusBadRequest)
+			return
+		}
+		settings, err := model.GetGuildSettings(guildID)
+		if err != nil {
+			renderSafe(w, r, partials.SettingsMessageLog(partials.MessageLogData{
+				GuildID: guildIDStr, SaveError: "Failed to load settings.",
+			}))
+			return
+		}
+
+		renderErr := func(message string) {
+			data := buildMessageLogData(client, guildIDStr, settings)
+			data.LogChannel = r.FormValue("log_channel")
+			data.IgnoredChannels = r.Form["ignored_channels"]
+			data.SaveError = message
+			renderSafe(w, r, partials.SettingsMessageLog(data))
+		}
+
+		logChannel, err := parseSnowflakeOrZero(r.FormValue("log_channel"))
+		if err != nil {
+			renderErr("Invalid channel ID.")
+			return
+		}
+		ignored, err := parseChannelIDs(r.Form["ignored_channels"])
+		if err != nil {
+			renderErr("Invalid ignored channel.")
+			return
+		}
+
+		settings.MessageLogChannel = logChannel
+		if err := model.UpdateGuildSettingsColumns(settings, "MessageLogChannel"); err != nil {
+			slog.Error("failed to save message log settings", "error", err)
+			renderErr("Failed to save settings.")
+			return
+		}
+		if err := model.SetMessageLogIgnoredChannels(guildID, ignored); err != nil {
+			slog.Error("failed to save message log ignored channels", "error", err)
+			renderErr("Failed to save settings.")
+			return
+		}
+		logSettingsUpdate(sessionFromContext(r.Context()), guildID, "message_log", map[string]any{
+			"message_log_channel": idStr(settings.MessageLogChannel),
+			"ignored_channels":    ignored,
+		})
+
+		data := buildMessageLogData(client, guildIDStr, settings)
+		data.SaveSuccess = true
+		renderSafe(w, r, partials.SettingsMessageLog(data))
+	}
+}
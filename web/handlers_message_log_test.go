@@ -0,0 +1,27 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChannelIDs(t *testing.T) {
+	t.Run("duplicates are dropped", func(t *testing.T) {
+		ids, err := parseChannelIDs([]string{"2", "1", "2"})
+		assert.NoError(t, err)
+		assert.Equal(t, []snowflake.ID{2, 1}, ids)
+	})
+
+	t.Run("nothing submitted", func(t *testing.T) {
+		ids, err := parseChannelIDs(nil)
+		assert.NoError(t, err)
+		assert.Empty(t, ids)
+	})
+
+	t.Run("non-numeric input errors", func(t *testing.T) {
+		_, err := parseChannelIDs([]string{"1", "general"})
+		assert.Error(t, err)
+	})
+}
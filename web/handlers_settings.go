@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -17,6 +18,7 @@ import (
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/snowflake/v2"
 
+	"github.com/NLLCommunity/heimdallr/antispam"
 	"github.com/NLLCommunity/heimdallr/model"
 	"github.com/NLLCommunity/heimdallr/utils"
 	"github.com/NLLCommunity/heimdallr/web/templates/components"
@@ -31,6 +33,19 @@ import (
 // change between page loads. Pure ordering logic lives in groupChannels so
 // it can be unit tested without a live bot client.
 func guildChannels(client *bot.Client, guildID snowflake.ID) []components.ChannelGroup {
+	return groupChannels(cachedChannelInfo(client, guildID))
+}
+
+// guildVoiceChannels is guildChannels for voice and stage channels.
+func guildVoiceChannels(client *bot.Client, guildID snowflake.ID) []components.ChannelGroup {
+	return groupChannelsOf(cachedChannelInfo(client, guildID), isVoiceChannel)
+}
+
+func isVoiceChannel(t discord.ChannelType) bool {
+	return t == discord.ChannelTypeGuildVoice || t == discord.ChannelTypeGuildStageVoice
+}
+
+func cachedChannelInfo(client *bot.Client, guildID snowflake.ID) []components.ChannelInfo {
 	var all []components.ChannelInfo
 	for ch := range client.Caches.ChannelsForGuild(guildID) {
 		info := components.ChannelInfo{
@@ -44,7 +59,7 @@ func guildChannels(client *bot.Client, guildID snowflake.ID) []components.Channe
 		}
 		all = append(all, info)
 	}
-	return groupChannels(all)
+	return all
 }
 
 // groupChannels takes a flat list of channels (categories included) and
@@ -55,6 +70,12 @@ func guildChannels(client *bot.Client, guildID snowflake.ID) []components.Channe
 // a channel whose ParentID does not match any category in the input is
 // treated as uncategorized rather than producing an empty-label optgroup.
 func groupChannels(all []components.ChannelInfo) []components.ChannelGroup {
+	return groupChannelsOf(all, components.IsTextChannel)
+}
+
+// groupChannelsOf is groupChannels with the kept channel types chosen by
+// keep instead of fixed to text channels.
+func groupChannelsOf(all []components.ChannelInfo, keep func(discord.ChannelType) bool) []components.ChannelGroup {
 	type category struct {
 		name     string
 		position int
@@ -75,7 +96,7 @@ func groupChannels(all []components.ChannelInfo) []components.ChannelGroup {
 
 	var topLevel []components.ChannelInfo
 	for _, c := range all {
-		if !components.IsTextChannel(c.Type) {
+		if !keep(c.Type) {
 			continue
 		}
 		if c.ParentID == "" {
@@ -218,17 +239,18 @@ func handleDashboard(client *bot.Client) http.HandlerFunc {
 			IsPostMod: true,
 		}
 
-		allSections := allSettingsSections(guildIDStr, settings, ms, channels, roles)
+		allSections := allSettingsSections(client, guildIDStr, settings, ms, channels, roles)
 		renderSafe(w, r, pages.Dashboard(nav, guildIDStr, allSections))
 	}
 }
 
-// allSettingsSections renders all 7 settings sections as a single component.
-func allSettingsSections(guildID string, settings *model.GuildSettings, ms *model.ModmailSettings, channels []components.ChannelGroup, roles []components.RoleInfo) templ.Component {
+// allSettingsSections renders all settings sections as a single component.
+func allSettingsSections(client *bot.Client, guildID string, settings *model.GuildSettings, ms *model.ModmailSettings, channels []components.ChannelGroup, roles []components.RoleInfo) templ.Component {
 	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
 		if err := partials.SettingsModChannel(partials.ModChannelData{
 			GuildID:          guildID,
 			ModeratorChannel: idStr(settings.ModeratorChannel),
+			NotifyJoinInvite: settings.NotifyJoinInvite,
 			Channels:         channels,
 		}).Render(ctx, w); err != nil {
 			return err
@@ -238,6 +260,8 @@ func allSettingsSections(guildID string, settings *model.GuildSettings, ms *mode
 			HalfLifeDays:                settings.InfractionHalfLifeDays,
 			NotifyOnWarnedUserJoin:      settings.NotifyOnWarnedUserJoin,
 			NotifyWarnSeverityThreshold: settings.NotifyWarnSeverityThreshold,
+			AutoModInfractionEnabled:    settings.AutoModInfractionEnabled,
+			AutoModInfractionSeverity:   settings.AutoModInfractionSeverity,
 		}).Render(ctx, w); err != nil {
 			return err
 		}
@@ -271,12 +295,13 @@ func allSettingsSections(guildID string, settings *model.GuildSettings, ms *mode
 		}).Render(ctx, w); err != nil {
 			return err
 		}
-		if err := partials.SettingsAntiSpam(partials.AntiSpamData{
-			GuildID:         guildID,
-			Enabled:         settings.AntiSpamEnabled,
-			Count:           settings.AntiSpamCount,
-			CooldownSeconds: settings.AntiSpamCooldownSeconds,
-		}).Render(ctx, w); err != nil {
+		if err := partials.SettingsAntiSpam(buildAntiSpamData(client, guildID, settings)).Render(ctx, w); err != nil {
+			return err
+		}
+		if err := partials.SettingsAntiSpamExemptions(buildAntiSpamExemptionsData(client, guildID, settings.GuildID)).Render(ctx, w); err != nil {
+			return err
+		}
+		if err := partials.SettingsRaid(buildRaidData(client, guildID, settings)).Render(ctx, w); err != nil {
 			return err
 		}
 		if err := partials.SettingsBanFooter(partials.BanFooterData{
@@ -306,7 +331,13 @@ func allSettingsSections(guildID string, settings *model.GuildSettings, ms *mode
 		}).Render(ctx, w); err != nil {
 			return err
 		}
-		if err := partials.SettingsAuditLog(buildAuditLogSettingsData(guildID, settings)).Render(ctx, w); err != nil {
+		if err := partials.SettingsAuditLog(buildAuditLogSettingsData(client, guildID, settings)).Render(ctx, w); err != nil {
+			return err
+		}
+		if err := partials.SettingsMessageLog(buildMessageLogData(client, guildID, settings)).Render(ctx, w); err != nil {
+			return err
+		}
+		if err := partials.SettingsAuditStream(buildAuditStreamData(client, guildID, settings)).Render(ctx, w); err != nil {
 			return err
 		}
 		return nil
@@ -403,6 +434,7 @@ func handleSaveModChannel(client *bot.Client) http.HandlerFunc {
 			renderSafe(w, r, partials.SettingsModChannel(partials.ModChannelData{
 				GuildID:          guildIDStr,
 				ModeratorChannel: idStr(settings.ModeratorChannel),
+				NotifyJoinInvite: settings.NotifyJoinInvite,
 				Channels:         guildChannels(client, guildID),
 				SaveError:        message,
 			}))
@@ -414,17 +446,22 @@ func handleSaveModChannel(client *bot.Client) http.HandlerFunc {
 			return
 		}
 		settings.ModeratorChannel = modChannel
-		if err := model.UpdateGuildSettingsColumns(settings, "ModeratorChannel"); err != nil {
+		settings.NotifyJoinInvite = r.FormValue("notify_join_invite") == "true"
+		if err := model.UpdateGuildSettingsColumns(settings, "ModeratorChannel", "NotifyJoinInvite"); err != nil {
 			slog.Error("failed to save mod channel settings", "error", err)
 			renderModChannelError("Failed to save settings.")
 			return
 		}
 		logSettingsUpdate(sessionFromContext(r.Context()), guildID, "mod_channel",
-			map[string]any{"moderator_channel": idStr(settings.ModeratorChannel)})
+			map[string]any{
+				"moderator_channel":  idStr(settings.ModeratorChannel),
+				"notify_join_invite": settings.NotifyJoinInvite,
+			})
 
 		renderSafe(w, r, partials.SettingsModChannel(partials.ModChannelData{
 			GuildID:          guildIDStr,
 			ModeratorChannel: idStr(settings.ModeratorChannel),
+			NotifyJoinInvite: settings.NotifyJoinInvite,
 			Channels:         guildChannels(client, guildID),
 			SaveSuccess:      true,
 		}))
@@ -457,6 +494,8 @@ func handleSaveInfractions(client *bot.Client) http.HandlerFunc {
 				HalfLifeDays:                settings.InfractionHalfLifeDays,
 				NotifyOnWarnedUserJoin:      settings.NotifyOnWarnedUserJoin,
 				NotifyWarnSeverityThreshold: settings.NotifyWarnSeverityThreshold,
+				AutoModInfractionEnabled:    settings.AutoModInfractionEnabled,
+				AutoModInfractionSeverity:   settings.AutoModInfractionSeverity,
 				SaveError:                   message,
 			}))
 		}
@@ -471,12 +510,20 @@ func handleSaveInfractions(client *bot.Client) http.HandlerFunc {
 			renderInfractionsError("Severity threshold must be between 0 and 100.")
 			return
 		}
+		autoModSeverity, err := parseFloat(r.FormValue("automod_infraction_severity"))
+		if err != nil || autoModSeverity < minAutoModInfractionSeverity || autoModSeverity > maxAutoModInfractionSeverity {
+			renderInfractionsError("AutoMod infraction severity must be between 0 and 10.")
+			return
+		}
 		settings.InfractionHalfLifeDays = halfLife
 		settings.NotifyOnWarnedUserJoin = r.FormValue("notify_on_warned_user_join") == "true"
 		settings.NotifyWarnSeverityThreshold = threshold
+		settings.AutoModInfractionEnabled = r.FormValue("automod_infraction_enabled") == "true"
+		settings.AutoModInfractionSeverity = autoModSeverity
 
 		if err := model.UpdateGuildSettingsColumns(settings,
 			"InfractionHalfLifeDays", "NotifyOnWarnedUserJoin", "NotifyWarnSeverityThreshold",
+			"AutoModInfractionEnabled", "AutoModInfractionSeverity",
 		); err != nil {
 			slog.Error("failed to save infraction settings", "error", err)
 			renderInfractionsError("Failed to save settings.")
@@ -486,6 +533,8 @@ func handleSaveInfractions(client *bot.Client) http.HandlerFunc {
 			"half_life_days":                 settings.InfractionHalfLifeDays,
 			"notify_on_warned_user_join":     settings.NotifyOnWarnedUserJoin,
 			"notify_warn_severity_threshold": settings.NotifyWarnSeverityThreshold,
+			"automod_infraction_enabled":     settings.AutoModInfractionEnabled,
+			"automod_infraction_severity":    settings.AutoModInfractionSeverity,
 		})
 
 		renderSafe(w, r, partials.SettingsInfractions(partials.InfractionsData{
@@ -493,6 +542,8 @@ func handleSaveInfractions(client *bot.Client) http.HandlerFunc {
 			HalfLifeDays:                settings.InfractionHalfLifeDays,
 			NotifyOnWarnedUserJoin:      settings.NotifyOnWarnedUserJoin,
 			NotifyWarnSeverityThreshold: settings.NotifyWarnSeverityThreshold,
+			AutoModInfractionEnabled:    settings.AutoModInfractionEnabled,
+			AutoModInfractionSeverity:   settings.AutoModInfractionSeverity,
 			SaveSuccess:                 true,
 		}))
 	}
@@ -519,18 +570,14 @@ func handleSaveAntiSpam(client *bot.Client) http.HandlerFunc {
 		}
 
 		renderAntiSpamError := func(message string) {
-			renderSafe(w, r, partials.SettingsAntiSpam(partials.AntiSpamData{
-				GuildID:         guildIDStr,
-				Enabled:         settings.AntiSpamEnabled,
-				Count:           settings.AntiSpamCount,
-				CooldownSeconds: settings.AntiSpamCooldownSeconds,
-				SaveError:       message,
-			}))
+			data := buildAntiSpamData(client, guildIDStr, settings)
+			data.SaveError = message
+			renderSafe(w, r, partials.SettingsAntiSpam(data))
 		}
 
 		count := parseInt(r.FormValue("count"), 5)
 		if count < minAntiSpamCount || count > maxAntiSpamCount {
-			renderAntiSpamError("Message count must be between 2 and 10.")
+			renderAntiSpamError("Spam score threshold must be between 2 and 10.")
 			return
 		}
 		cooldown := parseInt(r.FormValue("cooldown_seconds"), 20)
@@ -538,31 +585,212 @@ func handleSaveAntiSpam(client *bot.Client) http.HandlerFunc {
 			renderAntiSpamError("Cooldown must be between 1 and 60 seconds.")
 			return
 		}
-		settings.AntiSpamEnabled = r.FormValue("enabled") == "true"
-		settings.AntiSpamCount = count
-		settings.AntiSpamCooldownSeconds = cooldown
+
+		// Parse the rule limits into a copy so a validation error re-renders
+		// the stored values rather than a half-applied form.
+		updated := *settings
+		ruleLimits := []struct {
+			field    string
+			label    string
+			dest     *int
+			min, max int
+		}{
+			{"timeout_minutes", "Timeout length", &updated.AntiSpamTimeoutMinutes, minAntiSpamTimeoutMinutes, maxAntiSpamTimeoutMinutes},
+			{"burst_count", "Burst message count", &updated.AntiSpamBurstCount, minAntiSpamBurstCount, maxAntiSpamBurstCount},
+			{"burst_seconds", "Burst window", &updated.AntiSpamBurstSeconds, minAntiSpamBurstSeconds, maxAntiSpamBurstSeconds},
+			{"max_mentions", "Mentions allowed", &updated.AntiSpamMaxMentions, minAntiSpamMaxMentions, maxAntiSpamMaxMentions},
+			{"max_invites", "Invite links allowed", &updated.AntiSpamMaxInvites, minAntiSpamMaxInvites, maxAntiSpamMaxInvites},
+			{"max_attachments", "Attachments allowed", &updated.AntiSpamMaxAttachments, minAntiSpamMaxAttachments, maxAntiSpamMaxAttachments},
+			{"max_emoji", "Emoji allowed", &updated.AntiSpamMaxEmoji, minAntiSpamMaxEmoji, maxAntiSpamMaxEmoji},
+			{"max_combining_marks", "Combining characters allowed", &updated.AntiSpamMaxCombiningMarks, minAntiSpamMaxCombiningMarks, maxAntiSpamMaxCombiningMarks},
+			{"coordinated_authors", "Coordinated spam account count", &updated.AntiSpamCoordinatedAuthors, minAntiSpamCoordinatedAuthors, maxAntiSpamCoordinatedAuthors},
+			{"coordinated_window_seconds", "Coordinated spam window", &updated.AntiSpamCoordinatedWindowSeconds, minAntiSpamCoordinatedWindowSeconds, maxAntiSpamCoordinatedWindowSeconds},
+		}
+		for _, l := range ruleLimits {
+			v := parseInt(r.FormValue(l.field), *l.dest)
+			if v < l.min || v > l.max {
+				renderAntiSpamError(fmt.Sprintf("%s must be between %d and %d.", l.label, l.min, l.max))
+				return
+			}
+			*l.dest = v
+		}
+
+		action := antispam.Action(r.FormValue("action"))
+		if !slices.Contains(antispam.Actions, action) {
+			renderAntiSpamError("Invalid action.")
+			return
+		}
+		quarantineRole, err := parseSnowflakeOrZero(r.FormValue("quarantine_role"))
+		if err != nil || quarantineRole == guildID {
+			renderAntiSpamError("Invalid quarantine role.")
+			return
+		}
+		if action == antispam.ActionQuarantine && quarantineRole == 0 {
+			renderAntiSpamError("Choose a quarantine role to use the quarantine action.")
+			return
+		}
+		severity, err := parseFloat(r.FormValue("infraction_severity"))
+		if err != nil || severity < minAntiSpamInfractionSeverity || severity > maxAntiSpamInfractionSeverity {
+			renderAntiSpamError("Infraction severity must be between 0 and 10.")
+			return
+		}
+
+		updated.AntiSpamEnabled = r.FormValue("enabled") == "true"
+		updated.AntiSpamObserveOnly = r.FormValue("observe_only") == "true"
+		updated.AntiSpamAction = string(action)
+		updated.AntiSpamQuarantineRole = quarantineRole
+		updated.AntiSpamInfractionEnabled = r.FormValue("infraction_enabled") == "true"
+		updated.AntiSpamInfractionSeverity = severity
+		updated.AntiSpamCount = count
+		updated.AntiSpamCooldownSeconds = cooldown
+		updated.AntiSpamBurstEnabled = r.FormValue("burst_enabled") == "true"
+		updated.AntiSpamMentionsEnabled = r.FormValue("mentions_enabled") == "true"
+		updated.AntiSpamInvitesEnabled = r.FormValue("invites_enabled") == "true"
+		updated.AntiSpamMediaEnabled = r.FormValue("media_enabled") == "true"
+		updated.AntiSpamZalgoEnabled = r.FormValue("zalgo_enabled") == "true"
+		updated.AntiSpamCoordinatedEnabled = r.FormValue("coordinated_enabled") == "true"
+		updated.AntiSpamCoordinatedBan = r.FormValue("coordinated_ban") == "true"
+		updated.AntiSpamPhishingEnabled = r.FormValue("phishing_enabled") == "true"
+
+		phishingAllow, message := parsePhishingDomains(r.FormValue("phishing_allow"), "Never block")
+		if message != "" {
+			renderAntiSpamError(message)
+			return
+		}
+		phishingDeny, message := parsePhishingDomains(r.FormValue("phishing_deny"), "Also block")
+		if message != "" {
+			renderAntiSpamError(message)
+			return
+		}
+		settings = &updated
 
 		if err := model.UpdateGuildSettingsColumns(settings,
 			"AntiSpamEnabled", "AntiSpamCount", "AntiSpamCooldownSeconds",
+			"AntiSpamObserveOnly", "AntiSpamAction", "AntiSpamTimeoutMinutes", "AntiSpamQuarantineRole",
+			"AntiSpamInfractionEnabled", "AntiSpamInfractionSeverity",
+			"AntiSpamBurstEnabled", "AntiSpamBurstCount", "AntiSpamBurstSeconds",
+			"AntiSpamMentionsEnabled", "AntiSpamMaxMentions",
+			"AntiSpamInvitesEnabled", "AntiSpamMaxInvites",
+			"AntiSpamMediaEnabled", "AntiSpamMaxAttachments", "AntiSpamMaxEmoji",
+			"AntiSpamZalgoEnabled", "AntiSpamMaxCombiningMarks",
+			"AntiSpamCoordinatedEnabled", "AntiSpamCoordinatedAuthors",
+			"AntiSpamCoordinatedWindowSeconds", "AntiSpamCoordinatedBan",
+			"AntiSpamPhishingEnabled",
 		); err != nil {
 			slog.Error("failed to save anti-spam settings", "error", err)
 			renderAntiSpamError("Failed to save settings.")
 			return
 		}
+		if err := model.SetPhishingDomains(guildID, phishingAllow, phishingDeny); err != nil {
+			slog.Error("failed to save phishing domains", "error", err)
+			renderAntiSpamError("Failed to save settings.")
+			return
+		}
 		logSettingsUpdate(sessionFromContext(r.Context()), guildID, "anti_spam", map[string]any{
-			"enabled":          settings.AntiSpamEnabled,
-			"count":            settings.AntiSpamCount,
-			"cooldown_seconds": settings.AntiSpamCooldownSeconds,
+			"enabled":             settings.AntiSpamEnabled,
+			"count":               settings.AntiSpamCount,
+			"cooldown_seconds":    settings.AntiSpamCooldownSeconds,
+			"observe_only":        settings.AntiSpamObserveOnly,
+			"action":              settings.AntiSpamAction,
+			"timeout_minutes":     settings.AntiSpamTimeoutMinutes,
+			"quarantine_role":     idStr(settings.AntiSpamQuarantineRole),
+			"infraction_enabled":  settings.AntiSpamInfractionEnabled,
+			"infraction_severity": settings.AntiSpamInfractionSeverity,
+			"burst_enabled":       settings.AntiSpamBurstEnabled,
+			"burst_count":         settings.AntiSpamBurstCount,
+			"burst_seconds":       settings.AntiSpamBurstSeconds,
+			"mentions_enabled":    settings.AntiSpamMentionsEnabled,
+			"max_mentions":        settings.AntiSpamMaxMentions,
+			"invites_enabled":     settings.AntiSpamInvitesEnabled,
+			"max_invites":         settings.AntiSpamMaxInvites,
+			"media_enabled":       settings.AntiSpamMediaEnabled,
+			"max_attachments":     settings.AntiSpamMaxAttachments,
+			"max_emoji":           settings.AntiSpamMaxEmoji,
+			"zalgo_enabled":       settings.AntiSpamZalgoEnabled,
+			"max_combining_marks": settings.AntiSpamMaxCombiningMarks,
+			"coordinated_enabled": settings.AntiSpamCoordinatedEnabled,
+			"coordinated_authors": settings.AntiSpamCoordinatedAuthors,
+			"coordinated_window":  settings.AntiSpamCoordinatedWindowSeconds,
+			"coordinated_ban":     settings.AntiSpamCoordinatedBan,
+			"phishing_enabled":    settings.AntiSpamPhishingEnabled,
+			"phishing_allow":      phishingAllow,
+			"phishing_deny":       phishingDeny,
 		})
 
-		renderSafe(w, r, partials.SettingsAntiSpam(partials.AntiSpamData{
-			GuildID:         guildIDStr,
-			Enabled:         settings.AntiSpamEnabled,
-			Count:           settings.AntiSpamCount,
-			CooldownSeconds: settings.AntiSpamCooldownSeconds,
-			SaveSuccess:     true,
-		}))
+		data := buildAntiSpamData(client, guildIDStr, settings)
+		data.SaveSuccess = true
+		renderSafe(w, r, partials.SettingsAntiSpam(data))
+	}
+}
+
+func buildAntiSpamData(client *bot.Client, guildIDStr string, settings *model.GuildSettings) partials.AntiSpamData {
+	data := partials.AntiSpamData{
+		GuildID:            guildIDStr,
+		Enabled:            settings.AntiSpamEnabled,
+		Count:              settings.AntiSpamCount,
+		CooldownSeconds:    settings.AntiSpamCooldownSeconds,
+		ObserveOnly:        settings.AntiSpamObserveOnly,
+		Action:             string(antispam.ParseAction(settings.AntiSpamAction)),
+		TimeoutMinutes:     settings.AntiSpamTimeoutMinutes,
+		QuarantineRole:     idStr(settings.AntiSpamQuarantineRole),
+		Roles:              rolesWithoutEveryone(guildRoles(client, settings.GuildID), guildIDStr),
+		InfractionEnabled:  settings.AntiSpamInfractionEnabled,
+		InfractionSeverity: settings.AntiSpamInfractionSeverity,
+
+		MentionsEnabled:   settings.AntiSpamMentionsEnabled,
+		MaxMentions:       settings.AntiSpamMaxMentions,
+		InvitesEnabled:    settings.AntiSpamInvitesEnabled,
+		MaxInvites:        settings.AntiSpamMaxInvites,
+		MediaEnabled:      settings.AntiSpamMediaEnabled,
+		MaxAttachments:    settings.AntiSpamMaxAttachments,
+		MaxEmoji:          settings.AntiSpamMaxEmoji,
+		ZalgoEnabled:      settings.AntiSpamZalgoEnabled,
+		MaxCombiningMarks: settings.AntiSpamMaxCombiningMarks,
+		BurstEnabled:      settings.AntiSpamBurstEnabled,
+		BurstCount:        settings.AntiSpamBurstCount,
+		BurstSeconds:      settings.AntiSpamBurstSeconds,
+
+		CoordinatedEnabled:       settings.AntiSpamCoordinatedEnabled,
+		CoordinatedAuthors:       settings.AntiSpamCoordinatedAuthors,
+		CoordinatedWindowSeconds: settings.AntiSpamCoordinatedWindowSeconds,
+		CoordinatedBan:           settings.AntiSpamCoordinatedBan,
+
+		PhishingEnabled: settings.AntiSpamPhishingEnabled,
+	}
+	for _, a := range antispam.Actions {
+		data.Actions = append(data.Actions, partials.AntiSpamActionOption{Value: string(a), Label: a.Label()})
+	}
+	allow, deny, err := model.GetPhishingDomains(settings.GuildID)
+	if err != nil {
+		slog.Warn("anti-spam: failed to load phishing domains", "guild_id", settings.GuildID, "err", err)
+	}
+	data.PhishingAllow = strings.Join(allow, "\n")
+	data.PhishingDeny = strings.Join(deny, "\n")
+	return data
+}
+
+// parsePhishingDomains reads a textarea of domains, one per line, into
+// their normalized form. On invalid input it returns a user-facing
+// message naming the list.
+func parsePhishingDomains(value, label string) ([]string, string) {
+	var domains []string
+	for line := range strings.Lines(value) {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		d, ok := antispam.NormalizeDomain(line)
+		if !ok {
+			return nil, fmt.Sprintf("%q in %q is not a domain.", line, label)
+		}
+		if !slices.Contains(domains, d) {
+			domains = append(domains, d)
+		}
+	}
+	if len(domains) > maxPhishingDomains {
+		return nil, fmt.Sprintf("%q may list at most %d domains.", label, maxPhishingDomains)
 	}
+	return domains, ""
 }
 
 func handleSaveBanFooter(client *bot.Client) http.HandlerFunc {
@@ -907,14 +1135,42 @@ func handleSaveJoinLeave(client *bot.Client) http.HandlerFunc {
 // templ partials — keep them in sync so client-side HTML5 validation and
 // server-side validation agree.
 const (
-	minAntiSpamCount               = 2
-	maxAntiSpamCount               = 10
-	minAntiSpamCooldownSeconds     = 1
-	maxAntiSpamCooldownSeconds     = 60
+	minAntiSpamCount              = 2
+	maxAntiSpamCount              = 10
+	minAntiSpamCooldownSeconds    = 1
+	maxAntiSpamCooldownSeconds    = 60
+	minAntiSpamTimeoutMinutes     = 1
+	maxAntiSpamTimeoutMinutes     = 40320 // 28 days, Discord's longest timeout
+	minAntiSpamInfractionSeverity = 0.0
+	maxAntiSpamInfractionSeverity = 10.0
+	minAntiSpamBurstCount         = 2
+	maxAntiSpamBurstCount         = 20
+	minAntiSpamBurstSeconds       = 1
+	maxAntiSpamBurstSeconds       = 30
+	minAntiSpamMaxMentions        = 1
+	maxAntiSpamMaxMentions        = 50
+	minAntiSpamMaxInvites         = 0
+	maxAntiSpamMaxInvites         = 10
+	minAntiSpamMaxAttachments     = 1
+	maxAntiSpamMaxAttachments     = 10
+	minAntiSpamMaxEmoji           = 1
+	maxAntiSpamMaxEmoji           = 100
+	minAntiSpamMaxCombiningMarks  = 1
+	maxAntiSpamMaxCombiningMarks  = 20
+	minAntiSpamCoordinatedAuthors = 2
+	maxAntiSpamCoordinatedAuthors = 50
+	// The upper bound mirrors maxCoordinatedWindow in the listeners
+	// package, which forgets campaigns quiet for longer.
+	minAntiSpamCoordinatedWindowSeconds = 10
+	maxAntiSpamCoordinatedWindowSeconds = 600
+	// Per list; the bot-wide blocklist is where long lists belong.
+	maxPhishingDomains             = 500
 	minInfractionHalfLifeDays      = 0.0
 	maxInfractionHalfLifeDays      = 365.0
 	minNotifyWarnSeverityThreshold = 0.0
 	maxNotifyWarnSeverityThreshold = 100.0
+	minAutoModInfractionSeverity   = 0.0
+	maxAutoModInfractionSeverity   = 10.0
 	// Cap on raw V2 JSON kept in the DB when the V2 toggle is off (the
 	// user's in-flight draft). Real Discord component payloads are
 	// kilobytes; 32 KiB leaves headroom without letting unbounded garbage
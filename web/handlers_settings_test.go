@@ -182,6 +182,18 @@ func TestParseFloat(t *testing.T) {
 	})
 }
 
+func TestParsePhishingDomains(t *testing.T) {
+	domains, message := parsePhishingDomains("Example.com\r\n\n  https://www.scam.example/login \nexample.com\nexämple.com", "Also block")
+	assert.Empty(t, message)
+	assert.Equal(t, []string{"example.com", "scam.example", "xn--exmple-cua.com"}, domains)
+
+	_, message = parsePhishingDomains("example.com\nnot a domain", "Also block")
+	assert.Equal(t, `"not a domain" in "Also block" is not a domain.`, message)
+
+	_, message = parsePhishingDomains(strings.Repeat("a.example\n", 2)+"localhost", "Never block")
+	assert.Contains(t, message, "localhost")
+}
+
 // Sanity check: the bound constants in this file must stay in sync with the
 // human-readable error messages in handleSaveAntiSpam / handleSaveInfractions.
 // If someone bumps maxAntiSpamCount to 20 but forgets to update the "between
@@ -191,6 +203,8 @@ func TestSettingsBoundsMatchErrorMessages(t *testing.T) {
 	assert.Equal(t, 10, maxAntiSpamCount, "anti-spam error text says 'between 2 and 10'")
 	assert.Equal(t, 1, minAntiSpamCooldownSeconds, "cooldown error text says 'between 1 and 60'")
 	assert.Equal(t, 60, maxAntiSpamCooldownSeconds, "cooldown error text says 'between 1 and 60'")
+	assert.Equal(t, 0.0, minAntiSpamInfractionSeverity, "anti-spam severity error text says 'between 0 and 10'")
+	assert.Equal(t, 10.0, maxAntiSpamInfractionSeverity, "anti-spam severity error text says 'between 0 and 10'")
 	assert.Equal(t, 0.0, minInfractionHalfLifeDays, "half-life error text says 'between 0 and 365'")
 	assert.Equal(t, 365.0, maxInfractionHalfLifeDays, "half-life error text says 'between 0 and 365'")
 	assert.Equal(t, 0.0, minNotifyWarnSeverityThreshold, "severity error text says 'between 0 and 100'")
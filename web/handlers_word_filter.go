@@ -0,0 +1,262 @@
+package web
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/disgoorg/disgo/bot"
+
+	"github.com/NLLCommunity/heimdallr/model"
+	"github.com/NLLCommunity/heimdallr/web/templates/layouts"
+	"github.com/NLLCommunity/heimdallr/web/templates/pages"
+	"github.com/NLLCommunity/heimdallr/web/templates/partials"
+	"github.com/NLLCommunity/heimdallr/wordfilter"
+)
+
+const (
+	minWordFilterSeverity = 0.0
+	maxWordFilterSeverity = 10.0
+)
+
+// handleWordFilter renders the word filter page.
+func handleWordFilter(client *bot.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := sessionFromContext(r.Context())
+		guildIDStr := r.PathValue("id")
+		guildID, ok := checkGuildAdmin(w, r, client, guildIDStr)
+		if !ok {
+			return
+		}
+
+		settings, err := model.GetGuildSettings(guildID)
+		if err != nil {
+			http.Error(w, "failed to load settings", http.StatusInternalServerError)
+			return
+		}
+
+		guild, _ := client.Caches.Guild(guildID)
+		nav := layouts.NavData{
+			User:      session,
+			GuildID:   guildIDStr,
+			GuildName: guild.Name,
+			IsAdmin:   true,
+			IsPostMod: true,
+		}
+
+		renderSafe(w, r, pages.WordFilter(nav, wordFilterData(guildIDStr, settings)))
+	}
+}
+
+// handleSaveWordFilterSettings saves the filter's enabled toggle.
+func handleSaveWordFilterSettings(client *bot.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		guildIDStr := r.PathValue("id")
+		guildID, ok := checkGuildAdmin(w, r, client, guildIDStr)
+		if !ok {
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "invalid form data", http.StatusBadRequest)
+			return
+		}
+		settings, err := model.GetGuildSettings(guildID)
+		if err != nil {
+			http.Error(w, "failed to load settings", http.StatusInternalServerError)
+			return
+		}
+
+		settings.WordFilterEnabled = r.FormValue("enabled") == "true"
+		data := wordFilterData(guildIDStr, settings)
+		if err := model.UpdateGuildSettingsColumns(settings, "WordFilterEnabled"); err != nil {
+			slog.Error("failed to save word filter settings", "error", err)
+			data.Error = "Failed to save settings."
+			renderSafe(w, r, partials.WordFilter(data))
+			return
+		}
+		logSettingsUpdate(sessionFromContext(r.Context()), guildID, "word_filter", map[string]any{
+			"enabled": settings.WordFilterEnabled,
+		})
+
+		data.Saved = true
+		renderSafe(w, r, partials.WordFilter(data))
+	}
+}
+
+// handleAddWordFilterEntry adds an entry to the guild's filter.
+func handleAddWordFilterEntry(client *bot.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		session := sessionFromContext(r.Context())
+		guildIDStr := r.PathValue("id")
+		guildID, ok := checkGuildAdmin(w, r, client, guildIDStr)
+		if !ok {
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			http.Error(w, "invalid form data", http.StatusBadRequest)
+			return
+		}
+		settings, err := model.GetGuildSettings(guildID)
+		if err != nil {
+			http.Error(w, "failed to load settings", http.StatusInternalServerError)
+			return
+		}
+
+		renderError := func(message string) {
+			data := wordFilterData(guildIDStr, settings)
+			data.Error = message
+			renderSafe(w, r, partials.WordFilter(data))
+		}
+
+		entry, message := parseWordFilterEntry(r)
+		if message != "" {
+			renderError(message)
+			return
+		}
+		existing, err := model.GetWordFilterEntries(guildID)
+		if err != nil {
+			slog.Error("failed to load word filter entries", "error", err)
+			renderError("Failed to save the entry.")
+			return
+		}
+		if len(existing) >= wordfilter.MaxEntries {
+			renderError("The filter already has the maximum of " + strconv.Itoa(wordfilter.MaxEntries) + " entries.")
+			return
+		}
+
+		entry.GuildID = guildID
+		entry.CreatedBy = session.UserID
+		if err := model.CreateWordFilterEntry(&entry); err != nil {
+			slog.Error("failed to save word filter entry", "error", err)
+			renderError("Failed to save the entry.")
+			return
+		}
+		wordfilter.Invalidate(guildID)
+		logSettingsUpdate(session, guildID, "word_filter", map[string]any{
+			"added_pattern": entry.Pattern,
+			"regex":         entry.Regex,
+			"whole_word":    entry.WholeWord,
+			"action":        entry.Action,
+			"severity":      entry.Severity,
+		})
+
+		data := wordFilterData(guildIDStr, settings)
+		data.Message = "Entry added."
+		renderSafe(w, r, partials.WordFilter(data))
+	}
+}
+
+// handleDeleteWordFilterEntry removes an entry from the guild's filter.
+func handleDeleteWordFilterEntry(client *bot.Client) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		guildIDStr := r.PathValue("id")
+		guildID, ok := checkGuildAdmin(w, r, client, guildIDStr)
+		if !ok {
+			return
+		}
+
+		entryID, err := strconv.ParseUint(r.PathValue("entryID"), 10, 64)
+		if err != nil {
+			http.Error(w, "invalid entry ID", http.StatusBadRequest)
+			return
+		}
+		settings, err := model.GetGuildSettings(guildID)
+		if err != nil {
+			http.Error(w, "failed to load settings", http.StatusInternalServerError)
+			return
+		}
+
+		pattern := ""
+		if entries, err := model.GetWordFilterEntries(guildID); err == nil {
+			if i := slices.IndexFunc(entries, func(e model.WordFilterEntry) bool { return e.ID == uint(entryID) }); i >= 0 {
+				pattern = entries[i].Pattern
+			}
+		}
+
+		deleted, err := model.DeleteWordFilterEntry(guildID, uint(entryID))
+		if err != nil {
+			slog.Error("failed to delete word filter entry", "error", err)
+			data := wordFilterData(guildIDStr, settings)
+			data.Error = "Failed to remove the entry."
+			renderSafe(w, r, partials.WordFilter(data))
+			return
+		}
+		if deleted {
+			wordfilter.Invalidate(guildID)
+			logSettingsUpdate(sessionFromContext(r.Context()), guildID, "word_filter", map[string]any{
+				"removed_pattern": pattern,
+			})
+		}
+
+		data := wordFilterData(guildIDStr, settings)
+		if deleted {
+			data.Message = "Entry removed."
+		}
+		renderSafe(w, r, partials.WordFilter(data))
+	}
+}
+
+// parseWordFilterEntry reads the add form. On invalid input it returns a
+// user-facing message.
+func parseWordFilterEntry(r *http.Request) (model.WordFilterEntry, string) {
+	entry := model.WordFilterEntry{Pattern: strings.TrimSpace(r.FormValue("pattern"))}
+	switch r.FormValue("matching") {
+	case "anywhere":
+	case "whole_word":
+		entry.WholeWord = true
+	case "regex":
+		entry.Regex = true
+	default:
+		return entry, "Invalid matching mode."
+	}
+	if err := wordfilter.Validate(entry.Pattern, entry.Regex); err != nil {
+		return entry, "Invalid pattern: " + err.Error() + "."
+	}
+
+	action := wordfilter.Action(r.FormValue("action"))
+	if !slices.Contains(wordfilter.Actions, action) {
+		return entry, "Invalid action."
+	}
+	entry.Action = string(action)
+	if action == wordfilter.ActionWarn {
+		severity, err := parseFloat(r.FormValue("severity"))
+		if err != nil || severity < minWordFilterSeverity || severity > maxWordFilterSeverity {
+			return entry, "Warning severity must be between 0 and 10."
+		}
+		entry.Severity = severity
+	}
+	return entry, ""
+}
+
+func wordFilterData(guildIDStr string, settings *model.GuildSettings) partials.WordFilterData {
+	data := partials.WordFilterData{
+		GuildID: guildIDStr,
+		Enabled: settings.WordFilterEnabled,
+	}
+	for _, a := range wordfilter.Actions {
+		data.Actions = append(data.Actions, partials.WordFilterActionOption{Value: string(a), Label: a.Label()})
+	}
+
+	entries, err := model.GetWordFilterEntries(settings.GuildID)
+	if err != nil {
+		slog.Warn("word filter: failed to load entries", "guild_id", settings.GuildID, "err", err)
+		data.Error = "Failed to load the filter's entries."
+		return data
+	}
+	for _, e := range entries {
+		data.Rows = append(data.Rows, partials.WordFilterRow{
+			ID:          strconv.FormatUint(uint64(e.ID), 10),
+			Pattern:     e.Pattern,
+			Regex:       e.Regex,
+			WholeWord:   e.WholeWord,
+			ActionLabel: wordfilter.Action(e.Action).Label(),
+			Severity:    e.Severity,
+		})
+	}
+	data.Full = len(entries) >= wordfilter.MaxEntries
+	return data
+}
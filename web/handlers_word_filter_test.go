@@ -0,0 +1,51 @@
+package web
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+func parseWordFilterForm(t *testing.T, form url.Values) (model.WordFilterEntry, string) {
+	t.Helper()
+	r := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	require.NoError(t, r.ParseForm())
+	return parseWordFilterEntry(r)
+}
+
+func TestParseWordFilterEntry(t *testing.T) {
+	entry, msg := parseWordFilterForm(t, url.Values{
+		"pattern": {"  free nitro "}, "matching": {"whole_word"}, "action": {"warn"}, "severity": {"2.5"},
+	})
+	require.Empty(t, msg)
+	assert.Equal(t, model.WordFilterEntry{Pattern: "free nitro", WholeWord: true, Action: "warn", Severity: 2.5}, entry)
+
+	entry, msg = parseWordFilterForm(t, url.Values{
+		"pattern": {"bad"}, "matching": {"anywhere"}, "action": {"delete"}, "severity": {"5"},
+	})
+	require.Empty(t, msg)
+	assert.Zero(t, entry.Severity, "severity is only kept for warnings")
+}
+
+func TestParseWordFilterEntry_Invalid(t *testing.T) {
+	cases := map[string]url.Values{
+		"empty pattern":     {"pattern": {" "}, "matching": {"anywhere"}, "action": {"delete"}},
+		"bad regex":         {"pattern": {"("}, "matching": {"regex"}, "action": {"delete"}},
+		"unknown matching":  {"pattern": {"bad"}, "matching": {"fuzzy"}, "action": {"delete"}},
+		"unknown action":    {"pattern": {"bad"}, "matching": {"anywhere"}, "action": {"ban"}},
+		"severity too high": {"pattern": {"bad"}, "matching": {"anywhere"}, "action": {"warn"}, "severity": {"11"}},
+	}
+	for name, form := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, msg := parseWordFilterForm(t, form)
+			assert.NotEmpty(t, msg)
+		})
+	}
+}
@@ -90,14 +90,32 @@ func StartServer(ctx context.Context, addr string, client *bot.Client) error {
 	mux.HandleFunc("POST /guild/{id}/settings/mod-channel", handleSaveModChannel(client))
 	mux.HandleFunc("POST /guild/{id}/settings/infractions", handleSaveInfractions(client))
 	mux.HandleFunc("POST /guild/{id}/settings/anti-spam", handleSaveAntiSpam(client))
+	mux.HandleFunc("POST /guild/{id}/settings/anti-spam-exemptions", handleSaveAntiSpamExemptions(client))
 	mux.HandleFunc("POST /guild/{id}/settings/ban-footer", handleSaveBanFooter(client))
 	mux.HandleFunc("POST /guild/{id}/settings/modmail", handleSaveModmail(client))
 	mux.HandleFunc("POST /guild/{id}/settings/gatekeep", handleSaveGatekeep(client))
 	mux.HandleFunc("POST /guild/{id}/settings/join-leave", handleSaveJoinLeave(client))
 	mux.HandleFunc("POST /guild/{id}/settings/posts", handleSavePosts(client))
+	mux.HandleFunc("POST /guild/{id}/settings/raid", handleSaveRaid(client))
+	mux.HandleFunc("POST /guild/{id}/lockdown/start", handleLockdownStart(client))
+	mux.HandleFunc("POST /guild/{id}/lockdown/end", handleLockdownEnd(client))
+
+	mux.HandleFunc("GET /guild/{id}/gatekeep", handleGatekeepQueue(client))
+	mux.HandleFunc("POST /guild/{id}/gatekeep", handleGatekeepAction(client))
+	mux.HandleFunc("GET /guild/{id}/invites", handleInvites(client))
+	mux.HandleFunc("GET /guild/{id}/word-filter", handleWordFilter(client))
+	mux.HandleFunc("POST /guild/{id}/word-filter", handleAddWordFilterEntry(client))
+	mux.HandleFunc("POST /guild/{id}/word-filter/settings", handleSaveWordFilterSettings(client))
+	mux.HandleFunc("POST /guild/{id}/word-filter/{entryID}/delete", handleDeleteWordFilterEntry(client))
 
 	mux.HandleFunc("GET /guild/{id}/auditlog", handleAuditLog(client))
+	mux.HandleFunc("GET /guild/{id}/auditlog/export", handleAuditLogExport(client))
+	mux.HandleFunc("GET /guild/{id}/auditlog/integrity", handleAuditLogIntegrity(client))
+	mux.HandleFunc("GET /guild/{id}/auditlog/archive", handleAuditLogArchive(client))
+	mux.HandleFunc("GET /guild/{id}/auditlog/voice", handleAuditLogVoice(client))
 	mux.HandleFunc("POST /guild/{id}/settings/audit-log", handleSaveAuditLog(client))
+	mux.HandleFunc("POST /guild/{id}/settings/message-log", handleSaveMessageLog(client))
+	mux.HandleFunc("POST /guild/{id}/settings/audit-stream", handleSaveAuditStream(client))
 
 	// Per-session rate limiter for sandbox sends — keyed by user ID rather
 	// than IP, since the threat is admin abuse, not anonymous flooding.
@@ -1,6 +1,6 @@
 // Code generated by templ - DO NOT EDIT.
 
-// templ: version: v0.3.1001
+// templ: version: v0.3.1020
 package pages
 
 //lint:file-ignore SA4006 This context is only used if a nested component is present.
@@ -21,10 +21,14 @@ var SidebarSections = []SidebarSection{
 	{"gatekeep", "Gatekeep"},
 	{"join-leave", "Join/Leave Messages"},
 	{"anti-spam", "Anti-Spam"},
+	{"anti-spam-exemptions", "Anti-Spam Exemptions"},
+	{"raid-protection", "Raid Protection"},
 	{"ban-footer", "Ban Footer"},
 	{"modmail", "Modmail"},
 	{"posts", "Posts"},
 	{"audit-log", "Audit Log"},
+	{"message-log", "Message Log"},
+	{"audit-stream", "Audit Log Channels"},
 }
 
 func Dashboard(nav layouts.NavData, guildID string, settingsContent templ.Component) templ.Component {
@@ -72,7 +76,7 @@ func Dashboard(nav layouts.NavData, guildID string, settingsContent templ.Compon
 				var templ_7745c5c3_Var3 templ.SafeURL
 				templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinURLErrs(templ.SafeURL("#" + s.ID))
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/pages/dashboard.templ`, Line: 29, Col: 46}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/pages/dashboard.templ`, Line: 33, Col: 46}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
 				if templ_7745c5c3_Err != nil {
@@ -85,7 +89,7 @@ func Dashboard(nav layouts.NavData, guildID string, settingsContent templ.Compon
 				var templ_7745c5c3_Var4 string
 				templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.JoinStringErrs(s.Label)
 				if templ_7745c5c3_Err != nil {
-					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/pages/dashboard.templ`, Line: 29, Col: 58}
+					return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/pages/dashboard.templ`, Line: 33, Col: 58}
 				}
 				_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var4))
 				if templ_7745c5c3_Err != nil {
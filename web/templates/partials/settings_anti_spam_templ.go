@@ -1,6 +1,6 @@
 // Code generated by templ - DO NOT EDIT.
 
-// templ: version: v0.3.1001
+// templ: version: v0.3.1020
 package partials
 
 //lint:file-ignore SA4006 This context is only used if a nested component is present.
@@ -10,11 +10,47 @@ import templruntime "github.com/a-h/templ/runtime"
 
 import "github.com/NLLCommunity/heimdallr/web/templates/components"
 
+// AntiSpamActionOption is one choice in the anti-spam action select.
+type AntiSpamActionOption struct {
+	Value string
+	Label string
+}
+
 type AntiSpamData struct {
-	GuildID         string
-	Enabled         bool
-	Count           int
-	CooldownSeconds int
+	GuildID                  string
+	Enabled                  bool
+	Count                    int
+	CooldownSeconds          int
+	ObserveOnly              bool
+	Action                   string
+	Actions                  []AntiSpamActionOption
+	TimeoutMinutes           int
+	QuarantineRole           string
+	Roles                    []components.RoleInfo
+	InfractionEnabled        bool
+	InfractionSeverity       float64
+	MentionsEnabled          bool
+	MaxMentions              int
+	InvitesEnabled           bool
+	MaxInvites               int
+	MediaEnabled             bool
+	MaxAttachments           int
+	MaxEmoji                 int
+	ZalgoEnabled             bool
+	MaxCombiningMarks        int
+	BurstEnabled             bool
+	BurstCount               int
+	BurstSeconds             int
+	CoordinatedEnabled       bool
+	CoordinatedAuthors       int
+	CoordinatedWindowSeconds int
+	CoordinatedBan           bool
+
+	// PhishingAllow and PhishingDeny hold the guild's domains one per
+	// line, as edited in the textareas.
+	PhishingEnabled bool
+	PhishingAllow   string
+	PhishingDeny    string
 	SaveSuccess     bool
 	SaveError       string
 }
@@ -47,7 +83,7 @@ func SettingsAntiSpam(data AntiSpamData) templ.Component {
 		var templ_7745c5c3_Var2 templ.SafeURL
 		templ_7745c5c3_Var2, templ_7745c5c3_Err = templ.JoinURLErrs(templ.SafeURL("/guild/" + data.GuildID + "/settings/anti-spam"))
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/partials/settings_anti_spam.templ`, Line: 19, Col: 75}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/partials/settings_anti_spam.templ`, Line: 55, Col: 75}
 		}
 		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var2))
 		if templ_7745c5c3_Err != nil {
@@ -58,11 +94,11 @@ func SettingsAntiSpam(data AntiSpamData) templ.Component {
 			return templ_7745c5c3_Err
 		}
 		var templ_7745c5c3_Var3 string
-		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.JoinStringErrs("/guild/" + data.GuildID + "/settings/anti-spam")
+		templ_7745c5c3_Var3, templ_7745c5c3_Err = templ.ResolveAttributeValue("/guild/" + data.GuildID + "/settings/anti-spam")
 		if templ_7745c5c3_Err != nil {
-			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/partials/settings_anti_spam.templ`, Line: 20, Col: 61}
+			return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/partials/settings_anti_spam.templ`, Line: 56, Col: 61}
 		}
-		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var3))
+		_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var3)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -82,11 +118,11 @@ func SettingsAntiSpam(data AntiSpamData) templ.Component {
 				return templ_7745c5c3_Err
 			}
 		}
-		templ_7745c5c3_Err = components.ToggleField("enabled", "Enable anti-spam", "", data.Enabled).Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = components.ToggleField("enabled", "Enable anti-spam", "Each rule below adds points to a member's spam score. Repeating a message adds 1. The response below is taken when a member's score reaches the threshold.", data.Enabled).Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = components.NumberField("count", "Message count threshold", float64(data.Count), 2, 10, 1).Render(ctx, templ_7745c5c3_Buffer)
+		templ_7745c5c3_Err = components.NumberField("count", "Spam score threshold", float64(data.Count), 2, 10, 1).Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
@@ -94,11 +130,189 @@ func SettingsAntiSpam(data AntiSpamData) templ.Component {
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "<hr><h4>Response</h4>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.ToggleField("observe_only", "Observe only", "Report to the moderator channel what would have happened, without deleting or punishing anything. Useful while tuning thresholds.", data.ObserveOnly).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 5, "<label for=\"action\">Action</label> <select id=\"action\" name=\"action\">")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		for _, opt := range data.Actions {
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 6, "<option value=\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var4 string
+			templ_7745c5c3_Var4, templ_7745c5c3_Err = templ.ResolveAttributeValue(opt.Value)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/partials/settings_anti_spam.templ`, Line: 76, Col: 30}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ_7745c5c3_Var4)
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 7, "\"")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			if opt.Value == data.Action {
+				templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 8, " selected")
+				if templ_7745c5c3_Err != nil {
+					return templ_7745c5c3_Err
+				}
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 9, ">")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			var templ_7745c5c3_Var5 string
+			templ_7745c5c3_Var5, templ_7745c5c3_Err = templ.JoinStringErrs(opt.Label)
+			if templ_7745c5c3_Err != nil {
+				return templ.Error{Err: templ_7745c5c3_Err, FileName: `web/templates/partials/settings_anti_spam.templ`, Line: 76, Col: 83}
+			}
+			_, templ_7745c5c3_Err = templ_7745c5c3_Buffer.WriteString(templ.EscapeString(templ_7745c5c3_Var5))
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+			templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 10, "</option>")
+			if templ_7745c5c3_Err != nil {
+				return templ_7745c5c3_Err
+			}
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 11, "</select>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.NumberField("timeout_minutes", "Timeout length (minutes)", float64(data.TimeoutMinutes), 1, 40320, 1).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.RoleSelect("quarantine_role", "Quarantine role", data.Roles, data.QuarantineRole).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.ToggleField("infraction_enabled", "Record an infraction", "Adds a silent infraction for every member the anti-spam acts on, so repeat spammers build up infraction weight.", data.InfractionEnabled).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.NumberField("infraction_severity", "Infraction severity", data.InfractionSeverity, 0, 10, 0.1).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 12, "<hr><h4>Message Bursts</h4>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.ToggleField("burst_enabled", "Detect message bursts", "Adds 1 point for every message past the limit, whatever it says.", data.BurstEnabled).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.NumberField("burst_count", "Messages allowed…", float64(data.BurstCount), 2, 20, 1).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.NumberField("burst_seconds", "…within this many seconds", float64(data.BurstSeconds), 1, 30, 1).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 13, "<hr><h4>Mention Flooding</h4>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.ToggleField("mentions_enabled", "Detect mention flooding", "Adds 3 points for a message that mentions too many users or roles. @everyone and @here count as one mention.", data.MentionsEnabled).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.NumberField("max_mentions", "Mentions allowed per message", float64(data.MaxMentions), 1, 50, 1).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 14, "<hr><h4>Invite Links</h4>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.ToggleField("invites_enabled", "Detect Discord invite links", "Adds 2 points for a message with too many invite links.", data.InvitesEnabled).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.NumberField("max_invites", "Invite links allowed per message", float64(data.MaxInvites), 0, 10, 1).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 15, "<hr><h4>Attachments and Emoji</h4>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.ToggleField("media_enabled", "Detect excessive attachments or emoji", "Adds 2 points for a message over either limit.", data.MediaEnabled).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.NumberField("max_attachments", "Attachments allowed per message", float64(data.MaxAttachments), 1, 10, 1).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.NumberField("max_emoji", "Emoji allowed per message", float64(data.MaxEmoji), 1, 100, 1).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 16, "<hr><h4>Zalgo Text</h4>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.ToggleField("zalgo_enabled", "Detect zalgo and invisible-character abuse", "Adds 2 points for a message with too many combining or invisible characters stacked on one character.", data.ZalgoEnabled).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.NumberField("max_combining_marks", "Combining characters allowed on one character", float64(data.MaxCombiningMarks), 1, 20, 1).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 17, "<hr><h4>Coordinated Spam</h4>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.ToggleField("coordinated_enabled", "Detect coordinated spam", "Takes the action above against every account at once when several post the same message, even if each posts it only once. Their messages are deleted and one report is sent.", data.CoordinatedEnabled).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.NumberField("coordinated_authors", "Act when this many accounts post the same message…", float64(data.CoordinatedAuthors), 2, 50, 1).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.NumberField("coordinated_window_seconds", "…within this many seconds", float64(data.CoordinatedWindowSeconds), 10, 600, 1).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.ToggleField("coordinated_ban", "Always ban coordinated spammers", "Ban them whatever the action above is.", data.CoordinatedBan).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 18, "<hr><h4>Scam Links</h4>")
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.ToggleField("phishing_enabled", "Block scam links", "Takes the action above as soon as a member links to a known scam or phishing domain, without waiting for the score threshold. Subdomains and look-alike international domains are matched too.", data.PhishingEnabled).Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.TextareaField("phishing_deny", "Also block these domains", data.PhishingDeny, "One domain per line, added to the bot's blocklist for this server.").Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
+		templ_7745c5c3_Err = components.TextareaField("phishing_allow", "Never block these domains", data.PhishingAllow, "One domain per line. Overrides the bot's blocklist and the list above.").Render(ctx, templ_7745c5c3_Buffer)
+		if templ_7745c5c3_Err != nil {
+			return templ_7745c5c3_Err
+		}
 		templ_7745c5c3_Err = components.SaveButton().Render(ctx, templ_7745c5c3_Buffer)
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
-		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 4, "</form></section>")
+		templ_7745c5c3_Err = templruntime.WriteString(templ_7745c5c3_Buffer, 19, "</form></section>")
 		if templ_7745c5c3_Err != nil {
 			return templ_7745c5c3_Err
 		}
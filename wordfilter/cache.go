@@ -0,0 +1,34 @@
+package wordfilter
+
+import (
+	"sync"
+
+	"github.com/disgoorg/snowflake/v2"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// filters caches each guild's compiled filter, since every message is
+// checked against it. The dashboard calls Invalidate after changing a
+// guild's entries.
+var filters sync.Map // snowflake.ID -> *Filter
+
+// For returns the guild's compiled filter, loading it on first use.
+func For(guildID snowflake.ID) (*Filter, error) {
+	if f, ok := filters.Load(guildID); ok {
+		return f.(*Filter), nil
+	}
+	entries, err := model.GetWordFilterEntries(guildID)
+	if err != nil {
+		return nil, err
+	}
+	f := Compile(entries)
+	filters.Store(guildID, f)
+	return f, nil
+}
+
+// Invalidate drops the guild's cached filter so the next message reloads
+// it.
+func Invalidate(guildID snowflake.ID) {
+	filters.Delete(guildID)
+}
@@ -0,0 +1,281 @@
+// Package wordfilter matches message content against a guild's list of
+// blocked words and regular expressions.
+package wordfilter
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+// Action is what happens to a message that matches an entry. Stored as
+// model.WordFilterEntry.Action.
+type Action string
+
+const (
+	ActionDelete Action = "delete"
+	ActionWarn   Action = "warn"
+	ActionAlert  Action = "alert"
+)
+
+// Actions lists every action in the order the dashboard offers them.
+var Actions = []Action{ActionDelete, ActionWarn, ActionAlert}
+
+// Label is the action's name on the dashboard.
+func (a Action) Label() string {
+	switch a {
+	case ActionDelete:
+		return "Delete"
+	case ActionWarn:
+		return "Delete and warn"
+	case ActionAlert:
+		return "Alert moderators only"
+	}
+	return string(a)
+}
+
+// rank orders actions by how much they do, so a message matching several
+// entries gets the strongest of their actions.
+func (a Action) rank() int {
+	switch a {
+	case ActionWarn:
+		return 2
+	case ActionDelete:
+		return 1
+	}
+	return 0
+}
+
+const (
+	MaxPatternLength = 200
+	// MaxEntries is the most entries a guild's filter may hold.
+	MaxEntries = 200
+)
+
+// leetSubstitutions are the common single-character leetspeak
+// substitutions and the letter each stands for.
+var leetSubstitutions = []struct{ from, to rune }{
+	{'0', 'o'},
+	{'1', 'i'},
+	{'3', 'e'},
+	{'4', 'a'},
+	{'5', 's'},
+	{'7', 't'},
+	{'8', 'b'},
+	{'@', 'a'},
+	{'$', 's'},
+	{'!', 'i'},
+	{'|', 'i'},
+	{'+', 't'},
+}
+
+// leetReplacer undoes leetSubstitutions.
+var leetReplacer = func() *strings.Replacer {
+	var pairs []string
+	for _, s := range leetSubstitutions {
+		pairs = append(pairs, string(s.from), string(s.to))
+	}
+	return strings.NewReplacer(pairs...)
+}()
+
+// separators are what is typically put between the letters of a word to
+// slip it past a filter, as in "b.a.d" or "b a d", besides separating
+// words.
+const separators = " \u00A0\t\n.,-_*~'"
+
+func isSeparator(r rune) bool {
+	return strings.ContainsRune(separators, r)
+}
+
+// tokenize splits s into words at separators, joining runs of two or more
+// single characters back into one word so "b a d" and "b.a.d" read as
+// "bad". The words are rejoined with single spaces, so a word can only be
+// matched within a word and never across the boundary of two, as "bad" in
+// "grab a dog" would be with the separators simply removed.
+func tokenize(s string) string {
+	fields := strings.FieldsFunc(s, isSeparator)
+	words := make([]string, 0, len(fields))
+	for i := 0; i < len(fields); {
+		j := i
+		for j < len(fields) && utf8.RuneCountInString(fields[j]) == 1 {
+			j++
+		}
+		if j-i >= 2 {
+			words = append(words, strings.Join(fields[i:j], ""))
+			i = j
+			continue
+		}
+		words = append(words, fields[i])
+		i++
+	}
+	return strings.Join(words, " ")
+}
+
+// Normalize lowercases s, drops combining and invisible characters and
+// undoes leetspeak, so "Fr33 N!tro" and "free nitro" compare equal.
+func Normalize(s string) string {
+	return leetReplacer.Replace(fold(s))
+}
+
+// fold lowercases s and drops combining and invisible characters.
+func fold(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, s)
+}
+
+// leetPattern is a regular expression matching word, a normalized word,
+// with any of its letters written as leetspeak. Whole words can't be
+// matched in normalized text because normalizing also rewrites the
+// punctuation around them: "bad!" would become "badi".
+func leetPattern(word string) string {
+	var b strings.Builder
+	for _, r := range word {
+		var class []rune
+		for _, s := range leetSubstitutions {
+			if s.to == r {
+				class = append(class, s.from)
+			}
+		}
+		if len(class) == 0 {
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			continue
+		}
+		b.WriteString("[" + regexp.QuoteMeta(string(r)))
+		for _, c := range class {
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+		b.WriteString("]")
+	}
+	return b.String()
+}
+
+// Validate checks a pattern before it is added to a filter. The returned
+// error is user-facing.
+func Validate(pattern string, regex bool) error {
+	if strings.TrimSpace(pattern) == "" {
+		return errors.New("the pattern is empty")
+	}
+	if len(pattern) > MaxPatternLength {
+		return fmt.Errorf("the pattern is longer than %d characters", MaxPatternLength)
+	}
+	if regex {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid regular expression: %w", err)
+		}
+	}
+	return nil
+}
+
+type compiledEntry struct {
+	entry model.WordFilterEntry
+	re    *regexp.Regexp
+	// word is the normalized, tokenized pattern of a non-regex,
+	// non-whole-word entry.
+	word string
+}
+
+// Filter is a guild's entries, compiled for matching.
+type Filter struct {
+	entries []compiledEntry
+}
+
+// Compile prepares entries for matching. Entries that don't compile are
+// logged and skipped, so one bad row can't disable the whole filter.
+func Compile(entries []model.WordFilterEntry) *Filter {
+	f := &Filter{}
+	for _, e := range entries {
+		c := compiledEntry{entry: e}
+		switch {
+		case e.Regex:
+			re, err := regexp.Compile("(?i)" + e.Pattern)
+			if err != nil {
+				slog.Warn("Skipping invalid word filter regex.", "err", err, "guild", e.GuildID, "entry", e.ID)
+				continue
+			}
+			c.re = re
+		case e.WholeWord:
+			// Words are bounded by anything that isn't a letter or digit;
+			// \b only knows ASCII.
+			c.re = regexp.MustCompile(`(?:^|[^\pL\pN])(` + leetPattern(Normalize(e.Pattern)) + `)(?:$|[^\pL\pN])`)
+		default:
+			c.word = tokenize(Normalize(e.Pattern))
+			if c.word == "" {
+				continue
+			}
+		}
+		f.entries = append(f.entries, c)
+	}
+	return f
+}
+
+// Match is an entry that matched a message, with the text it matched.
+type Match struct {
+	Entry model.WordFilterEntry
+	Text  string
+}
+
+// Action is the match's entry's action.
+func (m Match) Action() Action {
+	return Action(m.Entry.Action)
+}
+
+// Match returns every entry that matches content, in entry order.
+//
+// Regexes are tried on the content as written, then normalized. Whole-word
+// entries are matched with their letters allowed as leetspeak; other words
+// anywhere within the words of the normalized content, where letters
+// separated one by one count as a word, so "b a d" and "badger" match
+// "bad" but "grab a dog" doesn't.
+func (f *Filter) Match(content string) []Match {
+	if f == nil || len(f.entries) == 0 || content == "" {
+		return nil
+	}
+	folded := fold(content)
+	normalized := leetReplacer.Replace(folded)
+	words := tokenize(normalized)
+
+	var matches []Match
+	for _, c := range f.entries {
+		switch {
+		case c.entry.Regex:
+			if text := c.re.FindString(content); text != "" {
+				matches = append(matches, Match{Entry: c.entry, Text: text})
+			} else if text := c.re.FindString(normalized); text != "" {
+				matches = append(matches, Match{Entry: c.entry, Text: text})
+			}
+		case c.re != nil:
+			if m := c.re.FindStringSubmatch(folded); m != nil {
+				matches = append(matches, Match{Entry: c.entry, Text: m[1]})
+			}
+		default:
+			if strings.Contains(words, c.word) {
+				matches = append(matches, Match{Entry: c.entry, Text: c.word})
+			}
+		}
+	}
+	return matches
+}
+
+// Strongest returns the match whose action does the most, preferring the
+// highest severity among warnings and the first entry among equals.
+// matches must not be empty.
+func Strongest(matches []Match) Match {
+	best := matches[0]
+	for _, m := range matches[1:] {
+		if m.Action().rank() > best.Action().rank() ||
+			m.Action() == best.Action() && m.Entry.Severity > best.Entry.Severity {
+			best = m
+		}
+	}
+	return best
+}
@@ -0,0 +1,84 @@
+package wordfilter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NLLCommunity/heimdallr/model"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "free nitro", Normalize("Fr33 N!tro"))
+	assert.Equal(t, "bad", Normalize("b\u200bA\u0301d"), "invisible and combining characters are dropped")
+	assert.Equal(t, "assassin", Normalize("@$$a$$1n"))
+}
+
+func TestFilter_Match(t *testing.T) {
+	cases := []struct {
+		name    string
+		entry   model.WordFilterEntry
+		content string
+		want    string
+	}{
+		{"word", model.WordFilterEntry{Pattern: "bad"}, "this is BAD", "bad"},
+		{"word inside another", model.WordFilterEntry{Pattern: "bad"}, "badger", "bad"},
+		{"leetspeak", model.WordFilterEntry{Pattern: "bad"}, "so b4d", "bad"},
+		{"separated letters", model.WordFilterEntry{Pattern: "bad"}, "b.a.d", "bad"},
+		{"spaced letters", model.WordFilterEntry{Pattern: "bad"}, "so b a d", "bad"},
+		{"across words", model.WordFilterEntry{Pattern: "bad"}, "grab a dog", ""},
+		{"across a separator", model.WordFilterEntry{Pattern: "bad"}, "b.ad", ""},
+		{"phrase", model.WordFilterEntry{Pattern: "free  nitro"}, "get free-nitro now", "free nitro"},
+		{"whole word", model.WordFilterEntry{Pattern: "bad", WholeWord: true}, "not bad!", "bad"},
+		{"whole word inside another", model.WordFilterEntry{Pattern: "bad", WholeWord: true}, "badger", ""},
+		{"whole word leetspeak", model.WordFilterEntry{Pattern: "bad", WholeWord: true}, "b4d idea", "b4d"},
+		{"whole word non-ASCII boundary", model.WordFilterEntry{Pattern: "bad", WholeWord: true}, "ébad", ""},
+		{"whole word phrase", model.WordFilterEntry{Pattern: "free nitro", WholeWord: true}, "get FREE NITRO now", "free nitro"},
+		{"regex", model.WordFilterEntry{Pattern: `discord\.gift/\w+`, Regex: true}, "discord.gift/abc", "discord.gift/abc"},
+		{"regex is case-insensitive", model.WordFilterEntry{Pattern: `nitro`, Regex: true}, "NITRO", "NITRO"},
+		{"regex falls back to normalized", model.WordFilterEntry{Pattern: `free\s+nitro`, Regex: true}, "fr33 n1tro", "free nitro"},
+		{"no match", model.WordFilterEntry{Pattern: "bad"}, "all good", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			matches := Compile([]model.WordFilterEntry{tc.entry}).Match(tc.content)
+			if tc.want == "" {
+				assert.Empty(t, matches)
+				return
+			}
+			require.Len(t, matches, 1)
+			assert.Equal(t, tc.want, matches[0].Text)
+		})
+	}
+}
+
+func TestCompile_SkipsInvalidRegex(t *testing.T) {
+	f := Compile([]model.WordFilterEntry{
+		{ID: 1, Pattern: "(", Regex: true},
+		{ID: 2, Pattern: "bad"},
+	})
+	matches := f.Match("bad (")
+	require.Len(t, matches, 1)
+	assert.Equal(t, uint(2), matches[0].Entry.ID)
+}
+
+func TestStrongest(t *testing.T) {
+	alert := Match{Entry: model.WordFilterEntry{ID: 1, Action: string(ActionAlert)}}
+	del := Match{Entry: model.WordFilterEntry{ID: 2, Action: string(ActionDelete)}}
+	warn := Match{Entry: model.WordFilterEntry{ID: 3, Action: string(ActionWarn), Severity: 1}}
+	severeWarn := Match{Entry: model.WordFilterEntry{ID: 4, Action: string(ActionWarn), Severity: 3}}
+
+	assert.Equal(t, del, Strongest([]Match{alert, del}))
+	assert.Equal(t, warn, Strongest([]Match{warn, del, alert}))
+	assert.Equal(t, severeWarn, Strongest([]Match{warn, severeWarn}))
+	assert.Equal(t, alert, Strongest([]Match{alert}))
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate("bad", false))
+	assert.NoError(t, Validate(`free\s+nitro`, true))
+	assert.Error(t, Validate("  ", false))
+	assert.Error(t, Validate("(", true))
+	assert.NoError(t, Validate("(", false), "words aren't regexes")
+}